@@ -0,0 +1,269 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package animation changes values smoothly over time. Animations are built
+// from tweens, which interpolate a single value, combined with Sequence,
+// Parallel and Repeat, and are run by an Animator.
+//
+// Animations are updated on the UI go-routine once per frame, so the functions
+// that set the animated values may safely modify controls.
+package animation
+
+import (
+	"time"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+)
+
+// Forever is the duration of an animation that never finishes.
+const Forever = time.Duration(1<<63 - 1)
+
+// Animation is a change to one or more values over time.
+type Animation interface {
+	// Duration returns the length of the animation, which may be Forever.
+	Duration() time.Duration
+
+	// Update sets the animated values for the time t since the start of the
+	// animation, where 0 <= t <= Duration. Each run of an animation starts with
+	// an Update of 0 and, unless it is cancelled, finishes with an Update of
+	// Duration. Between these t only increases.
+	Update(t time.Duration)
+}
+
+type tween struct {
+	duration time.Duration
+	easing   Easing
+	set      func(f float32)
+}
+
+func (t *tween) Duration() time.Duration {
+	return t.duration
+}
+
+func (t *tween) Update(at time.Duration) {
+	f := float32(1)
+	if at < t.duration {
+		f = float32(at) / float32(t.duration)
+	}
+	t.set(t.easing(f))
+}
+
+func newTween(duration time.Duration, easing Easing, set func(f float32)) Animation {
+	if easing == nil {
+		easing = Linear
+	}
+	return &tween{duration: duration, easing: easing, set: set}
+}
+
+// Float returns an animation that calls set with values from from to to over
+// duration, eased by easing. If easing is nil then Linear is used.
+func Float(from, to float32, duration time.Duration, easing Easing, set func(float32)) Animation {
+	return newTween(duration, easing, func(f float32) {
+		set(math.Lerpf(from, to, f))
+	})
+}
+
+// Point returns an animation that calls set with points from from to to over
+// duration, eased by easing. If easing is nil then Linear is used.
+func Point(from, to math.Point, duration time.Duration, easing Easing, set func(math.Point)) Animation {
+	return newTween(duration, easing, func(f float32) {
+		set(math.Point{
+			X: math.Lerp(from.X, to.X, f),
+			Y: math.Lerp(from.Y, to.Y, f),
+		})
+	})
+}
+
+// Size returns an animation that calls set with sizes from from to to over
+// duration, eased by easing. If easing is nil then Linear is used.
+func Size(from, to math.Size, duration time.Duration, easing Easing, set func(math.Size)) Animation {
+	return newTween(duration, easing, func(f float32) {
+		set(math.Size{
+			W: math.Lerp(from.W, to.W, f),
+			H: math.Lerp(from.H, to.H, f),
+		})
+	})
+}
+
+// Color returns an animation that calls set with colours from from to to over
+// duration, eased by easing. If easing is nil then Linear is used.
+func Color(from, to gxui.Color, duration time.Duration, easing Easing, set func(gxui.Color)) Animation {
+	return newTween(duration, easing, func(f float32) {
+		set(gxui.Color{
+			R: math.Lerpf(from.R, to.R, f),
+			G: math.Lerpf(from.G, to.G, f),
+			B: math.Lerpf(from.B, to.B, f),
+			A: math.Lerpf(from.A, to.A, f),
+		})
+	})
+}
+
+type delay time.Duration
+
+func (d delay) Duration() time.Duration { return time.Duration(d) }
+func (d delay) Update(time.Duration)    {}
+
+// Delay returns an animation that does nothing for duration. It is used to
+// leave gaps in a Sequence.
+func Delay(duration time.Duration) Animation {
+	return delay(duration)
+}
+
+type call func()
+
+func (c call) Duration() time.Duration { return 0 }
+func (c call) Update(time.Duration)    { c() }
+
+// Call returns an animation that calls f once when it is reached. It is
+// typically used at the end of a Sequence to be notified when the animations
+// before it have finished.
+func Call(f func()) Animation {
+	return call(f)
+}
+
+type sequence struct {
+	animations []Animation
+	duration   time.Duration
+	last       time.Duration
+	finished   int // The number of animations that have been updated to their end.
+}
+
+// Sequence returns an animation that runs each of animations in turn.
+func Sequence(animations ...Animation) Animation {
+	s := &sequence{animations: animations}
+	for _, a := range animations {
+		s.duration = add(s.duration, a.Duration())
+	}
+	return s
+}
+
+func (s *sequence) Duration() time.Duration {
+	return s.duration
+}
+
+func (s *sequence) Update(t time.Duration) {
+	if t < s.last {
+		s.finished = 0
+	}
+	s.last = t
+	start := time.Duration(0)
+	for i, a := range s.animations {
+		if t < start {
+			return
+		}
+		d := a.Duration()
+		end := add(start, d)
+		switch {
+		case t < end:
+			a.Update(t - start)
+			return
+		case i >= s.finished:
+			a.Update(d)
+			s.finished = i + 1
+		}
+		start = end
+	}
+}
+
+type parallel struct {
+	animations []Animation
+	duration   time.Duration
+	last       time.Duration
+	finished   []bool
+}
+
+// Parallel returns an animation that runs all of animations at the same time.
+// The animation lasts as long as the longest of animations.
+func Parallel(animations ...Animation) Animation {
+	p := &parallel{
+		animations: animations,
+		finished:   make([]bool, len(animations)),
+	}
+	for _, a := range animations {
+		if d := a.Duration(); d > p.duration {
+			p.duration = d
+		}
+	}
+	return p
+}
+
+func (p *parallel) Duration() time.Duration {
+	return p.duration
+}
+
+func (p *parallel) Update(t time.Duration) {
+	restart := t < p.last
+	p.last = t
+	for i, a := range p.animations {
+		if restart {
+			p.finished[i] = false
+		}
+		if p.finished[i] {
+			continue
+		}
+		if d := a.Duration(); t >= d {
+			p.finished[i] = true
+			a.Update(d)
+		} else {
+			a.Update(t)
+		}
+	}
+}
+
+type repeat struct {
+	animation Animation
+	count     int
+	cycle     int64
+}
+
+// Repeat returns an animation that runs animation count times. If count is 0
+// or less then animation is repeated Forever.
+func Repeat(animation Animation, count int) Animation {
+	return &repeat{animation: animation, count: count}
+}
+
+func (r *repeat) Duration() time.Duration {
+	d := r.animation.Duration()
+	if r.count <= 0 || d == Forever {
+		return Forever
+	}
+	if d > Forever/time.Duration(r.count) {
+		return Forever
+	}
+	return d * time.Duration(r.count)
+}
+
+func (r *repeat) Update(t time.Duration) {
+	d := r.animation.Duration()
+	if d <= 0 {
+		r.animation.Update(0)
+		return
+	}
+	cycle := int64(t / d)
+	if r.count > 0 && cycle >= int64(r.count) {
+		if r.cycle < cycle {
+			r.animation.Update(d)
+			r.cycle = cycle
+		}
+		return
+	}
+	switch {
+	case cycle < r.cycle:
+		r.cycle = cycle
+	case cycle > r.cycle:
+		// Finish the previous cycle before starting the next.
+		r.animation.Update(d)
+		r.cycle = cycle
+	}
+	r.animation.Update(t - time.Duration(cycle)*d)
+}
+
+// add returns a + b, or Forever if the sum is too large.
+func add(a, b time.Duration) time.Duration {
+	if a > Forever-b {
+		return Forever
+	}
+	return a + b
+}
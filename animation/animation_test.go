@@ -0,0 +1,150 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import test "github.com/google/gxui/testing"
+import (
+	"testing"
+	"time"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+)
+
+// createAnimator returns an Animator whose frames are interval apart, and the
+// clock that runs them.
+func createAnimator(interval time.Duration) (*Animator, *gxui.ManualClock) {
+	clock := gxui.CreateManualClock(time.Unix(0, 0))
+	return CreateAnimator(ClockFrames(clock, interval)), clock
+}
+
+func TestTweens(t *testing.T) {
+	a, clock := createAnimator(10 * time.Millisecond)
+	var f float32
+	var p math.Point
+	var s math.Size
+	var c gxui.Color
+	a.Start(Parallel(
+		Float(10, 20, 100*time.Millisecond, nil, func(v float32) { f = v }),
+		Point(math.Point{X: 0, Y: 100}, math.Point{X: 100, Y: 0}, 100*time.Millisecond, Linear, func(v math.Point) { p = v }),
+		Size(math.Size{W: 10, H: 10}, math.Size{W: 20, H: 30}, 50*time.Millisecond, Linear, func(v math.Size) { s = v }),
+		Color(gxui.Black, gxui.White, 100*time.Millisecond, Linear, func(v gxui.Color) { c = v }),
+	))
+	test.AssertEquals(t, float32(10), f)
+	test.AssertEquals(t, math.Point{X: 0, Y: 100}, p)
+	test.AssertEquals(t, math.Size{W: 10, H: 10}, s)
+
+	clock.Advance(50 * time.Millisecond)
+	test.AssertEquals(t, float32(15), f)
+	test.AssertEquals(t, math.Point{X: 50, Y: 50}, p)
+	test.AssertEquals(t, math.Size{W: 20, H: 30}, s)
+	test.AssertEquals(t, gxui.Color{R: 0.5, G: 0.5, B: 0.5, A: 1}, c)
+
+	clock.Advance(time.Second)
+	test.AssertEquals(t, float32(20), f)
+	test.AssertEquals(t, math.Point{X: 100, Y: 0}, p)
+	test.AssertEquals(t, gxui.White, c)
+	test.AssertEquals(t, 0, a.Running())
+}
+
+func TestEasing(t *testing.T) {
+	for _, e := range []Easing{Linear, EaseIn, EaseOut, EaseInOut, Overshoot} {
+		test.AssertEquals(t, true, math.Absf(e(0)) < 1e-6)
+		test.AssertEquals(t, true, math.Absf(e(1)-1) < 1e-6)
+	}
+	test.AssertEquals(t, true, EaseIn(0.5) < 0.5)
+	test.AssertEquals(t, true, EaseOut(0.5) > 0.5)
+	test.AssertEquals(t, float32(0.5), EaseInOut(0.5))
+	test.AssertEquals(t, true, Overshoot(0.8) > 1)
+}
+
+func TestSequence(t *testing.T) {
+	a, clock := createAnimator(10 * time.Millisecond)
+	events := []string{}
+	var f float32
+	a.Start(Sequence(
+		Float(0, 10, 20*time.Millisecond, nil, func(v float32) { f = v }),
+		Call(func() { events = append(events, "a") }),
+		Delay(20*time.Millisecond),
+		Float(10, 0, 20*time.Millisecond, nil, func(v float32) { f = v }),
+		Call(func() { events = append(events, "b") }),
+	))
+	clock.Advance(10 * time.Millisecond)
+	test.AssertEquals(t, float32(5), f)
+	test.AssertEquals(t, []string{}, events)
+	clock.Advance(50 * time.Millisecond)
+	test.AssertEquals(t, float32(0), f)
+	test.AssertEquals(t, []string{"a", "b"}, events)
+	test.AssertEquals(t, 0, a.Running())
+}
+
+func TestSequenceSkippedFrames(t *testing.T) {
+	a, clock := createAnimator(25 * time.Millisecond)
+	events := []string{}
+	var f float32
+	a.Start(Sequence(
+		Float(0, 10, 20*time.Millisecond, nil, func(v float32) { f = v }),
+		Call(func() { events = append(events, "a") }),
+		Delay(20*time.Millisecond),
+		Float(10, 0, 20*time.Millisecond, nil, func(v float32) { f = v }),
+		Call(func() { events = append(events, "b") }),
+	))
+	// Frames can skip past the end of an animation, which is still finished.
+	clock.Advance(25 * time.Millisecond)
+	test.AssertEquals(t, float32(10), f)
+	test.AssertEquals(t, []string{"a"}, events)
+	clock.Advance(50 * time.Millisecond)
+	test.AssertEquals(t, float32(0), f)
+	test.AssertEquals(t, []string{"a", "b"}, events)
+	test.AssertEquals(t, 0, a.Running())
+}
+
+func TestRepeatAndCancel(t *testing.T) {
+	a, clock := createAnimator(10 * time.Millisecond)
+	var f float32
+	cycles := 0
+	h := a.Start(Repeat(Sequence(
+		Float(0, 1, 40*time.Millisecond, nil, func(v float32) { f = v }),
+		Call(func() { cycles++ }),
+	), 0))
+	clock.Advance(100 * time.Millisecond)
+	test.AssertEquals(t, 2, cycles)
+	test.AssertEquals(t, float32(0.5), f)
+	test.AssertEquals(t, true, h.Running())
+
+	h.Cancel()
+	test.AssertEquals(t, false, h.Running())
+	test.AssertEquals(t, 0, clock.Pending())
+	clock.Advance(100 * time.Millisecond)
+	test.AssertEquals(t, float32(0.5), f)
+
+	h = a.Start(Repeat(Float(0, 1, 40*time.Millisecond, nil, func(v float32) { f = v }), 2))
+	clock.Advance(50 * time.Millisecond)
+	test.AssertEquals(t, float32(0.25), f)
+	h.Finish()
+	test.AssertEquals(t, float32(1), f)
+	test.AssertEquals(t, false, h.Running())
+}
+
+func TestFramesStopWhenIdle(t *testing.T) {
+	a, clock := createAnimator(10 * time.Millisecond)
+	test.AssertEquals(t, 0, clock.Pending())
+	h1 := a.Start(Delay(20 * time.Millisecond))
+	h2 := a.Start(Delay(40 * time.Millisecond))
+	// All the animations share the same frames.
+	test.AssertEquals(t, 1, clock.Pending())
+	clock.Advance(20 * time.Millisecond)
+	test.AssertEquals(t, false, h1.Running())
+	test.AssertEquals(t, true, h2.Running())
+	clock.Advance(20 * time.Millisecond)
+	test.AssertEquals(t, 0, clock.Pending())
+
+	// Animations with no duration finish immediately.
+	called := false
+	h := a.Start(Call(func() { called = true }))
+	test.AssertEquals(t, true, called)
+	test.AssertEquals(t, false, h.Running())
+	test.AssertEquals(t, 0, clock.Pending())
+}
@@ -0,0 +1,147 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import (
+	"time"
+)
+
+// Animator runs animations, updating all of its running animations together
+// once per frame of its clock. Frames are only requested from the clock while
+// there are animations running. All methods must be called on the UI
+// go-routine.
+type Animator struct {
+	clock   Clock
+	handles []*Handle
+	stop    func()
+}
+
+// CreateAnimator returns a new Animator using clock.
+func CreateAnimator(clock Clock) *Animator {
+	if clock == nil {
+		panic("Clock cannot be nil")
+	}
+	return &Animator{clock: clock}
+}
+
+// Clock returns the clock used by the animator.
+func (a *Animator) Clock() Clock {
+	return a.clock
+}
+
+// Start starts running animation, immediately updating it with a time of 0.
+// Animations with a duration of 0 finish immediately.
+func (a *Animator) Start(animation Animation) *Handle {
+	h := &Handle{
+		animator:  a,
+		animation: animation,
+		start:     a.clock.Now(),
+		running:   true,
+	}
+	animation.Update(0)
+	if animation.Duration() <= 0 {
+		h.running = false
+		return h
+	}
+	a.handles = append(a.handles, h)
+	if a.stop == nil {
+		a.stop = a.clock.Frames(a.frame)
+	}
+	return h
+}
+
+// Running returns the number of animations that have not yet finished or been
+// cancelled.
+func (a *Animator) Running() int {
+	n := 0
+	for _, h := range a.handles {
+		if h.running {
+			n++
+		}
+	}
+	return n
+}
+
+// CancelAll cancels all the running animations.
+func (a *Animator) CancelAll() {
+	for _, h := range a.handles {
+		h.running = false
+	}
+	a.compact()
+}
+
+func (a *Animator) frame() {
+	now := a.clock.Now()
+	// Animations started by the updates are not updated until the next frame.
+	for _, h := range append([]*Handle{}, a.handles...) {
+		if !h.running {
+			continue
+		}
+		d := h.animation.Duration()
+		t := now.Sub(h.start)
+		if t >= d {
+			t = d
+			h.running = false
+		}
+		h.animation.Update(t)
+	}
+	a.compact()
+}
+
+// compact removes the handles that are no longer running, stopping frames if
+// there are none left.
+func (a *Animator) compact() {
+	handles := a.handles[:0]
+	for _, h := range a.handles {
+		if h.running {
+			handles = append(handles, h)
+		}
+	}
+	for i := len(handles); i < len(a.handles); i++ {
+		a.handles[i] = nil
+	}
+	a.handles = handles
+	if len(a.handles) == 0 && a.stop != nil {
+		a.stop()
+		a.stop = nil
+	}
+}
+
+// Handle controls an animation started by Animator.Start.
+type Handle struct {
+	animator  *Animator
+	animation Animation
+	start     time.Time
+	running   bool
+}
+
+// Running returns true if the animation has not yet finished or been
+// cancelled.
+func (h *Handle) Running() bool {
+	return h.running
+}
+
+// Cancel stops the animation, leaving the animated values as they are. Cancel
+// does nothing if the animation is not running.
+func (h *Handle) Cancel() {
+	if h.running {
+		h.running = false
+		h.animator.compact()
+	}
+}
+
+// Finish stops the animation, first updating it to its end. Animations that
+// last Forever are stopped as they are. Finish does nothing if the animation
+// is not running.
+func (h *Handle) Finish() {
+	if !h.running {
+		return
+	}
+	h.running = false
+	if d := h.animation.Duration(); d != Forever {
+		h.animation.Update(d)
+	}
+	h.animator.compact()
+}
@@ -0,0 +1,81 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import (
+	"time"
+
+	"github.com/google/gxui"
+)
+
+// FrameInterval is the time between the frames of a clock returned by
+// DriverClock.
+const FrameInterval = gxui.FrameInterval
+
+// Clock is the source of time and frames for an Animator.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// Frames calls f on the UI go-routine once per frame until the returned
+	// stop function is called. f is not called after stop returns.
+	Frames(f func()) (stop func())
+}
+
+type driverClock struct {
+	driver gxui.Driver
+}
+
+// DriverClock returns a Clock that uses the driver's clock for time and
+// Driver.OnFrame for frames.
+func DriverClock(driver gxui.Driver) Clock {
+	return driverClock{driver}
+}
+
+func (c driverClock) Now() time.Time {
+	return c.driver.Clock().Now()
+}
+
+func (c driverClock) Frames(f func()) func() {
+	timer := c.driver.OnFrame(f)
+	return func() { timer.Stop() }
+}
+
+type clockFrames struct {
+	clock    gxui.Clock
+	interval time.Duration
+}
+
+// ClockFrames returns a Clock that uses clock for time, and calls the frames
+// each time interval elapses using clock.AfterFunc. The frames are called on
+// the go-routine that clock calls its functions on, so ClockFrames is intended
+// for tests using a gxui.ManualClock, where the frames are run by Advance.
+func ClockFrames(clock gxui.Clock, interval time.Duration) Clock {
+	if interval <= 0 {
+		panic("Frame interval must be positive")
+	}
+	return clockFrames{clock, interval}
+}
+
+func (c clockFrames) Now() time.Time {
+	return c.clock.Now()
+}
+
+func (c clockFrames) Frames(f func()) func() {
+	var timer gxui.Timer
+	stopped := false
+	var tick func()
+	tick = func() {
+		if !stopped {
+			timer = c.clock.AfterFunc(c.interval, tick)
+			f()
+		}
+	}
+	timer = c.clock.AfterFunc(c.interval, tick)
+	return func() {
+		stopped = true
+		timer.Stop()
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+// Easing maps the linear progress of an animation, from 0 at the start to 1
+// at the end, to the fraction of the change applied to the animated value.
+// Easings return 0 for 0 and 1 for 1, but may go beyond this range in
+// between.
+type Easing func(t float32) float32
+
+// Linear changes the value at a constant rate.
+func Linear(t float32) float32 {
+	return t
+}
+
+// EaseIn starts slowly and accelerates to the end.
+func EaseIn(t float32) float32 {
+	return t * t * t
+}
+
+// EaseOut starts quickly and decelerates to the end.
+func EaseOut(t float32) float32 {
+	t = 1 - t
+	return 1 - t*t*t
+}
+
+// EaseInOut accelerates from the start and decelerates to the end.
+func EaseInOut(t float32) float32 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	t = 2 - 2*t
+	return 1 - t*t*t/2
+}
+
+// Overshoot decelerates past the end, then settles back to it.
+func Overshoot(t float32) float32 {
+	const s = 1.70158
+	t = t - 1
+	return 1 + t*t*((s+1)*t+s)
+}
@@ -0,0 +1,239 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package automation provides a driver-independent API for finding controls in
+// a window and synthesizing user input, for use in behavioural tests.
+//
+// Input is injected through the window's gxui.InputInjector interface, so it
+// is dispatched by the window's mouse, keyboard and focus controllers exactly
+// as input from a real viewport would be.
+package automation
+
+import (
+	"fmt"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+)
+
+// The number of intermediate mouse move events raised by Drag.
+const dragSteps = 4
+
+// The number of round-trips to the UI go-routine performed by WaitIdle for
+// drivers that cannot report whether they are idle.
+const idleRounds = 3
+
+// The maximum number of round-trips to the UI go-routine performed by WaitIdle
+// before it gives up waiting for the driver to become idle.
+const maxIdleRounds = 1000
+
+// idler is implemented by drivers that can report whether there is any work
+// queued on the UI go-routine, such as the soft driver. Idle is called on the
+// UI go-routine.
+type idler interface {
+	Idle() bool
+}
+
+// Automation drives a single window. All methods of Automation block on the
+// UI go-routine, and so must not be called from it.
+type Automation struct {
+	driver   gxui.Driver
+	window   gxui.Window
+	injector gxui.InputInjector
+}
+
+// Create returns a new Automation for the window. window must implement
+// gxui.InputInjector.
+func Create(driver gxui.Driver, window gxui.Window) *Automation {
+	injector, ok := window.(gxui.InputInjector)
+	if !ok {
+		panic(fmt.Errorf("Window %T does not implement gxui.InputInjector", window))
+	}
+	return &Automation{
+		driver:   driver,
+		window:   window,
+		injector: injector,
+	}
+}
+
+// Window returns the window driven by the Automation.
+func (a *Automation) Window() gxui.Window {
+	return a.window
+}
+
+// Do calls f on the UI go-routine, blocking until it has returned.
+func (a *Automation) Do(f func()) {
+	if !a.driver.CallSync(f) {
+		panic("Driver has been terminated")
+	}
+}
+
+// WaitIdle blocks until all the functions queued on the UI go-routine have
+// been executed, along with the functions those queue in turn, such as the
+// window's relayout and redraw, and the timer functions that have fallen due.
+// WaitIdle panics if the driver is still busy after maxIdleRounds round-trips.
+// Drivers that cannot report whether they are idle are given a fixed number of
+// round-trips instead.
+func (a *Automation) WaitIdle() {
+	d, ok := a.driver.(idler)
+	if !ok {
+		for i := 0; i < idleRounds; i++ {
+			a.Do(func() {})
+		}
+		return
+	}
+	for i := 0; i < maxIdleRounds; i++ {
+		idle := false
+		a.Do(func() { idle = d.Idle() })
+		if idle {
+			return
+		}
+	}
+	panic(fmt.Errorf("Driver is still busy after %d rounds", maxIdleRounds))
+}
+
+// Find returns the first control in the window matched by m, or nil if there
+// are no matches.
+func (a *Automation) Find(m Matcher) gxui.Control {
+	var c gxui.Control
+	a.Do(func() { c = Find(a.window, m) })
+	return c
+}
+
+// FindAll returns all the controls in the window matched by m.
+func (a *Automation) FindAll(m Matcher) []gxui.Control {
+	var l []gxui.Control
+	a.Do(func() { l = FindAll(a.window, m) })
+	return l
+}
+
+// MustFind returns the first control in the window matched by m, panicking if
+// there are no matches.
+func (a *Automation) MustFind(m Matcher) gxui.Control {
+	c := a.Find(m)
+	if c == nil {
+		panic("No control found matching the Matcher")
+	}
+	return c
+}
+
+// Center returns the center of the control c in window coordinates.
+func (a *Automation) Center(c gxui.Control) math.Point {
+	var p math.Point
+	a.Do(func() { p = a.center(c) })
+	return p
+}
+
+func (a *Automation) center(c gxui.Control) math.Point {
+	gxui.ValidateHierarchy(a.window)
+	return gxui.ChildToParent(c.Size().Rect().Mid(), c, a.window)
+}
+
+// Click performs a left-button click at the center of c. As with real input,
+// a click that follows another within the double-click time is dispatched as a
+// double-click.
+func (a *Automation) Click(c gxui.Control) {
+	a.Do(func() { a.click(a.center(c), gxui.MouseButtonLeft, 0) })
+}
+
+// DoubleClick performs two left-button clicks in quick succession at the
+// center of c.
+func (a *Automation) DoubleClick(c gxui.Control) {
+	a.Do(func() {
+		p := a.center(c)
+		a.click(p, gxui.MouseButtonLeft, 0)
+		a.click(p, gxui.MouseButtonLeft, 0)
+	})
+}
+
+// ClickAt performs a click with the given button and modifiers at the point p
+// in window coordinates.
+func (a *Automation) ClickAt(p math.Point, button gxui.MouseButton, modifier gxui.KeyboardModifier) {
+	a.Do(func() { a.click(p, button, modifier) })
+}
+
+func (a *Automation) click(p math.Point, button gxui.MouseButton, modifier gxui.KeyboardModifier) {
+	state := gxui.MouseState(1 << uint(button))
+	a.injector.InjectMouseMove(gxui.MouseEvent{Point: p, Modifier: modifier})
+	a.injector.InjectMouseDown(gxui.MouseEvent{Point: p, Button: button, State: state, Modifier: modifier})
+	a.injector.InjectMouseUp(gxui.MouseEvent{Point: p, Button: button, Modifier: modifier})
+}
+
+// MoveTo moves the mouse to the point p in window coordinates.
+func (a *Automation) MoveTo(p math.Point) {
+	a.Do(func() { a.injector.InjectMouseMove(gxui.MouseEvent{Point: p}) })
+}
+
+// Drag presses the left mouse button at the center of c, moves the mouse by
+// delta and then releases the button.
+func (a *Automation) Drag(c gxui.Control, delta math.Point) {
+	a.Do(func() {
+		from := a.center(c)
+		a.drag(from, from.Add(delta))
+	})
+}
+
+// DragTo presses the left mouse button at from, moves the mouse to to and then
+// releases the button. Both points are in window coordinates.
+func (a *Automation) DragTo(from, to math.Point) {
+	a.Do(func() { a.drag(from, to) })
+}
+
+func (a *Automation) drag(from, to math.Point) {
+	button := gxui.MouseButtonLeft
+	state := gxui.MouseState(1 << uint(button))
+	a.injector.InjectMouseMove(gxui.MouseEvent{Point: from})
+	a.injector.InjectMouseDown(gxui.MouseEvent{Point: from, Button: button, State: state})
+	for i := 1; i <= dragSteps; i++ {
+		p := math.Point{
+			X: from.X + (to.X-from.X)*i/dragSteps,
+			Y: from.Y + (to.Y-from.Y)*i/dragSteps,
+		}
+		a.injector.InjectMouseMove(gxui.MouseEvent{Point: p, State: state})
+	}
+	a.injector.InjectMouseUp(gxui.MouseEvent{Point: to, Button: button})
+}
+
+// Scroll raises a mouse scroll event at the center of c.
+func (a *Automation) Scroll(c gxui.Control, dx, dy int) {
+	a.Do(func() {
+		p := a.center(c)
+		a.injector.InjectMouseMove(gxui.MouseEvent{Point: p})
+		a.injector.InjectMouseScroll(gxui.MouseEvent{Point: p, ScrollX: dx, ScrollY: dy})
+	})
+}
+
+// Focus gives c focus, returning true on success.
+func (a *Automation) Focus(c gxui.Control) bool {
+	var ok bool
+	a.Do(func() { ok = a.window.SetFocus(c) })
+	return ok
+}
+
+// KeyPress raises a key down followed by a key up event for key. The events
+// are delivered to the focused control.
+func (a *Automation) KeyPress(key gxui.KeyboardKey, modifier gxui.KeyboardModifier) {
+	a.Do(func() {
+		ev := gxui.KeyboardEvent{Key: key, Modifier: modifier}
+		a.injector.InjectKeyDown(ev)
+		a.injector.InjectKeyUp(ev)
+	})
+}
+
+// KeyStroke raises a key stroke event for the character r. The event is
+// delivered to the focused control.
+func (a *Automation) KeyStroke(r rune, modifier gxui.KeyboardModifier) {
+	a.Do(func() {
+		a.injector.InjectKeyStroke(gxui.KeyStrokeEvent{Character: r, Modifier: modifier})
+	})
+}
+
+// Type raises a key stroke event for each character of text.
+func (a *Automation) Type(text string) {
+	a.Do(func() {
+		for _, r := range text {
+			a.injector.InjectKeyStroke(gxui.KeyStrokeEvent{Character: r})
+		}
+	})
+}
@@ -0,0 +1,247 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package automation
+
+import test "github.com/google/gxui/testing"
+import (
+	"testing"
+	"time"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/drivers/soft"
+	"github.com/google/gxui/math"
+	"github.com/google/gxui/themes/dark"
+)
+
+// run builds the control tree returned by create in a soft driver window, then
+// calls f on a separate go-routine with an Automation for the window.
+func run(create func(gxui.Theme) gxui.Control, f func(a *Automation)) {
+	runWithClock(gxui.SystemClock, create, f)
+}
+
+// runWithClock is like run, but the driver uses clock for time.
+func runWithClock(clock gxui.Clock, create func(gxui.Theme) gxui.Control, f func(a *Automation)) {
+	soft.StartDriverWithClock(clock, func(driver gxui.Driver) {
+		theme := dark.CreateTheme(driver)
+		window := theme.CreateWindow(200, 100, "test")
+		window.AddChild(create(theme))
+		a := Create(driver, window)
+		go func() {
+			defer driver.Terminate()
+			a.WaitIdle()
+			f(a)
+		}()
+	})
+}
+
+func createButtons(theme gxui.Theme) gxui.Control {
+	layout := theme.CreateLinearLayout()
+	for _, s := range []string{"One", "Two"} {
+		b := theme.CreateButton()
+		b.SetText(s)
+		layout.AddChild(b)
+	}
+	return layout
+}
+
+func TestFind(t *testing.T) {
+	run(createButtons, func(a *Automation) {
+		test.AssertEquals(t, 2, len(a.FindAll(Type("Button"))))
+		test.AssertEquals(t, 2, len(a.FindAll(Implements((*gxui.Button)(nil)))))
+		test.AssertEquals(t, 2, len(a.FindAll(Path("*mixins.LinearLayout > Button"))))
+		test.AssertEquals(t, 0, len(a.FindAll(Path("Window > Button"))))
+		test.AssertEquals(t, 2, len(a.FindAll(Path("Window > * > Button > Label"))))
+
+		two := a.Find(And(Type("Button"), Text("Two")))
+		test.AssertEquals(t, true, two != nil)
+		test.AssertEquals(t, 2, len(a.FindAll(Path(gxui.Path(two)))))
+		test.AssertEquals(t, true, a.Find(Text("Three")) == nil)
+	})
+}
+
+func TestClick(t *testing.T) {
+	clicked := []string{}
+	run(func(theme gxui.Theme) gxui.Control {
+		layout := createButtons(theme)
+		for _, c := range layout.(gxui.Container).Children() {
+			b := c.Control.(gxui.Button)
+			b.OnClick(func(gxui.MouseEvent) { clicked = append(clicked, "click "+b.Text()) })
+			b.OnDoubleClick(func(gxui.MouseEvent) { clicked = append(clicked, "double-click "+b.Text()) })
+		}
+		return layout
+	}, func(a *Automation) {
+		a.Click(a.MustFind(Text("Two")))
+		// The second click is within the double-click time of the first.
+		a.Click(a.MustFind(Text("One")))
+	})
+	test.AssertEquals(t, []string{"click Two", "double-click One"}, clicked)
+}
+
+func TestType(t *testing.T) {
+	var box gxui.TextBox
+	run(func(theme gxui.Theme) gxui.Control {
+		box = theme.CreateTextBox()
+		return box
+	}, func(a *Automation) {
+		a.Click(box)
+		a.Type("hello")
+		a.KeyPress(gxui.KeyBackspace, 0)
+	})
+	test.AssertEquals(t, "hell", box.Text())
+}
+
+func TestDoubleClickTime(t *testing.T) {
+	clock := gxui.CreateManualClock(time.Unix(0, 0))
+	clicked := []string{}
+	run := func(f func(a *Automation)) {
+		runWithClock(clock, func(theme gxui.Theme) gxui.Control {
+			b := theme.CreateButton()
+			b.SetText("Button")
+			b.OnClick(func(gxui.MouseEvent) { clicked = append(clicked, "click") })
+			b.OnDoubleClick(func(gxui.MouseEvent) { clicked = append(clicked, "double-click") })
+			return b
+		}, f)
+	}
+	run(func(a *Automation) {
+		b := a.MustFind(Text("Button"))
+		a.Click(b)
+		clock.Advance(time.Second)
+		a.Click(b)
+		clock.Advance(100 * time.Millisecond)
+		a.Click(b)
+	})
+	test.AssertEquals(t, []string{"click", "click", "double-click"}, clicked)
+}
+
+func TestToolTip(t *testing.T) {
+	clock := gxui.CreateManualClock(time.Unix(0, 0))
+	var label gxui.Label
+	var toolTip gxui.Label
+	runWithClock(clock, func(theme gxui.Theme) gxui.Control {
+		overlay := theme.CreateBubbleOverlay()
+		controller := gxui.CreateToolTipController(overlay, theme.Driver())
+		label = theme.CreateLabel()
+		label.SetText("Label")
+		controller.AddToolTip(label, 0.5, func(math.Point) gxui.Control {
+			toolTip = theme.CreateLabel()
+			toolTip.SetText("Tip")
+			return toolTip
+		})
+		layout := theme.CreateLinearLayout()
+		layout.AddChild(label)
+		layout.AddChild(overlay)
+		return layout
+	}, func(a *Automation) {
+		a.MoveTo(a.Center(label))
+		clock.Advance(400 * time.Millisecond)
+		a.WaitIdle()
+		test.AssertEquals(t, true, toolTip == nil)
+
+		clock.Advance(100 * time.Millisecond)
+		a.WaitIdle()
+		test.AssertEquals(t, true, toolTip != nil)
+		a.Do(func() { test.AssertEquals(t, true, toolTip.Attached()) })
+
+		a.MoveTo(math.Point{X: 199, Y: 99})
+		a.Do(func() { test.AssertEquals(t, false, toolTip.Attached()) })
+	})
+}
+
+func TestCaretBlink(t *testing.T) {
+	clock := gxui.CreateManualClock(time.Unix(0, 0))
+	var box gxui.TextBox
+	runWithClock(clock, func(theme gxui.Theme) gxui.Control {
+		box = theme.CreateTextBox()
+		return box
+	}, func(a *Automation) {
+		visible := func() bool {
+			v := false
+			a.Do(func() { v = box.(interface{ CaretVisible() bool }).CaretVisible() })
+			return v
+		}
+		a.Click(box)
+		test.AssertEquals(t, true, visible())
+
+		clock.Advance(500 * time.Millisecond)
+		test.AssertEquals(t, false, visible())
+
+		clock.Advance(500 * time.Millisecond)
+		test.AssertEquals(t, true, visible())
+
+		// Typing shows the caret and restarts the blinking.
+		clock.Advance(500 * time.Millisecond)
+		a.Type("a")
+		test.AssertEquals(t, true, visible())
+		clock.Advance(400 * time.Millisecond)
+		test.AssertEquals(t, true, visible())
+
+		// The caret stops blinking when focus is lost.
+		a.Focus(nil)
+		clock.Advance(time.Second)
+		test.AssertEquals(t, true, visible())
+	})
+}
+
+func TestUndoKeys(t *testing.T) {
+	var box gxui.TextBox
+	texts := []string{}
+	run(func(theme gxui.Theme) gxui.Control {
+		box = theme.CreateTextBox()
+		return box
+	}, func(a *Automation) {
+		text := func() {
+			a.Do(func() { texts = append(texts, box.Text()) })
+		}
+		a.Click(box)
+		a.Type("hello world")
+		a.KeyPress(gxui.KeyZ, gxui.ModControl)
+		text()
+		a.KeyPress(gxui.KeyZ, gxui.ModControl)
+		text()
+		a.KeyPress(gxui.KeyZ, gxui.ModControl|gxui.ModShift)
+		text()
+		a.KeyPress(gxui.KeyY, gxui.ModControl)
+		text()
+	})
+	test.AssertEquals(t, []string{"hello ", "", "hello ", "hello world"}, texts)
+}
+
+func TestFindBarKeys(t *testing.T) {
+	var editor *dark.CodeEditor
+	var bar gxui.FindBar
+	selections := []gxui.TextSelection{}
+	run(func(theme gxui.Theme) gxui.Control {
+		editor = theme.CreateCodeEditor().(*dark.CodeEditor)
+		editor.SetText("a\nfoo\nb foo\nfoo")
+		bar = theme.CreateFindBar()
+		bar.SetTarget(editor)
+		layout := theme.CreateLinearLayout()
+		layout.AddChild(bar)
+		layout.AddChild(editor)
+		return layout
+	}, func(a *Automation) {
+		selection := func() {
+			a.Do(func() { selections = append(selections, editor.Controller().LastSelection()) })
+		}
+		a.Do(func() { bar.SetPattern("foo") })
+		a.Focus(editor)
+		a.KeyPress(gxui.KeyF3, gxui.ModNone)
+		selection()
+		a.KeyPress(gxui.KeyF3, gxui.ModNone)
+		selection()
+		a.KeyPress(gxui.KeyF3, gxui.ModShift)
+		selection()
+		a.Do(func() {
+			test.AssertEquals(t, editor.SyntaxLayers()[0], bar.Finder().Layer())
+			bar.SetTarget(nil)
+			test.AssertEquals(t, 0, len(editor.SyntaxLayers()))
+		})
+	})
+	test.AssertEquals(t, []gxui.TextSelection{
+		gxui.CreateTextSelection(2, 5, false),
+		gxui.CreateTextSelection(8, 11, false),
+		gxui.CreateTextSelection(2, 5, false),
+	}, selections)
+}
@@ -0,0 +1,135 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package automation
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/google/gxui"
+)
+
+// Matcher is a predicate used to find controls.
+type Matcher func(gxui.Control) bool
+
+// And returns a Matcher that matches controls matched by all of matchers.
+func And(matchers ...Matcher) Matcher {
+	return func(c gxui.Control) bool {
+		for _, m := range matchers {
+			if !m(c) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Type returns a Matcher that matches controls whose dynamic type has the
+// given name. name can either be the fully qualified type as returned by
+// gxui.Path (for example "*dark.Button"), or just the type's name ("Button").
+func Type(name string) Matcher {
+	return func(c gxui.Control) bool {
+		return typeNameMatches(reflect.TypeOf(c), name)
+	}
+}
+
+// Implements returns a Matcher that matches controls implementing the
+// interface pointed to by ptr. For example:
+//
+//	automation.Implements((*gxui.Button)(nil))
+func Implements(ptr interface{}) Matcher {
+	i := reflect.TypeOf(ptr).Elem()
+	if i.Kind() != reflect.Interface {
+		panic("Implements requires a pointer to an interface type")
+	}
+	return func(c gxui.Control) bool {
+		return reflect.TypeOf(c).Implements(i)
+	}
+}
+
+// Text returns a Matcher that matches controls with a Text() method returning
+// text. This includes labels, buttons and text boxes.
+func Text(text string) Matcher {
+	return func(c gxui.Control) bool {
+		t, ok := c.(interface {
+			Text() string
+		})
+		return ok && t.Text() == text
+	}
+}
+
+// Path returns a Matcher that matches controls using a selector in the form
+// produced by gxui.Path. The selector is a list of type names separated by
+// '>', where each name is matched as described by Type, or is "*" to match
+// any type. The last name is matched against the control, and each preceding
+// name against the next parent up the hierarchy. For example:
+//
+//	"LinearLayout > Button"
+//	"*dark.Window > * > *dark.Label"
+func Path(selector string) Matcher {
+	parts := strings.Split(selector, ">")
+	for i, p := range parts {
+		parts[i] = strings.TrimSpace(p)
+	}
+	return func(c gxui.Control) bool {
+		var p interface{} = c
+		for i := len(parts) - 1; i >= 0; i-- {
+			if p == nil {
+				return false
+			}
+			if parts[i] != "*" && !typeNameMatches(reflect.TypeOf(p), parts[i]) {
+				return false
+			}
+			if c, ok := p.(gxui.Control); ok && c.Parent() != nil {
+				p = c.Parent()
+			} else {
+				p = nil
+			}
+		}
+		return true
+	}
+}
+
+func typeNameMatches(t reflect.Type, name string) bool {
+	if t.String() == name {
+		return true
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name() == name
+}
+
+// FindAll returns all the controls under root that are matched by m, in
+// depth-first order. FindAll must be called on the UI go-routine.
+func FindAll(root gxui.Parent, m Matcher) []gxui.Control {
+	found := []gxui.Control{}
+	for _, child := range root.Children() {
+		if m(child.Control) {
+			found = append(found, child.Control)
+		}
+		if p, ok := child.Control.(gxui.Parent); ok {
+			found = append(found, FindAll(p, m)...)
+		}
+	}
+	return found
+}
+
+// Find returns the first control under root matched by m, in depth-first
+// order, or nil if there are no matches. Find must be called on the UI
+// go-routine.
+func Find(root gxui.Parent, m Matcher) gxui.Control {
+	for _, child := range root.Children() {
+		if m(child.Control) {
+			return child.Control
+		}
+		if p, ok := child.Control.(gxui.Parent); ok {
+			if c := Find(p, m); c != nil {
+				return c
+			}
+		}
+	}
+	return nil
+}
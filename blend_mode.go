@@ -0,0 +1,24 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gxui
+
+// BlendMode describes how a canvas drawn with Canvas.DrawCanvasBlend is
+// combined with what has already been drawn beneath it.
+type BlendMode int
+
+const (
+	// BlendNormal draws the canvas over the destination.
+	BlendNormal BlendMode = iota
+	// BlendMultiply multiplies the canvas and destination colours, which
+	// darkens the destination. Multiplying with white leaves the destination
+	// unchanged.
+	BlendMultiply
+	// BlendScreen multiplies the inverses of the canvas and destination
+	// colours, which lightens the destination. Screening with black leaves the
+	// destination unchanged.
+	BlendScreen
+	// BlendAdditive adds the canvas colours to the destination colours.
+	BlendAdditive
+)
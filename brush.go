@@ -4,15 +4,219 @@
 
 package gxui
 
+import "github.com/google/gxui/math"
+
 var WhiteBrush = CreateBrush(White)
 var TransparentBrush = CreateBrush(Transparent)
 var BlackBrush = CreateBrush(Black)
 var DefaultBrush = WhiteBrush
 
+// Brush describes how the interior of a shape is filled.
+// If Gradient or Pattern is non-nil then the shape is filled with the
+// gradient or pattern, otherwise it is filled with the solid Color.
 type Brush struct {
-	Color Color
+	// Color is the solid fill colour. For gradient brushes, Color holds the
+	// colour of the first stop so that code that only handles solid colours
+	// still has a sensible approximation.
+	Color    Color
+	Gradient *Gradient
+	Pattern  *Pattern
 }
 
 func CreateBrush(color Color) Brush {
-	return Brush{color}
+	return Brush{Color: color}
+}
+
+// CreateLinearGradientBrush returns a brush that linearly interpolates the
+// stop colours along the line from start to end, in the coordinates of the
+// canvas being drawn to.
+func CreateLinearGradientBrush(start, end math.Vec2, stops ...GradientStop) Brush {
+	return createGradientBrush(Gradient{
+		Type:  LinearGradient,
+		Start: start,
+		End:   end,
+		Stops: stops,
+	})
+}
+
+// CreateRadialGradientBrush returns a brush that interpolates the stop colours
+// outwards from center to the circle of the given radius, in the coordinates
+// of the canvas being drawn to.
+func CreateRadialGradientBrush(center math.Vec2, radius float32, stops ...GradientStop) Brush {
+	return createGradientBrush(Gradient{
+		Type:  RadialGradient,
+		Start: center,
+		End:   center.Add(math.Vec2{X: radius}),
+		Stops: stops,
+	})
+}
+
+// CreateVerticalGradientBrush returns a relative linear gradient brush that
+// blends from top at the top of the filled shape to bottom at the bottom.
+func CreateVerticalGradientBrush(top, bottom Color) Brush {
+	b := CreateLinearGradientBrush(math.Vec2{}, math.Vec2{Y: 1},
+		GradientStop{Offset: 0, Color: top},
+		GradientStop{Offset: 1, Color: bottom},
+	)
+	b.Gradient.Relative = true
+	return b
+}
+
+func createGradientBrush(g Gradient) Brush {
+	if len(g.Stops) == 0 {
+		panic("Gradients require at least one stop")
+	}
+	return Brush{Color: g.Stops[0].Color, Gradient: &g}
+}
+
+// CreatePatternBrush returns a brush that fills with the texture t, drawn to
+// the rectangle r in the coordinates of the canvas being drawn to. If mode is
+// PatternTile then the texture is repeated in both directions beyond r.
+// See Pattern for the behaviour when r is empty.
+func CreatePatternBrush(t Texture, mode PatternMode, r math.Rect) Brush {
+	if t == nil {
+		panic("Texture cannot be nil")
+	}
+	return Brush{Pattern: &Pattern{Texture: t, Mode: mode, Rect: r}}
+}
+
+// Resolve returns the brush with any coordinates that are relative to the
+// bounds of the shape being filled converted to canvas coordinates.
+// Resolve is called by the drivers, and should not usually be called
+// directly.
+func (b Brush) Resolve(bounds math.Rect) Brush {
+	if g := b.Gradient; g != nil && g.Relative {
+		size := bounds.Size().Vec2()
+		min := bounds.Min.Vec2()
+		resolved := *g
+		resolved.Relative = false
+		resolved.Start = min.Add(math.Vec2{X: g.Start.X * size.X, Y: g.Start.Y * size.Y})
+		resolved.End = min.Add(math.Vec2{X: g.End.X * size.X, Y: g.End.Y * size.Y})
+		b.Gradient = &resolved
+	}
+	if p := b.Pattern; p != nil && (p.Rect.W() == 0 || p.Rect.H() == 0) {
+		resolved := *p
+		if p.Mode == PatternTile {
+			resolved.Rect = p.Texture.Size().Rect().Offset(bounds.Min)
+		} else {
+			resolved.Rect = bounds
+		}
+		b.Pattern = &resolved
+	}
+	return b
+}
+
+// IsTransparent returns true if the brush has no visible fill.
+func (b Brush) IsTransparent() bool {
+	return b.Gradient == nil && b.Pattern == nil && b.Color.A == 0
+}
+
+// IsSolid returns true if the brush is a solid colour fill.
+func (b Brush) IsSolid() bool {
+	return b.Gradient == nil && b.Pattern == nil
+}
+
+type GradientType int
+
+const (
+	LinearGradient GradientType = iota
+	RadialGradient
+)
+
+// GradientStop is a colour at the offset along a gradient, where 0 is the
+// gradient's start and 1 is the gradient's end.
+type GradientStop struct {
+	Offset float32
+	Color  Color
+}
+
+// Gradient describes a linear or radial gradient.
+// Linear gradients vary along the line from Start to End.
+// Radial gradients are centered at Start and end at the circle passing through
+// End. Points beyond either end of the gradient use the colour of the nearest
+// stop.
+// If Relative is true then Start and End are fractions of the bounds of the
+// shape being filled, where {0, 0} is the top-left and {1, 1} is the
+// bottom-right. Relative gradients let a single brush be shared by controls of
+// different sizes.
+type Gradient struct {
+	Type       GradientType
+	Start, End math.Vec2
+	Stops      []GradientStop // Sorted by ascending offset
+	Relative   bool
+}
+
+// Offset returns the gradient offset for the point p, clamped to [0, 1].
+func (g *Gradient) Offset(p math.Vec2) float32 {
+	d := g.End.Sub(g.Start)
+	switch g.Type {
+	case RadialGradient:
+		r := d.Len()
+		if r == 0 {
+			return 1
+		}
+		return math.Saturate(p.Sub(g.Start).Len() / r)
+	default:
+		l := d.Dot(d)
+		if l == 0 {
+			return 1
+		}
+		return math.Saturate(p.Sub(g.Start).Dot(d) / l)
+	}
+}
+
+// ColorAt returns the interpolated colour at the gradient offset t.
+func (g *Gradient) ColorAt(t float32) Color {
+	stops := g.Stops
+	if t <= stops[0].Offset {
+		return stops[0].Color
+	}
+	for i := 1; i < len(stops); i++ {
+		a, b := stops[i-1], stops[i]
+		if t < b.Offset {
+			f := (t - a.Offset) / (b.Offset - a.Offset)
+			return Color{
+				R: math.Lerpf(a.Color.R, b.Color.R, f),
+				G: math.Lerpf(a.Color.G, b.Color.G, f),
+				B: math.Lerpf(a.Color.B, b.Color.B, f),
+				A: math.Lerpf(a.Color.A, b.Color.A, f),
+			}
+		}
+	}
+	return stops[len(stops)-1].Color
+}
+
+type PatternMode int
+
+const (
+	PatternStretch PatternMode = iota
+	PatternTile
+)
+
+// Pattern describes a fill using a texture drawn to Rect, and repeated if Mode
+// is PatternTile. If Rect is empty then stretched patterns are drawn to the
+// bounds of the shape being filled, and tiled patterns start at the top-left
+// of the shape, using the texture's size.
+type Pattern struct {
+	Texture Texture
+	Mode    PatternMode
+	Rect    math.Rect
+}
+
+// UV returns the normalized texture coordinate for the point v.
+// Coordinates are in the range [0, 1] for points inside Rect. For points
+// outside Rect, tiled patterns wrap and stretched patterns are clamped.
+func (p *Pattern) UV(v math.Vec2) math.Vec2 {
+	min, size := p.Rect.Min.Vec2(), p.Rect.Size().Vec2()
+	uv := math.Vec2{}
+	if size.X > 0 {
+		uv.X = (v.X - min.X) / size.X
+	}
+	if size.Y > 0 {
+		uv.Y = (v.Y - min.Y) / size.Y
+	}
+	if p.Mode == PatternTile {
+		return math.Vec2{X: uv.X - math.Floorf(uv.X), Y: uv.Y - math.Floorf(uv.Y)}
+	}
+	return math.Vec2{X: math.Saturate(uv.X), Y: math.Saturate(uv.Y)}
 }
@@ -0,0 +1,48 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gxui
+
+import (
+	"testing"
+
+	"github.com/google/gxui/math"
+	test "github.com/google/gxui/testing"
+)
+
+func TestGradientColorAt(t *testing.T) {
+	b := CreateLinearGradientBrush(math.Vec2{X: 10}, math.Vec2{X: 20},
+		GradientStop{Offset: 0.0, Color: Black},
+		GradientStop{Offset: 0.5, Color: Red},
+		GradientStop{Offset: 1.0, Color: White},
+	)
+	g := b.Gradient
+	test.AssertEquals(t, Black, b.Color)
+	test.AssertEquals(t, float32(0), g.Offset(math.Vec2{X: 5, Y: 3}))
+	test.AssertEquals(t, float32(0.5), g.Offset(math.Vec2{X: 15, Y: 3}))
+	test.AssertEquals(t, float32(1), g.Offset(math.Vec2{X: 25, Y: 3}))
+	test.AssertEquals(t, Black, g.ColorAt(0))
+	test.AssertEquals(t, Color{0.5, 0, 0, 1}, g.ColorAt(0.25))
+	test.AssertEquals(t, Red, g.ColorAt(0.5))
+	test.AssertEquals(t, White, g.ColorAt(1))
+}
+
+func TestRadialGradientOffset(t *testing.T) {
+	g := CreateRadialGradientBrush(math.Vec2{X: 10, Y: 10}, 10,
+		GradientStop{Offset: 0, Color: Black},
+	).Gradient
+	test.AssertEquals(t, float32(0), g.Offset(math.Vec2{X: 10, Y: 10}))
+	test.AssertEquals(t, float32(0.5), g.Offset(math.Vec2{X: 10, Y: 5}))
+	test.AssertEquals(t, float32(1), g.Offset(math.Vec2{X: 30, Y: 10}))
+}
+
+func TestBrushResolve(t *testing.T) {
+	b := CreateVerticalGradientBrush(Black, White).Resolve(math.CreateRect(10, 20, 30, 60))
+	test.AssertEquals(t, false, b.Gradient.Relative)
+	test.AssertEquals(t, math.Vec2{X: 10, Y: 20}, b.Gradient.Start)
+	test.AssertEquals(t, math.Vec2{X: 10, Y: 60}, b.Gradient.End)
+
+	solid := CreateBrush(Red)
+	test.AssertEquals(t, solid, solid.Resolve(math.CreateRect(10, 20, 30, 60)))
+}
@@ -15,13 +15,47 @@ type Canvas interface {
 	Push()
 	Pop()
 	AddClip(math.Rect)
+
+	// Transform applies the affine transform m to all subsequent drawing. m is
+	// applied before the canvas's existing transform, so nested transforms
+	// compose like nested canvases. The transform is saved by Push and restored
+	// by Pop. Clip rectangles added while a rotating or skewing transform is
+	// active clip to their transformed bounds.
+	Transform(m math.Mat3)
+
 	Clear(Color)
 	DrawCanvas(c Canvas, position math.Point)
+
+	// DrawCanvasBlend draws c at position like DrawCanvas, except that c is
+	// first drawn to an offscreen surface which is then combined with the
+	// canvas using mode, with the opacity alpha. alpha ranges from 0 for
+	// transparent to 1 for opaque.
+	DrawCanvasBlend(c Canvas, position math.Point, alpha float32, mode BlendMode)
+
+	// DrawCanvasBlur draws c at position like DrawCanvas, blurred with a
+	// Gaussian of the given radius in DIPs.
+	DrawCanvasBlur(c Canvas, position math.Point, radius float32)
 	DrawTexture(t Texture, bounds math.Rect)
 	DrawRunes(font Font, runes []rune, points []math.Point, color Color)
 	DrawLines(Polygon, Pen)
 	DrawPolygon(Polygon, Pen, Brush)
 	DrawRect(math.Rect, Brush)
 	DrawRoundedRect(rect math.Rect, tl, tr, bl, br float32, p Pen, b Brush)
+
+	// DrawShadow draws the shadow s of the rounded rectangle rect, which has
+	// the corner radii tl, tr, bl and br. The rectangle itself is not drawn.
+	// Outer shadows are only drawn outside of rect and inner shadows only
+	// inside it, so rect can then be drawn with a translucent brush.
+	DrawShadow(rect math.Rect, tl, tr, bl, br float32, s Shadow)
+
+	// FillPath fills the inside of path, as decided by the path's fill rule.
+	// All contours are treated as closed.
+	FillPath(path *VectorPath, b Brush)
+
+	// StrokePath draws the outline of path with the stroke centered on each
+	// contour. Unlike DrawLines, contours closed with VectorPath.Close are joined
+	// at their start.
+	StrokePath(path *VectorPath, p Pen)
+
 	Release()
 }
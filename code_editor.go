@@ -13,6 +13,110 @@ type CodeSuggestionProvider interface {
 	SuggestionsAt(runeIndex int) []CodeSuggestion
 }
 
+// FoldRange is a range of lines that can be folded. Folding the range hides
+// the lines after First, up to and including Last.
+type FoldRange struct {
+	First, Last int
+}
+
+// Contains returns true if line is between First and Last inclusive.
+func (r FoldRange) Contains(line int) bool {
+	return r.First <= line && line <= r.Last
+}
+
+type FoldingProvider interface {
+	// FoldRanges returns the ranges of lines in the text of controller that can
+	// be folded, ordered by First. Ranges may be nested, but must not otherwise
+	// overlap.
+	FoldRanges(controller *TextBoxController) []FoldRange
+}
+
+// IndentFoldingProvider is a FoldingProvider that folds each run of lines
+// that are indented further than the line before them. Blank lines do not end
+// a fold, but are not folded at the end of one.
+type IndentFoldingProvider struct{}
+
+func (IndentFoldingProvider) FoldRanges(controller *TextBoxController) []FoldRange {
+	type open struct {
+		line, indent, index int
+	}
+	ranges := []FoldRange{}
+	stack := []open{}
+	last := -1 // The last non-blank line
+	for i, c := 0, controller.LineCount(); i <= c; i++ {
+		indent := -1 // Closes every open range after the last line
+		if i < c {
+			indent = controller.LineIndent(i)
+			if controller.LineStart(i)+indent == controller.LineEnd(i) {
+				continue // Blank
+			}
+		}
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			o := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			ranges[o.index].Last = last
+		}
+		if i < c {
+			// Add the range now, so that ranges are ordered by First.
+			stack = append(stack, open{i, indent, len(ranges)})
+			ranges = append(ranges, FoldRange{i, i})
+			last = i
+		}
+	}
+	folds := ranges[:0]
+	for _, r := range ranges {
+		if r.Last > r.First {
+			folds = append(folds, r)
+		}
+	}
+	return folds
+}
+
+// LineNumberMode controls the line numbers displayed in the gutter of a
+// CodeEditor.
+type LineNumberMode int
+
+const (
+	// AbsoluteLineNumbers displays the number of each line, starting at 1.
+	AbsoluteLineNumbers LineNumberMode = iota
+	// RelativeLineNumbers displays the number of the line holding the last
+	// caret, and the number of displayed lines between it and every other line.
+	RelativeLineNumbers
+	// NoLineNumbers displays no line numbers.
+	NoLineNumbers
+)
+
+// LineMarkerShape is the shape drawn for a LineMarker that has no icon.
+type LineMarkerShape int
+
+const (
+	// CircleMarker is drawn as a filled circle, such as for a breakpoint.
+	CircleMarker LineMarkerShape = iota
+	// BarMarker is drawn as a thin bar along the left edge of the gutter, such
+	// as for a line changed since the last commit.
+	BarMarker
+)
+
+// LineMarker is a marker displayed in the gutter of a CodeEditor line, such as
+// a breakpoint, an error or a version control change. Markers move with the
+// line they were added to as the text is edited.
+type LineMarker struct {
+	Shape LineMarkerShape
+	Color Color
+	Icon  Texture     // If not nil, drawn instead of the shape
+	Data  interface{} // For use by the application
+}
+
+// BracketPair is a pair of runes that open and close a bracketed range of
+// text.
+type BracketPair struct {
+	Open, Close rune
+}
+
+// DefaultBracketPairs holds the bracket pairs matched by a CodeEditor, unless
+// replaced with SetBracketPairs.
+var DefaultBracketPairs = []BracketPair{{'(', ')'}, {'[', ']'}, {'{', '}'}}
+
 type CodeEditor interface {
 	TextBox
 	SyntaxLayers() CodeSyntaxLayers
@@ -23,4 +127,35 @@ type CodeEditor interface {
 	SetSuggestionProvider(CodeSuggestionProvider)
 	ShowSuggestionList()
 	HideSuggestionList()
+	FoldingProvider() FoldingProvider
+	SetFoldingProvider(FoldingProvider)
+	FoldRanges() []FoldRange
+	IsFolded(line int) bool
+	IsLineHidden(line int) bool
+	Fold(line int) bool
+	Unfold(line int) bool
+	ToggleFold(line int)
+	FoldAll()
+	UnfoldAll()
+	GutterVisible() bool
+	SetGutterVisible(bool)
+	LineNumberMode() LineNumberMode
+	SetLineNumberMode(LineNumberMode)
+	AddLineMarker(line int, marker *LineMarker)
+	RemoveLineMarker(marker *LineMarker)
+	ClearLineMarkers()
+	LineMarkers(line int) []*LineMarker
+	LineMarkerLine(marker *LineMarker) int
+	OnGutterClicked(func(line int, ev MouseEvent)) EventSubscription
+	OnLineMarkerClicked(func(marker *LineMarker, ev MouseEvent)) EventSubscription
+	OnLineMarkerEnter(func(marker *LineMarker)) EventSubscription
+	OnLineMarkerExit(func(marker *LineMarker)) EventSubscription
+	BracketPairs() []BracketPair
+	SetBracketPairs([]BracketPair)
+	AutoCloseBrackets() bool
+	SetAutoCloseBrackets(bool)
+	BracketLayer() *CodeSyntaxLayer
+	MatchingBracket(runeIndex int) (int, bool)
+	JumpToMatchingBracket()
+	SelectToMatchingBracket()
 }
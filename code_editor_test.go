@@ -0,0 +1,30 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gxui
+
+import (
+	test "github.com/google/gxui/testing"
+	"testing"
+)
+
+func TestIndentFoldingProvider(t *testing.T) {
+	c := CreateTextBoxController()
+	c.SetText(`func a() {
+  if b {
+    c()
+
+  }
+
+  d()
+}
+
+e
+  f
+    g`)
+	test.AssertEquals(t, []FoldRange{{0, 6}, {1, 2}, {9, 11}, {10, 11}}, IndentFoldingProvider{}.FoldRanges(c))
+
+	c.SetText("a\nb\n  \n")
+	test.AssertEquals(t, []FoldRange{}, IndentFoldingProvider{}.FoldRanges(c))
+}
@@ -15,6 +15,7 @@ type CodeSyntaxLayer struct {
 	backgroundColor *Color
 	borderColor     *Color
 	data            interface{}
+	plainText       bool
 }
 
 func CreateCodeSyntaxLayer() *CodeSyntaxLayer { return &CodeSyntaxLayer{} }
@@ -23,6 +24,8 @@ func (l *CodeSyntaxLayer) Clear() {
 	l.spans = interval.IntDataList{}
 }
 
+// UpdateSpans moves the spans by the edits, so that they continue to cover the
+// same runes. Spans that only covered deleted runes are removed.
 func (l *CodeSyntaxLayer) UpdateSpans(runeCount int, edits []TextBoxEdit) {
 	min := 0
 	max := runeCount
@@ -30,20 +33,31 @@ func (l *CodeSyntaxLayer) UpdateSpans(runeCount int, edits []TextBoxEdit) {
 		if l == nil {
 			continue
 		}
-		for j, s := range l.spans {
-			at := e.At
+		at := e.At
+		move := func(p int) int {
+			if e.Delta < 0 && p < at-e.Delta {
+				return at // Inside the deleted runes
+			}
+			return p + e.Delta
+		}
+		spans := l.spans[:0]
+		for _, s := range l.spans {
 			start, end := s.Range()
 			if start >= at {
-				start = math.Clamp(start+e.Delta, min, max)
+				start = move(start)
 			}
 			if end > at {
-				end = math.Clamp(end+e.Delta, min, max)
+				end = move(end)
 			}
-			if end < start {
-				end = start
+			if end > start {
+				spans = append(spans, interval.CreateIntData(start, end, s.Data()))
 			}
-			l.spans[j] = interval.CreateIntData(start, end, s.Data())
 		}
+		l.spans = spans
+	}
+	for i, s := range l.spans {
+		start, end := s.Range()
+		l.spans[i] = interval.CreateIntData(math.Clamp(start, min, max), math.Clamp(end, min, max), s.Data())
 	}
 }
 
@@ -60,6 +74,11 @@ func (l *CodeSyntaxLayer) AddSpan(span interval.IntData) {
 	interval.Replace(&l.spans, span)
 }
 
+// Remove removes the runes from start to start+count from the spans.
+func (l *CodeSyntaxLayer) Remove(start, count int) {
+	interval.Remove(&l.spans, interval.CreateIntData(start, start+count, nil))
+}
+
 func (l *CodeSyntaxLayer) Spans() interval.IntDataList {
 	return l.spans
 }
@@ -117,6 +136,17 @@ func (l *CodeSyntaxLayer) SetData(data interface{}) {
 	l.data = data
 }
 
+// PlainText returns true if the layer holds the spans of text that is not
+// code, such as strings and comments. A CodeEditor does not match brackets
+// inside the spans of plain text layers.
+func (l *CodeSyntaxLayer) PlainText() bool {
+	return l.plainText
+}
+
+func (l *CodeSyntaxLayer) SetPlainText(plainText bool) {
+	l.plainText = plainText
+}
+
 type CodeSyntaxLayers []*CodeSyntaxLayer
 
 func (l *CodeSyntaxLayers) Get(idx int) *CodeSyntaxLayer {
@@ -0,0 +1,42 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gxui
+
+import (
+	"testing"
+
+	"github.com/google/gxui/interval"
+	test "github.com/google/gxui/testing"
+)
+
+func TestCodeSyntaxLayerUpdateSpans(t *testing.T) {
+	l := CreateCodeSyntaxLayer()
+	l.Add(0, 5)
+	l.Add(10, 5)
+	l.Add(20, 5)
+	// Insert 2 runes at 10, then delete the runes from 3 to 18.
+	l.UpdateSpans(12, []TextBoxEdit{{10, 2}, {3, -15}})
+	test.AssertEquals(t, interval.IntDataList{
+		interval.CreateIntData(0, 3, nil),
+		interval.CreateIntData(7, 12, nil),
+	}, l.Spans())
+
+	l.UpdateSpans(10, []TextBoxEdit{{3, -2}})
+	test.AssertEquals(t, interval.IntDataList{
+		interval.CreateIntData(0, 3, nil),
+		interval.CreateIntData(5, 10, nil),
+	}, l.Spans())
+}
+
+func TestCodeSyntaxLayerRemove(t *testing.T) {
+	l := CreateCodeSyntaxLayer()
+	l.Add(0, 5)
+	l.Add(10, 5)
+	l.Remove(3, 9)
+	test.AssertEquals(t, interval.IntDataList{
+		interval.CreateIntData(0, 3, nil),
+		interval.CreateIntData(12, 15, nil),
+	}, l.Spans())
+}
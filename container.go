@@ -14,6 +14,11 @@ import (
 type Child struct {
 	Control Control
 	Offset  math.Point
+
+	// Transform is an optional affine transform applied to the child control
+	// before it is offset by Offset. A nil Transform is the identity.
+	// Hit-testing and coordinate conversions apply the inverse transform.
+	Transform *math.Mat3
 }
 
 type Parent interface {
@@ -38,9 +43,42 @@ func (c *Child) String() string {
 	return fmt.Sprintf("Type: %T, Bounds: %v", c.Control, c.Bounds())
 }
 
-// Bounds returns the Child bounds relative to the parent.
+// Bounds returns the rectangle bounding the child in the parent's coordinates.
 func (c *Child) Bounds() math.Rect {
-	return c.Control.Size().Rect().Offset(c.Offset)
+	r := c.Control.Size().Rect()
+	if c.Transform != nil {
+		r = c.Transform.TransformRect(r)
+	}
+	return r.Offset(c.Offset)
+}
+
+// ToParent transforms the point p from the child's coordinates to the
+// parent's coordinates.
+func (c *Child) ToParent(p math.Point) math.Point {
+	if c.Transform != nil {
+		p = c.Transform.TransformPoint(p)
+	}
+	return p.Add(c.Offset)
+}
+
+// ToChild transforms the point p from the parent's coordinates to the child's
+// coordinates.
+func (c *Child) ToChild(p math.Point) math.Point {
+	p = p.Sub(c.Offset)
+	if c.Transform != nil {
+		p = c.Transform.Invert().TransformPoint(p)
+	}
+	return p
+}
+
+// CanvasTransform returns the transform used to draw the child's canvas onto
+// the parent's canvas.
+func (c *Child) CanvasTransform() math.Mat3 {
+	t := math.CreateMat3Translate(c.Offset.Vec2())
+	if c.Transform != nil {
+		t = c.Transform.Mul(t)
+	}
+	return t
 }
 
 // Layout sets the Child size and offset relative to the parent.
@@ -0,0 +1,24 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gxui
+
+import (
+	"testing"
+
+	"github.com/google/gxui/math"
+	test "github.com/google/gxui/testing"
+)
+
+func TestChildTransformedCoordinates(t *testing.T) {
+	rotate := math.CreateMat3Rotate(math.Pi / 2)
+	c := &Child{Offset: math.Point{X: 10, Y: 20}, Transform: &rotate}
+
+	test.AssertEquals(t, math.Point{X: 7, Y: 21}, c.ToParent(math.Point{X: 1, Y: 3}))
+	test.AssertEquals(t, math.Point{X: 1, Y: 3}, c.ToChild(math.Point{X: 7, Y: 21}))
+
+	c.Transform = nil
+	test.AssertEquals(t, math.Point{X: 11, Y: 23}, c.ToParent(math.Point{X: 1, Y: 3}))
+	test.AssertEquals(t, math.Point{X: 1, Y: 3}, c.ToChild(math.Point{X: 11, Y: 23}))
+}
@@ -59,6 +59,14 @@ type Control interface {
 	// SetVisible sets the visibility of the control.
 	SetVisible(bool)
 
+	// Opacity returns the opacity of the control, from 0 for fully transparent
+	// to 1 for fully opaque.
+	Opacity() float32
+
+	// SetOpacity sets the opacity of the control, clamped to the range [0, 1].
+	// The opacity is applied by the parent when it paints the control.
+	SetOpacity(float32)
+
 	// ContainsPoint returns true if the specified local-space point is considered
 	// within the control.
 	ContainsPoint(math.Point) bool
@@ -48,11 +48,10 @@ func FunctionName(i interface{}) string {
 func BreadcrumbsAt(p Container, pnt math.Point) string {
 	s := reflect.TypeOf(p).String()
 	for _, c := range p.Children() {
-		b := c.Control.Size().Rect().Offset(c.Offset)
-		if b.Contains(pnt) {
+		if c.Control.Size().Rect().Contains(c.ToChild(pnt)) {
 			switch t := c.Control.(type) {
 			case Container:
-				return s + " > " + BreadcrumbsAt(t, pnt.Sub(c.Offset))
+				return s + " > " + BreadcrumbsAt(t, c.ToChild(pnt))
 			default:
 				return s + " > " + reflect.TypeOf(c.Control).String()
 			}
@@ -61,6 +60,7 @@ func BreadcrumbsAt(p Container, pnt math.Point) string {
 	return s
 }
 
+// Path returns a string describing the types of p and its ancestors.
 func Path(p interface{}) string {
 	if p == nil {
 		return "nil"
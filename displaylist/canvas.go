@@ -0,0 +1,404 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package displaylist records the calls made to a gxui.Canvas as a list of
+// plain data operations. Display lists can be replayed onto any other canvas,
+// dumped as text and diffed against each other, which makes them useful for
+// finding out why a control redrew, and for testing what a control draws
+// without a GPU.
+//
+// To record every canvas of an application, create its theme with a driver
+// returned by WrapDriver. The canvas returned by a control's Draw method is
+// then a *Canvas.
+package displaylist
+
+import (
+	"fmt"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+)
+
+// OpType identifies the gxui.Canvas method recorded by an Op.
+type OpType int
+
+const (
+	Push OpType = iota
+	Pop
+	AddClip
+	Transform
+	Clear
+	DrawCanvas
+	DrawCanvasBlend
+	DrawCanvasBlur
+	DrawTexture
+	DrawRunes
+	DrawLines
+	DrawPolygon
+	DrawRect
+	DrawRoundedRect
+	DrawShadow
+	FillPath
+	StrokePath
+)
+
+var opNames = [...]string{
+	Push:            "Push",
+	Pop:             "Pop",
+	AddClip:         "AddClip",
+	Transform:       "Transform",
+	Clear:           "Clear",
+	DrawCanvas:      "DrawCanvas",
+	DrawCanvasBlend: "DrawCanvasBlend",
+	DrawCanvasBlur:  "DrawCanvasBlur",
+	DrawTexture:     "DrawTexture",
+	DrawRunes:       "DrawRunes",
+	DrawLines:       "DrawLines",
+	DrawPolygon:     "DrawPolygon",
+	DrawRect:        "DrawRect",
+	DrawRoundedRect: "DrawRoundedRect",
+	DrawShadow:      "DrawShadow",
+	FillPath:        "FillPath",
+	StrokePath:      "StrokePath",
+}
+
+func (t OpType) String() string {
+	if int(t) < len(opNames) {
+		return opNames[t]
+	}
+	return fmt.Sprintf("OpType(%d)", int(t))
+}
+
+// Op is a single recorded canvas call. Only the fields used by the call's
+// arguments are set.
+type Op struct {
+	Type    OpType
+	Rect    math.Rect        // AddClip, DrawTexture, DrawRect, DrawRoundedRect, DrawShadow
+	Point   math.Point       // DrawCanvas, DrawCanvasBlend, DrawCanvasBlur
+	Matrix  math.Mat3        // Transform
+	Color   gxui.Color       // Clear, DrawRunes
+	Pen     gxui.Pen         // DrawLines, DrawPolygon, DrawRoundedRect, StrokePath
+	Brush   gxui.Brush       // DrawPolygon, DrawRect, DrawRoundedRect, FillPath
+	Polygon gxui.Polygon     // DrawLines, DrawPolygon
+	Radii   [4]float32       // DrawRoundedRect, DrawShadow, in the order tl, tr, bl, br
+	Shadow  gxui.Shadow      // DrawShadow
+	Path    *gxui.VectorPath // FillPath, StrokePath
+	Font    gxui.Font        // DrawRunes
+	Runes   []rune           // DrawRunes
+	Points  []math.Point     // DrawRunes
+	Texture gxui.Texture     // DrawTexture
+
+	// Canvas is the canvas drawn by DrawCanvas, DrawCanvasBlend and
+	// DrawCanvasBlur. If it is a *Canvas then its operations are part of this
+	// display list.
+	Canvas gxui.Canvas
+
+	Alpha     float32        // DrawCanvasBlend
+	BlendMode gxui.BlendMode // DrawCanvasBlend
+	Radius    float32        // DrawCanvasBlur
+}
+
+// Canvas is a gxui.Canvas that records the calls made to it as a list of Ops.
+// If the canvas was created with a target canvas then every call is also
+// forwarded to the target, so a Canvas can be displayed as normal while it
+// records.
+type Canvas struct {
+	target            gxui.Canvas
+	size              math.Size
+	ops               []Op
+	built             bool
+	buildingPushCount int
+}
+
+// CreateCanvas returns a new display list canvas of the given size in DIPs.
+// target may be nil, in which case the canvas only records.
+func CreateCanvas(size math.Size, target gxui.Canvas) *Canvas {
+	if size.W <= 0 || size.H < 0 {
+		panic(fmt.Errorf("Canvas width and height must be positive. Size: %d", size))
+	}
+	return &Canvas{target: target, size: size}
+}
+
+// Target returns the canvas that calls are forwarded to, which may be nil.
+func (c *Canvas) Target() gxui.Canvas {
+	return c.target
+}
+
+// Ops returns the recorded operations. The returned slice must not be
+// modified.
+func (c *Canvas) Ops() []Op {
+	return c.ops
+}
+
+// Replay makes the recorded calls on dst. Canvases drawn with DrawCanvas that
+// are display lists are replayed directly onto dst, offset with a transform.
+// Display lists drawn with DrawCanvasBlend or DrawCanvasBlur must be
+// composited as a whole, so are passed to dst unchanged if dst is a display
+// list, or otherwise as their target canvas. Other canvases are passed to dst
+// unchanged, and so must have been created by the same driver as dst.
+// Replay does not call dst.Complete.
+func (c *Canvas) Replay(dst gxui.Canvas) {
+	for _, op := range c.ops {
+		switch op.Type {
+		case Push:
+			dst.Push()
+		case Pop:
+			dst.Pop()
+		case AddClip:
+			dst.AddClip(op.Rect)
+		case Transform:
+			dst.Transform(op.Matrix)
+		case Clear:
+			dst.Clear(op.Color)
+		case DrawCanvas:
+			if child, ok := op.Canvas.(*Canvas); ok {
+				dst.Push()
+				if op.Point != math.ZeroPoint {
+					dst.Transform(math.CreateMat3Translate(op.Point.Vec2()))
+				}
+				child.Replay(dst)
+				dst.Pop()
+			} else {
+				dst.DrawCanvas(op.Canvas, op.Point)
+			}
+		case DrawCanvasBlend:
+			dst.DrawCanvasBlend(replayed(op, dst), op.Point, op.Alpha, op.BlendMode)
+		case DrawCanvasBlur:
+			dst.DrawCanvasBlur(replayed(op, dst), op.Point, op.Radius)
+		case DrawTexture:
+			dst.DrawTexture(op.Texture, op.Rect)
+		case DrawRunes:
+			dst.DrawRunes(op.Font, op.Runes, op.Points, op.Color)
+		case DrawLines:
+			dst.DrawLines(op.Polygon, op.Pen)
+		case DrawPolygon:
+			dst.DrawPolygon(op.Polygon, op.Pen, op.Brush)
+		case DrawRect:
+			dst.DrawRect(op.Rect, op.Brush)
+		case DrawRoundedRect:
+			dst.DrawRoundedRect(op.Rect, op.Radii[0], op.Radii[1], op.Radii[2], op.Radii[3], op.Pen, op.Brush)
+		case DrawShadow:
+			dst.DrawShadow(op.Rect, op.Radii[0], op.Radii[1], op.Radii[2], op.Radii[3], op.Shadow)
+		case FillPath:
+			dst.FillPath(op.Path, op.Brush)
+		case StrokePath:
+			dst.StrokePath(op.Path, op.Pen)
+		}
+	}
+}
+
+// replayed returns the canvas to pass to dst for an op that composites its
+// canvas as a whole.
+func replayed(op Op, dst gxui.Canvas) gxui.Canvas {
+	child, ok := op.Canvas.(*Canvas)
+	if !ok {
+		return op.Canvas
+	}
+	if _, ok := dst.(*Canvas); ok {
+		return child
+	}
+	if child.target == nil {
+		panic(fmt.Errorf("Display lists without a target cannot be replayed with %s", op.Type))
+	}
+	return child.target
+}
+
+func (c *Canvas) appendOp(op Op) {
+	if c.built {
+		panic(fmt.Errorf("%s() called after Complete()", op.Type))
+	}
+	c.ops = append(c.ops, op)
+}
+
+// gxui.Canvas compliance
+func (c *Canvas) Size() math.Size {
+	return c.size
+}
+
+func (c *Canvas) IsComplete() bool {
+	return c.built
+}
+
+func (c *Canvas) Complete() {
+	if c.built {
+		panic("Complete() called twice")
+	}
+	if c.buildingPushCount != 0 {
+		panic(fmt.Errorf("Push() count was %d when calling Complete", c.buildingPushCount))
+	}
+	c.built = true
+	if c.target != nil {
+		c.target.Complete()
+	}
+}
+
+func (c *Canvas) Push() {
+	c.buildingPushCount++
+	c.appendOp(Op{Type: Push})
+	if c.target != nil {
+		c.target.Push()
+	}
+}
+
+func (c *Canvas) Pop() {
+	c.buildingPushCount--
+	c.appendOp(Op{Type: Pop})
+	if c.target != nil {
+		c.target.Pop()
+	}
+}
+
+func (c *Canvas) AddClip(r math.Rect) {
+	c.appendOp(Op{Type: AddClip, Rect: r})
+	if c.target != nil {
+		c.target.AddClip(r)
+	}
+}
+
+func (c *Canvas) Transform(m math.Mat3) {
+	c.appendOp(Op{Type: Transform, Matrix: m})
+	if c.target != nil {
+		c.target.Transform(m)
+	}
+}
+
+func (c *Canvas) Clear(color gxui.Color) {
+	c.appendOp(Op{Type: Clear, Color: color})
+	if c.target != nil {
+		c.target.Clear(color)
+	}
+}
+
+func (c *Canvas) DrawCanvas(cc gxui.Canvas, offset math.Point) {
+	if cc == nil {
+		panic("Canvas cannot be nil")
+	}
+	c.appendOp(Op{Type: DrawCanvas, Canvas: cc, Point: offset})
+	if child, ok := cc.(*Canvas); ok {
+		cc = child.target
+	}
+	if c.target != nil && cc != nil {
+		c.target.DrawCanvas(cc, offset)
+	}
+}
+
+func (c *Canvas) DrawCanvasBlend(cc gxui.Canvas, offset math.Point, alpha float32, mode gxui.BlendMode) {
+	if cc == nil {
+		panic("Canvas cannot be nil")
+	}
+	c.appendOp(Op{Type: DrawCanvasBlend, Canvas: cc, Point: offset, Alpha: alpha, BlendMode: mode})
+	if child, ok := cc.(*Canvas); ok {
+		cc = child.target
+	}
+	if c.target != nil && cc != nil {
+		c.target.DrawCanvasBlend(cc, offset, alpha, mode)
+	}
+}
+
+func (c *Canvas) DrawCanvasBlur(cc gxui.Canvas, offset math.Point, radius float32) {
+	if cc == nil {
+		panic("Canvas cannot be nil")
+	}
+	c.appendOp(Op{Type: DrawCanvasBlur, Canvas: cc, Point: offset, Radius: radius})
+	if child, ok := cc.(*Canvas); ok {
+		cc = child.target
+	}
+	if c.target != nil && cc != nil {
+		c.target.DrawCanvasBlur(cc, offset, radius)
+	}
+}
+
+func (c *Canvas) DrawTexture(t gxui.Texture, r math.Rect) {
+	if t == nil {
+		panic("Texture cannot be nil")
+	}
+	c.appendOp(Op{Type: DrawTexture, Texture: t, Rect: r})
+	if c.target != nil {
+		c.target.DrawTexture(t, r)
+	}
+}
+
+func (c *Canvas) DrawRunes(f gxui.Font, r []rune, p []math.Point, col gxui.Color) {
+	if f == nil {
+		panic("Font cannot be nil")
+	}
+	c.appendOp(Op{
+		Type:   DrawRunes,
+		Font:   f,
+		Runes:  append([]rune{}, r...),
+		Points: append([]math.Point{}, p...),
+		Color:  col,
+	})
+	if c.target != nil {
+		c.target.DrawRunes(f, r, p, col)
+	}
+}
+
+func (c *Canvas) DrawLines(lines gxui.Polygon, pen gxui.Pen) {
+	c.appendOp(Op{Type: DrawLines, Polygon: append(gxui.Polygon{}, lines...), Pen: pen})
+	if c.target != nil {
+		c.target.DrawLines(lines, pen)
+	}
+}
+
+func (c *Canvas) DrawPolygon(poly gxui.Polygon, pen gxui.Pen, brush gxui.Brush) {
+	c.appendOp(Op{Type: DrawPolygon, Polygon: append(gxui.Polygon{}, poly...), Pen: pen, Brush: brush})
+	if c.target != nil {
+		c.target.DrawPolygon(poly, pen, brush)
+	}
+}
+
+func (c *Canvas) DrawRect(r math.Rect, brush gxui.Brush) {
+	c.appendOp(Op{Type: DrawRect, Rect: r, Brush: brush})
+	if c.target != nil {
+		c.target.DrawRect(r, brush)
+	}
+}
+
+func (c *Canvas) DrawRoundedRect(r math.Rect, tl, tr, bl, br float32, pen gxui.Pen, brush gxui.Brush) {
+	c.appendOp(Op{Type: DrawRoundedRect, Rect: r, Radii: [4]float32{tl, tr, bl, br}, Pen: pen, Brush: brush})
+	if c.target != nil {
+		c.target.DrawRoundedRect(r, tl, tr, bl, br, pen, brush)
+	}
+}
+
+func (c *Canvas) DrawShadow(r math.Rect, tl, tr, bl, br float32, s gxui.Shadow) {
+	c.appendOp(Op{Type: DrawShadow, Rect: r, Radii: [4]float32{tl, tr, bl, br}, Shadow: s})
+	if c.target != nil {
+		c.target.DrawShadow(r, tl, tr, bl, br, s)
+	}
+}
+
+// copyPath returns a copy of p that is unaffected by further changes to p.
+func copyPath(p *gxui.VectorPath) *gxui.VectorPath {
+	if p == nil {
+		panic("Path cannot be nil")
+	}
+	// Paths are only ever appended to, so a shallow copy holds the current
+	// commands.
+	cp := *p
+	return &cp
+}
+
+func (c *Canvas) FillPath(path *gxui.VectorPath, brush gxui.Brush) {
+	c.appendOp(Op{Type: FillPath, Path: copyPath(path), Brush: brush})
+	if c.target != nil {
+		c.target.FillPath(path, brush)
+	}
+}
+
+func (c *Canvas) StrokePath(path *gxui.VectorPath, pen gxui.Pen) {
+	c.appendOp(Op{Type: StrokePath, Path: copyPath(path), Pen: pen})
+	if c.target != nil {
+		c.target.StrokePath(path, pen)
+	}
+}
+
+// Release releases the target canvas. The recorded operations remain
+// available to any display list that draws this canvas.
+func (c *Canvas) Release() {
+	if c.target != nil {
+		c.target.Release()
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package displaylist
+
+import test "github.com/google/gxui/testing"
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/drivers/soft"
+	"github.com/google/gxui/math"
+	"github.com/google/gxui/themes/dark"
+)
+
+func drawFrame(color gxui.Color) *Canvas {
+	child := CreateCanvas(math.Size{W: 5, H: 5}, nil)
+	child.DrawRoundedRect(math.CreateRect(0, 0, 5, 5), 1, 1, 0, 0, gxui.CreatePen(1, gxui.Black), gxui.CreateBrush(color))
+	child.Complete()
+
+	c := CreateCanvas(math.Size{W: 20, H: 10}, nil)
+	c.Clear(gxui.White)
+	c.Push()
+	c.AddClip(math.CreateRect(0, 0, 10, 10))
+	c.DrawCanvas(child, math.Point{X: 2, Y: 3})
+	c.Pop()
+	c.DrawLines(gxui.Polygon{
+		gxui.PolygonVertex{Position: math.Point{X: 0, Y: 0}},
+		gxui.PolygonVertex{Position: math.Point{X: 10, Y: 0}, RoundedRadius: 2},
+		gxui.PolygonVertex{Position: math.Point{X: 10, Y: 10}},
+	}, gxui.Pen{Width: 2, Color: gxui.Red, Cap: gxui.RoundCap, Dash: gxui.CreateDash(1, 3, 2)})
+	p := &gxui.VectorPath{}
+	p.MoveTo(math.Vec2{X: 1, Y: 1})
+	p.LineTo(math.Vec2{X: 9, Y: 1})
+	p.Close()
+	c.FillPath(p, gxui.CreateVerticalGradientBrush(gxui.Black, gxui.White))
+	c.Complete()
+	return c
+}
+
+func TestDump(t *testing.T) {
+	test.AssertEquals(t, `Clear rgba(1, 1, 1, 1)
+Push
+AddClip (0, 0)-(10, 10)
+DrawCanvas (2, 3) 5x5
+  DrawRoundedRect (0, 0)-(5, 5) radii(1, 1, 0, 0) pen(1 rgba(0, 0, 0, 1)) rgba(1, 0, 0, 1)
+Pop
+DrawLines [(0, 0) (10, 0)r2 (10, 10)] pen(2 rgba(1, 0, 0, 1) round miter dash=[3 2]+1)
+FillPath path(nonzero M (1, 1) L (9, 1) Z) gradient(linear relative (0, 0) (0, 1) [0:rgba(0, 0, 0, 1) 1:rgba(1, 1, 1, 1)])
+`, drawFrame(gxui.Red).String())
+}
+
+func TestReplay(t *testing.T) {
+	c := drawFrame(gxui.Red)
+	r := CreateCanvas(c.Size(), nil)
+	c.Replay(r)
+	r.Complete()
+
+	// The child canvas is replayed inline with a translation.
+	want := strings.Replace(c.String(), `DrawCanvas (2, 3) 5x5
+  DrawRoundedRect`, `Push
+Transform [1 0 0; 0 1 0; 2 3 1]
+DrawRoundedRect`, 1)
+	want = strings.Replace(want, "Pop\n", "Pop\nPop\n", 1)
+	test.AssertEquals(t, want, r.String())
+}
+
+func TestDiff(t *testing.T) {
+	test.AssertEquals(t, "", Diff(drawFrame(gxui.Red), drawFrame(gxui.Red)))
+	test.AssertEquals(t,
+		"-   DrawRoundedRect (0, 0)-(5, 5) radii(1, 1, 0, 0) pen(1 rgba(0, 0, 0, 1)) rgba(1, 0, 0, 1)\n"+
+			"+   DrawRoundedRect (0, 0)-(5, 5) radii(1, 1, 0, 0) pen(1 rgba(0, 0, 0, 1)) rgba(0, 0, 1, 1)\n",
+		Diff(drawFrame(gxui.Red), drawFrame(gxui.Blue)))
+}
+
+func TestWrapDriver(t *testing.T) {
+	var before, after *Canvas
+	soft.StartDriver(func(driver gxui.Driver) {
+		theme := dark.CreateTheme(WrapDriver(driver))
+		label := theme.CreateLabel()
+		label.SetText("one")
+		window := theme.CreateWindow(100, 50, "test")
+		window.AddChild(label)
+		driver.Call(func() {
+			before = label.Draw().(*Canvas)
+			label.SetText("two")
+			driver.Call(func() {
+				defer driver.Terminate()
+				after = label.Draw().(*Canvas)
+			})
+		})
+	})
+	diff := Diff(before, after)
+	if !strings.Contains(diff, `- DrawRunes`) || !strings.Contains(diff, `"one"`) ||
+		!strings.Contains(diff, `+ DrawRunes`) || !strings.Contains(diff, `"two"`) {
+		t.Errorf("Expected the diff to show the changed text. Got:\n%s", diff)
+	}
+}
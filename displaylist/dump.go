@@ -0,0 +1,293 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package displaylist
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+)
+
+// String returns the display list as text, with one operation per line. The
+// operations of display lists drawn with DrawCanvas are indented beneath the
+// DrawCanvas line. Fonts and textures are numbered in the order that they
+// first appear, so the dumps of two frames that use the same resources in the
+// same order can be compared directly.
+func (c *Canvas) String() string {
+	d := &dumper{
+		fonts:    make(map[gxui.Font]int),
+		textures: make(map[gxui.Texture]int),
+	}
+	d.canvas(c, 0)
+	return d.buf.String()
+}
+
+// Diff compares the text dumps of a and b, returning the lines only in a
+// prefixed with "- " and the lines only in b prefixed with "+ ". Diff returns
+// an empty string if the dumps are equal.
+func Diff(a, b *Canvas) string {
+	x := strings.SplitAfter(a.String(), "\n")
+	y := strings.SplitAfter(b.String(), "\n")
+
+	// Skip the common prefix and suffix, which are usually most of a frame.
+	for len(x) > 0 && len(y) > 0 && x[0] == y[0] {
+		x, y = x[1:], y[1:]
+	}
+	for len(x) > 0 && len(y) > 0 && x[len(x)-1] == y[len(y)-1] {
+		x, y = x[:len(x)-1], y[:len(y)-1]
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and
+	// y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = math.Max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			i, j = i+1, j+1
+		case j == len(y) || (i < len(x) && lcs[i+1][j] >= lcs[i][j+1]):
+			buf.WriteString("- " + x[i])
+			i++
+		default:
+			buf.WriteString("+ " + y[j])
+			j++
+		}
+	}
+	return buf.String()
+}
+
+type dumper struct {
+	buf      bytes.Buffer
+	fonts    map[gxui.Font]int
+	textures map[gxui.Texture]int
+}
+
+func (d *dumper) canvas(c *Canvas, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for _, op := range c.ops {
+		d.buf.WriteString(indent)
+		d.buf.WriteString(op.Type.String())
+		if args := d.args(op); args != "" {
+			d.buf.WriteString(" ")
+			d.buf.WriteString(args)
+		}
+		d.buf.WriteString("\n")
+		if child, ok := op.Canvas.(*Canvas); ok {
+			d.canvas(child, depth+1)
+		}
+	}
+}
+
+func (d *dumper) args(op Op) string {
+	switch op.Type {
+	case AddClip:
+		return rect(op.Rect)
+	case Transform:
+		m := op.Matrix
+		return fmt.Sprintf("[%s %s %s; %s %s %s; %s %s %s]",
+			num(m[0]), num(m[1]), num(m[2]),
+			num(m[3]), num(m[4]), num(m[5]),
+			num(m[6]), num(m[7]), num(m[8]))
+	case Clear:
+		return color(op.Color)
+	case DrawCanvas, DrawCanvasBlend, DrawCanvasBlur:
+		s := fmt.Sprintf("%s %dx%d", point(op.Point), op.Canvas.Size().W, op.Canvas.Size().H)
+		switch op.Type {
+		case DrawCanvasBlend:
+			s += fmt.Sprintf(" alpha=%s %s", num(op.Alpha), blendModes[op.BlendMode])
+		case DrawCanvasBlur:
+			s += " radius=" + num(op.Radius)
+		}
+		if _, ok := op.Canvas.(*Canvas); !ok {
+			s += " (not recorded)"
+		}
+		return s
+	case DrawTexture:
+		return d.texture(op.Texture) + " " + rect(op.Rect)
+	case DrawRunes:
+		points := make([]string, len(op.Points))
+		for i, p := range op.Points {
+			points[i] = point(p)
+		}
+		return fmt.Sprintf("%s %s %s [%s]", d.font(op.Font), color(op.Color),
+			strconv.Quote(string(op.Runes)), strings.Join(points, " "))
+	case DrawLines:
+		return polygon(op.Polygon) + " " + d.pen(op.Pen)
+	case DrawPolygon:
+		return polygon(op.Polygon) + " " + d.pen(op.Pen) + " " + d.brush(op.Brush)
+	case DrawRect:
+		return rect(op.Rect) + " " + d.brush(op.Brush)
+	case DrawRoundedRect:
+		return fmt.Sprintf("%s radii(%s, %s, %s, %s) %s %s", rect(op.Rect),
+			num(op.Radii[0]), num(op.Radii[1]), num(op.Radii[2]), num(op.Radii[3]),
+			d.pen(op.Pen), d.brush(op.Brush))
+	case DrawShadow:
+		return fmt.Sprintf("%s radii(%s, %s, %s, %s) %s", rect(op.Rect),
+			num(op.Radii[0]), num(op.Radii[1]), num(op.Radii[2]), num(op.Radii[3]),
+			shadow(op.Shadow))
+	case FillPath:
+		return path(op.Path) + " " + d.brush(op.Brush)
+	case StrokePath:
+		return path(op.Path) + " " + d.pen(op.Pen)
+	}
+	return ""
+}
+
+func (d *dumper) font(f gxui.Font) string {
+	id, ok := d.fonts[f]
+	if !ok {
+		id = len(d.fonts) + 1
+		d.fonts[f] = id
+	}
+	return fmt.Sprintf("font#%d(%d)", id, f.Size())
+}
+
+func (d *dumper) texture(t gxui.Texture) string {
+	id, ok := d.textures[t]
+	if !ok {
+		id = len(d.textures) + 1
+		d.textures[t] = id
+	}
+	s := fmt.Sprintf("texture#%d(%dx%d", id, t.Size().W, t.Size().H)
+	if t.FlipY() {
+		s += " flipped"
+	}
+	return s + ")"
+}
+
+var blendModes = [...]string{
+	gxui.BlendNormal:   "normal",
+	gxui.BlendMultiply: "multiply",
+	gxui.BlendScreen:   "screen",
+	gxui.BlendAdditive: "additive",
+}
+
+var lineCaps = [...]string{gxui.ButtCap: "butt", gxui.RoundCap: "round", gxui.SquareCap: "square"}
+var lineJoins = [...]string{gxui.MiterJoin: "miter", gxui.RoundJoin: "round", gxui.BevelJoin: "bevel"}
+
+func (d *dumper) pen(p gxui.Pen) string {
+	s := fmt.Sprintf("pen(%s %s", num(p.Width), color(p.Color))
+	if p.IsStyled() {
+		s += fmt.Sprintf(" %s %s", lineCaps[p.Cap], lineJoins[p.Join])
+		if p.MiterLimit != 0 {
+			s += " limit=" + num(p.MiterLimit)
+		}
+		if p.Dash != nil {
+			lengths := make([]string, len(p.Dash.Lengths))
+			for i, l := range p.Dash.Lengths {
+				lengths[i] = num(l)
+			}
+			s += fmt.Sprintf(" dash=[%s]+%s", strings.Join(lengths, " "), num(p.Dash.Offset))
+		}
+	}
+	return s + ")"
+}
+
+func (d *dumper) brush(b gxui.Brush) string {
+	switch {
+	case b.Gradient != nil:
+		g := b.Gradient
+		ty := "linear"
+		if g.Type == gxui.RadialGradient {
+			ty = "radial"
+		}
+		if g.Relative {
+			ty += " relative"
+		}
+		stops := make([]string, len(g.Stops))
+		for i, s := range g.Stops {
+			stops[i] = num(s.Offset) + ":" + color(s.Color)
+		}
+		return fmt.Sprintf("gradient(%s %s %s [%s])", ty, vec(g.Start), vec(g.End), strings.Join(stops, " "))
+	case b.Pattern != nil:
+		p := b.Pattern
+		mode := "stretch"
+		if p.Mode == gxui.PatternTile {
+			mode = "tile"
+		}
+		return fmt.Sprintf("pattern(%s %s %s)", d.texture(p.Texture), mode, rect(p.Rect))
+	default:
+		return color(b.Color)
+	}
+}
+
+func num(f float32) string {
+	return strconv.FormatFloat(float64(f), 'g', -1, 32)
+}
+
+func point(p math.Point) string {
+	return fmt.Sprintf("(%d, %d)", p.X, p.Y)
+}
+
+func vec(v math.Vec2) string {
+	return fmt.Sprintf("(%s, %s)", num(v.X), num(v.Y))
+}
+
+func rect(r math.Rect) string {
+	return point(r.Min) + "-" + point(r.Max)
+}
+
+func color(c gxui.Color) string {
+	return fmt.Sprintf("rgba(%s, %s, %s, %s)", num(c.R), num(c.G), num(c.B), num(c.A))
+}
+
+func shadow(s gxui.Shadow) string {
+	ty := "outer"
+	if s.Inner {
+		ty = "inner"
+	}
+	return fmt.Sprintf("shadow(%s %s offset=%s blur=%s)", ty, color(s.Color), point(s.Offset), num(s.Blur))
+}
+
+func polygon(p gxui.Polygon) string {
+	vertices := make([]string, len(p))
+	for i, v := range p {
+		vertices[i] = point(v.Position)
+		if v.RoundedRadius != 0 {
+			vertices[i] += "r" + num(v.RoundedRadius)
+		}
+	}
+	return "[" + strings.Join(vertices, " ") + "]"
+}
+
+func path(p *gxui.VectorPath) string {
+	s := []string{"nonzero"}
+	if p.FillRule == gxui.EvenOdd {
+		s[0] = "evenodd"
+	}
+	for _, c := range p.Commands() {
+		switch c.Type {
+		case gxui.MoveTo:
+			s = append(s, "M", vec(c.Points[0]))
+		case gxui.LineTo:
+			s = append(s, "L", vec(c.Points[0]))
+		case gxui.QuadTo:
+			s = append(s, "Q", vec(c.Points[0]), vec(c.Points[1]))
+		case gxui.CubicTo:
+			s = append(s, "C", vec(c.Points[0]), vec(c.Points[1]), vec(c.Points[2]))
+		case gxui.Close:
+			s = append(s, "Z")
+		}
+	}
+	return "path(" + strings.Join(s, " ") + ")"
+}
@@ -6,6 +6,7 @@ package gxui
 
 import (
 	"image"
+	"time"
 
 	"github.com/google/gxui/math"
 )
@@ -21,6 +22,24 @@ type Driver interface {
 	// not be called.
 	CallSync(f func()) bool
 
+	// Clock returns the source of time used by the driver's timers.
+	Clock() Clock
+
+	// After calls f on the UI go-routine once d has elapsed, unless the
+	// returned Timer is stopped first.
+	After(d time.Duration, f func()) Timer
+
+	// Every calls f on the UI go-routine each time interval elapses, until the
+	// returned Timer is stopped.
+	Every(interval time.Duration, f func()) Timer
+
+	// OnFrame calls f on the UI go-routine once per frame, until the returned
+	// Timer is stopped. If the UI go-routine falls behind, frames are dropped
+	// instead of queuing up.
+	OnFrame(f func()) Timer
+
+	// Terminate closes all viewports, stops all timers and then stops the
+	// driver once all pending functions have been run.
 	Terminate()
 	SetClipboard(str string)
 	GetClipboard() (string, error)
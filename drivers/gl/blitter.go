@@ -32,6 +32,40 @@ const (
     gl_FragColor = texture2D(source, vTexcoords);
   }`
 
+	fsLayerSrc = `
+  uniform sampler2D source;
+  uniform float Alpha;
+  varying vec2 vTexcoords;
+  void main() {
+    gl_FragColor = texture2D(source, vTexcoords) * Alpha; // PMA
+  }`
+
+	fsBlurSrc = `
+  uniform sampler2D source;
+  uniform vec2 Step;
+  uniform float Sigma;
+  uniform float Taps;
+  uniform vec2 UVMin;
+  uniform vec2 UVMax;
+  varying vec2 vTexcoords;
+  void main() {
+    vec4 sum = vec4(0.0);
+    float total = 0.0;
+    for (int i = -32; i <= 32; i++) {
+      float x = float(i);
+      if (abs(x) > Taps) {
+        continue;
+      }
+      float w = exp(-x * x / (2.0 * Sigma * Sigma));
+      vec2 uv = vTexcoords + Step * x;
+      total += w;
+      if (all(greaterThanEqual(uv, UVMin)) && all(lessThanEqual(uv, UVMax))) {
+        sum += texture2D(source, uv) * w;
+      }
+    }
+    gl_FragColor = sum / total; // PMA
+  }`
+
 	vsColorSrc = `
   attribute vec2 aPosition;
   uniform mat3 mPos;
@@ -47,6 +81,73 @@ const (
     gl_FragColor *= gl_FragColor.a; // PMA
   }`
 
+	vsPaintSrc = `
+  attribute vec2 aPosition;
+  varying vec2 vLocal;
+  uniform mat3 mPos;
+  uniform mat3 mLocal;
+  void main() {
+  	vec3 pos3 = vec3(aPosition, 1.0);
+    gl_Position = vec4((mPos * pos3).xy, 0.0, 1.0);
+    vLocal = (mLocal * pos3).xy;
+  }`
+
+	fsGradientSrc = `
+  uniform sampler2D ramp;
+  uniform vec2 Start;
+  uniform vec2 End;
+  uniform float Radial;
+  varying vec2 vLocal;
+  void main() {
+    vec2 d = End - Start;
+    vec2 p = vLocal - Start;
+    float t = mix(dot(p, d) / dot(d, d), length(p) / length(d), Radial);
+    t = clamp(t, 0.0, 1.0);
+    gl_FragColor = texture2D(ramp, vec2((t * 255.0 + 0.5) / 256.0, 0.5));
+  }`
+
+	fsPatternSrc = `
+  uniform sampler2D source;
+  uniform mat3 mUV;
+  uniform float Tile;
+  varying vec2 vLocal;
+  void main() {
+    vec2 uv = (mUV * vec3(vLocal, 1.0)).xy;
+    uv = mix(clamp(uv, 0.0, 1.0), fract(uv), Tile);
+    gl_FragColor = texture2D(source, uv);
+  }`
+
+	fsShadowSrc = `
+  uniform vec4 Color;
+  uniform vec2 Center;
+  uniform vec2 Half;
+  uniform vec4 Radii;
+  uniform vec2 Offset;
+  uniform float Scale;
+  uniform float Sigma;
+  uniform float Inner;
+  varying vec2 vLocal;
+  float roundedRectDistance(vec2 p) {
+    vec2 q = p - Center;
+    float r = q.y < 0.0 ? (q.x < 0.0 ? Radii.x : Radii.y) : (q.x < 0.0 ? Radii.z : Radii.w);
+    vec2 d = abs(q) - Half + r;
+    return length(max(d, 0.0)) + min(max(d.x, d.y), 0.0) - r;
+  }
+  float erf(float x) {
+    float a = 0.147;
+    float x2 = x * x;
+    return sign(x) * sqrt(1.0 - exp(-x2 * (1.2732395 + a * x2) / (1.0 + a * x2)));
+  }
+  void main() {
+    float inside = clamp(0.5 - roundedRectDistance(vLocal) * Scale, 0.0, 1.0);
+    float d = roundedRectDistance(vLocal - Offset) * Scale;
+    float shadow = Sigma > 0.5
+      ? 0.5 - 0.5 * erf(d / (Sigma * 1.4142136))
+      : clamp(0.5 - d, 0.0, 1.0);
+    float coverage = mix(shadow * (1.0 - inside), inside * (1.0 - shadow), Inner);
+    gl_FragColor = vec4(Color.rgb, 1.0) * Color.a * coverage; // PMA
+  }`
+
 	vsFontSrc = `
   attribute vec2 aSrc;
   attribute vec2 aDst;
@@ -88,28 +189,43 @@ type glyphBatch struct {
 }
 
 type blitter struct {
-	stats       *contextStats
-	quad        *shape
-	copyShader  *shaderProgram
-	colorShader *shaderProgram
-	fontShader  *shaderProgram
-	glyphBatch  glyphBatch
+	stats          *contextStats
+	quad           *shape
+	copyShader     *shaderProgram
+	layerShader    *shaderProgram
+	blurShader     *shaderProgram
+	shadowShader   *shaderProgram
+	colorShader    *shaderProgram
+	gradientShader *shaderProgram
+	patternShader  *shaderProgram
+	fontShader     *shaderProgram
+	glyphBatch     glyphBatch
 }
 
 func newBlitter(ctx *context, stats *contextStats) *blitter {
 	return &blitter{
-		stats:       stats,
-		quad:        newQuadShape(),
-		copyShader:  newShaderProgram(ctx, vsCopySrc, fsCopySrc),
-		colorShader: newShaderProgram(ctx, vsColorSrc, fsColorSrc),
-		fontShader:  newShaderProgram(ctx, vsFontSrc, fsFontSrc),
+		stats:          stats,
+		quad:           newQuadShape(),
+		copyShader:     newShaderProgram(ctx, vsCopySrc, fsCopySrc),
+		layerShader:    newShaderProgram(ctx, vsCopySrc, fsLayerSrc),
+		blurShader:     newShaderProgram(ctx, vsCopySrc, fsBlurSrc),
+		shadowShader:   newShaderProgram(ctx, vsPaintSrc, fsShadowSrc),
+		colorShader:    newShaderProgram(ctx, vsColorSrc, fsColorSrc),
+		gradientShader: newShaderProgram(ctx, vsPaintSrc, fsGradientSrc),
+		patternShader:  newShaderProgram(ctx, vsPaintSrc, fsPatternSrc),
+		fontShader:     newShaderProgram(ctx, vsFontSrc, fsFontSrc),
 	}
 }
 
 func (b *blitter) destroy(ctx *context) {
 	b.quad.release()
 	b.copyShader.destroy(ctx)
+	b.layerShader.destroy(ctx)
+	b.blurShader.destroy(ctx)
+	b.shadowShader.destroy(ctx)
 	b.colorShader.destroy(ctx)
+	b.gradientShader.destroy(ctx)
+	b.patternShader.destroy(ctx)
 	b.fontShader.destroy(ctx)
 }
 
@@ -117,9 +233,28 @@ func (b *blitter) blit(ctx *context, tc *textureContext, srcRect, dstRect math.R
 	b.commitGlyphs(ctx)
 
 	dstRect = dstRect.Offset(ds.OriginPixels)
-	sw, sh := tc.sizePixels.WH()
 	dw, dh := ctx.sizePixels.WH()
 
+	mPos := math.CreateMat3(
+		+2.0*float32(dstRect.W())/float32(dw), 0, 0,
+		0, -2.0*float32(dstRect.H())/float32(dh), 0,
+		-1.0+2.0*float32(dstRect.Min.X)/float32(dw),
+		+1.0-2.0*float32(dstRect.Min.Y)/float32(dh), 1,
+	)
+	b.drawTexturedQuad(ctx, tc, srcRect, mPos)
+}
+
+// blitTransformed draws the srcRect region of the texture to dstRectDips,
+// which is in local DIPs and transformed by the draw state's transform.
+func (b *blitter) blitTransformed(ctx *context, tc *textureContext, srcRect, dstRectDips math.Rect, ds *drawState) {
+	b.commitGlyphs(ctx)
+	mPos := unitQuadToRect(dstRectDips).Mul(ds.Transform).Mul(ctx.pixelsToNDC())
+	b.drawTexturedQuad(ctx, tc, srcRect, mPos)
+}
+
+func (b *blitter) drawTexturedQuad(ctx *context, tc *textureContext, srcRect math.Rect, mPos math.Mat3) {
+	sw, sh := tc.sizePixels.WH()
+
 	var mUV math.Mat3
 	if tc.flipY {
 		mUV = math.CreateMat3(
@@ -136,12 +271,6 @@ func (b *blitter) blit(ctx *context, tc *textureContext, srcRect, dstRect math.R
 			float32(srcRect.Min.Y)/float32(sh), 1,
 		)
 	}
-	mPos := math.CreateMat3(
-		+2.0*float32(dstRect.W())/float32(dw), 0, 0,
-		0, -2.0*float32(dstRect.H())/float32(dh), 0,
-		-1.0+2.0*float32(dstRect.Min.X)/float32(dw),
-		+1.0-2.0*float32(dstRect.Min.Y)/float32(dh), 1,
-	)
 	if !tc.pma {
 		gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
 	}
@@ -156,9 +285,131 @@ func (b *blitter) blit(ctx *context, tc *textureContext, srcRect, dstRect math.R
 	b.stats.drawCallCount++
 }
 
+// blitLayer composites the framebuffer onto the current render target within
+// the clip, scaled by alpha and combined using mode. The layer and target hold
+// pre-multiplied colours.
+func (b *blitter) blitLayer(ctx *context, fb *framebuffer, alpha float32, mode gxui.BlendMode) {
+	b.commitGlyphs(ctx)
+	mPos := unitQuadToRect(ctx.sizePixels.Rect()).Mul(ctx.pixelsToNDC())
+	// Framebuffer rows start at the bottom of the window.
+	mUV := math.CreateMat3(1, 0, 0, 0, -1, 0, 0, 1, 1)
+	draw := func() {
+		b.quad.draw(ctx, b.layerShader, uniformBindings{
+			"source": fb.tc,
+			"mUV":    mUV,
+			"mPos":   mPos,
+			"Alpha":  alpha,
+		})
+		b.stats.drawCallCount++
+	}
+	switch mode {
+	case gxui.BlendMultiply:
+		// s·d + s·(1-da) + d·(1-sa) takes two passes: the first computes
+		// s·d + d·(1-sa) leaving the destination alpha untouched, the second
+		// adds s·(1-da) and combines the alphas normally.
+		gl.BlendFuncSeparate(gl.DST_COLOR, gl.ONE_MINUS_SRC_ALPHA, gl.ZERO, gl.ONE)
+		draw()
+		gl.BlendFuncSeparate(gl.ONE_MINUS_DST_ALPHA, gl.ONE, gl.ONE, gl.ONE_MINUS_SRC_ALPHA)
+		draw()
+	case gxui.BlendScreen:
+		gl.BlendFunc(gl.ONE, gl.ONE_MINUS_SRC_COLOR)
+		draw()
+	case gxui.BlendAdditive:
+		gl.BlendFunc(gl.ONE, gl.ONE)
+		draw()
+	default:
+		draw()
+	}
+	gl.BlendFunc(gl.ONE, gl.ONE_MINUS_SRC_ALPHA)
+}
+
+// blitBlur draws the framebuffer onto the current render target within the
+// clip, blurred along one axis by a Gaussian with the standard deviation sigma
+// in pixels. Only the framebuffer pixels inside rect are sampled.
+func (b *blitter) blitBlur(ctx *context, fb *framebuffer, rect math.Rect, sigma float32, horizontal bool) {
+	b.commitGlyphs(ctx)
+	mPos := unitQuadToRect(ctx.sizePixels.Rect()).Mul(ctx.pixelsToNDC())
+	// Framebuffer rows start at the bottom of the window.
+	mUV := math.CreateMat3(1, 0, 0, 0, -1, 0, 0, 1, 1)
+	w, h := float32(fb.sizePixels.W), float32(fb.sizePixels.H)
+
+	// The shader takes at most 32 samples either side of each pixel, so wide
+	// blurs sample every spacing pixels.
+	spacing := math.Maxf(1, math.Ceilf(sigma*3/32))
+	step := math.Vec2{Y: spacing / h}
+	if horizontal {
+		step = math.Vec2{X: spacing / w}
+	}
+	b.quad.draw(ctx, b.blurShader, uniformBindings{
+		"source": fb.tc,
+		"mUV":    mUV,
+		"mPos":   mPos,
+		"Step":   step,
+		"Sigma":  sigma / spacing,
+		"Taps":   math.Ceilf(sigma * 3 / spacing),
+		"UVMin":  math.Vec2{X: float32(rect.Min.X) / w, Y: 1 - float32(rect.Max.Y)/h},
+		"UVMax":  math.Vec2{X: float32(rect.Max.X) / w, Y: 1 - float32(rect.Min.Y)/h},
+	})
+	b.stats.drawCallCount++
+}
+
+// blitShadow draws the shadow s of the rounded rectangle r, which is in local
+// DIPs and has the corner radii tl, tr, bl and br.
+func (b *blitter) blitShadow(ctx *context, r math.Rect, radii [4]float32, s gxui.Shadow, ds *drawState) {
+	b.commitGlyphs(ctx)
+	mLocal := unitQuadToRect(s.Bounds(r).ExpandI(1))
+	mPos := mLocal.Mul(ds.pixelTransform(ctx.resolution)).Mul(ctx.pixelsToNDC())
+	scale := ds.pixelScale(ctx.resolution)
+	half := math.Vec2{X: float32(r.W()) / 2, Y: float32(r.H()) / 2}
+	limit := math.Minf(half.X, half.Y)
+	inner := float32(0)
+	if s.Inner {
+		inner = 1
+	}
+	// The edge of the shadow is a step blurred by a Gaussian with a standard
+	// deviation of half the blur radius. Blurs narrower than a pixel are
+	// anti-aliased like other shapes instead.
+	b.quad.draw(ctx, b.shadowShader, uniformBindings{
+		"mPos":   mPos,
+		"mLocal": mLocal,
+		"Color":  s.Color,
+		"Center": r.Min.Vec2().Add(half),
+		"Half":   half,
+		"Radii": math.Vec4{
+			X: math.Clampf(radii[0], 0, limit),
+			Y: math.Clampf(radii[1], 0, limit),
+			Z: math.Clampf(radii[2], 0, limit),
+			W: math.Clampf(radii[3], 0, limit),
+		},
+		"Offset": s.Offset.Vec2(),
+		"Scale":  scale,
+		"Sigma":  s.Blur / 2 * scale,
+		"Inner":  inner,
+	})
+	b.stats.drawCallCount++
+}
+
 func (b *blitter) blitGlyph(ctx *context, tc *textureContext, c gxui.Color, srcRect, dstRect math.Rect, ds *drawState) {
 	dstRect = dstRect.Offset(ds.OriginPixels)
+	b.blitGlyphQuad(ctx, tc, c, srcRect, [4]math.Vec2{
+		dstRect.TL().Vec2(), dstRect.TR().Vec2(),
+		dstRect.BL().Vec2(), dstRect.BR().Vec2(),
+	}, ds)
+}
+
+// blitGlyphTransformed draws the glyph to dstRectDips, which is in local DIPs
+// and transformed by the draw state's transform.
+func (b *blitter) blitGlyphTransformed(ctx *context, tc *textureContext, c gxui.Color, srcRect math.Rect, dstRectDips [4]math.Vec2, ds *drawState) {
+	for i, v := range dstRectDips {
+		dstRectDips[i] = ds.Transform.Transform(v)
+	}
+	b.blitGlyphQuad(ctx, tc, c, srcRect, dstRectDips, ds)
+}
 
+// blitGlyphQuad adds the glyph to the batch, drawing it to the quad with the
+// top-left, top-right, bottom-left and bottom-right vertices dst, in window
+// pixels.
+func (b *blitter) blitGlyphQuad(ctx *context, tc *textureContext, c gxui.Color, srcRect math.Rect, dst [4]math.Vec2, ds *drawState) {
 	if b.glyphBatch.GlyphPage != tc {
 		b.commitGlyphs(ctx)
 		b.glyphBatch.GlyphPage = tc
@@ -171,10 +422,10 @@ func (b *blitter) blitGlyph(ctx *context, tc *textureContext, c gxui.Color, srcR
 		float32(ds.ClipPixels.Max.Y),
 	}
 	b.glyphBatch.DstRects = append(b.glyphBatch.DstRects,
-		float32(dstRect.Min.X), float32(dstRect.Min.Y),
-		float32(dstRect.Max.X), float32(dstRect.Min.Y),
-		float32(dstRect.Min.X), float32(dstRect.Max.Y),
-		float32(dstRect.Max.X), float32(dstRect.Max.Y),
+		dst[0].X, dst[0].Y,
+		dst[1].X, dst[1].Y,
+		dst[2].X, dst[2].Y,
+		dst[3].X, dst[3].Y,
 	)
 	b.glyphBatch.SrcRects = append(b.glyphBatch.SrcRects,
 		float32(srcRect.Min.X), float32(srcRect.Min.Y),
@@ -204,14 +455,19 @@ func (b *blitter) blitGlyph(ctx *context, tc *textureContext, c gxui.Color, srcR
 
 func (b *blitter) blitShape(ctx *context, shape shape, color gxui.Color, ds *drawState) {
 	b.commitGlyphs(ctx)
-	dipsToPixels := ctx.resolution.dipsToPixels()
-	dw, dh := ctx.sizePixels.WH()
-	mPos := math.CreateMat3(
-		+2.0*dipsToPixels/float32(dw), 0, 0,
-		0, -2.0*dipsToPixels/float32(dh), 0,
-		-1.0+2.0*float32(ds.OriginPixels.X)/float32(dw),
-		+1.0-2.0*float32(ds.OriginPixels.Y)/float32(dh), 1,
-	)
+	var mPos math.Mat3
+	if ds.Transformed {
+		mPos = ds.Transform.Mul(ctx.pixelsToNDC())
+	} else {
+		dipsToPixels := ctx.resolution.dipsToPixels()
+		dw, dh := ctx.sizePixels.WH()
+		mPos = math.CreateMat3(
+			+2.0*dipsToPixels/float32(dw), 0, 0,
+			0, -2.0*dipsToPixels/float32(dh), 0,
+			-1.0+2.0*float32(ds.OriginPixels.X)/float32(dw),
+			+1.0-2.0*float32(ds.OriginPixels.Y)/float32(dh), 1,
+		)
+	}
 
 	shape.draw(ctx, b.colorShader, uniformBindings{
 		"mPos":  mPos,
@@ -247,6 +503,78 @@ func (b *blitter) blitRect(ctx *context, dstRect math.Rect, color gxui.Color, ds
 	b.stats.drawCallCount++
 }
 
+// blitRectTransformed fills rectDips, which is in local DIPs and transformed
+// by the draw state's transform.
+func (b *blitter) blitRectTransformed(ctx *context, rectDips math.Rect, color gxui.Color, ds *drawState) {
+	b.commitGlyphs(ctx)
+	mPos := unitQuadToRect(rectDips).Mul(ds.Transform).Mul(ctx.pixelsToNDC())
+	b.quad.draw(ctx, b.colorShader, uniformBindings{
+		"mPos":  mPos,
+		"Color": color,
+	})
+	b.stats.drawCallCount++
+}
+
+// blitPaint fills the shape with the non-solid brush. mLocal transforms the
+// shape's vertices to local DIPs, which are then transformed by the draw
+// state. ramp is the texture holding the colours of a gradient brush.
+func (b *blitter) blitPaint(ctx *context, shape *shape, mLocal math.Mat3, brush gxui.Brush, ramp *texture, ds *drawState) {
+	b.commitGlyphs(ctx)
+	mPos := mLocal.Mul(ds.pixelTransform(ctx.resolution)).Mul(ctx.pixelsToNDC())
+	switch {
+	case brush.Gradient != nil:
+		g := brush.Gradient
+		radial := float32(0)
+		if g.Type == gxui.RadialGradient {
+			radial = 1
+		}
+		shape.draw(ctx, b.gradientShader, uniformBindings{
+			"mPos":   mPos,
+			"mLocal": mLocal,
+			"ramp":   ctx.getOrCreateTextureContext(ramp),
+			"Start":  g.Start,
+			"End":    g.End,
+			"Radial": radial,
+		})
+	case brush.Pattern != nil:
+		p := brush.Pattern
+		tc := ctx.getOrCreateTextureContext(p.Texture.(*texture))
+		size := p.Rect.Size()
+		mUV := math.CreateMat3Translate(p.Rect.Min.Vec2().Neg()).Mul(
+			math.CreateMat3Scale(math.Vec2{X: 1 / float32(size.W), Y: 1 / float32(size.H)}))
+		if tc.flipY {
+			mUV = mUV.Mul(math.CreateMat3(1, 0, 0, 0, -1, 0, 0, 1, 1))
+		}
+		tile := float32(0)
+		if p.Mode == gxui.PatternTile {
+			tile = 1
+		}
+		if !tc.pma {
+			gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+		}
+		shape.draw(ctx, b.patternShader, uniformBindings{
+			"mPos":   mPos,
+			"mLocal": mLocal,
+			"mUV":    mUV,
+			"source": tc,
+			"Tile":   tile,
+		})
+		if !tc.pma {
+			gl.BlendFunc(gl.ONE, gl.ONE_MINUS_SRC_ALPHA)
+		}
+	}
+	b.stats.drawCallCount++
+}
+
+// unitQuadToRect returns the transform from the unit quad to the rectangle r.
+func unitQuadToRect(r math.Rect) math.Mat3 {
+	return math.CreateMat3(
+		float32(r.W()), 0, 0,
+		0, float32(r.H()), 0,
+		float32(r.Min.X), float32(r.Min.Y), 1,
+	)
+}
+
 func (b *blitter) commit(ctx *context) {
 	b.commitGlyphs(ctx)
 }
@@ -6,9 +6,12 @@ package gl
 
 import (
 	"fmt"
+	"image"
+	"image/color"
 
 	"github.com/go-gl/gl/v2.1/gl"
 	"github.com/google/gxui"
+	"github.com/google/gxui/drivers/internal/stroke"
 	"github.com/google/gxui/math"
 )
 
@@ -30,6 +33,31 @@ type drawState struct {
 	// The below are all in window coordinates
 	ClipPixels   math.Rect
 	OriginPixels math.Point
+	// If Transformed is true then Transform maps local DIPs to window pixels,
+	// and OriginPixels is unused.
+	Transform   math.Mat3
+	Transformed bool
+}
+
+// pixelTransform returns the transform from local DIPs to window pixels.
+func (s *drawState) pixelTransform(r resolution) math.Mat3 {
+	if s.Transformed {
+		return s.Transform
+	}
+	dipsToPixels := r.dipsToPixels()
+	return math.CreateMat3(
+		dipsToPixels, 0, 0,
+		0, dipsToPixels, 0,
+		float32(s.OriginPixels.X), float32(s.OriginPixels.Y), 1,
+	)
+}
+
+// pixelScale returns the number of window pixels per local DIP. For
+// transforms that scale the axes differently this is the geometric mean of the
+// scales.
+func (s *drawState) pixelScale(r resolution) float32 {
+	m := s.pixelTransform(r)
+	return math.Sqrtf(math.Absf(m[0]*m[4] - m[1]*m[3]))
 }
 
 type resource interface {
@@ -81,6 +109,37 @@ func (c *canvas) appendResource(r resource) {
 	c.resources = append(c.resources, r)
 }
 
+// appendBrush holds references to the textures needed to paint the brush for
+// the lifetime of the canvas. If brush is a gradient then appendBrush returns
+// the texture holding the gradient's colours.
+func (c *canvas) appendBrush(brush gxui.Brush) (ramp *texture) {
+	switch {
+	case brush.Gradient != nil:
+		ramp = newGradientRamp(brush.Gradient)
+		c.appendResource(ramp)
+		ramp.release()
+	case brush.Pattern != nil:
+		c.appendResource(brush.Pattern.Texture.(*texture))
+	}
+	return ramp
+}
+
+// newGradientRamp returns a 256x1 texture holding the pre-multiplied colours
+// of the gradient g, sampled at regular offsets from 0 to 1.
+func newGradientRamp(g *gxui.Gradient) *texture {
+	img := image.NewRGBA(image.Rect(0, 0, 256, 1))
+	for i := 0; i < 256; i++ {
+		col := g.ColorAt(float32(i) / 255).Saturate()
+		img.SetRGBA(i, 0, color.RGBA{
+			R: uint8(col.R*col.A*255 + 0.5),
+			G: uint8(col.G*col.A*255 + 0.5),
+			B: uint8(col.B*col.A*255 + 0.5),
+			A: uint8(col.A*255 + 0.5),
+		})
+	}
+	return newTexture(img, 1)
+}
+
 func (c *canvas) release() bool {
 	if !c.refCounted.release() {
 		return false
@@ -131,13 +190,29 @@ func (c *canvas) Pop() {
 func (c *canvas) AddClip(r math.Rect) {
 	c.appendOp("AddClip", func(ctx *context, dss *drawStateStack) {
 		ds := dss.head()
-		rectLocalPixels := ctx.resolution.rectDipsToPixels(r)
-		rectWindowPixels := rectLocalPixels.Offset(ds.OriginPixels)
+		var rectWindowPixels math.Rect
+		if ds.Transformed {
+			rectWindowPixels = ds.Transform.TransformRect(r)
+		} else {
+			rectLocalPixels := ctx.resolution.rectDipsToPixels(r)
+			rectWindowPixels = rectLocalPixels.Offset(ds.OriginPixels)
+		}
 		ds.ClipPixels = ds.ClipPixels.Intersect(rectWindowPixels)
 		ctx.apply(ds)
 	})
 }
 
+func (c *canvas) Transform(m math.Mat3) {
+	if m == math.Mat3Ident {
+		return
+	}
+	c.appendOp("Transform", func(ctx *context, dss *drawStateStack) {
+		ds := dss.head()
+		ds.Transform = m.Mul(ds.pixelTransform(ctx.resolution))
+		ds.Transformed = true
+	})
+}
+
 func (c *canvas) Clear(color gxui.Color) {
 	c.appendOp("Clear", func(ctx *context, dss *drawStateStack) {
 		gl.ClearColor(
@@ -156,17 +231,88 @@ func (c *canvas) DrawCanvas(cc gxui.Canvas, offsetDips math.Point) {
 	}
 	childCanvas := cc.(*canvas)
 	c.appendOp("DrawCanvas", func(ctx *context, dss *drawStateStack) {
-		offsetPixels := ctx.resolution.pointDipsToPixels(offsetDips)
-		dss.push(*dss.head())
+		childCanvas.drawAt(ctx, dss, offsetDips)
+		ctx.apply(dss.head())
+	})
+	c.appendResource(childCanvas)
+}
+
+func (c *canvas) DrawCanvasBlend(cc gxui.Canvas, offsetDips math.Point, alpha float32, mode gxui.BlendMode) {
+	if cc == nil {
+		panic("Canvas cannot be nil")
+	}
+	if alpha >= 1 && mode == gxui.BlendNormal {
+		c.DrawCanvas(cc, offsetDips)
+		return
+	}
+	childCanvas := cc.(*canvas)
+	c.appendOp("DrawCanvasBlend", func(ctx *context, dss *drawStateStack) {
 		ds := dss.head()
-		ds.OriginPixels = ds.OriginPixels.Add(offsetPixels)
-		childCanvas.draw(ctx, dss)
+		if alpha <= 0 || ds.ClipPixels.W() <= 0 || ds.ClipPixels.H() <= 0 {
+			return
+		}
+		fb := ctx.pushLayer(ds)
+		childCanvas.drawAt(ctx, dss, offsetDips)
+		ctx.popLayer()
+		ctx.apply(ds)
+		ctx.blitter.blitLayer(ctx, fb, alpha, mode)
+		ctx.releaseLayer(fb)
+	})
+	c.appendResource(childCanvas)
+}
+
+func (c *canvas) DrawCanvasBlur(cc gxui.Canvas, offsetDips math.Point, radius float32) {
+	if cc == nil {
+		panic("Canvas cannot be nil")
+	}
+	if radius <= 0 {
+		c.DrawCanvas(cc, offsetDips)
+		return
+	}
+	childCanvas := cc.(*canvas)
+	c.appendOp("DrawCanvasBlur", func(ctx *context, dss *drawStateStack) {
+		ds := dss.head()
+		if ds.ClipPixels.W() <= 0 || ds.ClipPixels.H() <= 0 {
+			return
+		}
+		// Pixels up to three standard deviations outside the clip contribute
+		// to the blurred pixels inside it, so the child is drawn to a layer
+		// covering the clip expanded by that much, limited to the window.
+		sigma := radius / 2 * ds.pixelScale(ctx.resolution)
+		expanded := *ds
+		expanded.ClipPixels = ds.ClipPixels.ExpandI(int(sigma*3 + 0.999)).Intersect(ctx.sizePixels.Rect())
+		src := ctx.pushLayer(&expanded)
+		dss.push(expanded)
+		childCanvas.drawAt(ctx, dss, offsetDips)
 		dss.pop()
-		ctx.apply(dss.head())
+		ctx.popLayer()
+		// Blur horizontally into a second layer, then vertically onto the
+		// render target.
+		dst := ctx.pushLayer(&expanded)
+		ctx.blitter.blitBlur(ctx, src, expanded.ClipPixels, sigma, true)
+		ctx.popLayer()
+		ctx.releaseLayer(src)
+		ctx.apply(ds)
+		ctx.blitter.blitBlur(ctx, dst, expanded.ClipPixels, sigma, false)
+		ctx.releaseLayer(dst)
 	})
 	c.appendResource(childCanvas)
 }
 
+// drawAt draws the canvas with its origin offset by offsetDips.
+func (c *canvas) drawAt(ctx *context, dss *drawStateStack, offsetDips math.Point) {
+	dss.push(*dss.head())
+	ds := dss.head()
+	if ds.Transformed {
+		ds.Transform = math.CreateMat3Translate(offsetDips.Vec2()).Mul(ds.Transform)
+	} else {
+		offsetPixels := ctx.resolution.pointDipsToPixels(offsetDips)
+		ds.OriginPixels = ds.OriginPixels.Add(offsetPixels)
+	}
+	c.draw(ctx, dss)
+	dss.pop()
+}
+
 func (c *canvas) DrawRunes(f gxui.Font, r []rune, p []math.Point, col gxui.Color) {
 	if f == nil {
 		panic("Font cannot be nil")
@@ -179,7 +325,12 @@ func (c *canvas) DrawRunes(f gxui.Font, r []rune, p []math.Point, col gxui.Color
 }
 
 func (c *canvas) DrawLines(lines gxui.Polygon, pen gxui.Pen) {
-	edge := openPolyToShape(lines, pen.Width)
+	var edge *shape
+	if pen.IsStyled() {
+		edge = convexPolygonsToShape(stroke.Stroke(stroke.Outline(lines, false), false, pen))
+	} else {
+		edge = openPolyToShape(lines, pen.Width)
+	}
 	c.appendOp("DrawLines", func(ctx *context, dss *drawStateStack) {
 		ds := dss.head()
 		if edge != nil && pen.Color.A > 0 {
@@ -194,10 +345,22 @@ func (c *canvas) DrawLines(lines gxui.Polygon, pen gxui.Pen) {
 
 func (c *canvas) DrawPolygon(poly gxui.Polygon, pen gxui.Pen, brush gxui.Brush) {
 	fill, edge := closedPolyToShape(poly, pen.Width)
+	if pen.IsStyled() {
+		if edge != nil {
+			edge.release()
+		}
+		edge = convexPolygonsToShape(stroke.Stroke(stroke.Outline(poly, true), true, pen))
+	}
+	brush = brush.Resolve(poly.Bounds())
+	ramp := c.appendBrush(brush)
 	c.appendOp("DrawPolygon", func(ctx *context, dss *drawStateStack) {
 		ds := dss.head()
-		if fill != nil && brush.Color.A > 0 {
+		switch {
+		case fill == nil || brush.IsTransparent():
+		case brush.IsSolid():
 			ctx.blitter.blitShape(ctx, *fill, brush.Color, ds)
+		default:
+			ctx.blitter.blitPaint(ctx, fill, math.Mat3Ident, brush, ramp, ds)
 		}
 		if edge != nil && pen.Color.A > 0 {
 			ctx.blitter.blitShape(ctx, *edge, pen.Color, ds)
@@ -214,8 +377,17 @@ func (c *canvas) DrawPolygon(poly gxui.Polygon, pen gxui.Pen, brush gxui.Brush)
 }
 
 func (c *canvas) DrawRect(r math.Rect, brush gxui.Brush) {
+	brush = brush.Resolve(r)
+	ramp := c.appendBrush(brush)
 	c.appendOp("DrawRect", func(ctx *context, dss *drawStateStack) {
-		ctx.blitter.blitRect(ctx, ctx.resolution.rectDipsToPixels(r), brush.Color, dss.head())
+		ds := dss.head()
+		if !brush.IsSolid() {
+			ctx.blitter.blitPaint(ctx, ctx.blitter.quad, unitQuadToRect(r), brush, ramp, ds)
+		} else if ds.Transformed {
+			ctx.blitter.blitRectTransformed(ctx, r, brush.Color, ds)
+		} else {
+			ctx.blitter.blitRect(ctx, ctx.resolution.rectDipsToPixels(r), brush.Color, ds)
+		}
 	})
 }
 
@@ -233,14 +405,67 @@ func (c *canvas) DrawRoundedRect(r math.Rect, tl, tr, bl, br float32, pen gxui.P
 	c.DrawPolygon(p, pen, brush)
 }
 
+func (c *canvas) DrawShadow(r math.Rect, tl, tr, bl, br float32, s gxui.Shadow) {
+	if !s.IsVisible() {
+		return
+	}
+	c.appendOp("DrawShadow", func(ctx *context, dss *drawStateStack) {
+		ctx.blitter.blitShadow(ctx, r, [4]float32{tl, tr, bl, br}, s, dss.head())
+	})
+}
+
+func (c *canvas) FillPath(path *gxui.VectorPath, brush gxui.Brush) {
+	if path == nil {
+		panic("Path cannot be nil")
+	}
+	fill := pathFillShape(path)
+	brush = brush.Resolve(path.Bounds())
+	ramp := c.appendBrush(brush)
+	c.appendOp("FillPath", func(ctx *context, dss *drawStateStack) {
+		ds := dss.head()
+		switch {
+		case fill == nil || brush.IsTransparent():
+		case brush.IsSolid():
+			ctx.blitter.blitShape(ctx, *fill, brush.Color, ds)
+		default:
+			ctx.blitter.blitPaint(ctx, fill, math.Mat3Ident, brush, ramp, ds)
+		}
+	})
+	if fill != nil {
+		c.appendResource(fill)
+		fill.release()
+	}
+}
+
+func (c *canvas) StrokePath(path *gxui.VectorPath, pen gxui.Pen) {
+	if path == nil {
+		panic("Path cannot be nil")
+	}
+	edge := pathStrokeShape(path, pen)
+	c.appendOp("StrokePath", func(ctx *context, dss *drawStateStack) {
+		if edge != nil && pen.Color.A > 0 {
+			ctx.blitter.blitShape(ctx, *edge, pen.Color, dss.head())
+		}
+	})
+	if edge != nil {
+		c.appendResource(edge)
+		edge.release()
+	}
+}
+
 func (c *canvas) DrawTexture(t gxui.Texture, r math.Rect) {
 	if t == nil {
 		panic("Texture cannot be nil")
 	}
 
 	c.appendOp("DrawTexture", func(ctx *context, dss *drawStateStack) {
+		ds := dss.head()
 		tc := ctx.getOrCreateTextureContext(t.(*texture))
-		ctx.blitter.blit(ctx, tc, tc.sizePixels.Rect(), ctx.resolution.rectDipsToPixels(r), dss.head())
+		if ds.Transformed {
+			ctx.blitter.blitTransformed(ctx, tc, tc.sizePixels.Rect(), r, ds)
+		} else {
+			ctx.blitter.blit(ctx, tc, tc.sizePixels.Rect(), ctx.resolution.rectDipsToPixels(r), ds)
+		}
 	})
 	c.appendResource(t.(*texture))
 }
@@ -19,6 +19,8 @@ type context struct {
 	indexBufferContexts  map[*indexBuffer]*indexBufferContext
 	sizeDips, sizePixels math.Size
 	clip                 math.Rect
+	layers               []*framebuffer // Bound framebuffers, innermost last
+	freeFramebuffers     []*framebuffer
 }
 
 func newContext() *context {
@@ -47,6 +49,7 @@ func (c *context) destroy() {
 		ic.destroy()
 		c.stats.indexBufferCount--
 	}
+	c.destroyFramebuffers()
 	c.blitter.destroy(c)
 	c.blitter = nil
 }
@@ -75,6 +78,11 @@ func (c *context) beginDraw(sizeDips, sizePixels math.Size) {
 		}
 	}
 
+	// Layers are the size of the window, so cannot be reused after a resize.
+	if sizePixels != c.sizePixels {
+		c.destroyFramebuffers()
+	}
+
 	dipsToPixels := float32(sizePixels.W) / float32(sizeDips.W)
 
 	c.sizeDips = sizeDips
@@ -85,6 +93,17 @@ func (c *context) beginDraw(sizeDips, sizePixels math.Size) {
 	c.stats.timer("Frame").start()
 }
 
+// pixelsToNDC returns the transform from window pixels to normalized device
+// coordinates.
+func (c *context) pixelsToNDC() math.Mat3 {
+	dw, dh := c.sizePixels.WH()
+	return math.CreateMat3(
+		+2.0/float32(dw), 0, 0,
+		0, -2.0/float32(dh), 0,
+		-1.0, +1.0, 1,
+	)
+}
+
 func (c *context) endDraw() {
 	c.stats.timer("Frame").stop()
 	c.stats.frameCount++
@@ -24,6 +24,7 @@ func init() {
 }
 
 type driver struct {
+	*gxui.DriverTimers
 	pendingDriver chan func()
 	pendingApp    chan func()
 	terminated    int32 // non-zero represents driver terminations
@@ -45,6 +46,7 @@ func StartDriver(appRoutine func(driver gxui.Driver)) {
 		pendingApp:    make(chan func(), 256),
 		viewports:     list.New(),
 	}
+	driver.DriverTimers = gxui.CreateDriverTimers(gxui.SystemClock, driver.Call)
 
 	driver.pendingApp <- func() { appRoutine(driver) }
 	go driver.applicationLoop()
@@ -124,6 +126,7 @@ func (d *driver) CallSync(f func()) bool {
 }
 
 func (d *driver) Terminate() {
+	d.StopAll()
 	d.asyncDriver(func() {
 		// Close all viewports. This will notify the application.
 		for v := d.viewports.Front(); v != nil; v = v.Next() {
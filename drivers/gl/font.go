@@ -110,9 +110,23 @@ func (f *font) DrawRunes(ctx *context, runes []rune, offsets []math.Point, col g
 		page := table.get(r, glyph)
 		texture := page.texture()
 		srcRect := glyph.size(resolution).Rect().Offset(page.offset(r))
-		dstRect := glyph.rect(resolution).Offset(resolution.pointDipsToPixels(offsets[i]))
 		tc := ctx.getOrCreateTextureContext(texture)
-		ctx.blitter.blitGlyph(ctx, tc, col, srcRect, dstRect, ds)
+		if ds.Transformed {
+			// Glyph rect in local DIPs
+			rect := glyph.rect(resolution)
+			pixelsToDips := 1.0 / resolution.dipsToPixels()
+			o := offsets[i].Vec2()
+			dst := [4]math.Vec2{
+				rect.TL().Vec2().MulS(pixelsToDips).Add(o),
+				rect.TR().Vec2().MulS(pixelsToDips).Add(o),
+				rect.BL().Vec2().MulS(pixelsToDips).Add(o),
+				rect.BR().Vec2().MulS(pixelsToDips).Add(o),
+			}
+			ctx.blitter.blitGlyphTransformed(ctx, tc, col, srcRect, dst, ds)
+		} else {
+			dstRect := glyph.rect(resolution).Offset(resolution.pointDipsToPixels(offsets[i]))
+			ctx.blitter.blitGlyph(ctx, tc, col, srcRect, dstRect, ds)
+		}
 	}
 }
 
@@ -0,0 +1,118 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gl
+
+import (
+	"fmt"
+
+	"github.com/google/gxui/math"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// framebuffer is an offscreen render target the size of the window. Canvases
+// drawn with DrawCanvasBlend are drawn to a framebuffer, which is then
+// composited onto the window.
+type framebuffer struct {
+	fbo        uint32
+	tc         *textureContext
+	sizePixels math.Size
+}
+
+func newFramebuffer(sizePixels math.Size) *framebuffer {
+	var texture uint32
+	w, h := sizePixels.WH()
+	gl.GenTextures(1, &texture)
+	gl.BindTexture(gl.TEXTURE_2D, texture)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, int32(w), int32(h), 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	var fbo uint32
+	gl.GenFramebuffers(1, &fbo)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, fbo)
+	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, texture, 0)
+	if status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER); status != gl.FRAMEBUFFER_COMPLETE {
+		panic(fmt.Errorf("Framebuffer incomplete. Status: 0x%x", status))
+	}
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	checkError()
+
+	return &framebuffer{
+		fbo: fbo,
+		tc: &textureContext{
+			texture:    texture,
+			sizePixels: sizePixels,
+			flipY:      true,
+			pma:        true,
+		},
+		sizePixels: sizePixels,
+	}
+}
+
+func (f *framebuffer) bytes() int {
+	return f.sizePixels.W * f.sizePixels.H * 4
+}
+
+func (f *framebuffer) destroy() {
+	gl.DeleteFramebuffers(1, &f.fbo)
+	f.tc.destroy()
+	f.fbo = 0
+}
+
+// pushLayer redirects drawing to a framebuffer that is cleared to transparent
+// within the draw state's clip.
+func (c *context) pushLayer(ds *drawState) *framebuffer {
+	c.blitter.commit(c)
+	var fb *framebuffer
+	if n := len(c.freeFramebuffers); n > 0 {
+		fb = c.freeFramebuffers[n-1]
+		c.freeFramebuffers = c.freeFramebuffers[:n-1]
+		c.stats.framebufferFreeCount--
+	} else {
+		fb = newFramebuffer(c.sizePixels)
+		c.stats.framebufferBytesAllocated += fb.bytes()
+	}
+	c.stats.framebufferUsedCount++
+	c.layers = append(c.layers, fb)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, fb.fbo)
+	c.apply(ds)
+	gl.ClearColor(0, 0, 0, 0)
+	gl.Clear(gl.COLOR_BUFFER_BIT)
+	return fb
+}
+
+// popLayer restores drawing to the render target that was active before the
+// matching call to pushLayer, returning the layer's framebuffer.
+func (c *context) popLayer() *framebuffer {
+	c.blitter.commit(c)
+	n := len(c.layers)
+	fb := c.layers[n-1]
+	c.layers = c.layers[:n-1]
+	if n > 1 {
+		gl.BindFramebuffer(gl.FRAMEBUFFER, c.layers[n-2].fbo)
+	} else {
+		gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	}
+	return fb
+}
+
+// releaseLayer returns the framebuffer to the pool for reuse.
+func (c *context) releaseLayer(fb *framebuffer) {
+	c.stats.framebufferUsedCount--
+	c.stats.framebufferFreeCount++
+	c.freeFramebuffers = append(c.freeFramebuffers, fb)
+}
+
+// destroyFramebuffers destroys all the pooled framebuffers.
+func (c *context) destroyFramebuffers() {
+	for _, fb := range c.freeFramebuffers {
+		c.stats.framebufferBytesAllocated -= fb.bytes()
+		fb.destroy()
+	}
+	c.stats.framebufferFreeCount = 0
+	c.freeFramebuffers = nil
+}
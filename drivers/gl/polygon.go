@@ -6,9 +6,13 @@ package gl
 
 import (
 	"github.com/google/gxui"
+	"github.com/google/gxui/drivers/internal/stroke"
 	"github.com/google/gxui/math"
 )
 
+// The maximum distance in DIPs between a curve and the lines used to draw it.
+const pathTolerance = 0.1
+
 func appendVec2(arr []float32, vecs ...math.Vec2) []float32 {
 	for _, v := range vecs {
 		arr = append(arr, v.X, v.Y)
@@ -216,3 +220,46 @@ func openPolyToShape(p gxui.Polygon, penWidth float32) *shape {
 		return nil
 	}
 }
+
+// convexPolygonsToShape returns a shape covering the convex polygons, or nil if
+// there are no polygons.
+func convexPolygonsToShape(polygons [][]math.Vec2) *shape {
+	vsPos := []float32{}
+	for _, poly := range polygons {
+		for i := 2; i < len(poly); i++ {
+			vsPos = appendVec2(vsPos, poly[0], poly[i-1], poly[i])
+		}
+	}
+	if len(vsPos) == 0 {
+		return nil
+	}
+	return newShape(newVertexBuffer(
+		newVertexStream("aPosition", stFloatVec2, vsPos),
+	), nil, dmTriangles)
+}
+
+// pathFillShape returns a shape filling the inside of path, or nil if the path
+// encloses no area.
+func pathFillShape(path *gxui.VectorPath) *shape {
+	contours := [][]math.Vec2{}
+	for _, c := range path.Flatten(pathTolerance) {
+		contours = append(contours, c.Points)
+	}
+	tris := triangulatePath(contours, path.FillRule)
+	if len(tris) == 0 {
+		return nil
+	}
+	return newShape(newVertexBuffer(
+		newVertexStream("aPosition", stFloatVec2, appendVec2(nil, tris...)),
+	), nil, dmTriangles)
+}
+
+// pathStrokeShape returns a shape covering the outline of path drawn with pen,
+// or nil if the outline is empty.
+func pathStrokeShape(path *gxui.VectorPath, pen gxui.Pen) *shape {
+	polygons := [][]math.Vec2{}
+	for _, c := range path.Flatten(pathTolerance) {
+		polygons = append(polygons, stroke.Centered(c.Points, c.Closed, pen)...)
+	}
+	return convexPolygonsToShape(polygons)
+}
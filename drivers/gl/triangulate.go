@@ -7,6 +7,9 @@ package gl
 import (
 	"container/list"
 	"fmt"
+	"sort"
+
+	"github.com/google/gxui"
 	"github.com/google/gxui/math"
 )
 
@@ -37,6 +40,9 @@ func isEar(edges []math.Vec2, a, b, c int) bool {
 		if i == a || i == b || i == c {
 			continue
 		}
+		if e := edges[i]; e == edges[a] || e == edges[b] || e == edges[c] {
+			continue // Duplicated vertex of a bridge to a hole
+		}
 		v := edges[i].Vec3(1)
 
 		if v.Dot(plane[0]) > -epsilon &&
@@ -124,3 +130,213 @@ func triangulate(edges []math.Vec2) []math.Vec2 {
 	// assert.True(l.Len() < 3, "Failed to prune an ear! edges: %#v, out: %v", edges, out)
 	return out
 }
+
+// signedArea returns twice the area of the polygon edges. The area is positive
+// for polygons with the winding expected by triangulate.
+func signedArea(edges []math.Vec2) float32 {
+	a := float32(0)
+	for i, p := range edges {
+		q := edges[(i+1)%len(edges)]
+		a += p.X*q.Y - q.X*p.Y
+	}
+	return a
+}
+
+// windingNumber returns the number of times the polygon edges winds around p.
+// Windings in the direction expected by triangulate are positive.
+func windingNumber(edges []math.Vec2, p math.Vec2) int {
+	w := 0
+	for i, a := range edges {
+		b := edges[(i+1)%len(edges)]
+		switch {
+		case a.Y <= p.Y && p.Y < b.Y:
+			if a.X+(p.Y-a.Y)*(b.X-a.X)/(b.Y-a.Y) > p.X {
+				w++
+			}
+		case b.Y <= p.Y && p.Y < a.Y:
+			if a.X+(p.Y-a.Y)*(b.X-a.X)/(b.Y-a.Y) > p.X {
+				w--
+			}
+		}
+	}
+	return w
+}
+
+func reversed(edges []math.Vec2) []math.Vec2 {
+	out := make([]math.Vec2, len(edges))
+	for i, e := range edges {
+		out[len(edges)-1-i] = e
+	}
+	return out
+}
+
+// segmentsCross returns true if the line segments ab and cd intersect at a
+// point that is not an end point of either segment.
+func segmentsCross(a, b, c, d math.Vec2) bool {
+	side := func(p, q, r math.Vec2) float32 { return q.Sub(p).Cross(r.Sub(p)) }
+	d1, d2 := side(a, b, c), side(a, b, d)
+	d3, d4 := side(c, d, a), side(c, d, b)
+	return ((d1 > epsilon && d2 < -epsilon) || (d1 < -epsilon && d2 > epsilon)) &&
+		((d3 > epsilon && d4 < -epsilon) || (d3 < -epsilon && d4 > epsilon))
+}
+
+// bridgeHole returns the single polygon formed by joining the hole to the
+// polygon outer with a pair of coincident edges, so that it can be passed to
+// triangulate. others holds the holes that are yet to be bridged, which the
+// bridge must not cross.
+func bridgeHole(outer, hole []math.Vec2, others [][]math.Vec2) []math.Vec2 {
+	// Bridge from the hole vertex with the greatest X.
+	m := 0
+	for i, v := range hole {
+		if v.X > hole[m].X {
+			m = i
+		}
+	}
+	mv := hole[m]
+
+	crosses := func(p math.Vec2) bool {
+		for _, poly := range append([][]math.Vec2{outer, hole}, others...) {
+			for i, a := range poly {
+				if segmentsCross(mv, p, a, poly[(i+1)%len(poly)]) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	// Use the closest outer vertex that can be reached without crossing an
+	// edge.
+	candidates := make([]int, len(outer))
+	for i := range candidates {
+		candidates[i] = i
+	}
+	sort.Sort(byDistance{outer, candidates, mv})
+	k := candidates[0]
+	for _, i := range candidates {
+		if !crosses(outer[i]) {
+			k = i
+			break
+		}
+	}
+
+	out := make([]math.Vec2, 0, len(outer)+len(hole)+2)
+	out = append(out, outer[:k+1]...)
+	out = append(out, hole[m:]...)
+	out = append(out, hole[:m+1]...)
+	out = append(out, outer[k:]...)
+	return out
+}
+
+type byDistance struct {
+	points  []math.Vec2
+	indices []int
+	from    math.Vec2
+}
+
+func (l byDistance) Len() int      { return len(l.indices) }
+func (l byDistance) Swap(i, j int) { l.indices[i], l.indices[j] = l.indices[j], l.indices[i] }
+func (l byDistance) Less(i, j int) bool {
+	return l.points[l.indices[i]].Sub(l.from).Len() < l.points[l.indices[j]].Sub(l.from).Len()
+}
+
+// triangulatePath returns the triangles filling the region bounded by the
+// closed contours, using the fill rule. Contours may be nested to form holes
+// and islands, but must not cross themselves or each other.
+func triangulatePath(contours [][]math.Vec2, rule gxui.FillRule) []math.Vec2 {
+	type contour struct {
+		edges []math.Vec2
+		area  float32
+		holes [][]math.Vec2
+	}
+	all := []*contour{}
+	for _, c := range contours {
+		edges := pruneEdgeDuplicates(c)
+		if len(edges) > 1 && edges[0].Sub(edges[len(edges)-1]).Len() <= 0.0001 {
+			edges = edges[:len(edges)-1]
+		}
+		if len(edges) < 3 {
+			continue
+		}
+		if area := signedArea(edges); area != 0 {
+			all = append(all, &contour{edges: edges, area: area})
+		}
+	}
+
+	filled := func(w int) bool {
+		if rule == gxui.EvenOdd {
+			return w%2 != 0
+		}
+		return w != 0
+	}
+
+	// Classify each contour as the boundary of a filled region (outer), the
+	// boundary of an unfilled region inside a filled region (hole), or neither.
+	outers, holes := []*contour{}, []*contour{}
+	for _, c := range all {
+		outside := 0
+		for _, o := range all {
+			if o != c {
+				outside += windingNumber(o.edges, c.edges[0])
+			}
+		}
+		inside := outside + 1
+		if c.area < 0 {
+			inside = outside - 1
+		}
+		switch {
+		case filled(inside) && !filled(outside):
+			if c.area < 0 {
+				c.edges = reversed(c.edges)
+			}
+			outers = append(outers, c)
+		case !filled(inside) && filled(outside):
+			if c.area > 0 {
+				c.edges = reversed(c.edges)
+			}
+			holes = append(holes, c)
+		}
+	}
+
+	// Assign each hole to the smallest outer that contains it.
+	for _, h := range holes {
+		var parent *contour
+		for _, o := range outers {
+			if windingNumber(o.edges, h.edges[0]) != 0 {
+				if parent == nil || math.Absf(o.area) < math.Absf(parent.area) {
+					parent = o
+				}
+			}
+		}
+		if parent != nil {
+			parent.holes = append(parent.holes, h.edges)
+		}
+	}
+
+	out := []math.Vec2{}
+	for _, o := range outers {
+		// Bridge the holes from right to left so that earlier bridges do not
+		// block later ones.
+		sort.Sort(byMaxX(o.holes))
+		edges := o.edges
+		for i, h := range o.holes {
+			edges = bridgeHole(edges, h, o.holes[i+1:])
+		}
+		out = append(out, triangulate(edges)...)
+	}
+	return out
+}
+
+type byMaxX [][]math.Vec2
+
+func maxX(edges []math.Vec2) float32 {
+	x := edges[0].X
+	for _, e := range edges {
+		x = math.Maxf(x, e.X)
+	}
+	return x
+}
+
+func (l byMaxX) Len() int           { return len(l) }
+func (l byMaxX) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+func (l byMaxX) Less(i, j int) bool { return maxX(l[i]) > maxX(l[j]) }
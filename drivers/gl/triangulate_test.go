@@ -6,6 +6,7 @@ package gl
 
 import test "github.com/google/gxui/testing"
 import (
+	"github.com/google/gxui"
 	"github.com/google/gxui/math"
 	"testing"
 )
@@ -256,3 +257,43 @@ func TestTriangulateChevron(t *testing.T) {
 	}
 	test.AssertEquals(t, tris, triangulate(edges))
 }
+
+func triangleArea(tris []math.Vec2) float32 {
+	area := float32(0)
+	for i := 0; i < len(tris); i += 3 {
+		area += signedArea(tris[i:i+3]) / 2
+	}
+	return area
+}
+
+func square(x, y, size float32, clockwise bool) []math.Vec2 {
+	s := []math.Vec2{v(x, y), v(x+size, y), v(x+size, y+size), v(x, y+size)}
+	if !clockwise {
+		s = reversed(s)
+	}
+	return s
+}
+
+func TestTriangulatePathHole(t *testing.T) {
+	outer := square(0, 0, 10, true)
+	for _, rule := range []gxui.FillRule{gxui.NonZero, gxui.EvenOdd} {
+		// Anti-clockwise hole
+		tris := triangulatePath([][]math.Vec2{outer, square(2, 2, 4, false)}, rule)
+		test.AssertEquals(t, float32(100-16), triangleArea(tris))
+	}
+
+	// A clockwise inner contour is only a hole with the even-odd rule.
+	inner := square(2, 2, 4, true)
+	test.AssertEquals(t, float32(100), triangleArea(triangulatePath([][]math.Vec2{outer, inner}, gxui.NonZero)))
+	test.AssertEquals(t, float32(100-16), triangleArea(triangulatePath([][]math.Vec2{outer, inner}, gxui.EvenOdd)))
+}
+
+func TestTriangulatePathIsland(t *testing.T) {
+	contours := [][]math.Vec2{
+		square(0, 0, 10, true),
+		square(1, 1, 8, false),
+		square(3, 3, 2, true), // Island inside the hole
+		square(20, 0, 2, false),
+	}
+	test.AssertEquals(t, float32(100-64+4+4), triangleArea(triangulatePath(contours, gxui.EvenOdd)))
+}
@@ -0,0 +1,349 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package stroke builds the geometry for lines drawn with styled pens. It is
+// shared by the drivers so that dashes, caps and joins look the same
+// everywhere.
+package stroke
+
+import (
+	stdmath "math"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+)
+
+// The minimum distance between two distinct points.
+const epsilon = 0.001
+
+// Outline returns the points of the polygon p with each rounded vertex
+// replaced by the points of its arc. If closed is false then the first and last
+// vertices of p are never rounded.
+func Outline(p gxui.Polygon, closed bool) []math.Vec2 {
+	p = pruneDuplicates(p)
+	out := make([]math.Vec2, 0, len(p))
+	for i, cnt := 0, len(p); i < cnt; i++ {
+		a := p[i].Position.Vec2()
+		r := p[i].RoundedRadius
+		if r <= 0 || cnt < 3 || (!closed && (i == 0 || i == cnt-1)) {
+			out = append(out, a)
+			continue
+		}
+		b := p[(i+cnt-1)%cnt].Position.Vec2()
+		c := p[(i+1)%cnt].Position.Vec2()
+		out = appendArc(out, r, a, b, c)
+	}
+	return out
+}
+
+// appendArc appends the points of the rounded vertex a, with neighbours b and
+// c, to out. The arc has the same shape as the edges built by the drivers for
+// unstyled pens.
+func appendArc(out []math.Vec2, r float32, a, b, c math.Vec2) []math.Vec2 {
+	ba, ca := a.Sub(b), a.Sub(c)
+	baLen, caLen := ba.Len(), ca.Len()
+	baDir, caDir := ba.DivS(baLen), ca.DivS(caLen)
+	dp := baDir.Dot(caDir)
+	if dp < -0.99999 {
+		return append(out, a) // Straight line
+	}
+	α := math.Acosf(dp) / 2
+	v := baDir.Add(caDir).Normalize()
+	u := v.Tangent()
+	d := r / math.Sinf(α)
+
+	// The arc cannot start or end further than half way along ab or ac.
+	dMax := math.Minf(baLen, caLen) / (2 * math.Cosf(α))
+	if d > dMax {
+		d = dMax
+		r = d * math.Sinf(α)
+	}
+
+	x := a.Sub(v.MulS(d))
+	β := math.Pi/2 - α
+	if baDir.Tangent().Dot(caDir) > 0 {
+		β = -β // Concave
+	}
+
+	steps := 2 + int(d*α)
+	for j := 0; j < steps; j++ {
+		γ := math.Lerpf(-β, β, float32(j)/float32(steps-1))
+		dir := v.MulS(math.Cosf(γ)).Add(u.MulS(math.Sinf(γ)))
+		out = append(out, x.Add(dir.MulS(r)))
+	}
+	return out
+}
+
+// Stroke returns the convex polygons that together cover the stroke of the
+// line through points with pen. The stroke lies to the right of the line, as
+// described by gxui.Pen. If closed is true then the last point is joined to
+// the first.
+func Stroke(points []math.Vec2, closed bool, pen gxui.Pen) [][]math.Vec2 {
+	return build(points, closed, pen, true)
+}
+
+// Centered is like Stroke, except that the stroke is centered on the line.
+func Centered(points []math.Vec2, closed bool, pen gxui.Pen) [][]math.Vec2 {
+	return build(points, closed, pen, false)
+}
+
+func build(points []math.Vec2, closed bool, pen gxui.Pen, right bool) [][]math.Vec2 {
+	points = prune(points)
+	if closed && len(points) > 1 && points[0].Sub(points[len(points)-1]).Len() < epsilon {
+		points = points[:len(points)-1]
+	}
+	if len(points) < 2 || pen.Width <= 0 {
+		return nil
+	}
+	h := pen.Width / 2
+	center := points
+	if right {
+		center = Offset(points, closed, h)
+	}
+
+	out := [][]math.Vec2{}
+	if pen.Dash != nil {
+		for _, piece := range dash(center, closed, pen.Dash) {
+			out = strokePiece(out, piece, false, h, pen)
+		}
+	} else {
+		out = strokePiece(out, center, closed, h, pen)
+	}
+	return out
+}
+
+// Offset returns the line through points moved to the right by h.
+func Offset(points []math.Vec2, closed bool, h float32) []math.Vec2 {
+	cnt := len(points)
+	out := make([]math.Vec2, cnt)
+	for i, p := range points {
+		var n0, n1 math.Vec2
+		hasPrev, hasNext := closed || i > 0, closed || i < cnt-1
+		if hasPrev {
+			n0 = p.Sub(points[(i+cnt-1)%cnt]).Normalize().Tangent()
+		}
+		if hasNext {
+			n1 = points[(i+1)%cnt].Sub(p).Normalize().Tangent()
+		}
+		switch {
+		case !hasPrev:
+			out[i] = p.Add(n1.MulS(h))
+		case !hasNext:
+			out[i] = p.Add(n0.MulS(h))
+		default:
+			// Move along the bisector so that both adjacent edges are h away.
+			out[i] = p.Add(n0.Add(n1).MulS(h / math.Maxf(1+n0.Dot(n1), 0.1)))
+		}
+	}
+	return out
+}
+
+// dash splits the line through points into the pieces that are on in the
+// dash pattern d.
+func dash(points []math.Vec2, closed bool, d *gxui.Dash) [][]math.Vec2 {
+	lengths := d.Lengths
+	if len(lengths)%2 != 0 {
+		lengths = append(append([]float32{}, lengths...), lengths...)
+	}
+	total := float32(0)
+	for _, l := range lengths {
+		total += math.Maxf(l, 0)
+	}
+	if total <= 0 {
+		return [][]math.Vec2{points}
+	}
+
+	// Find the position in the pattern at the start of the line.
+	phase := float32(stdmath.Mod(float64(d.Offset), float64(total)))
+	if phase < 0 {
+		phase += total
+	}
+	k := 0
+	for phase >= math.Maxf(lengths[k], 0) {
+		phase -= math.Maxf(lengths[k], 0)
+		k = (k + 1) % len(lengths)
+	}
+	rem := lengths[k] - phase
+	on := k%2 == 0
+	startsOn := on
+
+	pieces := [][]math.Vec2{}
+	var cur []math.Vec2
+	if on {
+		cur = []math.Vec2{points[0]}
+	}
+	end := func(p, dir math.Vec2) {
+		if p.Sub(cur[len(cur)-1]).Len() < epsilon {
+			// Zero length dashes still need a direction for their caps.
+			p = p.Add(dir.MulS(epsilon))
+		}
+		pieces = append(pieces, append(cur, p))
+		cur = nil
+	}
+
+	segments := len(points) - 1
+	if closed {
+		segments++
+	}
+	for i := 0; i < segments; i++ {
+		a, b := points[i], points[(i+1)%len(points)]
+		ab := b.Sub(a)
+		segLen := ab.Len()
+		if segLen == 0 {
+			continue
+		}
+		dir := ab.DivS(segLen)
+		t := float32(0)
+		for segLen-t >= rem {
+			t += math.Maxf(rem, 0)
+			p := a.Add(dir.MulS(t))
+			if on {
+				end(p, dir)
+			} else {
+				cur = []math.Vec2{p}
+			}
+			on = !on
+			k = (k + 1) % len(lengths)
+			rem = lengths[k]
+		}
+		rem -= segLen - t
+		if on && b.Sub(cur[len(cur)-1]).Len() >= epsilon {
+			cur = append(cur, b)
+		}
+	}
+	if on && len(cur) >= 2 {
+		if closed && startsOn && len(pieces) > 0 {
+			// The dash continues over the start of the closed line.
+			pieces[0] = append(cur, pieces[0][1:]...)
+		} else {
+			pieces = append(pieces, cur)
+		}
+	}
+	return pieces
+}
+
+// strokePiece appends the polygons covering the line through points, which is
+// the center of the stroke, to out.
+func strokePiece(out [][]math.Vec2, points []math.Vec2, closed bool, h float32, pen gxui.Pen) [][]math.Vec2 {
+	cnt := len(points)
+	if cnt < 2 {
+		return out
+	}
+	segments := cnt - 1
+	if closed {
+		segments++
+	}
+	dirs := make([]math.Vec2, segments)
+	for i := range dirs {
+		a, b := points[i], points[(i+1)%cnt]
+		dirs[i] = b.Sub(a).Normalize()
+		n := dirs[i].Tangent().MulS(h)
+		out = append(out, []math.Vec2{a.Add(n), b.Add(n), b.Sub(n), a.Sub(n)})
+	}
+
+	for i := 1; i < segments; i++ {
+		out = join(out, points[i], dirs[i-1], dirs[i], h, pen)
+	}
+	if closed {
+		out = join(out, points[0], dirs[segments-1], dirs[0], h, pen)
+	} else {
+		out = lineCap(out, points[0], dirs[0], h, pen.Cap)
+		out = lineCap(out, points[cnt-1], dirs[segments-1].MulS(-1), h, pen.Cap)
+	}
+	return out
+}
+
+// join appends the polygon joining the segment ending at p with direction d0
+// to the segment starting at p with direction d1.
+func join(out [][]math.Vec2, p, d0, d1 math.Vec2, h float32, pen gxui.Pen) [][]math.Vec2 {
+	cross := d0.X*d1.Y - d0.Y*d1.X
+	if math.Absf(cross) < 1e-6 && d0.Dot(d1) > 0 {
+		return out // Straight
+	}
+	// The join is on the outside of the turn.
+	s := float32(1)
+	if cross > 0 {
+		s = -1
+	}
+	n0, n1 := d0.Tangent().MulS(s), d1.Tangent().MulS(s)
+	a, b := p.Add(n0.MulS(h)), p.Add(n1.MulS(h))
+
+	switch pen.Join {
+	case gxui.RoundJoin:
+		return append(out, arc(p, n0, n1, h))
+	case gxui.MiterJoin:
+		limit := pen.MiterLimit
+		if limit == 0 {
+			limit = gxui.DefaultMiterLimit
+		}
+		c := n0.Dot(n1)
+		if 1+c > 1e-6 && math.Sqrtf(2/(1+c)) <= limit {
+			tip := p.Add(n0.Add(n1).MulS(h / (1 + c)))
+			return append(out, []math.Vec2{p, a, tip, b})
+		}
+	}
+	return append(out, []math.Vec2{p, a, b})
+}
+
+// lineCap appends the cap for the line end p, where the line leaves p in the
+// direction d.
+func lineCap(out [][]math.Vec2, p, d math.Vec2, h float32, c gxui.LineCap) [][]math.Vec2 {
+	n := d.Tangent()
+	switch c {
+	case gxui.SquareCap:
+		back := d.MulS(-h)
+		a, b := p.Add(n.MulS(h)), p.Sub(n.MulS(h))
+		return append(out, []math.Vec2{a, a.Add(back), b.Add(back), b})
+	case gxui.RoundCap:
+		return append(out, arc(p, n, d.MulS(-1), h), arc(p, d.MulS(-1), n.MulS(-1), h))
+	}
+	return out
+}
+
+// arc returns the pie slice of the circle centered at p with radius h, from
+// the unit direction from to the unit direction to, taking the shortest route.
+func arc(p, from, to math.Vec2, h float32) []math.Vec2 {
+	a0 := stdmath.Atan2(float64(from.Y), float64(from.X))
+	a1 := stdmath.Atan2(float64(to.Y), float64(to.X))
+	delta := a1 - a0
+	for delta > stdmath.Pi {
+		delta -= 2 * stdmath.Pi
+	}
+	for delta < -stdmath.Pi {
+		delta += 2 * stdmath.Pi
+	}
+	steps := 2 + int(stdmath.Abs(delta)*float64(math.Maxf(h, 1)))
+	out := make([]math.Vec2, 0, steps+1)
+	out = append(out, p)
+	for i := 0; i < steps; i++ {
+		θ := a0 + delta*float64(i)/float64(steps-1)
+		out = append(out, p.Add(math.Vec2{
+			X: float32(stdmath.Cos(θ)) * h,
+			Y: float32(stdmath.Sin(θ)) * h,
+		}))
+	}
+	return out
+}
+
+func pruneDuplicates(p gxui.Polygon) gxui.Polygon {
+	pruned := make(gxui.Polygon, 0, len(p))
+	last := gxui.PolygonVertex{}
+	for i, v := range p {
+		if i == 0 || last.Position.Sub(v.Position).Vec2().Len() > epsilon {
+			pruned = append(pruned, v)
+		}
+		last = v
+	}
+	return pruned
+}
+
+func prune(points []math.Vec2) []math.Vec2 {
+	pruned := make([]math.Vec2, 0, len(points))
+	for i, p := range points {
+		if i == 0 || p.Sub(pruned[len(pruned)-1]).Len() > epsilon/2 {
+			pruned = append(pruned, p)
+		}
+	}
+	return pruned
+}
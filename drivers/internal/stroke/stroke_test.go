@@ -0,0 +1,72 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stroke
+
+import (
+	"testing"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+	test "github.com/google/gxui/testing"
+)
+
+func v(x, y float32) math.Vec2 { return math.Vec2{X: x, Y: y} }
+
+func TestDash(t *testing.T) {
+	line := []math.Vec2{v(0, 0), v(5, 0), v(10, 0)}
+	test.AssertEquals(t, [][]math.Vec2{
+		{v(0, 0), v(2, 0)},
+		{v(4, 0), v(5, 0), v(6, 0)},
+		{v(8, 0), v(10, 0)},
+	}, dash(line, false, gxui.CreateDash(0, 2, 2)))
+
+	test.AssertEquals(t, [][]math.Vec2{
+		{v(0, 0), v(1, 0)},
+		{v(3, 0), v(5, 0)},
+		{v(7, 0), v(9, 0)},
+	}, dash(line, false, gxui.CreateDash(1, 2, 2)))
+}
+
+func TestDashClosed(t *testing.T) {
+	square := []math.Vec2{v(0, 0), v(4, 0), v(4, 4), v(0, 4)}
+	test.AssertEquals(t, [][]math.Vec2{
+		{v(0, 0), v(2, 0)},
+		{v(4, 2), v(4, 4)},
+		{v(0, 4), v(0, 2)},
+	}, dash(square, true, gxui.CreateDash(0, 2, 4)))
+
+	// The last dash continues over the start of the square.
+	test.AssertEquals(t, [][]math.Vec2{
+		{v(0, 1), v(0, 0), v(1, 0)},
+		{v(3, 0), v(4, 0), v(4, 1)},
+		{v(4, 3), v(4, 4), v(3, 4)},
+		{v(1, 4), v(0, 4), v(0, 3)},
+	}, dash(square, true, gxui.CreateDash(1, 2, 2)))
+}
+
+func TestOffset(t *testing.T) {
+	square := []math.Vec2{v(0, 0), v(4, 0), v(4, 4), v(0, 4)}
+	test.AssertEquals(t, []math.Vec2{v(1, 1), v(3, 1), v(3, 3), v(1, 3)}, Offset(square, true, 1))
+}
+
+func TestStrokeCapsAndJoins(t *testing.T) {
+	line := []math.Vec2{v(0, 0), v(10, 0), v(10, 10)}
+	pen := gxui.CreatePen(2, gxui.White)
+	count := func(pen gxui.Pen) int { return len(Stroke(line, false, pen)) }
+
+	pen.Cap = gxui.SquareCap
+	test.AssertEquals(t, 2+1+2, count(pen)) // 2 segments, 1 join, 2 caps
+
+	pen.Cap, pen.Join = gxui.ButtCap, gxui.BevelJoin
+	test.AssertEquals(t, 2+1, count(pen))
+
+	// The miter of a right angle is √2 times the pen width.
+	pen.Join, pen.MiterLimit = gxui.MiterJoin, 1.5
+	miter := Stroke(line, false, pen)[2]
+	test.AssertEquals(t, 4, len(miter))
+	pen.MiterLimit = 1.4
+	bevel := Stroke(line, false, pen)[2]
+	test.AssertEquals(t, 3, len(bevel))
+}
@@ -0,0 +1,399 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/drivers/internal/stroke"
+	"github.com/google/gxui/math"
+)
+
+type drawStateStack []drawState
+
+func (s *drawStateStack) head() *drawState {
+	return &(*s)[len(*s)-1]
+}
+func (s *drawStateStack) push(ds drawState) {
+	*s = append(*s, ds)
+}
+func (s *drawStateStack) pop() {
+	*s = (*s)[:len(*s)-1]
+}
+
+type canvasOp func(ctx *context, dss *drawStateStack)
+
+type drawState struct {
+	// The below are all in frame coordinates
+	ClipPixels   math.Rect
+	OriginPixels math.Point
+	// If Transformed is true then Transform maps local DIPs to frame pixels,
+	// and OriginPixels is unused.
+	Transform   math.Mat3
+	Transformed bool
+}
+
+// pixelTransform returns the transform from local DIPs to frame pixels.
+func (s *drawState) pixelTransform(r resolution) math.Mat3 {
+	if s.Transformed {
+		return s.Transform
+	}
+	dipsToPixels := r.dipsToPixels()
+	return math.CreateMat3(
+		dipsToPixels, 0, 0,
+		0, dipsToPixels, 0,
+		float32(s.OriginPixels.X), float32(s.OriginPixels.Y), 1,
+	)
+}
+
+// pixelScale returns the number of frame pixels per local DIP. For transforms
+// that scale the axes differently this is the geometric mean of the scales.
+func (s *drawState) pixelScale(r resolution) float32 {
+	m := s.pixelTransform(r)
+	return math.Sqrtf(math.Absf(m[0]*m[4] - m[1]*m[3]))
+}
+
+type resource interface {
+	addRef()
+	release() bool
+}
+
+type canvas struct {
+	refCounted
+	sizeDips          math.Size
+	resources         []resource
+	ops               []canvasOp
+	built             bool
+	buildingPushCount int
+}
+
+func newCanvas(sizeDips math.Size) *canvas {
+	if sizeDips.W <= 0 || sizeDips.H < 0 {
+		panic(fmt.Errorf("Canvas width and height must be positive. Size: %d", sizeDips))
+	}
+	c := &canvas{
+		sizeDips: sizeDips,
+	}
+	c.init()
+	return c
+}
+
+func (c *canvas) draw(ctx *context, dss *drawStateStack) {
+	c.assertAlive("draw")
+	for _, op := range c.ops {
+		op(ctx, dss)
+	}
+}
+
+func (c *canvas) appendOp(name string, op canvasOp) {
+	c.assertAlive(name)
+	if c.built {
+		panic(fmt.Errorf("%s() called after Complete()", name))
+	}
+	c.ops = append(c.ops, op)
+}
+
+func (c *canvas) appendResource(r resource) {
+	r.addRef()
+	c.resources = append(c.resources, r)
+}
+
+// appendBrush holds a reference to any texture used by brush for the lifetime
+// of the canvas.
+func (c *canvas) appendBrush(brush gxui.Brush) {
+	if brush.Pattern != nil {
+		c.appendResource(brush.Pattern.Texture.(*texture))
+	}
+}
+
+func (c *canvas) release() bool {
+	if !c.refCounted.release() {
+		return false
+	}
+	for _, r := range c.resources {
+		r.release()
+	}
+	c.ops = nil
+	c.resources = nil
+	return true
+}
+
+// gxui.Canvas compliance
+func (c *canvas) Size() math.Size {
+	return c.sizeDips
+}
+
+func (c *canvas) IsComplete() bool {
+	return c.built
+}
+
+func (c *canvas) Complete() {
+	if c.built {
+		panic("Complete() called twice")
+	}
+	if c.buildingPushCount != 0 {
+		panic(fmt.Errorf("Push() count was %d when calling Complete", c.buildingPushCount))
+	}
+	c.built = true
+}
+
+func (c *canvas) Push() {
+	c.buildingPushCount++
+	c.appendOp("Push", func(ctx *context, dss *drawStateStack) {
+		dss.push(*dss.head())
+	})
+}
+
+func (c *canvas) Pop() {
+	c.buildingPushCount--
+	c.appendOp("Pop", func(ctx *context, dss *drawStateStack) {
+		dss.pop()
+	})
+}
+
+func (c *canvas) AddClip(r math.Rect) {
+	c.appendOp("AddClip", func(ctx *context, dss *drawStateStack) {
+		ds := dss.head()
+		var rectFramePixels math.Rect
+		if ds.Transformed {
+			rectFramePixels = ds.Transform.TransformRect(r)
+		} else {
+			rectLocalPixels := ctx.resolution.rectDipsToPixels(r)
+			rectFramePixels = rectLocalPixels.Offset(ds.OriginPixels)
+		}
+		ds.ClipPixels = intersect(ds.ClipPixels, rectFramePixels)
+	})
+}
+
+func (c *canvas) Transform(m math.Mat3) {
+	if m == math.Mat3Ident {
+		return
+	}
+	c.appendOp("Transform", func(ctx *context, dss *drawStateStack) {
+		ds := dss.head()
+		ds.Transform = m.Mul(ds.pixelTransform(ctx.resolution))
+		ds.Transformed = true
+	})
+}
+
+func (c *canvas) Clear(color gxui.Color) {
+	c.appendOp("Clear", func(ctx *context, dss *drawStateStack) {
+		ctx.clear(dss.head().ClipPixels, color)
+	})
+}
+
+func (c *canvas) DrawCanvas(cc gxui.Canvas, offsetDips math.Point) {
+	if cc == nil {
+		panic("Canvas cannot be nil")
+	}
+	childCanvas := cc.(*canvas)
+	c.appendOp("DrawCanvas", func(ctx *context, dss *drawStateStack) {
+		childCanvas.drawAt(ctx, dss, offsetDips)
+	})
+	c.appendResource(childCanvas)
+}
+
+func (c *canvas) DrawCanvasBlend(cc gxui.Canvas, offsetDips math.Point, alpha float32, mode gxui.BlendMode) {
+	if cc == nil {
+		panic("Canvas cannot be nil")
+	}
+	if alpha >= 1 && mode == gxui.BlendNormal {
+		c.DrawCanvas(cc, offsetDips)
+		return
+	}
+	childCanvas := cc.(*canvas)
+	c.appendOp("DrawCanvasBlend", func(ctx *context, dss *drawStateStack) {
+		clip := dss.head().ClipPixels
+		if alpha <= 0 || clip.W() <= 0 || clip.H() <= 0 {
+			return
+		}
+		// Draw the child to a transparent layer covering the clip, then
+		// composite the layer.
+		frame := ctx.target
+		ctx.target = image.NewRGBA(imageRect(clip))
+		childCanvas.drawAt(ctx, dss, offsetDips)
+		layer := ctx.target
+		ctx.target = frame
+		ctx.composite(layer, clip, alpha, mode)
+	})
+	c.appendResource(childCanvas)
+}
+
+func (c *canvas) DrawCanvasBlur(cc gxui.Canvas, offsetDips math.Point, radius float32) {
+	if cc == nil {
+		panic("Canvas cannot be nil")
+	}
+	if radius <= 0 {
+		c.DrawCanvas(cc, offsetDips)
+		return
+	}
+	childCanvas := cc.(*canvas)
+	c.appendOp("DrawCanvasBlur", func(ctx *context, dss *drawStateStack) {
+		ds := dss.head()
+		clip := ds.ClipPixels
+		if clip.W() <= 0 || clip.H() <= 0 {
+			return
+		}
+		// Pixels up to three standard deviations outside the clip contribute
+		// to the blurred pixels inside it, so the child is drawn to a layer
+		// that covers the clip expanded by that much.
+		sigma := radius / 2 * ds.pixelScale(ctx.resolution)
+		layerRect := clip.ExpandI(ceil(sigma * 3))
+		frame := ctx.target
+		ctx.target = image.NewRGBA(imageRect(layerRect))
+		dss.push(*ds)
+		dss.head().ClipPixels = layerRect
+		childCanvas.drawAt(ctx, dss, offsetDips)
+		dss.pop()
+		layer := ctx.target
+		ctx.target = frame
+		gaussianBlur(layer, sigma)
+		ctx.composite(layer, clip, 1, gxui.BlendNormal)
+	})
+	c.appendResource(childCanvas)
+}
+
+// drawAt draws the canvas with its origin offset by offsetDips.
+func (c *canvas) drawAt(ctx *context, dss *drawStateStack, offsetDips math.Point) {
+	dss.push(*dss.head())
+	ds := dss.head()
+	if ds.Transformed {
+		ds.Transform = math.CreateMat3Translate(offsetDips.Vec2()).Mul(ds.Transform)
+	} else {
+		offsetPixels := ctx.resolution.pointDipsToPixels(offsetDips)
+		ds.OriginPixels = ds.OriginPixels.Add(offsetPixels)
+	}
+	c.draw(ctx, dss)
+	dss.pop()
+}
+
+func (c *canvas) DrawRunes(f gxui.Font, r []rune, p []math.Point, col gxui.Color) {
+	if f == nil {
+		panic("Font cannot be nil")
+	}
+	runes := append([]rune{}, r...)
+	points := append([]math.Point{}, p...)
+	c.appendOp("DrawRunes", func(ctx *context, dss *drawStateStack) {
+		f.(*font).DrawRunes(ctx, runes, points, col, dss.head())
+	})
+}
+
+func (c *canvas) DrawLines(lines gxui.Polygon, pen gxui.Pen) {
+	var edge *shape
+	if pen.IsStyled() {
+		edge = &shape{polygons: stroke.Stroke(stroke.Outline(lines, false), false, pen)}
+	} else {
+		edge = openPolyToShape(lines, pen.Width)
+	}
+	c.appendOp("DrawLines", func(ctx *context, dss *drawStateStack) {
+		if edge != nil && pen.Color.A > 0 {
+			ctx.fillShape(edge, gxui.CreateBrush(pen.Color), dss.head())
+		}
+	})
+}
+
+func (c *canvas) DrawPolygon(poly gxui.Polygon, pen gxui.Pen, brush gxui.Brush) {
+	fill, edge := closedPolyToShape(poly, pen.Width)
+	if pen.IsStyled() {
+		edge = &shape{polygons: stroke.Stroke(stroke.Outline(poly, true), true, pen)}
+	}
+	brush = brush.Resolve(poly.Bounds())
+	c.appendBrush(brush)
+	c.appendOp("DrawPolygon", func(ctx *context, dss *drawStateStack) {
+		ds := dss.head()
+		if fill != nil && !brush.IsTransparent() {
+			ctx.fillShape(fill, brush, ds)
+		}
+		if edge != nil && pen.Color.A > 0 {
+			ctx.fillShape(edge, gxui.CreateBrush(pen.Color), ds)
+		}
+	})
+}
+
+func (c *canvas) DrawRect(r math.Rect, brush gxui.Brush) {
+	brush = brush.Resolve(r)
+	c.appendBrush(brush)
+	c.appendOp("DrawRect", func(ctx *context, dss *drawStateStack) {
+		ds := dss.head()
+		if ds.Transformed || !brush.IsSolid() {
+			ctx.fillShape(rectToShape(r), brush, ds)
+			return
+		}
+		rect := ctx.resolution.rectDipsToPixels(r).Offset(ds.OriginPixels)
+		ctx.fill(intersect(rect, ds.ClipPixels), brush.Color)
+	})
+}
+
+func (c *canvas) DrawRoundedRect(r math.Rect, tl, tr, bl, br float32, pen gxui.Pen, brush gxui.Brush) {
+	if tl == 0 && tr == 0 && bl == 0 && br == 0 && pen.Color.A == 0 {
+		c.DrawRect(r, brush)
+		return
+	}
+	p := gxui.Polygon{
+		gxui.PolygonVertex{Position: r.TL(), RoundedRadius: tl},
+		gxui.PolygonVertex{Position: r.TR(), RoundedRadius: tr},
+		gxui.PolygonVertex{Position: r.BR(), RoundedRadius: br},
+		gxui.PolygonVertex{Position: r.BL(), RoundedRadius: bl},
+	}
+	c.DrawPolygon(p, pen, brush)
+}
+
+func (c *canvas) DrawShadow(r math.Rect, tl, tr, bl, br float32, s gxui.Shadow) {
+	if !s.IsVisible() {
+		return
+	}
+	c.appendOp("DrawShadow", func(ctx *context, dss *drawStateStack) {
+		ctx.drawShadow(r, [4]float32{tl, tr, bl, br}, s, dss.head())
+	})
+}
+
+func (c *canvas) FillPath(path *gxui.VectorPath, brush gxui.Brush) {
+	if path == nil {
+		panic("Path cannot be nil")
+	}
+	fill := pathFillShape(path)
+	brush = brush.Resolve(path.Bounds())
+	c.appendBrush(brush)
+	c.appendOp("FillPath", func(ctx *context, dss *drawStateStack) {
+		if !brush.IsTransparent() {
+			ctx.fillShape(fill, brush, dss.head())
+		}
+	})
+}
+
+func (c *canvas) StrokePath(path *gxui.VectorPath, pen gxui.Pen) {
+	if path == nil {
+		panic("Path cannot be nil")
+	}
+	edge := pathStrokeShape(path, pen)
+	c.appendOp("StrokePath", func(ctx *context, dss *drawStateStack) {
+		if pen.Color.A > 0 {
+			ctx.fillShape(edge, gxui.CreateBrush(pen.Color), dss.head())
+		}
+	})
+}
+
+func (c *canvas) DrawTexture(t gxui.Texture, r math.Rect) {
+	if t == nil {
+		panic("Texture cannot be nil")
+	}
+	tex := t.(*texture)
+	c.appendOp("DrawTexture", func(ctx *context, dss *drawStateStack) {
+		ds := dss.head()
+		if ds.Transformed {
+			ctx.blitTransformed(tex, r, ds)
+			return
+		}
+		rect := ctx.resolution.rectDipsToPixels(r).Offset(ds.OriginPixels)
+		ctx.blit(tex, rect, ds.ClipPixels)
+	})
+	c.appendResource(tex)
+}
+
+func (c *canvas) Release() {
+	c.release()
+}
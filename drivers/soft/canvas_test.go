@@ -0,0 +1,288 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import test "github.com/google/gxui/testing"
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+)
+
+func render(c *canvas) *image.RGBA {
+	size := c.Size()
+	ctx := newContext(newFrame(size), size)
+	dss := drawStateStack{drawState{ClipPixels: size.Rect()}}
+	c.draw(ctx, &dss)
+	return ctx.target
+}
+
+func TestDrawRectClipped(t *testing.T) {
+	c := newCanvas(math.Size{W: 8, H: 8})
+	c.Push()
+	c.AddClip(math.CreateRect(2, 2, 6, 6))
+	c.DrawRect(math.CreateRect(0, 0, 4, 8), gxui.CreateBrush(gxui.Red))
+	c.Pop()
+	c.Complete()
+
+	img := render(c)
+	red := color.RGBA{R: 255, A: 255}
+	test.AssertEquals(t, toRGBA(clearColor), img.RGBAAt(1, 3))
+	test.AssertEquals(t, red, img.RGBAAt(2, 2))
+	test.AssertEquals(t, red, img.RGBAAt(3, 5))
+	test.AssertEquals(t, toRGBA(clearColor), img.RGBAAt(4, 3))
+	test.AssertEquals(t, toRGBA(clearColor), img.RGBAAt(3, 6))
+}
+
+func TestDrawPolygonCoverage(t *testing.T) {
+	c := newCanvas(math.Size{W: 8, H: 8})
+	c.Clear(gxui.Black)
+	c.DrawPolygon(gxui.Polygon{
+		gxui.PolygonVertex{Position: math.Point{X: 0, Y: 0}},
+		gxui.PolygonVertex{Position: math.Point{X: 8, Y: 0}},
+		gxui.PolygonVertex{Position: math.Point{X: 0, Y: 8}},
+	}, gxui.TransparentPen, gxui.WhiteBrush)
+	c.Complete()
+
+	img := render(c)
+	test.AssertEquals(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, img.RGBAAt(1, 1))
+	test.AssertEquals(t, color.RGBA{A: 255}, img.RGBAAt(6, 6))
+	// Pixels on the diagonal are partially covered
+	edge := img.RGBAAt(4, 3)
+	test.AssertEquals(t, true, edge.R > 0 && edge.R < 255)
+}
+
+func TestTerminateProcessesQueuedCalls(t *testing.T) {
+	calls := []string{}
+	StartDriver(func(d gxui.Driver) {
+		d.Call(func() { calls = append(calls, "a") })
+		d.Terminate()
+		d.Call(func() { calls = append(calls, "b") })
+	})
+	test.AssertEquals(t, []string{"a", "b"}, calls)
+}
+
+func TestDrawRectTransformed(t *testing.T) {
+	c := newCanvas(math.Size{W: 8, H: 8})
+	c.Clear(gxui.Black)
+	c.Push()
+	c.Transform(math.CreateMat3Scale(math.Vec2{X: 2, Y: 2}).Mul(
+		math.CreateMat3Translate(math.Vec2{X: 2, Y: 1})))
+	c.DrawRect(math.CreateRect(0, 0, 2, 1), gxui.WhiteBrush)
+	c.Pop()
+	c.DrawRect(math.CreateRect(0, 7, 1, 8), gxui.WhiteBrush)
+	c.Complete()
+
+	img := render(c)
+	white, black := color.RGBA{R: 255, G: 255, B: 255, A: 255}, color.RGBA{A: 255}
+	test.AssertEquals(t, black, img.RGBAAt(1, 1))
+	test.AssertEquals(t, white, img.RGBAAt(2, 1))
+	test.AssertEquals(t, white, img.RGBAAt(5, 2))
+	test.AssertEquals(t, black, img.RGBAAt(6, 2))
+	test.AssertEquals(t, black, img.RGBAAt(5, 3))
+	// The transform is restored by Pop
+	test.AssertEquals(t, white, img.RGBAAt(0, 7))
+	test.AssertEquals(t, black, img.RGBAAt(1, 7))
+}
+
+func TestDrawCanvasTransformed(t *testing.T) {
+	child := newCanvas(math.Size{W: 2, H: 2})
+	child.DrawRect(math.CreateRect(0, 0, 1, 2), gxui.WhiteBrush)
+	child.Complete()
+
+	c := newCanvas(math.Size{W: 8, H: 8})
+	c.Clear(gxui.Black)
+	c.Push()
+	c.Transform(math.CreateMat3Rotate(math.Pi / 2).Mul(
+		math.CreateMat3Translate(math.Vec2{X: 4, Y: 4})))
+	c.DrawCanvas(child, math.Point{X: 1, Y: 0})
+	c.Pop()
+	c.Complete()
+
+	// The child's rect spans [1, 2]x[0, 2], which rotates to [2, 4]x[1, 2].
+	img := render(c)
+	white, black := color.RGBA{R: 255, G: 255, B: 255, A: 255}, color.RGBA{A: 255}
+	test.AssertEquals(t, white, img.RGBAAt(2, 5))
+	test.AssertEquals(t, white, img.RGBAAt(3, 5))
+	test.AssertEquals(t, black, img.RGBAAt(4, 5))
+	test.AssertEquals(t, black, img.RGBAAt(3, 6))
+	test.AssertEquals(t, black, img.RGBAAt(3, 4))
+}
+
+func TestDrawRectGradient(t *testing.T) {
+	c := newCanvas(math.Size{W: 8, H: 8})
+	c.Clear(gxui.Black)
+	c.DrawRect(math.CreateRect(0, 0, 8, 4), gxui.CreateLinearGradientBrush(
+		math.Vec2{X: 0}, math.Vec2{X: 8},
+		gxui.GradientStop{Offset: 0, Color: gxui.Red},
+		gxui.GradientStop{Offset: 1, Color: gxui.Blue},
+	))
+	c.DrawRoundedRect(math.CreateRect(0, 4, 8, 8), 0, 0, 0, 0, gxui.TransparentPen,
+		gxui.CreateVerticalGradientBrush(gxui.Black, gxui.White))
+	c.Complete()
+
+	img := render(c)
+	left, right := img.RGBAAt(0, 1), img.RGBAAt(7, 1)
+	test.AssertEquals(t, true, left.R > 200 && left.B < 50)
+	test.AssertEquals(t, true, right.B > 200 && right.R < 50)
+	top, bottom := img.RGBAAt(3, 4), img.RGBAAt(3, 7)
+	test.AssertEquals(t, true, top.G < bottom.G)
+}
+
+func TestDrawRectPattern(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.SetRGBA(0, 0, color.RGBA{R: 255, A: 255})
+	src.SetRGBA(1, 0, color.RGBA{G: 255, A: 255})
+	tex := newTexture(src, 1)
+
+	c := newCanvas(math.Size{W: 8, H: 2})
+	c.DrawRect(math.CreateRect(0, 0, 8, 2), gxui.CreatePatternBrush(tex, gxui.PatternTile, math.Rect{}))
+	c.Complete()
+	tex.Release()
+
+	img := render(c)
+	red, green := color.RGBA{R: 255, A: 255}, color.RGBA{G: 255, A: 255}
+	test.AssertEquals(t, red, img.RGBAAt(0, 0))
+	test.AssertEquals(t, green, img.RGBAAt(1, 0))
+	test.AssertEquals(t, red, img.RGBAAt(6, 1))
+	test.AssertEquals(t, green, img.RGBAAt(7, 1))
+}
+
+func TestDrawLinesDashed(t *testing.T) {
+	pen := gxui.CreatePen(2, gxui.White)
+	pen.Dash = gxui.CreateDash(0, 2, 2)
+	c := newCanvas(math.Size{W: 8, H: 2})
+	c.Clear(gxui.Black)
+	c.DrawLines(gxui.Polygon{
+		gxui.PolygonVertex{Position: math.Point{X: 0, Y: 0}},
+		gxui.PolygonVertex{Position: math.Point{X: 8, Y: 0}},
+	}, pen)
+	c.Complete()
+
+	img := render(c)
+	white, black := color.RGBA{R: 255, G: 255, B: 255, A: 255}, color.RGBA{A: 255}
+	for x, on := range []bool{true, true, false, false, true, true, false, false} {
+		expected := black
+		if on {
+			expected = white
+		}
+		test.AssertEquals(t, expected, img.RGBAAt(x, 1))
+	}
+}
+
+func TestFillPathFillRules(t *testing.T) {
+	path := &gxui.VectorPath{}
+	path.AddRect(math.CreateRect(0, 0, 8, 8))
+	path.AddRect(math.CreateRect(2, 2, 6, 6))
+
+	white, black := color.RGBA{R: 255, G: 255, B: 255, A: 255}, color.RGBA{A: 255}
+	for rule, center := range map[gxui.FillRule]color.RGBA{
+		gxui.NonZero: white,
+		gxui.EvenOdd: black,
+	} {
+		path.FillRule = rule
+		c := newCanvas(math.Size{W: 8, H: 8})
+		c.Clear(gxui.Black)
+		c.FillPath(path, gxui.WhiteBrush)
+		c.Complete()
+
+		img := render(c)
+		test.AssertEquals(t, white, img.RGBAAt(1, 1))
+		test.AssertEquals(t, center, img.RGBAAt(4, 4))
+	}
+}
+
+func TestDrawCanvasBlend(t *testing.T) {
+	for mode, expected := range map[gxui.BlendMode]color.RGBA{
+		gxui.BlendNormal:   {R: 192, G: 64, B: 64, A: 255},
+		gxui.BlendMultiply: {R: 128, G: 64, B: 64, A: 255},
+		gxui.BlendScreen:   {R: 192, G: 128, B: 128, A: 255},
+		gxui.BlendAdditive: {R: 255, G: 128, B: 128, A: 255},
+	} {
+		child := newCanvas(math.Size{W: 4, H: 4})
+		child.DrawRect(math.CreateRect(0, 0, 2, 4), gxui.CreateBrush(gxui.Red))
+		child.Complete()
+
+		c := newCanvas(math.Size{W: 8, H: 8})
+		c.Clear(gxui.Gray50)
+		c.DrawCanvasBlend(child, math.Point{X: 2, Y: 2}, 0.5, mode)
+		c.Complete()
+
+		img := render(c)
+		test.AssertEquals(t, expected, img.RGBAAt(3, 3))
+		// Transparent pixels of the child leave the destination unchanged.
+		test.AssertEquals(t, toRGBA(gxui.Gray50), img.RGBAAt(5, 3))
+		test.AssertEquals(t, toRGBA(gxui.Gray50), img.RGBAAt(1, 3))
+	}
+}
+
+func TestDrawShadow(t *testing.T) {
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	r := math.CreateRect(4, 4, 12, 12)
+
+	c := newCanvas(math.Size{W: 16, H: 16})
+	c.Clear(gxui.White)
+	c.DrawShadow(r, 0, 0, 0, 0, gxui.CreateShadow(gxui.Black, math.Point{X: 2, Y: 2}, 0))
+	c.Complete()
+	img := render(c)
+	// Outer shadows are not drawn inside the shape.
+	test.AssertEquals(t, white, img.RGBAAt(10, 10))
+	test.AssertEquals(t, color.RGBA{A: 255}, img.RGBAAt(13, 13))
+	test.AssertEquals(t, white, img.RGBAAt(3, 8))
+	test.AssertEquals(t, white, img.RGBAAt(14, 14))
+
+	c = newCanvas(math.Size{W: 16, H: 16})
+	c.Clear(gxui.White)
+	c.DrawShadow(r, 0, 0, 0, 0, gxui.CreateShadow(gxui.Black, math.ZeroPoint, 4))
+	c.Complete()
+	img = render(c)
+	// The blurred edge is half covered at the edge of the shape, fading out
+	// over the blur radius.
+	test.AssertEquals(t, white, img.RGBAAt(8, 8))
+	a, b, d := img.RGBAAt(12, 8).R, img.RGBAAt(13, 8).R, img.RGBAAt(15, 8).R
+	test.AssertEquals(t, true, a < b && b < d && d < 255)
+	test.AssertEquals(t, img.RGBAAt(12, 8), img.RGBAAt(3, 8))
+
+	c = newCanvas(math.Size{W: 16, H: 16})
+	c.Clear(gxui.White)
+	c.DrawShadow(r, 0, 0, 0, 0, gxui.CreateInnerShadow(gxui.Black, math.Point{X: 2, Y: 2}, 0))
+	c.Complete()
+	img = render(c)
+	// Inner shadows are only drawn inside the shape, where the offset shape
+	// does not cover it.
+	test.AssertEquals(t, color.RGBA{A: 255}, img.RGBAAt(5, 8))
+	test.AssertEquals(t, white, img.RGBAAt(6, 8))
+	test.AssertEquals(t, white, img.RGBAAt(3, 8))
+}
+
+func TestDrawCanvasBlur(t *testing.T) {
+	child := newCanvas(math.Size{W: 16, H: 16})
+	child.DrawRect(math.CreateRect(0, 0, 8, 16), gxui.WhiteBrush)
+	child.Complete()
+
+	c := newCanvas(math.Size{W: 16, H: 16})
+	c.Clear(gxui.Black)
+	c.Push()
+	c.AddClip(math.CreateRect(0, 0, 16, 8))
+	c.DrawCanvasBlur(child, math.ZeroPoint, 2)
+	c.Pop()
+	c.Complete()
+
+	img := render(c)
+	white, black := color.RGBA{R: 255, G: 255, B: 255, A: 255}, color.RGBA{A: 255}
+	// The edge is symmetrically blurred, fading out over three standard
+	// deviations.
+	left, right := img.RGBAAt(7, 4), img.RGBAAt(8, 4)
+	test.AssertEquals(t, 255, int(left.R)+int(right.R))
+	test.AssertEquals(t, true, left.R > 128)
+	test.AssertEquals(t, white, img.RGBAAt(4, 4))
+	test.AssertEquals(t, black, img.RGBAAt(11, 4))
+	// The child is blurred before it is clipped, so is not faded at the clip.
+	test.AssertEquals(t, white, img.RGBAAt(4, 7))
+	test.AssertEquals(t, black, img.RGBAAt(4, 8))
+}
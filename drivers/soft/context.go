@@ -0,0 +1,440 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	stdmath "math"
+	"sort"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+)
+
+// The number of coverage samples taken for each pixel when rasterizing shapes.
+// This matches the 4x multisampling used by the gl driver.
+const samplesPerPixel = 4
+
+// The sub-pixel sample positions, using a rotated-grid pattern.
+var samplePositions = [samplesPerPixel]math.Vec2{
+	{X: 0.375, Y: 0.125},
+	{X: 0.875, Y: 0.375},
+	{X: 0.125, Y: 0.625},
+	{X: 0.625, Y: 0.875},
+}
+
+// context holds the target image and state for a single frame render.
+type context struct {
+	target     *image.RGBA
+	resolution resolution
+}
+
+func newContext(target *image.RGBA, sizeDips math.Size) *context {
+	sizePixels := target.Bounds().Size()
+	dipsToPixels := float32(sizePixels.X) / float32(sizeDips.W)
+	return &context{
+		target:     target,
+		resolution: resolution(dipsToPixels*65536 + 0.5),
+	}
+}
+
+// premultiplied returns the pre-multiplied color of c scaled by the coverage
+// cov, where 255 is fully covered.
+func premultiplied(c gxui.Color, cov uint32) color.RGBA {
+	a := uint32(math.Clampf(c.A, 0, 1)*255+0.5) * cov / 255
+	return color.RGBA{
+		R: uint8(uint32(math.Clampf(c.R, 0, 1)*255+0.5) * a / 255),
+		G: uint8(uint32(math.Clampf(c.G, 0, 1)*255+0.5) * a / 255),
+		B: uint8(uint32(math.Clampf(c.B, 0, 1)*255+0.5) * a / 255),
+		A: uint8(a),
+	}
+}
+
+// toRGBA returns the pre-multiplied color.RGBA for c.
+func toRGBA(c gxui.Color) color.RGBA {
+	return premultiplied(c, 255)
+}
+
+// intersect returns the intersection of the rectangles a and b, or an empty
+// rectangle if they do not overlap.
+func intersect(a, b math.Rect) math.Rect {
+	r := math.Rect{Min: a.Min.Max(b.Min), Max: a.Max.Min(b.Max)}
+	if r.Min.X >= r.Max.X || r.Min.Y >= r.Max.Y {
+		return math.Rect{}
+	}
+	return r
+}
+
+func imageRect(r math.Rect) image.Rectangle {
+	return image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Max.Y)
+}
+
+// blend composites the pre-multiplied source color over the pixel at (x, y).
+func (c *context) blend(x, y int, s color.RGBA) {
+	if s.A == 0 {
+		return
+	}
+	i := c.target.PixOffset(x, y)
+	p := c.target.Pix[i : i+4 : i+4]
+	ia := 255 - uint32(s.A)
+	p[0] = s.R + uint8((uint32(p[0])*ia+127)/255)
+	p[1] = s.G + uint8((uint32(p[1])*ia+127)/255)
+	p[2] = s.B + uint8((uint32(p[2])*ia+127)/255)
+	p[3] = s.A + uint8((uint32(p[3])*ia+127)/255)
+}
+
+// composite combines the pre-multiplied pixels of layer within rect with the
+// target using mode, after scaling the layer by alpha.
+func (c *context) composite(layer *image.RGBA, rect math.Rect, alpha float32, mode gxui.BlendMode) {
+	a := int(math.Saturate(alpha)*255 + 0.5)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			i := layer.PixOffset(x, y)
+			src := layer.Pix[i : i+4 : i+4]
+			if src[3] == 0 {
+				continue
+			}
+			j := c.target.PixOffset(x, y)
+			dst := c.target.Pix[j : j+4 : j+4]
+			sa := (int(src[3])*a + 127) / 255
+			da := int(dst[3])
+			for k := 0; k < 4; k++ {
+				s := (int(src[k])*a + 127) / 255
+				d := int(dst[k])
+				var o int
+				switch {
+				case mode == gxui.BlendAdditive:
+					o = s + d
+				case mode == gxui.BlendMultiply && k < 3:
+					o = (s*d + s*(255-da) + d*(255-sa) + 127) / 255
+				case mode == gxui.BlendScreen && k < 3:
+					o = s + d - (s*d+127)/255
+				default:
+					// Normal blending, which is also the resulting alpha of
+					// the multiply and screen modes.
+					o = s + (d*(255-sa)+127)/255
+				}
+				dst[k] = uint8(math.Min(o, 255))
+			}
+		}
+	}
+}
+
+// clear replaces all the pixels in rect with col.
+func (c *context) clear(rect math.Rect, col gxui.Color) {
+	draw.Draw(c.target, imageRect(rect), image.NewUniform(toRGBA(col)), image.ZP, draw.Src)
+}
+
+// fill blends col over all the pixels in rect.
+func (c *context) fill(rect math.Rect, col gxui.Color) {
+	s := premultiplied(col, 255)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			c.blend(x, y, s)
+		}
+	}
+}
+
+// fillMask blends col over the pixels of dstRect using mask for coverage. Only
+// pixels inside clip are modified.
+func (c *context) fillMask(mask *image.Alpha, dstRect, clip math.Rect, col gxui.Color) {
+	rect := intersect(dstRect, clip)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			cov := mask.AlphaAt(x-dstRect.Min.X, y-dstRect.Min.Y).A
+			if cov != 0 {
+				c.blend(x, y, premultiplied(col, uint32(cov)))
+			}
+		}
+	}
+}
+
+// paint returns the pre-multiplied color of a brush for the frame pixel at
+// (x, y), scaled by the coverage cov, where 255 is fully covered.
+type paint func(x, y int, cov uint32) color.RGBA
+
+// brushPaint returns the paint for brush, positioned in DIPs relative to the
+// draw state's origin.
+func (c *context) brushPaint(brush gxui.Brush, ds *drawState) paint {
+	switch {
+	case brush.Gradient != nil:
+		g := brush.Gradient
+		inv := ds.pixelTransform(c.resolution).Invert()
+		return func(x, y int, cov uint32) color.RGBA {
+			p := inv.Transform(math.Vec2{X: float32(x) + 0.5, Y: float32(y) + 0.5})
+			return premultiplied(g.ColorAt(g.Offset(p)), cov)
+		}
+	case brush.Pattern != nil:
+		p := brush.Pattern
+		t := p.Texture.(*texture)
+		src := t.pixels()
+		sw, sh := float32(src.Bounds().Dx()), float32(src.Bounds().Dy())
+		inv := ds.pixelTransform(c.resolution).Invert()
+		return func(x, y int, cov uint32) color.RGBA {
+			uv := p.UV(inv.Transform(math.Vec2{X: float32(x) + 0.5, Y: float32(y) + 0.5}))
+			if t.flipY {
+				uv.Y = 1 - uv.Y
+			}
+			s := sampleBilinear(src, uv.X*sw-0.5, uv.Y*sh-0.5)
+			return color.RGBA{
+				R: uint8(uint32(s.R) * cov / 255),
+				G: uint8(uint32(s.G) * cov / 255),
+				B: uint8(uint32(s.B) * cov / 255),
+				A: uint8(uint32(s.A) * cov / 255),
+			}
+		}
+	default:
+		col := brush.Color
+		return func(x, y int, cov uint32) color.RGBA {
+			return premultiplied(col, cov)
+		}
+	}
+}
+
+// fillShape rasterizes the shape s, positioned in DIPs relative to the draw
+// state's origin, blending the brush over the covered pixels.
+func (c *context) fillShape(s *shape, brush gxui.Brush, ds *drawState) {
+	transform := ds.pixelTransform(c.resolution)
+
+	// Transform the polygons to frame pixels and calculate the bounds.
+	polygons := make([][]math.Vec2, len(s.polygons))
+	min := math.Vec2{X: stdmath.MaxFloat32, Y: stdmath.MaxFloat32}
+	max := math.Vec2{X: -stdmath.MaxFloat32, Y: -stdmath.MaxFloat32}
+	for i, poly := range s.polygons {
+		pixels := make([]math.Vec2, len(poly))
+		for j, v := range poly {
+			p := transform.Transform(v)
+			pixels[j] = p
+			min = math.Vec2{X: math.Minf(min.X, p.X), Y: math.Minf(min.Y, p.Y)}
+			max = math.Vec2{X: math.Maxf(max.X, p.X), Y: math.Maxf(max.Y, p.Y)}
+		}
+		polygons[i] = pixels
+	}
+	bounds := math.Rect{
+		Min: math.Point{X: floor(min.X), Y: floor(min.Y)},
+		Max: math.Point{X: ceil(max.X) + 1, Y: ceil(max.Y) + 1},
+	}
+	bounds = intersect(bounds, ds.ClipPixels)
+	if bounds.W() == 0 || bounds.H() == 0 {
+		return
+	}
+
+	paint := c.brushPaint(brush, ds)
+
+	// Build the per-pixel sample mask as the union of each polygon.
+	cov := newCoverage(bounds)
+	if s.combined {
+		cov.addPolygons(polygons, s.rule)
+	} else {
+		for _, poly := range polygons {
+			cov.addPolygons([][]math.Vec2{poly}, gxui.NonZero)
+		}
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if n := cov.samples(x, y); n > 0 {
+				c.blend(x, y, paint(x, y, uint32(n*255/samplesPerPixel)))
+			}
+		}
+	}
+}
+
+// blit draws the texture t stretched to dstRect, in frame pixels, using
+// bilinear filtering. Only pixels inside clip are modified.
+func (c *context) blit(t *texture, dstRect, clip math.Rect) {
+	if dstRect.W() <= 0 || dstRect.H() <= 0 {
+		return
+	}
+	src := t.pixels()
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	if sw == 0 || sh == 0 {
+		return
+	}
+	scaleX := float32(sw) / float32(dstRect.W())
+	scaleY := float32(sh) / float32(dstRect.H())
+
+	rect := intersect(dstRect, clip)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		v := (float32(y-dstRect.Min.Y)+0.5)*scaleY - 0.5
+		if t.flipY {
+			v = float32(sh-1) - v
+		}
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			u := (float32(x-dstRect.Min.X)+0.5)*scaleX - 0.5
+			c.blend(x, y, sampleBilinear(src, u, v))
+		}
+	}
+}
+
+// blitTransformed draws the texture t stretched to rect, in local DIPs, using
+// the draw state's transform.
+func (c *context) blitTransformed(t *texture, rect math.Rect, ds *drawState) {
+	src := t.pixels()
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	if sw == 0 || sh == 0 {
+		return
+	}
+	// Texels to local DIPs
+	m := math.CreateMat3Scale(math.Vec2{
+		X: float32(rect.W()) / float32(sw),
+		Y: float32(rect.H()) / float32(sh),
+	}).Mul(math.CreateMat3Translate(rect.Min.Vec2()))
+	if t.flipY {
+		m = math.CreateMat3(1, 0, 0, 0, -1, 0, 0, float32(sh), 1).Mul(m)
+	}
+	c.drawTransformed(math.Size{W: sw, H: sh}, m.Mul(ds.Transform), ds.ClipPixels,
+		func(u, v float32) color.RGBA { return sampleBilinear(src, u, v) })
+}
+
+// drawTransformed blends an image of the given size, transformed to frame
+// pixels by m, over the pixels inside clip. sample is called with the texel
+// coordinate for each covered pixel.
+func (c *context) drawTransformed(size math.Size, m math.Mat3, clip math.Rect, sample func(u, v float32) color.RGBA) {
+	bounds := intersect(m.TransformRect(size.Rect()), clip)
+	inv := m.Invert()
+	w, h := float32(size.W), float32(size.H)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			uv := inv.Transform(math.Vec2{X: float32(x) + 0.5, Y: float32(y) + 0.5})
+			if uv.X >= 0 && uv.Y >= 0 && uv.X < w && uv.Y < h {
+				c.blend(x, y, sample(uv.X-0.5, uv.Y-0.5))
+			}
+		}
+	}
+}
+
+// sampleBilinear returns the bilinearly filtered pre-multiplied color of img
+// at the texel coordinate (u, v), clamping to the image edges.
+func sampleBilinear(img *image.RGBA, u, v float32) color.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	u = math.Clampf(u, 0, float32(w-1))
+	v = math.Clampf(v, 0, float32(h-1))
+	x0, y0 := int(u), int(v)
+	x1, y1 := math.Min(x0+1, w-1), math.Min(y0+1, h-1)
+	fx, fy := u-float32(x0), v-float32(y0)
+
+	p00 := img.Pix[img.PixOffset(x0, y0):]
+	p10 := img.Pix[img.PixOffset(x1, y0):]
+	p01 := img.Pix[img.PixOffset(x0, y1):]
+	p11 := img.Pix[img.PixOffset(x1, y1):]
+	var out [4]uint8
+	for i := range out {
+		top := math.Lerpf(float32(p00[i]), float32(p10[i]), fx)
+		bottom := math.Lerpf(float32(p01[i]), float32(p11[i]), fx)
+		out[i] = uint8(math.Lerpf(top, bottom, fy) + 0.5)
+	}
+	return color.RGBA{R: out[0], G: out[1], B: out[2], A: out[3]}
+}
+
+// sampleAlpha returns the bilinearly filtered value of img at the texel
+// coordinate (u, v), clamping to the image edges.
+func sampleAlpha(img *image.Alpha, u, v float32) uint8 {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	u = math.Clampf(u, 0, float32(w-1))
+	v = math.Clampf(v, 0, float32(h-1))
+	x0, y0 := int(u), int(v)
+	x1, y1 := math.Min(x0+1, w-1), math.Min(y0+1, h-1)
+	fx, fy := u-float32(x0), v-float32(y0)
+	top := math.Lerpf(float32(img.AlphaAt(x0, y0).A), float32(img.AlphaAt(x1, y0).A), fx)
+	bottom := math.Lerpf(float32(img.AlphaAt(x0, y1).A), float32(img.AlphaAt(x1, y1).A), fx)
+	return uint8(math.Lerpf(top, bottom, fy) + 0.5)
+}
+
+// coverage is a per-pixel mask of the covered sample points for a rectangular
+// region of the frame.
+type coverage struct {
+	bounds math.Rect
+	mask   []uint8 // One bit per sample
+	xs     []crossing
+}
+
+type crossing struct {
+	x       float32
+	winding int
+}
+
+type crossings []crossing
+
+func (l crossings) Len() int           { return len(l) }
+func (l crossings) Less(i, j int) bool { return l[i].x < l[j].x }
+func (l crossings) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+
+func newCoverage(bounds math.Rect) *coverage {
+	return &coverage{
+		bounds: bounds,
+		mask:   make([]uint8, bounds.W()*bounds.H()),
+	}
+}
+
+// samples returns the number of covered samples for the pixel at (x, y).
+func (c *coverage) samples(x, y int) int {
+	m := c.mask[(x-c.bounds.Min.X)+(y-c.bounds.Min.Y)*c.bounds.W()]
+	n := 0
+	for ; m != 0; m &= m - 1 {
+		n++
+	}
+	return n
+}
+
+// addPolygons marks all the samples inside the region bounded by the closed
+// polygons, using the fill rule.
+func (c *coverage) addPolygons(polys [][]math.Vec2, rule gxui.FillRule) {
+	minY, maxY := float32(stdmath.MaxFloat32), float32(-stdmath.MaxFloat32)
+	for _, poly := range polys {
+		for _, p := range poly {
+			minY, maxY = math.Minf(minY, p.Y), math.Maxf(maxY, p.Y)
+		}
+	}
+	if minY > maxY {
+		return
+	}
+	y0 := math.Max(floor(minY), c.bounds.Min.Y)
+	y1 := math.Min(ceil(maxY)+1, c.bounds.Max.Y)
+
+	for y := y0; y < y1; y++ {
+		for s, sp := range samplePositions {
+			sy := float32(y) + sp.Y
+			c.xs = c.xs[:0]
+			for _, poly := range polys {
+				if len(poly) < 3 {
+					continue
+				}
+				for i := range poly {
+					a, b := poly[i], poly[(i+1)%len(poly)]
+					switch {
+					case a.Y <= sy && sy < b.Y:
+						c.xs = append(c.xs, crossing{a.X + (sy-a.Y)*(b.X-a.X)/(b.Y-a.Y), +1})
+					case b.Y <= sy && sy < a.Y:
+						c.xs = append(c.xs, crossing{a.X + (sy-a.Y)*(b.X-a.X)/(b.Y-a.Y), -1})
+					}
+				}
+			}
+			if len(c.xs) < 2 {
+				continue
+			}
+			sort.Sort(crossings(c.xs))
+			winding := 0
+			for i := 0; i < len(c.xs)-1; i++ {
+				winding += c.xs[i].winding
+				if winding == 0 || (rule == gxui.EvenOdd && winding%2 == 0) {
+					continue
+				}
+				// Pixels whose sample x position lies in [xs[i], xs[i+1])
+				x0 := math.Max(ceil(c.xs[i].x-sp.X), c.bounds.Min.X)
+				x1 := math.Min(ceil(c.xs[i+1].x-sp.X), c.bounds.Max.X)
+				row := (y - c.bounds.Min.Y) * c.bounds.W()
+				for x := x0; x < x1; x++ {
+					c.mask[row+x-c.bounds.Min.X] |= 1 << uint(s)
+				}
+			}
+		}
+	}
+}
+
+func floor(v float32) int { return int(stdmath.Floor(float64(v))) }
+func ceil(v float32) int  { return int(stdmath.Ceil(float64(v))) }
@@ -0,0 +1,165 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package soft is a headless gxui.Driver that renders into in-memory images.
+//
+// Unlike the gl driver, soft requires no display, OpenGL context or locked OS
+// thread, which makes it suitable for tests and for running GXUI on build
+// machines. All drawing is performed on the CPU with results that closely
+// match the gl driver.
+package soft
+
+import (
+	"container/list"
+	"image"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+)
+
+// The screen resolution reported for fullscreen viewports created with a
+// width or height of 0.
+var screenSize = math.Size{W: 1920, H: 1080}
+
+type driver struct {
+	sync.Mutex
+	*gxui.DriverTimers
+	pending    chan func()
+	terminated int32 // non-zero represents driver terminations
+	viewports  *list.List
+	clipboard  string
+}
+
+// StartDriver creates a new soft driver, calls appRoutine on the UI go-routine
+// and then blocks processing the UI go-routine until Driver.Terminate is
+// called.
+func StartDriver(appRoutine func(driver gxui.Driver)) {
+	StartDriverWithClock(gxui.SystemClock, appRoutine)
+}
+
+// StartDriverWithClock is like StartDriver, but the driver's timers use clock.
+// Tests can pass a gxui.ManualClock to control the passing of time.
+func StartDriverWithClock(clock gxui.Clock, appRoutine func(driver gxui.Driver)) {
+	driver := &driver{
+		pending:   make(chan func(), 256),
+		viewports: list.New(),
+	}
+	driver.DriverTimers = gxui.CreateDriverTimers(clock, driver.Call)
+	driver.pending <- func() { appRoutine(driver) }
+	driver.applicationLoop()
+}
+
+// applicationLoop pulls and executes funcs from the pending chan until the
+// driver has been terminated and all remaining funcs have been processed.
+func (d *driver) applicationLoop() {
+	for ev := range d.pending {
+		ev()
+		if atomic.LoadInt32(&d.terminated) != 0 && len(d.pending) == 0 {
+			return
+		}
+	}
+}
+
+// Idle returns true if there are no functions queued on the UI go-routine, and
+// no timer functions waiting to be queued. Idle is called on the UI go-routine
+// by automation.Automation.WaitIdle.
+func (d *driver) Idle() bool {
+	return len(d.pending) == 0 && d.Queued() == 0
+}
+
+func (d *driver) createAppEvent(signature interface{}) gxui.Event {
+	return gxui.CreateChanneledEvent(signature, d.pending)
+}
+
+// gxui.Driver compliance
+func (d *driver) Call(f func()) bool {
+	if f == nil {
+		panic("Function must not be nil")
+	}
+	if atomic.LoadInt32(&d.terminated) != 0 {
+		return false // Driver.Terminate has been called
+	}
+	d.pending <- f
+	return true
+}
+
+func (d *driver) CallSync(f func()) bool {
+	c := make(chan struct{})
+	if d.Call(func() { f(); close(c) }) {
+		<-c
+		return true
+	} else {
+		return false
+	}
+}
+
+func (d *driver) Terminate() {
+	d.StopAll()
+	d.Call(func() {
+		// Close all viewports. This will notify the application.
+		d.Lock()
+		viewports := []*viewport{}
+		for v := d.viewports.Front(); v != nil; v = v.Next() {
+			viewports = append(viewports, v.Value.(*viewport))
+		}
+		d.Unlock()
+		for _, v := range viewports {
+			v.Destroy()
+		}
+
+		// Any events already queued (including those raised by closing the
+		// viewports) are processed before applicationLoop returns.
+		atomic.StoreInt32(&d.terminated, 1)
+	})
+}
+
+func (d *driver) SetClipboard(str string) {
+	d.Lock()
+	defer d.Unlock()
+	d.clipboard = str
+}
+
+func (d *driver) GetClipboard() (str string, err error) {
+	d.Lock()
+	defer d.Unlock()
+	return d.clipboard, nil
+}
+
+func (d *driver) CreateFont(data []byte, size int) (gxui.Font, error) {
+	return newFont(data, size)
+}
+
+func (d *driver) CreateWindowedViewport(width, height int, name string) gxui.Viewport {
+	return d.createViewport(width, height, name, false)
+}
+
+func (d *driver) CreateFullscreenViewport(width, height int, name string) gxui.Viewport {
+	if width == 0 || height == 0 {
+		width, height = screenSize.WH()
+	}
+	return d.createViewport(width, height, name, true)
+}
+
+func (d *driver) createViewport(width, height int, name string, fullscreen bool) *viewport {
+	v := newViewport(d, width, height, name, fullscreen)
+	d.Lock()
+	e := d.viewports.PushBack(v)
+	d.Unlock()
+	v.onDestroy = func() {
+		d.Lock()
+		d.viewports.Remove(e)
+		d.Unlock()
+	}
+	return v
+}
+
+func (d *driver) CreateCanvas(s math.Size) gxui.Canvas {
+	return newCanvas(s)
+}
+
+func (d *driver) CreateTexture(img image.Image, pixelsPerDip float32) gxui.Texture {
+	return newTexture(img, pixelsPerDip)
+}
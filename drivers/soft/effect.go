@@ -0,0 +1,153 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"image"
+	stdmath "math"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+)
+
+// roundedRect is a rectangle with rounded corners, in local DIPs.
+type roundedRect struct {
+	center math.Vec2
+	half   math.Vec2
+	radii  [4]float32 // tl, tr, bl, br
+}
+
+// newRoundedRect returns the roundedRect for r with the corner radii, which
+// are limited so that no corner is larger than half the rectangle.
+func newRoundedRect(r math.Rect, radii [4]float32) roundedRect {
+	half := math.Vec2{X: float32(r.W()) / 2, Y: float32(r.H()) / 2}
+	limit := math.Minf(half.X, half.Y)
+	for i, radius := range radii {
+		radii[i] = math.Clampf(radius, 0, limit)
+	}
+	return roundedRect{
+		center: r.Min.Vec2().Add(half),
+		half:   half,
+		radii:  radii,
+	}
+}
+
+// distance returns the signed distance in DIPs from p to the edge of the
+// rectangle, which is negative inside the rectangle.
+func (r roundedRect) distance(p math.Vec2) float32 {
+	q := p.Sub(r.center)
+	var radius float32
+	switch {
+	case q.X < 0 && q.Y < 0:
+		radius = r.radii[0]
+	case q.Y < 0:
+		radius = r.radii[1]
+	case q.X < 0:
+		radius = r.radii[2]
+	default:
+		radius = r.radii[3]
+	}
+	dx := math.Absf(q.X) - r.half.X + radius
+	dy := math.Absf(q.Y) - r.half.Y + radius
+	outside := math.Vec2{X: math.Maxf(dx, 0), Y: math.Maxf(dy, 0)}.Len()
+	return outside + math.Minf(math.Maxf(dx, dy), 0) - radius
+}
+
+// drawShadow blends the shadow of the rounded rectangle r, in local DIPs, over
+// the pixels inside the draw state's clip.
+func (c *context) drawShadow(r math.Rect, radii [4]float32, s gxui.Shadow, ds *drawState) {
+	transform := ds.pixelTransform(c.resolution)
+	bounds := intersect(transform.TransformRect(s.Bounds(r)).ExpandI(1), ds.ClipPixels)
+	if bounds.W() == 0 || bounds.H() == 0 {
+		return
+	}
+	inv := transform.Invert()
+	scale := ds.pixelScale(c.resolution)
+	shape := newRoundedRect(r, radii)
+	shadow := newRoundedRect(r.Offset(s.Offset), radii)
+
+	// The edge of the shadow is a step blurred by a Gaussian with a standard
+	// deviation of half the blur radius. Blurs narrower than a pixel are
+	// anti-aliased like other shapes instead.
+	sigma := float64(s.Blur / 2 * scale)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			p := inv.Transform(math.Vec2{X: float32(x) + 0.5, Y: float32(y) + 0.5})
+			inside := math.Saturate(0.5 - shape.distance(p)*scale)
+			d := shadow.distance(p) * scale
+			cov := math.Saturate(0.5 - d)
+			if sigma > 0.5 {
+				cov = float32(0.5 * stdmath.Erfc(float64(d)/(sigma*stdmath.Sqrt2)))
+			}
+			if s.Inner {
+				cov = inside * (1 - cov)
+			} else {
+				cov = cov * (1 - inside)
+			}
+			if cov > 0 {
+				c.blend(x, y, premultiplied(s.Color, uint32(cov*255+0.5)))
+			}
+		}
+	}
+}
+
+// gaussianBlur blurs the pre-multiplied pixels of img with a Gaussian of
+// standard deviation sigma, in pixels. Pixels outside img are treated as
+// transparent.
+func gaussianBlur(img *image.RGBA, sigma float32) {
+	n := ceil(sigma * 3)
+	if n <= 0 {
+		return
+	}
+	kernel := make([]float32, 2*n+1)
+	var total float32
+	for i := range kernel {
+		x := float64(i - n)
+		kernel[i] = float32(stdmath.Exp(-x * x / (2 * float64(sigma*sigma))))
+		total += kernel[i]
+	}
+	for i := range kernel {
+		kernel[i] /= total
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	tmp := make([]float32, w*h*4)
+	// Horizontal pass from img to tmp.
+	for y := 0; y < h; y++ {
+		row := img.Pix[y*img.Stride:]
+		for x := 0; x < w; x++ {
+			var sum [4]float32
+			for k, weight := range kernel {
+				sx := x + k - n
+				if sx < 0 || sx >= w {
+					continue
+				}
+				for i := range sum {
+					sum[i] += float32(row[sx*4+i]) * weight
+				}
+			}
+			copy(tmp[(y*w+x)*4:], sum[:])
+		}
+	}
+	// Vertical pass from tmp back to img.
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var sum [4]float32
+			for k, weight := range kernel {
+				sy := y + k - n
+				if sy < 0 || sy >= h {
+					continue
+				}
+				for i := range sum {
+					sum[i] += tmp[(sy*w+x)*4+i] * weight
+				}
+			}
+			p := img.Pix[y*img.Stride+x*4:]
+			for i := range sum {
+				p[i] = uint8(math.Clampf(sum[i]+0.5, 0, 255))
+			}
+		}
+	}
+}
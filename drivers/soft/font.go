@@ -0,0 +1,253 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+
+	"code.google.com/p/freetype-go/freetype/raster"
+	"code.google.com/p/freetype-go/freetype/truetype"
+)
+
+type glyphKey struct {
+	resolution resolution
+	rune       rune
+}
+
+type font struct {
+	size             int
+	scale            int32
+	glyphMaxSizeDips math.Size
+	ascentDips       int
+	ttf              *truetype.Font
+	glyphs           map[rune]*glyph
+	masks            map[glyphKey]*image.Alpha
+}
+
+func newFont(data []byte, size int) (*font, error) {
+	ttf, err := truetype.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	scale := int32(size << 6)
+	bounds := ttf.Bounds(scale)
+	glyphMaxSizeDips := math.Size{
+		W: int(bounds.XMax-bounds.XMin) >> 6,
+		H: int(bounds.YMax-bounds.YMin) >> 6,
+	}
+	ascentDips := int(bounds.YMax >> 6)
+
+	return &font{
+		size:             size,
+		scale:            scale,
+		glyphMaxSizeDips: glyphMaxSizeDips,
+		ascentDips:       ascentDips,
+		ttf:              ttf,
+		glyphs:           make(map[rune]*glyph),
+		masks:            make(map[glyphKey]*image.Alpha),
+	}, nil
+}
+
+func (f *font) glyph(r rune) *glyph {
+	if g, found := f.glyphs[r]; found {
+		return g
+	}
+	idx := f.ttf.Index(r)
+	gb := truetype.NewGlyphBuf()
+	err := gb.Load(f.ttf, f.scale, idx, truetype.Hinting(truetype.FullHinting))
+	if err != nil {
+		panic(err)
+	}
+
+	g := glyph(*gb)
+	f.glyphs[r] = &g
+	return &g
+}
+
+// mask returns the rasterized coverage of the rune r at the given resolution.
+// The returned image is glyph.size(resolution) in size.
+func (f *font) mask(resolution resolution, r rune, g *glyph) *image.Alpha {
+	key := glyphKey{resolution, r}
+	if m, found := f.masks[key]; found {
+		return m
+	}
+
+	w, h := g.size(resolution).WH()
+	m := image.NewAlpha(image.Rect(0, 0, w, h))
+	if w > 0 && h > 0 {
+		rast := raster.NewRasterizer(w, h)
+		fx := -raster.Fix32((int64(g.B.XMin) * int64(resolution)) >> 14)
+		fy := +raster.Fix32((int64(g.B.YMax) * int64(resolution)) >> 14)
+		e0 := 0
+		for _, e1 := range g.End {
+			drawContour(rast, resolution, g.Point[e0:e1], fx, fy)
+			e0 = e1
+		}
+		rast.Rasterize(raster.NewAlphaSrcPainter(m))
+	}
+	f.masks[key] = m
+	return m
+}
+
+// drawContour draws the given closed contour with the given offset.
+func drawContour(rast *raster.Rasterizer, resolution resolution, ps []truetype.Point, dx, dy raster.Fix32) {
+	if len(ps) == 0 {
+		return
+	}
+	// ps[0] is a truetype.Point measured in FUnits and positive Y going upwards.
+	// start is the same thing measured in fixed point units and positive Y
+	// going downwards, and offset by (dx, dy)
+	start := raster.Point{
+		X: dx + raster.Fix32(int64(ps[0].X)*int64(resolution)>>14),
+		Y: dy - raster.Fix32(int64(ps[0].Y)*int64(resolution)>>14),
+	}
+	rast.Start(start)
+	q0, on0 := start, true
+	for _, p := range ps[1:] {
+		q := raster.Point{
+			X: dx + raster.Fix32(int64(p.X)*int64(resolution)>>14),
+			Y: dy - raster.Fix32(int64(p.Y)*int64(resolution)>>14),
+		}
+		on := p.Flags&0x01 != 0
+		if on {
+			if on0 {
+				rast.Add1(q)
+			} else {
+				rast.Add2(q0, q)
+			}
+		} else {
+			if on0 {
+				// No-op.
+			} else {
+				mid := raster.Point{
+					X: (q0.X + q.X) / 2,
+					Y: (q0.Y + q.Y) / 2,
+				}
+				rast.Add2(q0, mid)
+			}
+		}
+		q0, on0 = q, on
+	}
+	// Close the curve.
+	if on0 {
+		rast.Add1(start)
+	} else {
+		rast.Add2(q0, start)
+	}
+}
+
+func (f *font) align(rect math.Rect, size math.Size, ascent int, h gxui.HorizontalAlignment, v gxui.VerticalAlignment) math.Point {
+	var origin math.Point
+	switch h {
+	case gxui.AlignLeft:
+		origin.X = rect.Min.X
+	case gxui.AlignCenter:
+		origin.X = rect.Mid().X - (size.W / 2)
+	case gxui.AlignRight:
+		origin.X = rect.Max.X - size.W
+	}
+	switch v {
+	case gxui.AlignTop:
+		origin.Y = rect.Min.Y + ascent
+	case gxui.AlignMiddle:
+		origin.Y = rect.Mid().Y - (size.H / 2) + ascent
+	case gxui.AlignBottom:
+		origin.Y = rect.Max.Y - size.H + ascent
+	}
+	return origin
+}
+
+func (f *font) DrawRunes(ctx *context, runes []rune, offsets []math.Point, col gxui.Color, ds *drawState) {
+	if len(runes) != len(offsets) {
+		panic(fmt.Errorf("There must be the same number of runes to offsets. Got %d runes and %d offsets",
+			len(runes), len(offsets)))
+	}
+	resolution := ctx.resolution
+
+	for i, r := range runes {
+		if r == '\t' {
+			continue
+		}
+		glyph := f.glyph(r)
+		mask := f.mask(resolution, r, glyph)
+		if ds.Transformed {
+			// Mask pixels to local DIPs
+			rect := glyph.rect(resolution)
+			m := math.CreateMat3Translate(rect.Min.Vec2()).
+				Mul(math.CreateMat3Scale(math.Vec2{X: 1, Y: 1}.DivS(resolution.dipsToPixels()))).
+				Mul(math.CreateMat3Translate(offsets[i].Vec2())).
+				Mul(ds.Transform)
+			ctx.drawTransformed(rect.Size(), m, ds.ClipPixels, func(u, v float32) color.RGBA {
+				return premultiplied(col, uint32(sampleAlpha(mask, u, v)))
+			})
+			continue
+		}
+		dstRect := glyph.rect(resolution).
+			Offset(resolution.pointDipsToPixels(offsets[i])).
+			Offset(ds.OriginPixels)
+		ctx.fillMask(mask, dstRect, ds.ClipPixels, col)
+	}
+}
+
+func (f *font) Size() int {
+	return f.size
+}
+
+func (f *font) Measure(fl *gxui.TextBlock) math.Size {
+	size := math.Size{W: 0, H: f.glyphMaxSizeDips.H}
+	var offset math.Point
+	for _, r := range fl.Runes {
+		if r == '\n' {
+			offset.X = 0
+			offset.Y += f.glyphMaxSizeDips.H
+			continue
+		}
+		offset.X += f.glyph(r).advanceDips()
+		size = size.Max(math.Size{W: offset.X, H: offset.Y + f.glyphMaxSizeDips.H})
+	}
+	return size
+}
+
+func (f *font) Layout(fl *gxui.TextBlock) (offsets []math.Point) {
+	sizeDips := math.Size{}
+	offsets = make([]math.Point, len(fl.Runes))
+	var offset math.Point
+	for i, r := range fl.Runes {
+		if r == '\n' {
+			offset.X = 0
+			offset.Y += f.glyphMaxSizeDips.H
+			continue
+		}
+
+		offsets[i] = offset
+		offset.X += f.glyph(r).advanceDips()
+		sizeDips = sizeDips.Max(math.Size{W: offset.X, H: offset.Y + f.glyphMaxSizeDips.H})
+	}
+
+	origin := f.align(fl.AlignRect, sizeDips, f.ascentDips, fl.H, fl.V)
+	for i, p := range offsets {
+		offsets[i] = p.Add(origin)
+	}
+	return offsets
+}
+
+func (f *font) LoadGlyphs(first, last rune) {
+	if first > last {
+		first, last = last, first
+	}
+	for r := first; r < last; r++ {
+		f.glyph(r)
+	}
+}
+
+func (f *font) GlyphMaxSize() math.Size {
+	return f.glyphMaxSizeDips
+}
@@ -0,0 +1,207 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"github.com/google/gxui"
+	"github.com/google/gxui/drivers/internal/stroke"
+	"github.com/google/gxui/math"
+)
+
+// The maximum distance in DIPs between a curve and the lines used to draw it.
+const pathTolerance = 0.1
+
+// shape is a region described in DIPs by a number of polygons.
+type shape struct {
+	polygons [][]math.Vec2
+	// If combined is true then the polygons are the contours of a single
+	// region, filled using rule. Otherwise the shape is the union of the
+	// polygons.
+	combined bool
+	rule     gxui.FillRule
+}
+
+// pathFillShape returns the shape filling the inside of path.
+func pathFillShape(path *gxui.VectorPath) *shape {
+	s := &shape{combined: true, rule: path.FillRule}
+	for _, c := range path.Flatten(pathTolerance) {
+		s.polygons = append(s.polygons, c.Points)
+	}
+	return s
+}
+
+// pathStrokeShape returns the shape of the path's outline drawn with pen.
+func pathStrokeShape(path *gxui.VectorPath, pen gxui.Pen) *shape {
+	s := &shape{}
+	for _, c := range path.Flatten(pathTolerance) {
+		s.polygons = append(s.polygons, stroke.Centered(c.Points, c.Closed, pen)...)
+	}
+	return s
+}
+
+// rectToShape returns the shape of the rectangle r.
+func rectToShape(r math.Rect) *shape {
+	return &shape{polygons: [][]math.Vec2{{
+		r.TL().Vec2(), r.TR().Vec2(), r.BR().Vec2(), r.BL().Vec2(),
+	}}}
+}
+
+// triangleStripToShape returns the shape formed by the triangle strip verts.
+func triangleStripToShape(verts []math.Vec2) *shape {
+	if len(verts) < 3 {
+		return nil
+	}
+	s := &shape{polygons: make([][]math.Vec2, 0, len(verts)-2)}
+	for i := 2; i < len(verts); i++ {
+		s.polygons = append(s.polygons, verts[i-2:i+1])
+	}
+	return s
+}
+
+func pruneDuplicates(p gxui.Polygon) gxui.Polygon {
+	pruned := make(gxui.Polygon, 0, len(p))
+	last := gxui.PolygonVertex{}
+	for i, v := range p {
+		if i == 0 || last.Position.Sub(v.Position).Vec2().Len() > 0.001 {
+			pruned = append(pruned, v)
+		}
+		last = v
+	}
+	return pruned
+}
+
+// segment builds the edge and fill vertices for the polygon vertex a, with
+// neighbours b and c. The tessellation is identical to that of the gl driver so
+// that both drivers produce the same outlines. See drivers/gl/polygon.go for
+// a detailed description of the geometry.
+func segment(penWidth, r float32, a, b, c math.Vec2, aIsLast bool, edge []math.Vec2, fillEdge []math.Vec2) ([]math.Vec2, []math.Vec2) {
+	ba, ca := a.Sub(b), a.Sub(c)
+	baLen, caLen := ba.Len(), ca.Len()
+	baDir, caDir := ba.DivS(baLen), ca.DivS(caLen)
+	dp := baDir.Dot(caDir)
+	if dp < -0.99999 {
+		// Straight lines cause DBZs, special case
+		inner := a.Sub(caDir.Tangent().MulS(penWidth))
+		edge = append(edge, a, inner)
+		if fillEdge != nil {
+			fillEdge = append(fillEdge, inner)
+		}
+		return edge, fillEdge
+	}
+	α := math.Acosf(dp) / 2
+	v := baDir.Add(caDir).Normalize()
+	u := v.Tangent()
+	d := r / math.Sinf(α)
+
+	// X cannot be futher than half way along ab or ac
+	dMax := math.Minf(baLen, caLen) / (2 * math.Cosf(α))
+	if d > dMax {
+		// Adjust d and r to compensate
+		d = dMax
+		r = d * math.Sinf(α)
+	}
+
+	x := a.Sub(v.MulS(d))
+
+	convex := baDir.Tangent().Dot(caDir) <= 0
+
+	w := penWidth
+	β := math.Pi/2 - α
+
+	// Stop the inner vertices of convex corners overlapping when the pen width
+	// is greater than the rounding.
+	useFixedInnerPoint := convex && w > r
+	fixedInnerPoint := a.Sub(v.MulS(math.Minf(w/math.Sinf(α), dMax)))
+
+	// Concave vertices sweep in the opposite direction and extrude outwards.
+	if !convex {
+		w, β = -w, -β
+	}
+
+	steps := 1 + int(d*α)
+
+	if aIsLast {
+		// No curvy edge required for the last vertex.
+		// This is already done by the first vertex.
+		steps = 1
+	}
+
+	for j := 0; j < steps; j++ {
+		γ := float32(0)
+		if steps > 1 {
+			γ = math.Lerpf(-β, β, float32(j)/float32(steps-1))
+		}
+
+		dir := v.MulS(math.Cosf(γ)).Add(u.MulS(math.Sinf(γ)))
+		va := x.Add(dir.MulS(r))
+		vb := va.Sub(dir.MulS(w))
+		if useFixedInnerPoint {
+			vb = fixedInnerPoint
+		}
+
+		edge = append(edge, va, vb)
+		if fillEdge != nil {
+			fillEdge = append(fillEdge, vb)
+		}
+	}
+
+	return edge, fillEdge
+}
+
+func closedPolyToShape(p gxui.Polygon, penWidth float32) (fillShape, edgeShape *shape) {
+	p = pruneDuplicates(p)
+
+	fillEdge := []math.Vec2{}
+	edge := []math.Vec2{}
+
+	for i, cnt := 0, len(p); i < cnt; i++ {
+		r := p[i].RoundedRadius
+		a := p[i].Position.Vec2()
+		b := p[(i+cnt-1)%cnt].Position.Vec2()
+		c := p[(i+1)%cnt].Position.Vec2()
+		edge, fillEdge = segment(penWidth, r, a, b, c, i == len(p), edge, fillEdge)
+	}
+
+	// Close the edge
+	if len(edge) >= 2 {
+		edge = append(edge, edge[:2]...)
+	}
+
+	if len(fillEdge) >= 3 {
+		fillShape = &shape{polygons: [][]math.Vec2{fillEdge}}
+	}
+	edgeShape = triangleStripToShape(edge)
+	return fillShape, edgeShape
+}
+
+func openPolyToShape(p gxui.Polygon, penWidth float32) *shape {
+	p = pruneDuplicates(p)
+	if len(p) < 2 {
+		return nil
+	}
+
+	edge := []math.Vec2{}
+
+	{ // p[0] -> p[1]
+		a, c := p[0].Position.Vec2(), p[1].Position.Vec2()
+		caDir := a.Sub(c).Normalize()
+		inner := a.Sub(caDir.Tangent().MulS(penWidth))
+		edge = append(edge, a, inner)
+	}
+	for i := 1; i < len(p)-1; i++ {
+		r := p[i].RoundedRadius
+		a := p[i].Position.Vec2()
+		b := p[i-1].Position.Vec2()
+		c := p[i+1].Position.Vec2()
+		edge, _ = segment(penWidth, r, a, b, c, false, edge, nil)
+	}
+	{ // p[N-2] -> p[N-1]
+		a, c := p[len(p)-2].Position.Vec2(), p[len(p)-1].Position.Vec2()
+		caDir := a.Sub(c).Normalize()
+		inner := c.Sub(caDir.Tangent().MulS(penWidth))
+		edge = append(edge, c, inner)
+	}
+	return triangleStripToShape(edge)
+}
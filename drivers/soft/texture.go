@@ -0,0 +1,66 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/google/gxui/math"
+)
+
+type texture struct {
+	refCounted
+	image        image.Image
+	pixelsPerDip float32
+	flipY        bool
+	rgba         *image.RGBA // Lazily built pre-multiplied copy of image
+}
+
+func newTexture(img image.Image, pixelsPerDip float32) *texture {
+	t := &texture{
+		image:        img,
+		pixelsPerDip: pixelsPerDip,
+	}
+	t.init()
+	return t
+}
+
+// pixels returns the texture's image as a pre-multiplied RGBA image with
+// bounds starting at (0, 0).
+func (t *texture) pixels() *image.RGBA {
+	if t.rgba == nil {
+		b := t.image.Bounds()
+		t.rgba = image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(t.rgba, t.rgba.Bounds(), t.image, b.Min, draw.Src)
+	}
+	return t.rgba
+}
+
+// gxui.Texture compliance
+func (t *texture) Image() image.Image {
+	return t.image
+}
+
+func (t *texture) Size() math.Size {
+	return t.SizePixels().ScaleS(1.0 / t.pixelsPerDip)
+}
+
+func (t *texture) SizePixels() math.Size {
+	s := t.image.Bounds().Size()
+	return math.Size{W: s.X, H: s.Y}
+}
+
+func (t *texture) FlipY() bool {
+	return t.flipY
+}
+
+func (t *texture) SetFlipY(flipY bool) {
+	t.flipY = flipY
+}
+
+func (t *texture) Release() {
+	t.release()
+}
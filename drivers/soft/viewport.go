@@ -0,0 +1,327 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soft
+
+import (
+	"image"
+	"image/draw"
+	"sync"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+)
+
+var clearColor = gxui.Color{R: 0.5, G: 0.5, B: 0.5, A: 1.0}
+
+// Viewport is the interface implemented by all viewports created by the soft
+// driver.
+type Viewport interface {
+	gxui.Viewport
+
+	// The gxui.InputInjector methods raise the viewport's input events as if
+	// they came from a real windowing system. The events are queued on the UI
+	// go-routine, so the methods may be called from any go-routine.
+	gxui.InputInjector
+
+	// Image returns a copy of the most recently rendered frame. The returned
+	// image is SizePixels() in size.
+	Image() *image.RGBA
+}
+
+type viewport struct {
+	sync.Mutex
+
+	driver           *driver
+	canvas           *canvas
+	frame            *image.RGBA
+	fullscreen       bool
+	visible          bool
+	scaling          float32
+	sizeDipsUnscaled math.Size
+	sizeDips         math.Size
+	sizePixels       math.Size
+	title            string
+	destroyed        bool
+
+	// Broadcasts to application thread
+	onClose       gxui.Event // ()
+	onResize      gxui.Event // ()
+	onMouseMove   gxui.Event // (gxui.MouseEvent)
+	onMouseEnter  gxui.Event // (gxui.MouseEvent)
+	onMouseExit   gxui.Event // (gxui.MouseEvent)
+	onMouseDown   gxui.Event // (gxui.MouseEvent)
+	onMouseUp     gxui.Event // (gxui.MouseEvent)
+	onMouseScroll gxui.Event // (gxui.MouseEvent)
+	onKeyDown     gxui.Event // (gxui.KeyboardEvent)
+	onKeyUp       gxui.Event // (gxui.KeyboardEvent)
+	onKeyRepeat   gxui.Event // (gxui.KeyboardEvent)
+	onKeyStroke   gxui.Event // (gxui.KeyStrokeEvent)
+	// Called by Destroy
+	onDestroy func()
+}
+
+func newViewport(driver *driver, width, height int, title string, fullscreen bool) *viewport {
+	v := &viewport{
+		driver:     driver,
+		fullscreen: fullscreen,
+		visible:    true,
+		scaling:    1,
+		title:      title,
+	}
+	v.onClose = driver.createAppEvent(func() {})
+	v.onResize = driver.createAppEvent(func() {})
+	v.onMouseMove = driver.createAppEvent(func(gxui.MouseEvent) {})
+	v.onMouseEnter = driver.createAppEvent(func(gxui.MouseEvent) {})
+	v.onMouseExit = driver.createAppEvent(func(gxui.MouseEvent) {})
+	v.onMouseDown = driver.createAppEvent(func(gxui.MouseEvent) {})
+	v.onMouseUp = driver.createAppEvent(func(gxui.MouseEvent) {})
+	v.onMouseScroll = driver.createAppEvent(func(gxui.MouseEvent) {})
+	v.onKeyDown = driver.createAppEvent(func(gxui.KeyboardEvent) {})
+	v.onKeyUp = driver.createAppEvent(func(gxui.KeyboardEvent) {})
+	v.onKeyRepeat = driver.createAppEvent(func(gxui.KeyboardEvent) {})
+	v.onKeyStroke = driver.createAppEvent(func(gxui.KeyStrokeEvent) {})
+	v.sizeDipsUnscaled = math.Size{W: width, H: height}
+	v.sizeDips = v.sizeDipsUnscaled
+	v.sizePixels = v.sizeDipsUnscaled
+	v.frame = newFrame(v.sizePixels)
+	return v
+}
+
+func newFrame(sizePixels math.Size) *image.RGBA {
+	frame := image.NewRGBA(image.Rect(0, 0, sizePixels.W, sizePixels.H))
+	draw.Draw(frame, frame.Bounds(), image.NewUniform(toRGBA(clearColor)), image.ZP, draw.Src)
+	return frame
+}
+
+// render draws the current canvas into a new frame. render must be called with
+// the viewport locked.
+func (v *viewport) render() {
+	if v.destroyed || v.sizePixels.Area() == 0 {
+		return
+	}
+
+	ctx := newContext(newFrame(v.sizePixels), v.sizeDips)
+	dss := drawStateStack{drawState{
+		ClipPixels: v.sizePixels.Rect(),
+	}}
+
+	v.canvas.draw(ctx, &dss)
+	if len(dss) != 1 {
+		panic("DrawStateStack count was not 1 after calling Canvas.Draw")
+	}
+
+	v.frame = ctx.target
+}
+
+// soft.Viewport compliance
+func (v *viewport) Image() *image.RGBA {
+	v.Lock()
+	defer v.Unlock()
+	frame := image.NewRGBA(v.frame.Bounds())
+	copy(frame.Pix, v.frame.Pix)
+	return frame
+}
+
+// gxui.InputInjector compliance
+func (v *viewport) InjectMouseMove(ev gxui.MouseEvent) {
+	v.onMouseMove.Fire(ev)
+}
+
+func (v *viewport) InjectMouseEnter(ev gxui.MouseEvent) {
+	v.onMouseEnter.Fire(ev)
+}
+
+func (v *viewport) InjectMouseExit(ev gxui.MouseEvent) {
+	v.onMouseExit.Fire(ev)
+}
+
+func (v *viewport) InjectMouseDown(ev gxui.MouseEvent) {
+	v.onMouseDown.Fire(ev)
+}
+
+func (v *viewport) InjectMouseUp(ev gxui.MouseEvent) {
+	v.onMouseUp.Fire(ev)
+}
+
+func (v *viewport) InjectMouseScroll(ev gxui.MouseEvent) {
+	v.onMouseScroll.Fire(ev)
+}
+
+func (v *viewport) InjectKeyDown(ev gxui.KeyboardEvent) {
+	v.onKeyDown.Fire(ev)
+}
+
+func (v *viewport) InjectKeyUp(ev gxui.KeyboardEvent) {
+	v.onKeyUp.Fire(ev)
+}
+
+func (v *viewport) InjectKeyRepeat(ev gxui.KeyboardEvent) {
+	v.onKeyRepeat.Fire(ev)
+}
+
+func (v *viewport) InjectKeyStroke(ev gxui.KeyStrokeEvent) {
+	v.onKeyStroke.Fire(ev)
+}
+
+// gxui.Viewport compliance
+func (v *viewport) SetCanvas(cc gxui.Canvas) {
+	c, _ := cc.(*canvas)
+	if c != nil {
+		c.addRef()
+	}
+	v.Lock()
+	defer v.Unlock()
+	if v.canvas != nil {
+		v.canvas.release()
+	}
+	v.canvas = c
+	if v.canvas != nil {
+		v.render()
+	}
+}
+
+func (v *viewport) Scale() float32 {
+	v.Lock()
+	defer v.Unlock()
+	return v.scaling
+}
+
+func (v *viewport) SetScale(s float32) {
+	v.Lock()
+	changed := s != v.scaling
+	if changed {
+		v.scaling = s
+		v.sizeDips = v.sizeDipsUnscaled.ScaleS(1 / s)
+	}
+	v.Unlock()
+	if changed {
+		v.onResize.Fire()
+	}
+}
+
+func (v *viewport) SizeDips() math.Size {
+	v.Lock()
+	defer v.Unlock()
+	return v.sizeDips
+}
+
+func (v *viewport) SetSizeDips(size math.Size) {
+	v.Lock()
+	changed := size != v.sizeDips
+	if changed {
+		v.sizeDips = size
+		v.sizeDipsUnscaled = size.ScaleS(v.scaling)
+		v.sizePixels = v.sizeDipsUnscaled
+		v.frame = newFrame(v.sizePixels)
+	}
+	v.Unlock()
+	if changed {
+		v.onResize.Fire()
+	}
+}
+
+func (v *viewport) SizePixels() math.Size {
+	v.Lock()
+	defer v.Unlock()
+	return v.sizePixels
+}
+
+func (v *viewport) Title() string {
+	v.Lock()
+	defer v.Unlock()
+	return v.title
+}
+
+func (v *viewport) SetTitle(title string) {
+	v.Lock()
+	defer v.Unlock()
+	v.title = title
+}
+
+func (v *viewport) Fullscreen() bool {
+	return v.fullscreen
+}
+
+func (v *viewport) Show() {
+	v.Lock()
+	defer v.Unlock()
+	v.visible = true
+}
+
+func (v *viewport) Hide() {
+	v.Lock()
+	defer v.Unlock()
+	v.visible = false
+}
+
+func (v *viewport) Close() {
+	v.onClose.Fire()
+	v.Destroy()
+}
+
+func (v *viewport) OnResize(f func()) gxui.EventSubscription {
+	return v.onResize.Listen(f)
+}
+
+func (v *viewport) OnClose(f func()) gxui.EventSubscription {
+	return v.onClose.Listen(f)
+}
+
+func (v *viewport) OnMouseMove(f func(gxui.MouseEvent)) gxui.EventSubscription {
+	return v.onMouseMove.Listen(f)
+}
+
+func (v *viewport) OnMouseEnter(f func(gxui.MouseEvent)) gxui.EventSubscription {
+	return v.onMouseEnter.Listen(f)
+}
+
+func (v *viewport) OnMouseExit(f func(gxui.MouseEvent)) gxui.EventSubscription {
+	return v.onMouseExit.Listen(f)
+}
+
+func (v *viewport) OnMouseDown(f func(gxui.MouseEvent)) gxui.EventSubscription {
+	return v.onMouseDown.Listen(f)
+}
+
+func (v *viewport) OnMouseUp(f func(gxui.MouseEvent)) gxui.EventSubscription {
+	return v.onMouseUp.Listen(f)
+}
+
+func (v *viewport) OnMouseScroll(f func(gxui.MouseEvent)) gxui.EventSubscription {
+	return v.onMouseScroll.Listen(f)
+}
+
+func (v *viewport) OnKeyDown(f func(gxui.KeyboardEvent)) gxui.EventSubscription {
+	return v.onKeyDown.Listen(f)
+}
+
+func (v *viewport) OnKeyUp(f func(gxui.KeyboardEvent)) gxui.EventSubscription {
+	return v.onKeyUp.Listen(f)
+}
+
+func (v *viewport) OnKeyRepeat(f func(gxui.KeyboardEvent)) gxui.EventSubscription {
+	return v.onKeyRepeat.Listen(f)
+}
+
+func (v *viewport) OnKeyStroke(f func(gxui.KeyStrokeEvent)) gxui.EventSubscription {
+	return v.onKeyStroke.Listen(f)
+}
+
+func (v *viewport) Destroy() {
+	v.Lock()
+	if v.destroyed {
+		v.Unlock()
+		return
+	}
+	if v.canvas != nil {
+		v.canvas.release()
+		v.canvas = nil
+	}
+	v.destroyed = true
+	v.Unlock()
+	if v.onDestroy != nil {
+		v.onDestroy()
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package svg records the drawing of gxui canvases so that windows and
+// controls can be written out as resolution-independent SVG documents.
+package svg
+
+import (
+	"fmt"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+)
+
+type canvasOp func(e *encoder)
+
+// Canvas is a gxui.Canvas that records the calls made to it so that they can
+// be written as SVG with Encode. If the canvas was created with a target
+// canvas then every call is also forwarded to the target, so a Canvas can be
+// displayed as normal while it records.
+type Canvas struct {
+	target            gxui.Canvas
+	size              math.Size
+	ops               []canvasOp
+	built             bool
+	buildingPushCount int
+}
+
+// CreateCanvas returns a new recording canvas of the given size in DIPs.
+// target may be nil, in which case the canvas only records.
+func CreateCanvas(size math.Size, target gxui.Canvas) *Canvas {
+	if size.W <= 0 || size.H < 0 {
+		panic(fmt.Errorf("Canvas width and height must be positive. Size: %d", size))
+	}
+	return &Canvas{target: target, size: size}
+}
+
+// Target returns the canvas that calls are forwarded to, which may be nil.
+func (c *Canvas) Target() gxui.Canvas {
+	return c.target
+}
+
+func (c *Canvas) appendOp(name string, op canvasOp) {
+	if c.built {
+		panic(fmt.Errorf("%s() called after Complete()", name))
+	}
+	c.ops = append(c.ops, op)
+}
+
+// write writes the canvas's recorded operations to e.
+func (c *Canvas) write(e *encoder) {
+	e.push()
+	for _, op := range c.ops {
+		op(e)
+	}
+	e.pop()
+}
+
+// gxui.Canvas compliance
+func (c *Canvas) Size() math.Size {
+	return c.size
+}
+
+func (c *Canvas) IsComplete() bool {
+	return c.built
+}
+
+func (c *Canvas) Complete() {
+	if c.built {
+		panic("Complete() called twice")
+	}
+	if c.buildingPushCount != 0 {
+		panic(fmt.Errorf("Push() count was %d when calling Complete", c.buildingPushCount))
+	}
+	c.built = true
+	if c.target != nil {
+		c.target.Complete()
+	}
+}
+
+func (c *Canvas) Push() {
+	c.buildingPushCount++
+	c.appendOp("Push", func(e *encoder) { e.push() })
+	if c.target != nil {
+		c.target.Push()
+	}
+}
+
+func (c *Canvas) Pop() {
+	c.buildingPushCount--
+	c.appendOp("Pop", func(e *encoder) { e.pop() })
+	if c.target != nil {
+		c.target.Pop()
+	}
+}
+
+func (c *Canvas) AddClip(r math.Rect) {
+	c.appendOp("AddClip", func(e *encoder) { e.clip(r) })
+	if c.target != nil {
+		c.target.AddClip(r)
+	}
+}
+
+func (c *Canvas) Transform(m math.Mat3) {
+	c.appendOp("Transform", func(e *encoder) { e.transform(m) })
+	if c.target != nil {
+		c.target.Transform(m)
+	}
+}
+
+func (c *Canvas) Clear(color gxui.Color) {
+	size := c.size
+	c.appendOp("Clear", func(e *encoder) {
+		e.rect(size.Rect(), gxui.CreateBrush(color))
+	})
+	if c.target != nil {
+		c.target.Clear(color)
+	}
+}
+
+// DrawCanvas draws cc at offset. If cc is not a *Canvas then it is forwarded
+// to the target canvas, but is not recorded.
+func (c *Canvas) DrawCanvas(cc gxui.Canvas, offset math.Point) {
+	if cc == nil {
+		panic("Canvas cannot be nil")
+	}
+	child, ok := cc.(*Canvas)
+	if ok {
+		c.appendOp("DrawCanvas", func(e *encoder) { e.canvas(child, offset) })
+		cc = child.target
+	}
+	if c.target != nil && cc != nil {
+		c.target.DrawCanvas(cc, offset)
+	}
+}
+
+// DrawCanvasBlend draws cc at offset with the opacity alpha and blend mode.
+// If cc is not a *Canvas then it is forwarded to the target canvas, but is not
+// recorded.
+func (c *Canvas) DrawCanvasBlend(cc gxui.Canvas, offset math.Point, alpha float32, mode gxui.BlendMode) {
+	if cc == nil {
+		panic("Canvas cannot be nil")
+	}
+	child, ok := cc.(*Canvas)
+	if ok {
+		c.appendOp("DrawCanvasBlend", func(e *encoder) { e.blendCanvas(child, offset, alpha, mode) })
+		cc = child.target
+	}
+	if c.target != nil && cc != nil {
+		c.target.DrawCanvasBlend(cc, offset, alpha, mode)
+	}
+}
+
+// DrawCanvasBlur draws cc at offset blurred by radius. If cc is not a *Canvas
+// then it is forwarded to the target canvas, but is not recorded.
+func (c *Canvas) DrawCanvasBlur(cc gxui.Canvas, offset math.Point, radius float32) {
+	if cc == nil {
+		panic("Canvas cannot be nil")
+	}
+	child, ok := cc.(*Canvas)
+	if ok {
+		c.appendOp("DrawCanvasBlur", func(e *encoder) { e.blurCanvas(child, offset, radius) })
+		cc = child.target
+	}
+	if c.target != nil && cc != nil {
+		c.target.DrawCanvasBlur(cc, offset, radius)
+	}
+}
+
+func (c *Canvas) DrawTexture(t gxui.Texture, r math.Rect) {
+	if t == nil {
+		panic("Texture cannot be nil")
+	}
+	c.appendOp("DrawTexture", func(e *encoder) { e.texture(t, r) })
+	if c.target != nil {
+		c.target.DrawTexture(t, r)
+	}
+}
+
+func (c *Canvas) DrawRunes(f gxui.Font, r []rune, p []math.Point, col gxui.Color) {
+	if f == nil {
+		panic("Font cannot be nil")
+	}
+	if len(r) != len(p) {
+		panic(fmt.Errorf("There must be the same number of runes to offsets. Got %d runes and %d offsets",
+			len(r), len(p)))
+	}
+	runes := append([]rune{}, r...)
+	points := append([]math.Point{}, p...)
+	c.appendOp("DrawRunes", func(e *encoder) { e.runes(f, runes, points, col) })
+	if c.target != nil {
+		c.target.DrawRunes(f, r, p, col)
+	}
+}
+
+func (c *Canvas) DrawLines(lines gxui.Polygon, pen gxui.Pen) {
+	lines = append(gxui.Polygon{}, lines...)
+	c.appendOp("DrawLines", func(e *encoder) { e.lines(lines, pen) })
+	if c.target != nil {
+		c.target.DrawLines(lines, pen)
+	}
+}
+
+func (c *Canvas) DrawPolygon(poly gxui.Polygon, pen gxui.Pen, brush gxui.Brush) {
+	poly = append(gxui.Polygon{}, poly...)
+	resolved := brush.Resolve(poly.Bounds())
+	c.appendOp("DrawPolygon", func(e *encoder) { e.polygon(poly, pen, resolved) })
+	if c.target != nil {
+		c.target.DrawPolygon(poly, pen, brush)
+	}
+}
+
+func (c *Canvas) DrawRect(r math.Rect, brush gxui.Brush) {
+	resolved := brush.Resolve(r)
+	c.appendOp("DrawRect", func(e *encoder) { e.rect(r, resolved) })
+	if c.target != nil {
+		c.target.DrawRect(r, brush)
+	}
+}
+
+func (c *Canvas) DrawRoundedRect(r math.Rect, tl, tr, bl, br float32, pen gxui.Pen, brush gxui.Brush) {
+	if tl == 0 && tr == 0 && bl == 0 && br == 0 && pen.Color.A == 0 {
+		c.appendOp("DrawRoundedRect", func(e *encoder) { e.rect(r, brush.Resolve(r)) })
+	} else {
+		p := roundedRect(r, tl, tr, bl, br)
+		resolved := brush.Resolve(r)
+		c.appendOp("DrawRoundedRect", func(e *encoder) { e.polygon(p, pen, resolved) })
+	}
+	if c.target != nil {
+		c.target.DrawRoundedRect(r, tl, tr, bl, br, pen, brush)
+	}
+}
+
+// roundedRect returns the clockwise polygon for the rectangle r with the
+// corner radii tl, tr, bl and br.
+func roundedRect(r math.Rect, tl, tr, bl, br float32) gxui.Polygon {
+	return gxui.Polygon{
+		gxui.PolygonVertex{Position: r.TL(), RoundedRadius: tl},
+		gxui.PolygonVertex{Position: r.TR(), RoundedRadius: tr},
+		gxui.PolygonVertex{Position: r.BR(), RoundedRadius: br},
+		gxui.PolygonVertex{Position: r.BL(), RoundedRadius: bl},
+	}
+}
+
+func (c *Canvas) DrawShadow(r math.Rect, tl, tr, bl, br float32, s gxui.Shadow) {
+	if s.IsVisible() {
+		c.appendOp("DrawShadow", func(e *encoder) { e.shadow(r, [4]float32{tl, tr, bl, br}, s) })
+	}
+	if c.target != nil {
+		c.target.DrawShadow(r, tl, tr, bl, br, s)
+	}
+}
+
+func (c *Canvas) FillPath(path *gxui.VectorPath, brush gxui.Brush) {
+	if path == nil {
+		panic("Path cannot be nil")
+	}
+	commands := append([]gxui.PathCommand{}, path.Commands()...)
+	rule := path.FillRule
+	resolved := brush.Resolve(path.Bounds())
+	c.appendOp("FillPath", func(e *encoder) { e.fillPath(commands, rule, resolved) })
+	if c.target != nil {
+		c.target.FillPath(path, brush)
+	}
+}
+
+func (c *Canvas) StrokePath(path *gxui.VectorPath, pen gxui.Pen) {
+	if path == nil {
+		panic("Path cannot be nil")
+	}
+	commands := append([]gxui.PathCommand{}, path.Commands()...)
+	c.appendOp("StrokePath", func(e *encoder) { e.strokePath(commands, pen) })
+	if c.target != nil {
+		c.target.StrokePath(path, pen)
+	}
+}
+
+// Release releases the target canvas. The recorded operations remain
+// available to any canvas that draws this canvas.
+func (c *Canvas) Release() {
+	if c.target != nil {
+		c.target.Release()
+	}
+}
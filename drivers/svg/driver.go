@@ -0,0 +1,47 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+)
+
+type driver struct {
+	gxui.Driver
+}
+
+// WrapDriver returns a driver that behaves like d, except that every canvas it
+// creates is a recording *Canvas targeting a canvas of d. Themes created with
+// the returned driver produce windows and controls that can be passed to
+// EncodeDrawer.
+func WrapDriver(d gxui.Driver) gxui.Driver {
+	return driver{d}
+}
+
+func (d driver) CreateCanvas(size math.Size) gxui.Canvas {
+	return CreateCanvas(size, d.Driver.CreateCanvas(size))
+}
+
+func (d driver) CreateWindowedViewport(width, height int, name string) gxui.Viewport {
+	return viewport{d.Driver.CreateWindowedViewport(width, height, name)}
+}
+
+func (d driver) CreateFullscreenViewport(width, height int, name string) gxui.Viewport {
+	return viewport{d.Driver.CreateFullscreenViewport(width, height, name)}
+}
+
+// viewport unwraps recording canvases before passing them to the driver's
+// viewport.
+type viewport struct {
+	gxui.Viewport
+}
+
+func (v viewport) SetCanvas(c gxui.Canvas) {
+	if r, ok := c.(*Canvas); ok {
+		c = r.target
+	}
+	v.Viewport.SetCanvas(c)
+}
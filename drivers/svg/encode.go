@@ -0,0 +1,514 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"image/png"
+	"io"
+	stdmath "math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/drivers/internal/stroke"
+	"github.com/google/gxui/math"
+)
+
+// Drawer is implemented by anything that can draw itself to a canvas, such as
+// gxui.Control and the windows of the mixins package.
+type Drawer interface {
+	Draw() gxui.Canvas
+}
+
+// Encoder writes recorded canvases as SVG documents.
+type Encoder struct {
+	// FontFamily returns the CSS font-family list used for text drawn with
+	// font. If FontFamily is nil then all text uses "sans-serif".
+	FontFamily func(font gxui.Font) string
+}
+
+// Encode writes the recorded canvas c to w as an SVG document using the
+// default Encoder.
+func Encode(w io.Writer, c gxui.Canvas) error {
+	return (&Encoder{}).Encode(w, c)
+}
+
+// EncodeDrawer draws d and writes the result to w as an SVG document using the
+// default Encoder.
+func EncodeDrawer(w io.Writer, d Drawer) error {
+	return (&Encoder{}).EncodeDrawer(w, d)
+}
+
+// Encode writes the recorded canvas c to w as an SVG document. c must be a
+// *Canvas that has been completed.
+func (enc *Encoder) Encode(w io.Writer, c gxui.Canvas) error {
+	rc, ok := c.(*Canvas)
+	if !ok {
+		return fmt.Errorf("Canvas %T is not a recording canvas", c)
+	}
+	if !rc.IsComplete() {
+		return fmt.Errorf("Canvas must be completed before encoding")
+	}
+	e := &encoder{
+		family: enc.FontFamily,
+		images: make(map[gxui.Texture]string),
+	}
+	if e.family == nil {
+		e.family = func(gxui.Font) string { return "sans-serif" }
+	}
+	size := rc.Size()
+	fmt.Fprintf(&e.buf, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(&e.buf, `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" version="1.1" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		size.W, size.H, size.W, size.H)
+	rc.write(e)
+	e.buf.WriteString("</svg>\n")
+	if e.err != nil {
+		return e.err
+	}
+	_, err := e.buf.WriteTo(w)
+	return err
+}
+
+// EncodeDrawer draws d and writes the result to w as an SVG document. d must
+// draw to a recording canvas, which is the case for windows and controls
+// created by a theme using a driver returned by WrapDriver. EncodeDrawer must
+// be called on the UI go-routine.
+func (enc *Encoder) EncodeDrawer(w io.Writer, d Drawer) error {
+	c := d.Draw()
+	if c == nil {
+		return fmt.Errorf("%T has nothing to draw", d)
+	}
+	if _, ok := c.(*Canvas); !ok {
+		return fmt.Errorf("%T did not draw to a recording canvas. Was its theme created with WrapDriver?", d)
+	}
+	return enc.Encode(w, c)
+}
+
+// encoder holds the state used while writing a single document.
+type encoder struct {
+	buf    bytes.Buffer
+	family func(gxui.Font) string
+	images map[gxui.Texture]string // Texture to PNG data URI
+	groups []int                   // Number of open <g> elements per Push
+	depth  int
+	ids    int
+	err    error
+}
+
+func (e *encoder) printf(format string, args ...interface{}) {
+	e.buf.WriteString(strings.Repeat("  ", e.depth+1))
+	fmt.Fprintf(&e.buf, format, args...)
+	e.buf.WriteByte('\n')
+}
+
+func (e *encoder) id(prefix string) string {
+	e.ids++
+	return fmt.Sprintf("%s%d", prefix, e.ids)
+}
+
+func (e *encoder) openGroup(attrs string) {
+	e.printf("<g %s>", attrs)
+	e.depth++
+}
+
+func (e *encoder) closeGroup() {
+	e.depth--
+	e.printf("</g>")
+}
+
+func (e *encoder) push() {
+	e.groups = append(e.groups, 0)
+}
+
+func (e *encoder) pop() {
+	for i, n := 0, e.groups[len(e.groups)-1]; i < n; i++ {
+		e.closeGroup()
+	}
+	e.groups = e.groups[:len(e.groups)-1]
+}
+
+// openStateGroup opens a group that is closed by the next pop.
+func (e *encoder) openStateGroup(attrs string) {
+	e.openGroup(attrs)
+	e.groups[len(e.groups)-1]++
+}
+
+func (e *encoder) clip(r math.Rect) {
+	id := e.id("clip")
+	e.printf(`<clipPath id="%s"><rect %s/></clipPath>`, id, rectAttrs(r))
+	e.openStateGroup(fmt.Sprintf(`clip-path="url(#%s)"`, id))
+}
+
+func (e *encoder) transform(m math.Mat3) {
+	if m == math.Mat3Ident {
+		return
+	}
+	// Canvas transforms use row vectors, so the translation is in the third row.
+	e.openStateGroup(fmt.Sprintf(`transform="matrix(%s %s %s %s %s %s)"`,
+		num(m[0]), num(m[1]), num(m[3]), num(m[4]), num(m[6]), num(m[7])))
+}
+
+func (e *encoder) canvas(c *Canvas, offset math.Point) {
+	if offset == math.ZeroPoint {
+		c.write(e)
+		return
+	}
+	e.openGroup(fmt.Sprintf(`transform="translate(%d %d)"`, offset.X, offset.Y))
+	c.write(e)
+	e.closeGroup()
+}
+
+var blendModes = [...]string{
+	gxui.BlendNormal:   "normal",
+	gxui.BlendMultiply: "multiply",
+	gxui.BlendScreen:   "screen",
+	gxui.BlendAdditive: "plus-lighter",
+}
+
+func (e *encoder) blendCanvas(c *Canvas, offset math.Point, alpha float32, mode gxui.BlendMode) {
+	attrs := fmt.Sprintf(`transform="translate(%d %d)" opacity="%s"`, offset.X, offset.Y, num(math.Saturate(alpha)))
+	if mode != gxui.BlendNormal {
+		attrs += fmt.Sprintf(` style="mix-blend-mode: %s"`, blendModes[mode])
+	}
+	e.openGroup(attrs)
+	c.write(e)
+	e.closeGroup()
+}
+
+// blurFilter defines a Gaussian blur filter for content within bounds, returning
+// the filter's id. Blurs are given as a radius, which is twice the standard
+// deviation.
+func (e *encoder) blurFilter(bounds math.Rect, radius float32) string {
+	id := e.id("blur")
+	e.printf(`<filter id="%s" filterUnits="userSpaceOnUse" %s><feGaussianBlur stdDeviation="%s"/></filter>`,
+		id, rectAttrs(bounds), num(radius/2))
+	return id
+}
+
+func (e *encoder) blurCanvas(c *Canvas, offset math.Point, radius float32) {
+	// The blur spreads up to three standard deviations beyond the canvas.
+	bounds := c.Size().Rect().ExpandI(int(radius*1.5 + 0.999))
+	id := e.blurFilter(bounds, radius)
+	e.openGroup(fmt.Sprintf(`transform="translate(%d %d)" filter="url(#%s)"`, offset.X, offset.Y, id))
+	c.write(e)
+	e.closeGroup()
+}
+
+func (e *encoder) shadow(r math.Rect, radii [4]float32, s gxui.Shadow) {
+	d := polygonPath(roundedRect(r, radii[0], radii[1], radii[2], radii[3]))
+	shadow := polygonPath(roundedRect(r.Offset(s.Offset), radii[0], radii[1], radii[2], radii[3]))
+	bounds := s.Bounds(r)
+	if s.Inner {
+		// The inside of the shape is filled, except for the offset shape,
+		// from far enough outside that the blur does not fade the edges.
+		bounds = r.Union(r.Offset(s.Offset)).ExpandI(int(s.Blur*1.5 + 1))
+		shadow = rectPath(bounds) + " " + shadow
+	}
+	filter := ""
+	if s.Blur > 0 {
+		filter = fmt.Sprintf(` filter="url(#%s)"`, e.blurFilter(bounds, s.Blur))
+	}
+	clip := e.id("clip")
+	if s.Inner {
+		e.printf(`<clipPath id="%s"><path d="%s"/></clipPath>`, clip, d)
+	} else {
+		e.printf(`<clipPath id="%s"><path d="%s %s" clip-rule="evenodd"/></clipPath>`, clip, rectPath(bounds.ExpandI(1)), d)
+	}
+	e.printf(`<path d="%s" fill-rule="evenodd" clip-path="url(#%s)" %s%s/>`, shadow, clip, colorAttrs("fill", s.Color), filter)
+}
+
+func (e *encoder) rect(r math.Rect, b gxui.Brush) {
+	if b.IsTransparent() {
+		return
+	}
+	e.printf(`<rect %s %s/>`, rectAttrs(r), e.fill(b))
+}
+
+func (e *encoder) texture(t gxui.Texture, r math.Rect) {
+	e.printf("%s", e.image(t, r))
+}
+
+func (e *encoder) runes(f gxui.Font, runes []rune, points []math.Point, col gxui.Color) {
+	text := []rune{}
+	xs, ys := []string{}, []string{}
+	for i, r := range runes {
+		// Whitespace would be collapsed by SVG renderers, misaligning the
+		// glyph positions.
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			continue
+		}
+		text = append(text, r)
+		xs = append(xs, strconv.Itoa(points[i].X))
+		ys = append(ys, strconv.Itoa(points[i].Y))
+	}
+	if len(text) == 0 || col.A == 0 {
+		return
+	}
+	e.printf(`<text x="%s" y="%s" font-family="%s" font-size="%d" %s>%s</text>`,
+		strings.Join(xs, " "), strings.Join(ys, " "), escape(e.family(f)), f.Size(),
+		colorAttrs("fill", col), escape(string(text)))
+}
+
+func (e *encoder) lines(poly gxui.Polygon, pen gxui.Pen) {
+	if pen.Width <= 0 || pen.Color.A == 0 {
+		return
+	}
+	// Pens stroke to the right of the line, so stroke the offset line.
+	points := stroke.Offset(stroke.Outline(poly, false), false, pen.Width/2)
+	if len(points) < 2 {
+		return
+	}
+	e.printf(`<path d="%s" fill="none" %s/>`, pointsPath(points, false), strokeAttrs(pen, pen.Width))
+}
+
+func (e *encoder) polygon(poly gxui.Polygon, pen gxui.Pen, b gxui.Brush) {
+	if len(poly) < 2 {
+		return
+	}
+	d := polygonPath(poly)
+	if !b.IsTransparent() {
+		e.printf(`<path d="%s" %s/>`, d, e.fill(b))
+	}
+	if pen.Width <= 0 || pen.Color.A == 0 {
+		return
+	}
+	if !pen.IsStyled() && isClockwise(poly) {
+		// A centered stroke of twice the width, clipped to the polygon, keeps
+		// rounded corners as true arcs.
+		id := e.id("clip")
+		e.printf(`<clipPath id="%s"><path d="%s"/></clipPath>`, id, d)
+		e.printf(`<path d="%s" fill="none" clip-path="url(#%s)" %s/>`, d, id, strokeAttrs(pen, pen.Width*2))
+		return
+	}
+	points := stroke.Offset(stroke.Outline(poly, true), true, pen.Width/2)
+	e.printf(`<path d="%s" fill="none" %s/>`, pointsPath(points, true), strokeAttrs(pen, pen.Width))
+}
+
+func (e *encoder) fillPath(commands []gxui.PathCommand, rule gxui.FillRule, b gxui.Brush) {
+	if len(commands) == 0 || b.IsTransparent() {
+		return
+	}
+	fillRule := "nonzero"
+	if rule == gxui.EvenOdd {
+		fillRule = "evenodd"
+	}
+	e.printf(`<path d="%s" fill-rule="%s" %s/>`, commandsPath(commands), fillRule, e.fill(b))
+}
+
+func (e *encoder) strokePath(commands []gxui.PathCommand, pen gxui.Pen) {
+	if len(commands) == 0 || pen.Width <= 0 || pen.Color.A == 0 {
+		return
+	}
+	e.printf(`<path d="%s" fill="none" %s/>`, commandsPath(commands), strokeAttrs(pen, pen.Width))
+}
+
+// fill returns the attributes that fill a shape with b, writing any gradient
+// or pattern definitions that the attributes refer to.
+func (e *encoder) fill(b gxui.Brush) string {
+	switch {
+	case b.Gradient != nil:
+		g := b.Gradient
+		id := e.id("gradient")
+		if g.Type == gxui.RadialGradient {
+			e.printf(`<radialGradient id="%s" gradientUnits="userSpaceOnUse" cx="%s" cy="%s" r="%s">`,
+				id, num(g.Start.X), num(g.Start.Y), num(g.End.Sub(g.Start).Len()))
+		} else {
+			e.printf(`<linearGradient id="%s" gradientUnits="userSpaceOnUse" x1="%s" y1="%s" x2="%s" y2="%s">`,
+				id, num(g.Start.X), num(g.Start.Y), num(g.End.X), num(g.End.Y))
+		}
+		for _, s := range g.Stops {
+			e.printf(`  <stop offset="%s" %s/>`, num(s.Offset), colorAttrs("stop-color", s.Color))
+		}
+		if g.Type == gxui.RadialGradient {
+			e.printf(`</radialGradient>`)
+		} else {
+			e.printf(`</linearGradient>`)
+		}
+		return fmt.Sprintf(`fill="url(#%s)"`, id)
+	case b.Pattern != nil:
+		p := b.Pattern
+		id := e.id("pattern")
+		e.printf(`<pattern id="%s" patternUnits="userSpaceOnUse" %s>`, id, rectAttrs(p.Rect))
+		e.printf(`  %s`, e.image(p.Texture, p.Rect.Size().Rect()))
+		e.printf(`</pattern>`)
+		return fmt.Sprintf(`fill="url(#%s)"`, id)
+	default:
+		return colorAttrs("fill", b.Color)
+	}
+}
+
+// image returns an <image> element drawing t to r, with the texture embedded
+// as a PNG.
+func (e *encoder) image(t gxui.Texture, r math.Rect) string {
+	uri, ok := e.images[t]
+	if !ok {
+		buf := &bytes.Buffer{}
+		if err := png.Encode(buf, t.Image()); err != nil && e.err == nil {
+			e.err = err
+		}
+		uri = "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+		e.images[t] = uri
+	}
+	flip := ""
+	if t.FlipY() {
+		flip = fmt.Sprintf(` transform="matrix(1 0 0 -1 0 %d)"`, r.Min.Y*2+r.H())
+	}
+	return fmt.Sprintf(`<image %s preserveAspectRatio="none"%s xlink:href="%s"/>`, rectAttrs(r), flip, uri)
+}
+
+func rectPath(r math.Rect) string {
+	return fmt.Sprintf("M%d %dH%dV%dH%dZ", r.Min.X, r.Min.Y, r.Max.X, r.Max.Y, r.Min.X)
+}
+
+func rectAttrs(r math.Rect) string {
+	return fmt.Sprintf(`x="%d" y="%d" width="%d" height="%d"`, r.Min.X, r.Min.Y, r.W(), r.H())
+}
+
+func colorAttrs(name string, c gxui.Color) string {
+	s := fmt.Sprintf(`%s="#%.2x%.2x%.2x"`, name, channel(c.R), channel(c.G), channel(c.B))
+	if c.A < 1 {
+		s += fmt.Sprintf(` %s-opacity="%s"`, strings.TrimSuffix(name, "-color"), num(math.Saturate(c.A)))
+	}
+	return s
+}
+
+func channel(f float32) uint8 {
+	return uint8(math.Saturate(f)*255 + 0.5)
+}
+
+var lineCaps = [...]string{gxui.ButtCap: "butt", gxui.RoundCap: "round", gxui.SquareCap: "square"}
+var lineJoins = [...]string{gxui.MiterJoin: "miter", gxui.RoundJoin: "round", gxui.BevelJoin: "bevel"}
+
+func strokeAttrs(pen gxui.Pen, width float32) string {
+	limit := pen.MiterLimit
+	if limit == 0 {
+		limit = gxui.DefaultMiterLimit
+	}
+	s := fmt.Sprintf(`%s stroke-width="%s" stroke-linecap="%s" stroke-linejoin="%s" stroke-miterlimit="%s"`,
+		colorAttrs("stroke", pen.Color), num(width), lineCaps[pen.Cap], lineJoins[pen.Join], num(limit))
+	if d := pen.Dash; d != nil && len(d.Lengths) > 0 {
+		lengths := make([]string, len(d.Lengths))
+		for i, l := range d.Lengths {
+			lengths[i] = num(l)
+		}
+		s += fmt.Sprintf(` stroke-dasharray="%s" stroke-dashoffset="%s"`, strings.Join(lengths, " "), num(d.Offset))
+	}
+	return s
+}
+
+// num formats f to a thousandth of a DIP, which avoids float32 noise such as
+// 1.9999999 in the output.
+func num(f float32) string {
+	v := stdmath.Round(float64(f)*1000) / 1000
+	if v == 0 {
+		v = 0 // Avoid "-0"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func vec(v math.Vec2) string {
+	return num(v.X) + " " + num(v.Y)
+}
+
+func escape(s string) string {
+	buf := &bytes.Buffer{}
+	xml.EscapeText(buf, []byte(s))
+	return buf.String()
+}
+
+func pointsPath(points []math.Vec2, closed bool) string {
+	parts := make([]string, len(points))
+	for i, p := range points {
+		if i == 0 {
+			parts[i] = "M" + vec(p)
+		} else {
+			parts[i] = "L" + vec(p)
+		}
+	}
+	if closed {
+		parts = append(parts, "Z")
+	}
+	return strings.Join(parts, " ")
+}
+
+func commandsPath(commands []gxui.PathCommand) string {
+	parts := make([]string, len(commands))
+	for i, c := range commands {
+		switch c.Type {
+		case gxui.MoveTo:
+			parts[i] = "M" + vec(c.Points[0])
+		case gxui.LineTo:
+			parts[i] = "L" + vec(c.Points[0])
+		case gxui.QuadTo:
+			parts[i] = "Q" + vec(c.Points[0]) + " " + vec(c.Points[1])
+		case gxui.CubicTo:
+			parts[i] = "C" + vec(c.Points[0]) + " " + vec(c.Points[1]) + " " + vec(c.Points[2])
+		case gxui.Close:
+			parts[i] = "Z"
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// polygonPath returns the path data for the closed polygon p, with rounded
+// vertices drawn as circular arcs of the same shape as those drawn by the
+// drivers.
+func polygonPath(p gxui.Polygon) string {
+	parts := []string{}
+	add := func(cmd string, v math.Vec2) {
+		if len(parts) == 0 {
+			cmd = "M"
+		}
+		parts = append(parts, cmd+vec(v))
+	}
+	for i, cnt := 0, len(p); i < cnt; i++ {
+		a := p[i].Position.Vec2()
+		r := p[i].RoundedRadius
+		b := p[(i+cnt-1)%cnt].Position.Vec2()
+		c := p[(i+1)%cnt].Position.Vec2()
+		ba, ca := a.Sub(b), a.Sub(c)
+		baLen, caLen := ba.Len(), ca.Len()
+		if r <= 0 || cnt < 3 || baLen == 0 || caLen == 0 {
+			add("L", a)
+			continue
+		}
+		baDir, caDir := ba.DivS(baLen), ca.DivS(caLen)
+		dp := baDir.Dot(caDir)
+		if dp < -0.99999 {
+			add("L", a) // Straight line
+			continue
+		}
+		α := math.Acosf(dp) / 2
+		d := r / math.Sinf(α)
+		// The arc cannot start or end further than half way along ab or ac.
+		if dMax := math.Minf(baLen, caLen) / (2 * math.Cosf(α)); d > dMax {
+			d = dMax
+			r = d * math.Sinf(α)
+		}
+		t := d * math.Cosf(α)
+		sweep := 0
+		if ba.Cross(c.Sub(a)) > 0 {
+			sweep = 1 // Clockwise turn
+		}
+		add("L", a.Sub(baDir.MulS(t)))
+		parts = append(parts, fmt.Sprintf("A%s %s 0 0 %d %s", num(r), num(r), sweep, vec(a.Sub(caDir.MulS(t)))))
+	}
+	return strings.Join(append(parts, "Z"), " ")
+}
+
+// isClockwise returns true if the polygon p winds clockwise on the screen.
+func isClockwise(p gxui.Polygon) bool {
+	area := 0
+	for i, v := range p {
+		n := p[(i+1)%len(p)].Position
+		area += v.Position.X*n.Y - n.X*v.Position.Y
+	}
+	return area > 0
+}
@@ -0,0 +1,97 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import test "github.com/google/gxui/testing"
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/drivers/soft"
+	"github.com/google/gxui/math"
+	"github.com/google/gxui/themes/dark"
+)
+
+func TestEncodeClipsAndShapes(t *testing.T) {
+	c := CreateCanvas(math.Size{W: 20, H: 10}, nil)
+	c.Push()
+	c.AddClip(math.CreateRect(0, 0, 10, 10))
+	c.DrawRect(math.CreateRect(1, 2, 5, 6), gxui.CreateBrush(gxui.Red))
+	c.Pop()
+	c.DrawRoundedRect(math.CreateRect(0, 0, 10, 10), 2, 0, 0, 0, gxui.TransparentPen, gxui.CreateBrush(gxui.Gray50))
+	c.Complete()
+
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, c); err != nil {
+		t.Fatal(err)
+	}
+	test.AssertEquals(t, `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" version="1.1" width="20" height="10" viewBox="0 0 20 10">
+  <clipPath id="clip1"><rect x="0" y="0" width="10" height="10"/></clipPath>
+  <g clip-path="url(#clip1)">
+    <rect x="1" y="2" width="4" height="4" fill="#ff0000"/>
+  </g>
+  <path d="M0 2 A2 2 0 0 1 2 0 L10 0 L10 10 L0 10 Z" fill="#808080"/>
+</svg>
+`, buf.String())
+}
+
+func TestEncodePathAndDashedPen(t *testing.T) {
+	c := CreateCanvas(math.Size{W: 10, H: 10}, nil)
+	p := &gxui.VectorPath{FillRule: gxui.EvenOdd}
+	p.MoveTo(math.Vec2{X: 1, Y: 1})
+	p.QuadTo(math.Vec2{X: 5, Y: 0}, math.Vec2{X: 9, Y: 1})
+	p.LineTo(math.Vec2{X: 9, Y: 9})
+	p.Close()
+	c.FillPath(p, gxui.CreateBrush(gxui.Blue))
+	pen := gxui.CreatePen(2, gxui.White)
+	pen.Dash = gxui.CreateDash(1, 3, 2)
+	pen.Cap = gxui.RoundCap
+	c.StrokePath(p, pen)
+	c.Complete()
+
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, c); err != nil {
+		t.Fatal(err)
+	}
+	s := buf.String()
+	for _, want := range []string{
+		`<path d="M1 1 Q5 0 9 1 L9 9 Z" fill-rule="evenodd" fill="#0000ff"/>`,
+		`<path d="M1 1 Q5 0 9 1 L9 9 Z" fill="none" stroke="#ffffff" stroke-width="2" stroke-linecap="round" stroke-linejoin="miter" stroke-miterlimit="4" stroke-dasharray="3 2" stroke-dashoffset="1"/>`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("Output did not contain %s\nGot:\n%s", want, s)
+		}
+	}
+}
+
+func TestEncodeWindow(t *testing.T) {
+	var out string
+	var err error
+	soft.StartDriver(func(driver gxui.Driver) {
+		theme := dark.CreateTheme(WrapDriver(driver))
+		window := theme.CreateWindow(100, 50, "test")
+		label := theme.CreateLabel()
+		label.SetText("Hi")
+		window.AddChild(label)
+		driver.Call(func() {
+			defer driver.Terminate()
+			buf := &bytes.Buffer{}
+			err = EncodeDrawer(buf, window.(Drawer))
+			out = buf.String()
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, `>Hi</text>`) {
+		t.Errorf("Expected the label text in the output. Got:\n%s", out)
+	}
+	if !strings.Contains(out, `width="100" height="50"`) {
+		t.Errorf("Expected the window size in the output. Got:\n%s", out)
+	}
+}
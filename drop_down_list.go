@@ -15,6 +15,8 @@ type DropDownList interface {
 	SetBorderPen(Pen)
 	BackgroundBrush() Brush
 	SetBackgroundBrush(Brush)
+	Shadow() Shadow
+	SetShadow(Shadow)
 	Selected() AdapterItem
 	Select(AdapterItem)
 	OnSelectionChanged(func(AdapterItem)) EventSubscription
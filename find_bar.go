@@ -0,0 +1,61 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gxui
+
+// FindBar is a LinearLayout holding the controls to find and replace text in a
+// target TextBox. While attached to a target, the matches are highlighted with
+// a CodeSyntaxLayer added to the front of the target's syntax layers.
+// Pressing F3 or Shift+F3 in the target or the find bar selects the next or
+// previous match.
+type FindBar interface {
+	// FindBar extends the LinearLayout interface.
+	LinearLayout
+
+	// Target returns the TextBox being searched, or nil if there is no target.
+	Target() TextBox
+
+	// SetTarget sets the TextBox to be searched. The target must be a text box
+	// or code editor created by a theme.
+	SetTarget(TextBox)
+
+	// Finder returns the TextFinder for the target, or nil if there is no
+	// target.
+	Finder() *TextFinder
+
+	// Mode returns the mode used to match the pattern.
+	Mode() FindMode
+
+	// SetMode sets the mode used to match the pattern.
+	SetMode(FindMode)
+
+	// Pattern returns the text or regular expression to find.
+	Pattern() string
+
+	// SetPattern sets the text or regular expression to find.
+	SetPattern(string)
+
+	// Replacement returns the text used to replace matches.
+	Replacement() string
+
+	// SetReplacement sets the text used to replace matches.
+	SetReplacement(string)
+
+	// FindNext selects the next match in the target.
+	FindNext()
+
+	// FindPrevious selects the previous match in the target.
+	FindPrevious()
+
+	// Replace replaces the selected match in the target, and selects the next
+	// match.
+	Replace()
+
+	// ReplaceAll replaces all the matches in the target.
+	ReplaceAll()
+
+	// OnClose subscribes f to be called when the find bar's close button is
+	// clicked, or escape is pressed in the find bar.
+	OnClose(f func()) EventSubscription
+}
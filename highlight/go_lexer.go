@@ -0,0 +1,122 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package highlight
+
+import (
+	"bytes"
+	"go/scanner"
+	"go/token"
+	"strings"
+	"unicode/utf8"
+)
+
+// goState is the state of the Go lexer at the end of a line.
+type goState int
+
+const (
+	goCode         goState = iota
+	goBlockComment         // Inside a /* */ comment
+	goRawString            // Inside a `` string
+)
+
+type goLexer struct{}
+
+// GoLexer returns a Lexer for Go source, using the go/scanner package.
+func GoLexer() Lexer {
+	return goLexer{}
+}
+
+// Lexer compliance
+func (goLexer) InitialState() State {
+	return goCode
+}
+
+func (goLexer) Lex(line []rune, state State) ([]Token, State) {
+	src := []byte(string(line))
+	offsets := runeOffsets(src)
+	tokens := []Token{}
+	add := func(s, e int, scope Scope) {
+		if s < e {
+			tokens = append(tokens, Token{Start: offsets(s), End: offsets(e), Scope: scope})
+		}
+	}
+
+	// Finish a comment or raw string continued from the previous line.
+	start := 0
+	switch state {
+	case goBlockComment:
+		i := bytes.Index(src, []byte("*/"))
+		if i < 0 {
+			add(0, len(src), Comment)
+			return tokens, state
+		}
+		start = i + 2
+		add(0, start, Comment)
+	case goRawString:
+		i := bytes.IndexByte(src, '`')
+		if i < 0 {
+			add(0, len(src), String)
+			return tokens, state
+		}
+		start = i + 1
+		add(0, start, String)
+	}
+
+	end := goCode
+	src = src[start:]
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	s := scanner.Scanner{}
+	s.Init(file, src, nil, scanner.ScanComments)
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			continue // Automatically inserted
+		}
+		offset := start + file.Offset(pos)
+		length := len(lit)
+		if lit == "" {
+			length = len(tok.String())
+		}
+		switch {
+		case tok == token.COMMENT:
+			if strings.HasPrefix(lit, "/*") && (len(lit) < 4 || !strings.HasSuffix(lit, "*/")) {
+				end = goBlockComment
+			}
+			add(offset, offset+length, Comment)
+		case tok == token.STRING || tok == token.CHAR:
+			if strings.HasPrefix(lit, "`") && (len(lit) < 2 || !strings.HasSuffix(lit, "`")) {
+				end = goRawString
+			}
+			add(offset, offset+length, String)
+		case tok == token.INT || tok == token.FLOAT || tok == token.IMAG:
+			add(offset, offset+length, Number)
+		case tok == token.IDENT:
+			add(offset, offset+length, Identifier)
+		case tok.IsKeyword():
+			add(offset, offset+length, Keyword)
+		case tok.IsOperator():
+			add(offset, offset+length, Operator)
+		}
+	}
+	return tokens, end
+}
+
+// runeOffsets returns a function that converts byte offsets in src to rune
+// offsets. The byte offsets must be passed in non-decreasing order.
+func runeOffsets(src []byte) func(int) int {
+	byteOffset, runeOffset := 0, 0
+	return func(b int) int {
+		if b < byteOffset {
+			byteOffset, runeOffset = 0, 0
+		}
+		runeOffset += utf8.RuneCount(src[byteOffset:b])
+		byteOffset = b
+		return runeOffset
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package highlight
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// RootState is the name of the grammar state that lexing starts in.
+const RootState = "root"
+
+// The maximum number of consecutive rules that may match without consuming
+// any runes. Once reached, only a rule that consumes runes is applied, or the
+// rune is skipped if there is none. This stops grammars with cycles of empty
+// pushes and pops from looping forever.
+const maxGrammarStalls = 16
+
+// Grammar is a declarative definition of a Lexer, as a state machine of
+// regular expressions. Lexing starts in RootState. At each position in the
+// line, the rules of the state on the top of the state stack are tried in
+// order, and the first rule whose regular expression matches at the position
+// is applied. Runes that no rule matches are skipped. The state stack is
+// carried from one line to the next, so states can span lines.
+//
+// Grammars can be loaded from JSON with ParseGrammar:
+//
+//	{
+//	  "name": "ini",
+//	  "extensions": [".ini"],
+//	  "states": {
+//	    "root": [
+//	      {"match": ";.*", "scope": "comment"},
+//	      {"match": "(\\w+)(\\s*=)", "captures": ["string.key", "operator"]}
+//	    ]
+//	  }
+//	}
+type Grammar struct {
+	// Name is the name of the language.
+	Name string `json:"name"`
+
+	// Extensions are the file name extensions of the language, including the
+	// leading dot.
+	Extensions []string `json:"extensions,omitempty"`
+
+	// States holds the rules of each state.
+	States map[string][]Rule `json:"states"`
+}
+
+// Rule is a rule of a Grammar state.
+type Rule struct {
+	// Match is the regular expression matched at the current position, using
+	// the syntax of the regexp package.
+	Match string `json:"match"`
+
+	// Scope is the scope of the matched runes. An empty scope leaves the runes
+	// unhighlighted.
+	Scope Scope `json:"scope,omitempty"`
+
+	// Captures are the scopes of the regular expression's groups, starting
+	// from the first group. If Captures is set, Scope is ignored. Groups nested
+	// in other groups take precedence over the groups that contain them.
+	Captures []Scope `json:"captures,omitempty"`
+
+	// Push is the name of the state to push on to the state stack once the
+	// rule is matched.
+	Push string `json:"push,omitempty"`
+
+	// Pop pops the current state from the state stack once the rule is
+	// matched. The root state is never popped. Pop is applied before Push.
+	Pop bool `json:"pop,omitempty"`
+}
+
+// ParseGrammar reads a Grammar in JSON form from r.
+func ParseGrammar(r io.Reader) (*Grammar, error) {
+	g := &Grammar{}
+	if err := json.NewDecoder(r).Decode(g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+type grammarRule struct {
+	Rule
+	re *regexp.Regexp
+}
+
+// grammarLexer is a Lexer for a compiled Grammar. Its State is the state
+// stack, as the state names separated by spaces.
+type grammarLexer struct {
+	states map[string][]grammarRule
+}
+
+// Compile returns a Lexer for the grammar, or an error if the grammar has no
+// root state, pushes an unknown state, or has an invalid regular expression.
+func (g *Grammar) Compile() (Lexer, error) {
+	if _, ok := g.States[RootState]; !ok {
+		return nil, fmt.Errorf("Grammar %q has no %q state", g.Name, RootState)
+	}
+	l := &grammarLexer{states: make(map[string][]grammarRule, len(g.States))}
+	for name, rules := range g.States {
+		if strings.Contains(name, " ") {
+			return nil, fmt.Errorf("Grammar %q state %q contains a space", g.Name, name)
+		}
+		compiled := make([]grammarRule, len(rules))
+		for i, r := range rules {
+			if _, ok := g.States[r.Push]; r.Push != "" && !ok {
+				return nil, fmt.Errorf("Grammar %q state %q pushes unknown state %q", g.Name, name, r.Push)
+			}
+			re, err := regexp.Compile(`^(?:` + r.Match + `)`)
+			if err != nil {
+				return nil, fmt.Errorf("Grammar %q state %q rule %d: %v", g.Name, name, i, err)
+			}
+			compiled[i] = grammarRule{r, re}
+		}
+		l.states[name] = compiled
+	}
+	return l, nil
+}
+
+// MustCompile is like Compile, but panics if the grammar cannot be compiled.
+func (g *Grammar) MustCompile() Lexer {
+	l, err := g.Compile()
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
+
+// Lexer compliance
+func (l *grammarLexer) InitialState() State {
+	return RootState
+}
+
+func (l *grammarLexer) Lex(line []rune, state State) ([]Token, State) {
+	stack := strings.Split(state.(string), " ")
+	src := string(line)
+	offsets := runeOffsets([]byte(src))
+	tokens := []Token{}
+	add := func(s, e int, scope Scope) {
+		if s < e && scope != "" {
+			tokens = append(tokens, Token{Start: offsets(s), End: offsets(e), Scope: scope})
+		}
+	}
+
+	pos, stalls := 0, 0
+	for pos <= len(src) {
+		rule, m := l.match(stack[len(stack)-1], src, pos, stalls >= maxGrammarStalls)
+		if rule == nil {
+			if pos == len(src) {
+				break
+			}
+			_, size := utf8.DecodeRuneInString(src[pos:])
+			pos, stalls = pos+size, 0
+			continue
+		}
+		if rule.Captures != nil {
+			for _, t := range captureTokens(rule.Captures, m) {
+				add(pos+t.Start, pos+t.End, t.Scope)
+			}
+		} else {
+			add(pos, pos+m[1], rule.Scope)
+		}
+		if rule.Pop && len(stack) > 1 {
+			stack = stack[:len(stack)-1]
+		}
+		if rule.Push != "" {
+			stack = append(stack, rule.Push)
+		}
+		if m[1] == 0 {
+			stalls++
+		} else {
+			stalls = 0
+		}
+		pos += m[1]
+	}
+	return tokens, strings.Join(stack, " ")
+}
+
+// captureTokens returns the tokens of the groups of the submatch indices m,
+// with the scopes of captures, in byte offsets. The tokens of a group are
+// split around the groups nested inside it, so the tokens do not overlap.
+func captureTokens(captures []Scope, m []int) []Token {
+	groups := []Token{}
+	for i, scope := range captures {
+		if g := 2 * (i + 1); g+1 < len(m) && m[g] >= 0 && m[g] < m[g+1] && scope != "" {
+			groups = append(groups, Token{Start: m[g], End: m[g+1], Scope: scope})
+		}
+	}
+	if len(groups) < 2 {
+		return groups
+	}
+
+	// Split the groups at every group boundary. Each piece belongs to the
+	// innermost group holding it, which is the last, as groups are ordered by
+	// their opening parentheses.
+	bounds := make([]int, 0, 2*len(groups))
+	for _, g := range groups {
+		bounds = append(bounds, g.Start, g.End)
+	}
+	sort.Ints(bounds)
+	tokens := []Token{}
+	last := -1
+	for i := 1; i < len(bounds); i++ {
+		s, e := bounds[i-1], bounds[i]
+		if s == e {
+			continue
+		}
+		for g := len(groups) - 1; g >= 0; g-- {
+			if groups[g].Start <= s && e <= groups[g].End {
+				if g == last && tokens[len(tokens)-1].End == s {
+					tokens[len(tokens)-1].End = e
+				} else {
+					tokens = append(tokens, Token{Start: s, End: e, Scope: groups[g].Scope})
+				}
+				last = g
+				break
+			}
+		}
+	}
+	return tokens
+}
+
+// match returns the first rule of state that matches src at pos, along with
+// the submatch indices relative to pos. Rules that match no runes and do not
+// change the state are ignored, as are all rules that match no runes if
+// stalled is true.
+func (l *grammarLexer) match(state, src string, pos int, stalled bool) (*grammarRule, []int) {
+	rules := l.states[state]
+	for i := range rules {
+		r := &rules[i]
+		m := r.re.FindStringSubmatchIndex(src[pos:])
+		if m != nil && (m[1] > 0 || (!stalled && (r.Push != "" || r.Pop))) {
+			return r, m
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,241 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package highlight
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+	test "github.com/google/gxui/testing"
+	"github.com/google/gxui/themes/dark"
+)
+
+const sqlSource = `SELECT id, name FROM users -- All users
+/* A multi-line
+   comment */
+WHERE age > 21 AND name = 'O''Brien';
+`
+
+// scopedText returns the text of each of the tokens, followed by its scope.
+func scopedText(line string, tokens []Token) []string {
+	runes := []rune(line)
+	list := make([]string, len(tokens))
+	for i, t := range tokens {
+		list[i] = string(runes[t.Start:t.End]) + " " + string(t.Scope)
+	}
+	return list
+}
+
+func lexLines(l Lexer, lines ...string) ([][]string, State) {
+	state := l.InitialState()
+	list := make([][]string, len(lines))
+	for i, line := range lines {
+		var tokens []Token
+		tokens, state = l.Lex([]rune(line), state)
+		list[i] = scopedText(line, tokens)
+	}
+	return list, state
+}
+
+func TestParseGrammar(t *testing.T) {
+	g, err := ParseGrammar(strings.NewReader(`{
+		"name": "ini",
+		"extensions": [".ini"],
+		"states": {
+			"root": [
+				{"match": ";.*", "scope": "comment"},
+				{"match": "([\\pL\\d_]+)(\\s*=)", "captures": ["string.key", "operator"]},
+				{"match": "\"", "scope": "string", "push": "string"}
+			],
+			"string": [
+				{"match": "\"", "scope": "string", "pop": true},
+				{"match": "[^\"]+", "scope": "string"}
+			]
+		}
+	}`))
+	test.AssertEquals(t, nil, err)
+	test.AssertEquals(t, "ini", g.Name)
+	test.AssertEquals(t, []string{".ini"}, g.Extensions)
+
+	lines, state := lexLines(g.MustCompile(), `é = "a ; b`, `c" ; d`)
+	test.AssertEquals(t, [][]string{
+		{"é string.key", " = operator", `" string`, "a ; b string"},
+		{`c string`, `" string`, "; d comment"},
+	}, lines)
+	test.AssertEquals(t, RootState, state)
+}
+
+func TestGrammarCompileErrors(t *testing.T) {
+	for _, c := range []struct {
+		grammar Grammar
+		err     string
+	}{
+		{Grammar{Name: "a"}, `Grammar "a" has no "root" state`},
+		{Grammar{Name: "b", States: map[string][]Rule{
+			RootState: {{Match: "x", Push: "missing"}},
+		}}, `Grammar "b" state "root" pushes unknown state "missing"`},
+		{Grammar{Name: "c", States: map[string][]Rule{
+			RootState: {{Match: "x"}, {Match: "("}},
+		}}, "Grammar \"c\" state \"root\" rule 1: error parsing regexp: missing closing ): `^(?:()`"},
+	} {
+		_, err := c.grammar.Compile()
+		test.AssertEquals(t, c.err, err.Error())
+	}
+}
+
+func TestGrammarEmptyMatches(t *testing.T) {
+	// Empty matches that change state are applied, even at the end of a line,
+	// but cycles of empty matches do not hang the lexer.
+	g := &Grammar{States: map[string][]Rule{
+		RootState: {{Match: `#`, Scope: Comment, Push: "line"}},
+		"line": {
+			{Match: `$`, Pop: true},
+			{Match: `.`, Scope: Comment},
+		},
+	}}
+	lines, state := lexLines(g.MustCompile(), "a#b", "c")
+	test.AssertEquals(t, [][]string{{"# comment", "b comment"}, {}}, lines)
+	test.AssertEquals(t, RootState, state)
+
+	g = &Grammar{States: map[string][]Rule{
+		RootState: {{Match: ``, Push: "loop"}},
+		"loop":    {{Match: ``, Pop: true}},
+	}}
+	lines, _ = lexLines(g.MustCompile(), "ab")
+	test.AssertEquals(t, [][]string{{}}, lines)
+
+	// Once stalled, the lexer applies the rules that consume runes, and then
+	// carries on with the rest of the line.
+	g = &Grammar{States: map[string][]Rule{
+		RootState: {{Match: ``, Push: "a"}, {Match: `x`, Scope: Keyword}},
+		"a":       {{Match: ``, Pop: true}},
+	}}
+	lines, state = lexLines(g.MustCompile(), "x x x")
+	test.AssertEquals(t, [][]string{{"x keyword", "x keyword", "x keyword"}}, lines)
+	test.AssertEquals(t, RootState, state)
+}
+
+func TestGrammarNestedCaptures(t *testing.T) {
+	g := &Grammar{States: map[string][]Rule{
+		RootState: {
+			{Match: `(a(b)c)`, Captures: []Scope{Keyword, String}},
+			{Match: `(d(e))(f)`, Captures: []Scope{Keyword, String, Keyword}},
+			{Match: `(g(h))`, Captures: []Scope{Keyword, ""}},
+		},
+	}}
+	l := g.MustCompile()
+	tokens, _ := l.Lex([]rune("abc def gh"), l.InitialState())
+	test.AssertEquals(t, []Token{
+		{0, 1, Keyword}, {1, 2, String}, {2, 3, Keyword},
+		{4, 5, Keyword}, {5, 6, String}, {6, 7, Keyword},
+		{8, 10, Keyword},
+	}, tokens)
+}
+
+func TestBundledGrammars(t *testing.T) {
+	for _, c := range []struct {
+		file   string
+		lines  []string
+		tokens [][]string
+	}{
+		{"a.json", []string{`{"a": [1.5e3, true, "x\"y"]}`}, [][]string{{
+			"{ operator", `"a" string.key`, ": operator", "[ operator",
+			"1.5e3 number", ", operator", "true constant", ", operator",
+			`"x\"y" string`, "] operator", "} operator",
+		}}},
+		{"a.yml", []string{"---", "name: &n x # c", "- on: 12", `  "k": !t 'v'`}, [][]string{
+			{"--- keyword"},
+			{"name string.key", ": operator", "&n variable", "# c comment"},
+			{"- operator", "on string.key", ": operator", "12 number"},
+			{`"k" string.key`, ": operator", "!t type", "'v' string"},
+		}},
+		{"a.sh", []string{`if [ "$x" = 1 ]; then # c`, `  echo 'a`, `b' ${y}`, "fi"}, [][]string{
+			{"if keyword", "[ operator", `" string`, "$x variable", `" string`,
+				"= operator", "1 number", "]; operator", "then keyword", "# c comment"},
+			{"' string", "a string"},
+			{"b string", "' string", "${y} variable"},
+			{"fi keyword"},
+		}},
+		{"a.sql", strings.Split(sqlSource, "\n"), [][]string{
+			{"SELECT keyword", "id identifier", ", operator", "name identifier",
+				"FROM keyword", "users identifier", "-- All users comment"},
+			{"/* comment", " A multi-line comment"},
+			{"   comment  comment", "*/ comment"},
+			{"WHERE keyword", "age identifier", "> operator", "21 number",
+				"AND keyword", "name identifier", "= operator", "'O''Brien' string", "; operator"},
+			{},
+		}},
+		{"a.proto", []string{`message M { repeated int32 x = 1; } // c`}, [][]string{{
+			"message keyword", "M identifier", "{ operator", "repeated keyword",
+			"int32 type", "x identifier", "= operator", "1 number", "; operator",
+			"} operator", "// c comment",
+		}}},
+	} {
+		l := LexerForFile(c.file)
+		lines, state := lexLines(l, c.lines...)
+		test.AssertEquals(t, c.tokens, lines)
+		test.AssertEquals(t, RootState, state)
+	}
+	test.AssertEquals(t, GoLexer(), LexerForFile("/a/b.GO"))
+	test.AssertEquals(t, nil, LexerForFile("a.txt"))
+}
+
+func TestThemeResolve(t *testing.T) {
+	theme := Theme{String: gxui.White, Key: gxui.Red}
+	for _, c := range []struct {
+		scope    Scope
+		resolved Scope
+		ok       bool
+	}{
+		{String, String, true},
+		{Key, Key, true},
+		{"string.quoted", String, true},
+		{"string.key.json", Key, true},
+		{Keyword, "", false},
+		{"", "", false},
+	} {
+		resolved, ok := theme.Resolve(c.scope)
+		test.AssertEquals(t, c.resolved, resolved)
+		test.AssertEquals(t, c.ok, ok)
+	}
+}
+
+func TestHighlighterGrammar(t *testing.T) {
+	runEditor(func(editor *dark.CodeEditor) {
+		lexer := &countingLexer{Lexer: SQLGrammar.MustCompile()}
+		source := strings.Repeat(sqlSource, 20)
+		editor.SetText(source)
+		theme := Theme{Comment: gxui.Gray50, String: gxui.Red, "keyword.sql": gxui.Blue}
+		h := Create(editor, lexer, theme)
+		test.AssertEquals(t, lexText(SQLGrammar.MustCompile(), theme, source), layerSpans(h))
+		test.AssertEquals(t, 0, len(h.Layer("keyword.sql").Spans()))
+
+		// Closing the first comment early lexes the edited line, and the next
+		// line, which ends in the same state as before the edit.
+		c := editor.Controller()
+		lexer.count = 0
+		c.SetCaret(strings.Index(source, "A multi-line"))
+		c.ReplaceAll("*/")
+		test.AssertEquals(t, 2, lexer.count)
+		test.AssertEquals(t, lexText(SQLGrammar.MustCompile(), theme, c.Text()), layerSpans(h))
+
+		c.Undo()
+		test.AssertEquals(t, lexText(SQLGrammar.MustCompile(), theme, c.Text()), layerSpans(h))
+
+		rng := rand.New(rand.NewSource(1))
+		inserts := []string{"/*", "*/", "'", "\n", "--", "x", "12"}
+		for i := 0; i < 100; i++ {
+			l := c.TextLength()
+			s := rng.Intn(l + 1)
+			e := math.Min(s+rng.Intn(4), l)
+			c.SetSelections(gxui.TextSelectionList{gxui.CreateTextSelection(s, e, false)})
+			c.ReplaceAll(inserts[rng.Intn(len(inserts))])
+			test.AssertEquals(t, lexText(SQLGrammar.MustCompile(), theme, c.Text()), layerSpans(h))
+		}
+	})
+}
@@ -0,0 +1,160 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package highlight
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Rules shared by the grammars.
+var (
+	doubleQuotedRule = Rule{Match: `"(?:[^"\\]|\\.)*"?`, Scope: String}
+	singleQuotedRule = Rule{Match: `'(?:[^'\\]|\\.)*'?`, Scope: String}
+	whitespaceRule   = Rule{Match: `\s+`}
+
+	// The state of a /* */ comment.
+	blockCommentRules = []Rule{
+		{Match: `\*/`, Scope: Comment, Pop: true},
+		{Match: `[^*]+|\*`, Scope: Comment},
+	}
+)
+
+// JSONGrammar is a Grammar for JSON.
+var JSONGrammar = &Grammar{
+	Name:       "JSON",
+	Extensions: []string{".json"},
+	States: map[string][]Rule{
+		RootState: {
+			whitespaceRule,
+			{Match: `("(?:[^"\\]|\\.)*")(\s*:)`, Captures: []Scope{Key, Operator}},
+			doubleQuotedRule,
+			{Match: `-?\d+(?:\.\d+)?(?:[eE][+-]?\d+)?`, Scope: Number},
+			{Match: `(?:true|false|null)\b`, Scope: Constant},
+			{Match: `[{}\[\],:]`, Scope: Operator},
+		},
+	},
+}
+
+// YAMLGrammar is a Grammar for YAML. Block scalars are lexed as plain text.
+var YAMLGrammar = &Grammar{
+	Name:       "YAML",
+	Extensions: []string{".yaml", ".yml"},
+	States: map[string][]Rule{
+		RootState: {
+			whitespaceRule,
+			{Match: `#.*`, Scope: Comment},
+			{Match: `(?:---|\.\.\.)$`, Scope: Keyword},
+			{Match: `(?:(-)\s+)?([^\s#:"'\[\]{},][^#:]*?|"(?:[^"\\]|\\.)*"|'(?:[^']|'')*')(\s*:)(?:\s|$)`, Captures: []Scope{Operator, Key, Operator}},
+			doubleQuotedRule,
+			{Match: `'(?:[^']|'')*'?`, Scope: String},
+			{Match: `[&*][\w-]+`, Scope: Variable},
+			{Match: `!\S*`, Scope: Type},
+			{Match: `(true|false|yes|no|on|off|null|~)(?:\s|$)`, Captures: []Scope{Constant}},
+			{Match: `([-+]?(?:0x[0-9a-fA-F]+|\d+(?:\.\d+)?(?:[eE][+-]?\d+)?|\.inf|\.nan))(?:\s|$)`, Captures: []Scope{Number}},
+			{Match: `[|>][-+]?\d*$|[-?:,\[\]{}]`, Scope: Operator},
+			{Match: `[^\s#,\[\]{}]+`},
+		},
+	},
+}
+
+// ShellGrammar is a Grammar for POSIX and bash shell scripts.
+var ShellGrammar = &Grammar{
+	Name:       "Shell",
+	Extensions: []string{".sh", ".bash"},
+	States: map[string][]Rule{
+		RootState: {
+			whitespaceRule,
+			{Match: `#.*`, Scope: Comment},
+			{Match: `\$(?:\{[^}]*\}|\w+|[@*#?$!0-9-])`, Scope: Variable},
+			{Match: `"`, Scope: String, Push: "double"},
+			{Match: `'`, Scope: String, Push: "single"},
+			{Match: `(?:if|then|else|elif|fi|for|while|until|do|done|case|esac|in|function|select|return|break|continue|local|export|readonly)\b`, Scope: Keyword},
+			{Match: `\d+\b`, Scope: Number},
+			{Match: `\$\(|\[\[|\]\]|[|&;()<>!=\[\]{}]+`, Scope: Operator},
+			{Match: `\\.|[^\s$"'#|&;()<>!=\[\]{}\\]+`},
+		},
+		"double": {
+			{Match: `"`, Scope: String, Pop: true},
+			{Match: `\$(?:\{[^}]*\}|\w+|[@*#?$!0-9-])`, Scope: Variable},
+			{Match: `\\.|[^"\\$]+|\$`, Scope: String},
+		},
+		"single": {
+			{Match: `'`, Scope: String, Pop: true},
+			{Match: `[^']+`, Scope: String},
+		},
+	},
+}
+
+// SQLGrammar is a Grammar for SQL. Keywords are matched regardless of case.
+var SQLGrammar = &Grammar{
+	Name:       "SQL",
+	Extensions: []string{".sql"},
+	States: map[string][]Rule{
+		RootState: {
+			whitespaceRule,
+			{Match: `--.*`, Scope: Comment},
+			{Match: `/\*`, Scope: Comment, Push: "comment"},
+			{Match: `'(?:[^']|'')*'?`, Scope: String},
+			{Match: `"(?:[^"]|"")*"?`, Scope: Identifier},
+			{Match: `(?i:select|from|where|insert|into|values|update|set|delete|create|table|drop|alter|add|column|index|on|join|left|right|inner|outer|full|cross|natural|using|group|by|order|having|limit|offset|union|all|distinct|as|and|or|not|in|is|null|like|between|exists|case|when|then|else|end|primary|key|foreign|references|default|unique|check|constraint|view|begin|commit|rollback|transaction|with|asc|desc|if|returning)\b`, Scope: Keyword},
+			{Match: `(?i:int|integer|smallint|bigint|real|float|double|precision|decimal|numeric|char|varchar|text|date|time|timestamp|interval|boolean|bool|blob|serial)\b`, Scope: Type},
+			{Match: `(?i:true|false)\b`, Scope: Constant},
+			{Match: `\d+(?:\.\d+)?(?:[eE][+-]?\d+)?\b`, Scope: Number},
+			{Match: `[-+*/%=<>!|,;().]+`, Scope: Operator},
+			{Match: `\w+`, Scope: Identifier},
+		},
+		"comment": blockCommentRules,
+	},
+}
+
+// ProtobufGrammar is a Grammar for Protocol Buffers definitions.
+var ProtobufGrammar = &Grammar{
+	Name:       "Protocol Buffers",
+	Extensions: []string{".proto"},
+	States: map[string][]Rule{
+		RootState: {
+			whitespaceRule,
+			{Match: `//.*`, Scope: Comment},
+			{Match: `/\*`, Scope: Comment, Push: "comment"},
+			doubleQuotedRule,
+			singleQuotedRule,
+			{Match: `(?:syntax|edition|package|import|public|weak|option|message|enum|service|rpc|returns|stream|oneof|map|reserved|extensions|extend|to|max|repeated|optional|required)\b`, Scope: Keyword},
+			{Match: `(?:double|float|int32|int64|uint32|uint64|sint32|sint64|fixed32|fixed64|sfixed32|sfixed64|bool|string|bytes)\b`, Scope: Type},
+			{Match: `(?:true|false|inf|nan)\b`, Scope: Constant},
+			{Match: `-?(?:0[xX][0-9a-fA-F]+|\d+(?:\.\d*)?(?:[eE][+-]?\d+)?)\b`, Scope: Number},
+			{Match: `[{}\[\]()<>;,=.]`, Scope: Operator},
+			{Match: `\w+`, Scope: Identifier},
+		},
+		"comment": blockCommentRules,
+	},
+}
+
+// Grammars holds the bundled grammars.
+var Grammars = []*Grammar{
+	JSONGrammar,
+	YAMLGrammar,
+	ShellGrammar,
+	SQLGrammar,
+	ProtobufGrammar,
+}
+
+// LexerForFile returns a Lexer for the language of the file with the given
+// name, chosen by the file name's extension, or nil if there is no lexer for
+// the language.
+func LexerForFile(name string) Lexer {
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == ".go" {
+		return GoLexer()
+	}
+	for _, g := range Grammars {
+		for _, e := range g.Extensions {
+			if e == ext {
+				return g.MustCompile()
+			}
+		}
+	}
+	return nil
+}
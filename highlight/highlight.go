@@ -0,0 +1,325 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package highlight provides syntax highlighting for gxui.CodeEditors.
+//
+// A Lexer splits each line of text into Tokens, each tagged with a Scope. A
+// Highlighter runs a Lexer over the text of a CodeEditor, and adds a
+// gxui.CodeSyntaxLayer to the editor for each Scope that is given a color by
+// the Highlighter's Theme. As the text is edited, only the edited lines, and
+// any following lines whose lexer state changes, are lexed again.
+package highlight
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+)
+
+// Scope identifies the kind of a token, such as a keyword or a comment.
+// Scopes may be refined with dot-separated suffixes, such as "string.key",
+// which fall back to their parent scope when a Theme has no color for them.
+type Scope string
+
+const (
+	Comment    Scope = "comment"
+	Keyword    Scope = "keyword"
+	Identifier Scope = "identifier"
+	String     Scope = "string"
+	Number     Scope = "number"
+	Operator   Scope = "operator"
+	Constant   Scope = "constant"
+	Type       Scope = "type"
+	Variable   Scope = "variable"
+	Key        Scope = "string.key"
+)
+
+// Parent returns the scope with the last dot-separated suffix removed, or ""
+// if the scope has no suffix.
+func (s Scope) Parent() Scope {
+	if i := strings.LastIndex(string(s), "."); i >= 0 {
+		return s[:i]
+	}
+	return ""
+}
+
+// PlainText returns true if the scope, or its outermost parent, is String or
+// Comment. The layers of these scopes are marked as plain text, so that a
+// gxui.CodeEditor does not match the brackets inside them.
+func (s Scope) PlainText() bool {
+	for p := s.Parent(); p != ""; p = p.Parent() {
+		s = p
+	}
+	return s == String || s == Comment
+}
+
+// Token is a run of runes within a line, from Start up to End, that belongs
+// to a Scope.
+type Token struct {
+	Start, End int
+	Scope      Scope
+}
+
+// State is the state of a Lexer between two lines, such as being inside a
+// multi-line comment. States must be comparable with ==, as lexing stops once
+// the state at the end of a line is unchanged by an edit.
+type State interface{}
+
+// Lexer splits lines of text into Tokens.
+type Lexer interface {
+	// InitialState returns the state at the start of the text.
+	InitialState() State
+
+	// Lex returns the tokens of line, which does not include the newline, and
+	// the state at the end of the line. state is the state at the end of the
+	// previous line. The tokens must be in order and must not overlap.
+	Lex(line []rune, state State) ([]Token, State)
+}
+
+// Theme holds the color of each scope. Tokens with scopes that are not in the
+// theme, and whose parent scopes are not in the theme, are not highlighted.
+type Theme map[Scope]gxui.Color
+
+// Resolve returns scope, or its nearest parent scope, that is in the theme.
+// Resolve returns false if neither scope nor any of its parents are in the
+// theme.
+func (t Theme) Resolve(scope Scope) (Scope, bool) {
+	for ; scope != ""; scope = scope.Parent() {
+		if _, ok := t[scope]; ok {
+			return scope, true
+		}
+	}
+	return "", false
+}
+
+// DefaultTheme is a Theme for light text on a dark background.
+var DefaultTheme = Theme{
+	Comment:  gxui.ColorFromHex(0xFF6A9955),
+	Keyword:  gxui.ColorFromHex(0xFF569CD6),
+	String:   gxui.ColorFromHex(0xFFCE9178),
+	Number:   gxui.ColorFromHex(0xFFB5CEA8),
+	Operator: gxui.ColorFromHex(0xFFD4D4D4),
+	Constant: gxui.ColorFromHex(0xFF4FC1FF),
+	Type:     gxui.ColorFromHex(0xFF4EC9B0),
+	Variable: gxui.ColorFromHex(0xFF9CDCFE),
+	Key:      gxui.ColorFromHex(0xFF9CDCFE),
+}
+
+// line holds the tokens of a lexed line, the state at the end of the line and
+// a hash of the line's runes.
+type line struct {
+	tokens []Token
+	end    State
+	hash   uint64
+}
+
+// Highlighter keeps the syntax layers of a gxui.CodeEditor up to date with the
+// tokens produced by a Lexer.
+type Highlighter struct {
+	editor      gxui.CodeEditor
+	controller  *gxui.TextBoxController
+	lexer       Lexer
+	theme       Theme
+	lines       []line
+	layers      map[Scope]*gxui.CodeSyntaxLayer
+	textChanged gxui.EventSubscription
+}
+
+// controlled is implemented by the code editors of the mixins package, which
+// expose their TextBoxController.
+type controlled interface {
+	Controller() *gxui.TextBoxController
+}
+
+// Create returns a Highlighter that lexes the text of editor with lexer, and
+// adds a syntax layer for each of the scopes in theme to the end of the
+// editor's syntax layers. Release must be called to stop highlighting.
+//
+// editor must have a Controller method returning its gxui.TextBoxController,
+// as the code editors created by the themes do.
+func Create(editor gxui.CodeEditor, lexer Lexer, theme Theme) *Highlighter {
+	c, ok := editor.(controlled)
+	if !ok {
+		panic(fmt.Errorf("Editor %T does not have a TextBoxController", editor))
+	}
+	h := &Highlighter{
+		editor:     editor,
+		controller: c.Controller(),
+		lexer:      lexer,
+	}
+	h.SetTheme(theme)
+	h.textChanged = h.controller.OnTextChanged(h.update)
+	return h
+}
+
+// Release removes the highlighter's layers from the editor, and stops
+// updating them.
+func (h *Highlighter) Release() {
+	if h.textChanged != nil {
+		h.textChanged.Unlisten()
+		h.textChanged = nil
+		h.setLayers(nil)
+	}
+}
+
+// Lexer returns the Lexer used to split the text into tokens.
+func (h *Highlighter) Lexer() Lexer {
+	return h.lexer
+}
+
+// Theme returns the colors given to the scopes.
+func (h *Highlighter) Theme() Theme {
+	return h.theme
+}
+
+// SetTheme replaces the highlighter's syntax layers with a layer for each of
+// the scopes in theme.
+func (h *Highlighter) SetTheme(theme Theme) {
+	h.theme = theme
+	layers := make(map[Scope]*gxui.CodeSyntaxLayer, len(theme))
+	for scope, color := range theme {
+		l := gxui.CreateCodeSyntaxLayer()
+		l.SetColor(color)
+		l.SetData(scope)
+		l.SetPlainText(scope.PlainText())
+		layers[scope] = l
+	}
+	h.setLayers(layers)
+	h.lexAll()
+}
+
+// Layer returns the syntax layer of scope, or nil if the theme has no color
+// for scope. The layer holds the spans of the tokens of scope, and of any
+// refined scopes that resolve to scope in the theme.
+func (h *Highlighter) Layer(scope Scope) *gxui.CodeSyntaxLayer {
+	return h.layers[scope]
+}
+
+// layer returns the syntax layer that the tokens of scope are added to, or nil
+// if the scope does not resolve to a scope of the theme.
+func (h *Highlighter) layer(scope Scope) *gxui.CodeSyntaxLayer {
+	if resolved, ok := h.theme.Resolve(scope); ok {
+		return h.layers[resolved]
+	}
+	return nil
+}
+
+// Tokens returns the tokens of the line with index i.
+func (h *Highlighter) Tokens(i int) []Token {
+	return h.lines[i].tokens
+}
+
+// setLayers replaces the highlighter's layers in the editor with layers.
+func (h *Highlighter) setLayers(layers map[Scope]*gxui.CodeSyntaxLayer) {
+	list := gxui.CodeSyntaxLayers{}
+	for _, l := range h.editor.SyntaxLayers() {
+		if scope, ok := l.Data().(Scope); !ok || h.layers[scope] != l {
+			list = append(list, l)
+		}
+	}
+	h.layers = layers
+	scopes := make([]string, 0, len(layers))
+	for scope := range layers {
+		scopes = append(scopes, string(scope))
+	}
+	sort.Strings(scopes)
+	for _, scope := range scopes {
+		list = append(list, layers[Scope(scope)])
+	}
+	h.editor.SetSyntaxLayers(list)
+}
+
+// lexAll lexes every line of the text, replacing all the spans of the layers.
+func (h *Highlighter) lexAll() {
+	for _, l := range h.layers {
+		l.Clear()
+	}
+	h.lines = h.lines[:0]
+	h.lex(0, 0, 0)
+}
+
+// lex lexes the lines from first to last, and then each following line until
+// one has the same runes, and starts in the same state, as before the edit.
+// That line, and all the lines after it, are unchanged. Comparing the runes
+// catches edits that replace the runes of several lines, as an edit only gives
+// the change in the length of the text, not the number of runes replaced.
+// oldCount is the number of lines before the edit, or 0
+// if there are no lines before the edit to compare against. lex returns the
+// index of the last line lexed.
+func (h *Highlighter) lex(first, last, oldCount int) int {
+	c := h.controller
+	count := c.LineCount()
+	initial := h.lexer.InitialState()
+	state := initial
+	if first > 0 {
+		state = h.lines[first-1].end
+	}
+	lexed := []line{}
+	i := first
+	for ; i < count; i++ {
+		runes := c.LineRunes(i)
+		hash := gxui.HashRunes(runes)
+		if old := i + oldCount - count; i > last && old >= 0 && old < oldCount && h.lines[old].hash == hash {
+			oldState := initial
+			if old > 0 {
+				oldState = h.lines[old-1].end
+			}
+			if oldState == state {
+				break
+			}
+		}
+		tokens, end := h.lexer.Lex(runes, state)
+		lexed = append(lexed, line{tokens, end, hash})
+		state = end
+	}
+	last = math.Max(i-1, first)
+
+	// Replace the spans of the lexed lines.
+	tail := h.lines[math.Min(last+1+oldCount-count, len(h.lines)):]
+	h.lines = append(append(h.lines[:first:first], lexed...), tail...)
+	s, e := c.LineStart(first), c.LineEnd(last)
+	for _, l := range h.layers {
+		l.Remove(s, e-s+1)
+	}
+	for i, l := range lexed {
+		ls := c.LineStart(first + i)
+		for _, t := range l.tokens {
+			if layer := h.layer(t.Scope); layer != nil && t.End > t.Start {
+				layer.AddData(ls+t.Start, t.End-t.Start, t.Scope)
+			}
+		}
+	}
+	return last
+}
+
+// update lexes the lines touched by edits. The spans of the layers have
+// already been moved by the editor, which listened to the edits first.
+func (h *Highlighter) update(edits []gxui.TextBoxEdit) {
+	if len(edits) == 0 {
+		h.lexAll()
+		return
+	}
+
+	// Find the runes touched by the edits, after all the edits are applied.
+	s, e := edits[0].At, edits[0].At
+	for _, edit := range edits {
+		move := func(p int) int {
+			if p < edit.At {
+				return p
+			}
+			return math.Max(p+edit.Delta, edit.At)
+		}
+		s, e = move(s), move(e)
+		s = math.Min(s, edit.At)
+		e = math.Max(e, edit.At+math.Max(edit.Delta, 0))
+	}
+	runeCount := h.controller.TextLength()
+	s, e = math.Clamp(s, 0, runeCount), math.Clamp(e, 0, runeCount)
+
+	c := h.controller
+	h.lex(c.LineIndex(s), c.LineIndex(e), len(h.lines))
+}
@@ -0,0 +1,187 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package highlight
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/drivers/soft"
+	"github.com/google/gxui/interval"
+	test "github.com/google/gxui/testing"
+	"github.com/google/gxui/themes/dark"
+)
+
+const goSource = `package main
+
+import "fmt"
+
+/* A multi-line
+   comment */
+func main() {
+	s := ` + "`raw\nstring`" + `
+	x := 1.5 + 'c' // Trailing
+	fmt.Println(s, x)
+}
+`
+
+// countingLexer counts the number of lines lexed.
+type countingLexer struct {
+	Lexer
+	count int
+}
+
+func (l *countingLexer) Lex(line []rune, state State) ([]Token, State) {
+	l.count++
+	return l.Lexer.Lex(line, state)
+}
+
+func runEditor(f func(*dark.CodeEditor)) {
+	soft.StartDriver(func(driver gxui.Driver) {
+		defer driver.Terminate()
+		f(dark.CreateTheme(driver).CreateCodeEditor().(*dark.CodeEditor))
+	})
+}
+
+// lexText returns the spans of each scope in theme, found by lexing the
+// entire text.
+func lexText(lexer Lexer, theme Theme, text string) map[Scope][][2]int {
+	spans := map[Scope][][2]int{}
+	state, offset := lexer.InitialState(), 0
+	for _, l := range strings.Split(text, "\n") {
+		var tokens []Token
+		runes := []rune(l)
+		tokens, state = lexer.Lex(runes, state)
+		for _, t := range tokens {
+			if scope, ok := theme.Resolve(t.Scope); ok {
+				spans[scope] = append(spans[scope], [2]int{offset + t.Start, offset + t.End})
+			}
+		}
+		offset += len(runes) + 1
+	}
+	return spans
+}
+
+// layerSpans returns the spans of each of the highlighter's layers.
+func layerSpans(h *Highlighter) map[Scope][][2]int {
+	spans := map[Scope][][2]int{}
+	for scope, l := range h.layers {
+		for _, s := range l.Spans() {
+			start, end := s.Range()
+			spans[scope] = append(spans[scope], [2]int{start, end})
+		}
+	}
+	return spans
+}
+
+func TestGoLexer(t *testing.T) {
+	l := GoLexer()
+	tokens, state := l.Lex([]rune(`x := "é" + 12 // c`), l.InitialState())
+	test.AssertEquals(t, []Token{
+		{0, 1, Identifier}, {2, 4, Operator}, {5, 8, String}, {9, 10, Operator},
+		{11, 13, Number}, {14, 18, Comment},
+	}, tokens)
+	test.AssertEquals(t, goCode, state)
+
+	tokens, state = l.Lex([]rune(`if /* a`), l.InitialState())
+	test.AssertEquals(t, []Token{{0, 2, Keyword}, {3, 7, Comment}}, tokens)
+	test.AssertEquals(t, goBlockComment, state)
+
+	tokens, state = l.Lex([]rune(`b */ return`), state)
+	test.AssertEquals(t, []Token{{0, 4, Comment}, {5, 11, Keyword}}, tokens)
+	test.AssertEquals(t, goCode, state)
+
+	tokens, state = l.Lex([]rune("s := `a"), state)
+	test.AssertEquals(t, []Token{{0, 1, Identifier}, {2, 4, Operator}, {5, 7, String}}, tokens)
+	test.AssertEquals(t, goRawString, state)
+
+	tokens, state = l.Lex([]rune("b` + c"), state)
+	test.AssertEquals(t, []Token{{0, 2, String}, {3, 4, Operator}, {5, 6, Identifier}}, tokens)
+	test.AssertEquals(t, goCode, state)
+}
+
+func TestHighlighter(t *testing.T) {
+	runEditor(func(editor *dark.CodeEditor) {
+		other := gxui.CreateCodeSyntaxLayer()
+		editor.SetSyntaxLayers(gxui.CodeSyntaxLayers{other})
+		editor.SetText(goSource)
+		h := Create(editor, GoLexer(), DefaultTheme)
+		test.AssertEquals(t, len(DefaultTheme)+1, len(editor.SyntaxLayers()))
+		test.AssertEquals(t, other, editor.SyntaxLayers()[0])
+		test.AssertEquals(t, lexText(GoLexer(), DefaultTheme, goSource), layerSpans(h))
+		test.AssertEquals(t, interval.IntDataList{
+			interval.CreateIntData(28, 43, Comment),
+			interval.CreateIntData(44, 57, Comment),
+			interval.CreateIntData(107, 118, Comment),
+		}, h.Layer(Comment).Spans())
+
+		// Brackets are not matched in strings and comments.
+		test.AssertEquals(t, true, h.Layer(String).PlainText())
+		test.AssertEquals(t, true, h.Layer(Key).PlainText())
+		test.AssertEquals(t, true, h.Layer(Comment).PlainText())
+		test.AssertEquals(t, false, h.Layer(Keyword).PlainText())
+
+		h.Release()
+		test.AssertEquals(t, gxui.CodeSyntaxLayers{other}, editor.SyntaxLayers())
+	})
+}
+
+func TestHighlighterIncremental(t *testing.T) {
+	runEditor(func(editor *dark.CodeEditor) {
+		lexer := &countingLexer{Lexer: GoLexer()}
+		source := strings.Repeat(goSource, 20)
+		editor.SetText(source)
+		h := Create(editor, lexer, DefaultTheme)
+		c := editor.Controller()
+
+		// An edit within a line only lexes that line.
+		lexer.count = 0
+		c.SetCaret(strings.Index(source, "main"))
+		c.ReplaceAll("x")
+		test.AssertEquals(t, 1, lexer.count)
+
+		// Opening a comment lexes the following lines until one ends in the
+		// same state as before, which is the first line of the next comment.
+		lexer.count = 0
+		c.SetCaret(strings.Index(source, "func"))
+		c.ReplaceAll("/*")
+		test.AssertEquals(t, 12, lexer.count)
+		test.AssertEquals(t, lexText(GoLexer(), DefaultTheme, c.Text()), layerSpans(h))
+
+		// Replacing runes on several lines without changing the length of the
+		// text lexes each of the changed lines.
+		s := strings.Index(c.Text(), "import")
+		c.SetSelections(gxui.TextSelectionList{gxui.CreateTextSelection(s, s+13, false)})
+		c.ReplaceAll("x := 1\nvar y\n")
+		test.AssertEquals(t, lexText(GoLexer(), DefaultTheme, c.Text()), layerSpans(h))
+
+		rng := rand.New(rand.NewSource(1))
+		inserts := []string{"/*", "*/", "`", "\n", "\"", "x", "// ", "12", "\t"}
+		for i := 0; i < 200; i++ {
+			l := c.TextLength()
+			s := rng.Intn(l + 1)
+			e := s + rng.Intn(4)
+			if e > l {
+				e = l
+			}
+			c.SetSelections(gxui.TextSelectionList{gxui.CreateTextSelection(s, e, false)})
+			switch rng.Intn(3) {
+			case 0:
+				c.Backspace()
+			case 1:
+				c.Delete()
+			default:
+				c.ReplaceAll(inserts[rng.Intn(len(inserts))])
+			}
+			if i%10 == 0 {
+				c.AddCaret(rng.Intn(c.TextLength() + 1))
+				c.ReplaceAll("*/")
+			}
+			test.AssertEquals(t, lexText(GoLexer(), DefaultTheme, c.Text()), layerSpans(h))
+		}
+	})
+}
@@ -0,0 +1,24 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gxui
+
+// InputInjector is the interface implemented by windows that accept
+// synthesized input. Injected events are dispatched through the window exactly
+// as if they had been raised by the window's Viewport, with all points in
+// window coordinates.
+//
+// InputInjector methods must only be called on the UI go-routine.
+type InputInjector interface {
+	InjectMouseMove(MouseEvent)
+	InjectMouseEnter(MouseEvent)
+	InjectMouseExit(MouseEvent)
+	InjectMouseDown(MouseEvent)
+	InjectMouseUp(MouseEvent)
+	InjectMouseScroll(MouseEvent)
+	InjectKeyDown(KeyboardEvent)
+	InjectKeyUp(KeyboardEvent)
+	InjectKeyRepeat(KeyboardEvent)
+	InjectKeyStroke(KeyStrokeEvent)
+}
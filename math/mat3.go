@@ -38,6 +38,50 @@ func CreateMat3FromRows(r0, r1, r2 Vec3) Mat3 {
 	}
 }
 
+// Build a 3x3 matrix that translates 2D points by t.
+//  ╭         ╮
+//  │ 1   0 0 │
+//  │ 0   1 0 │
+//  │ tx ty 1 │
+//  ╰         ╯
+func CreateMat3Translate(t Vec2) Mat3 {
+	return Mat3{
+		1, 0, 0,
+		0, 1, 0,
+		t.X, t.Y, 1,
+	}
+}
+
+// Build a 3x3 matrix that scales 2D points by s.
+//  ╭          ╮
+//  │ sx  0  0 │
+//  │  0 sy  0 │
+//  │  0  0  1 │
+//  ╰          ╯
+func CreateMat3Scale(s Vec2) Mat3 {
+	return Mat3{
+		s.X, 0, 0,
+		0, s.Y, 0,
+		0, 0, 1,
+	}
+}
+
+// Build a 3x3 matrix that rotates 2D points by r radians. As the y-axis points
+// down, positive angles rotate clockwise.
+//  ╭                 ╮
+//  │  cos r  sin r 0 │
+//  │ -sin r  cos r 0 │
+//  │      0      0 1 │
+//  ╰                 ╯
+func CreateMat3Rotate(r float32) Mat3 {
+	s, c := Sinf(r), Cosf(r)
+	return Mat3{
+		c, s, 0,
+		-s, c, 0,
+		0, 0, 1,
+	}
+}
+
 //      A
 //     ╱ ╲
 //    ╱___╲
@@ -100,6 +144,47 @@ func (m Mat3) Row(i int) Vec3 {
 	return Vec3{m[i+0], m[i+1], m[i+2]}
 }
 
+// Mul returns the matrix product m • o. As vectors are multiplied as rows, the
+// transform m is applied before o.
+func (m Mat3) Mul(o Mat3) Mat3 {
+	c0, c1, c2 := o.Transpose().Rows()
+	r0, r1, r2 := m.Rows()
+	return Mat3{
+		r0.Dot(c0), r0.Dot(c1), r0.Dot(c2),
+		r1.Dot(c0), r1.Dot(c1), r1.Dot(c2),
+		r2.Dot(c0), r2.Dot(c1), r2.Dot(c2),
+	}
+}
+
+// Transform returns the 2D point v transformed by the affine matrix m.
+func (m Mat3) Transform(v Vec2) Vec2 {
+	return v.Vec3(1).MulM(m).XY()
+}
+
+// TransformPoint returns the point p transformed by the affine matrix m,
+// rounded to the nearest integer coordinates.
+func (m Mat3) TransformPoint(p Point) Point {
+	v := m.Transform(p.Vec2())
+	return Point{X: Round(v.X), Y: Round(v.Y)}
+}
+
+// TransformRect returns the smallest rectangle that bounds the rectangle r
+// transformed by the affine matrix m. Coordinates within a small epsilon of an
+// integer are snapped to it, so that precision errors do not grow the bounds.
+func (m Mat3) TransformRect(r Rect) Rect {
+	a := m.Transform(r.TL().Vec2())
+	b := m.Transform(r.TR().Vec2())
+	c := m.Transform(r.BL().Vec2())
+	d := m.Transform(r.BR().Vec2())
+	min := Vec2{X: Minf(a.X, b.X, c.X, d.X), Y: Minf(a.Y, b.Y, c.Y, d.Y)}
+	max := Vec2{X: Maxf(a.X, b.X, c.X, d.X), Y: Maxf(a.Y, b.Y, c.Y, d.Y)}
+	const ε = 1e-3
+	return Rect{
+		Min: Point{X: int(Floorf(min.X + ε)), Y: int(Floorf(min.Y + ε))},
+		Max: Point{X: int(Ceilf(max.X - ε)), Y: int(Ceilf(max.Y - ε))},
+	}
+}
+
 func (m Mat3) Invert() Mat3 {
 	//           ╭         ╮
 	//        1  │ C₁ ⨯ C₂ │
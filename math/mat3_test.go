@@ -21,3 +21,23 @@ func TestCreateMat3PositionToBarycentric(t *testing.T) {
 	test.AssertEquals(t, Vec3{0.0, 1.0, 1.0}, b.Vec3(1).MulM(m))
 	test.AssertEquals(t, Vec3{0.0, 0.0, 1.0}, c.Vec3(1).MulM(m))
 }
+
+func TestMat3Mul(t *testing.T) {
+	s := CreateMat3Scale(Vec2{2, 3})
+	tr := CreateMat3Translate(Vec2{10, 20})
+	test.AssertEquals(t, Vec2{12, 23}, s.Mul(tr).Transform(Vec2{1, 1}))
+	test.AssertEquals(t, Vec2{22, 63}, tr.Mul(s).Transform(Vec2{1, 1}))
+	test.AssertEquals(t, s, s.Mul(Mat3Ident))
+}
+
+func TestMat3Rotate(t *testing.T) {
+	r := CreateMat3Rotate(Pi / 2)
+	test.AssertEquals(t, Point{0, 1}, r.TransformPoint(Point{1, 0}))
+	test.AssertEquals(t, Point{-1, 0}, r.TransformPoint(Point{0, 1}))
+	test.AssertEquals(t, Point{1, 0}, r.Invert().TransformPoint(Point{0, 1}))
+}
+
+func TestMat3TransformRect(t *testing.T) {
+	r := CreateMat3Rotate(Pi / 2).Mul(CreateMat3Translate(Vec2{5, 0}))
+	test.AssertEquals(t, CreateRect(1, 1, 5, 3), r.TransformRect(CreateRect(1, 0, 3, 4)))
+}
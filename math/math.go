@@ -32,6 +32,14 @@ func Round(v float32) int {
 	}
 }
 
+func Floorf(v float32) float32 {
+	return float32(math.Floor(float64(v)))
+}
+
+func Ceilf(v float32) float32 {
+	return float32(math.Ceil(float64(v)))
+}
+
 func Sinf(v float32) float32 {
 	return float32(math.Sin(float64(v)))
 }
@@ -22,6 +22,7 @@ type BubbleOverlay struct {
 	arrowWidth  int
 	brush       gxui.Brush
 	pen         gxui.Pen
+	shadow      gxui.Shadow
 }
 
 func (o *BubbleOverlay) Init(outer BubbleOverlayOuter, theme gxui.Theme) {
@@ -81,6 +82,20 @@ func (o *BubbleOverlay) SetPen(pen gxui.Pen) {
 	}
 }
 
+// Shadow returns the shadow drawn beneath the bubble.
+func (o *BubbleOverlay) Shadow() gxui.Shadow {
+	return o.shadow
+}
+
+// SetShadow sets the shadow drawn beneath the bubble. The arrow pointing at
+// the target does not cast a shadow.
+func (o *BubbleOverlay) SetShadow(shadow gxui.Shadow) {
+	if o.shadow != shadow {
+		o.shadow = shadow
+		o.Redraw()
+	}
+}
+
 func (o *BubbleOverlay) Paint(c gxui.Canvas) {
 	if !o.IsVisible() {
 		return
@@ -167,6 +182,7 @@ func (o *BubbleOverlay) Paint(c gxui.Canvas) {
 			}
 			// fmt.Printf("D: %+v\n", p)
 		}
+		c.DrawShadow(b, 5, 5, 5, 5, o.shadow)
 		c.DrawPolygon(p, o.pen, o.brush)
 	}
 	o.PaintChildren.Paint(c)
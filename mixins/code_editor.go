@@ -5,7 +5,6 @@
 package mixins
 
 import (
-	"fmt"
 	"github.com/google/gxui"
 	"github.com/google/gxui/math"
 	"strings"
@@ -14,37 +13,59 @@ import (
 type CodeEditorOuter interface {
 	TextBoxOuter
 	CreateSuggestionList() gxui.List
+	PaintFoldMarker(c gxui.Canvas, r math.Rect, folded bool)
+	PaintFoldPlaceholder(c gxui.Canvas, r math.Rect)
+	PaintLineNumber(c gxui.Canvas, r math.Rect, number string, caretLine bool)
+	PaintLineMarker(c gxui.Canvas, r math.Rect, marker *gxui.LineMarker)
 }
 
 type CodeEditor struct {
 	TextBox
-	outer              CodeEditorOuter
-	layers             gxui.CodeSyntaxLayers
-	suggestionAdapter  *SuggestionAdapter
-	suggestionList     gxui.List
-	suggestionProvider gxui.CodeSuggestionProvider
-	tabWidth           int
-	theme              gxui.Theme
-}
-
-func (t *CodeEditor) updateSpans(edits []gxui.TextBoxEdit) {
-	runeCount := len(t.controller.TextRunes())
-	for _, l := range t.layers {
-		l.UpdateSpans(runeCount, edits)
-	}
+	outer               CodeEditorOuter
+	suggestionAdapter   *SuggestionAdapter
+	suggestionList      gxui.List
+	suggestionProvider  gxui.CodeSuggestionProvider
+	foldingProvider     gxui.FoldingProvider
+	foldRanges          []gxui.FoldRange
+	folds               codeEditorFolds
+	gutterVisible       bool
+	lineNumberMode      gxui.LineNumberMode
+	markers             []codeEditorMarker
+	onGutterClicked     gxui.Event
+	onLineMarkerClicked gxui.Event
+	onLineMarkerEnter   gxui.Event
+	onLineMarkerExit    gxui.Event
+	bracketPairs        []gxui.BracketPair
+	autoCloseBrackets   bool
+	bracketLayer        *gxui.CodeSyntaxLayer
+	bracketsDirty       bool
+	tabWidth            int
+	theme               gxui.Theme
 }
 
 func (t *CodeEditor) Init(outer CodeEditorOuter, driver gxui.Driver, theme gxui.Theme, font gxui.Font) {
 	t.outer = outer
 	t.tabWidth = 2
 	t.theme = theme
+	t.gutterVisible = true
 
 	t.suggestionAdapter = &SuggestionAdapter{}
 	t.suggestionList = t.outer.CreateSuggestionList()
 	t.suggestionList.SetAdapter(t.suggestionAdapter)
 
 	t.TextBox.Init(outer, driver, theme, font)
-	t.controller.OnTextChanged(t.updateSpans)
+
+	t.controller.SetTabWidth(t.tabWidth)
+	t.controller.OnTextChanged(t.updateFolds)
+	t.controller.OnTextChanged(t.updateMarkers)
+
+	t.bracketPairs = append([]gxui.BracketPair{}, gxui.DefaultBracketPairs...)
+	t.autoCloseBrackets = true
+	t.bracketLayer = gxui.CreateCodeSyntaxLayer()
+	t.bracketLayer.SetBorderColor(gxui.Gray70)
+	t.bracketsDirty = true
+	t.controller.OnSelectionChanged(func() { t.bracketsDirty = true })
+	t.controller.OnTextChanged(func([]gxui.TextBoxEdit) { t.bracketsDirty = true })
 
 	// Interface compliance test
 	_ = gxui.CodeEditor(t)
@@ -61,21 +82,15 @@ func (t *CodeEditor) CreateSuggestionList() gxui.List {
 	return l
 }
 
-func (t *CodeEditor) SyntaxLayers() gxui.CodeSyntaxLayers {
-	return t.layers
-}
-
-func (t *CodeEditor) SetSyntaxLayers(layers gxui.CodeSyntaxLayers) {
-	t.layers = layers
-	t.onRedrawLines.Fire()
-}
-
 func (t *CodeEditor) TabWidth() int {
 	return t.tabWidth
 }
 
+// SetTabWidth sets the number of spaces inserted by Tab, and the number of
+// columns between tab stops used by column selections.
 func (t *CodeEditor) SetTabWidth(tabWidth int) {
 	t.tabWidth = tabWidth
+	t.controller.SetTabWidth(tabWidth)
 }
 
 func (t *CodeEditor) SuggestionProvider() gxui.CodeSuggestionProvider {
@@ -120,10 +135,9 @@ func (t *CodeEditor) ShowSuggestionList() {
 	child := t.AddChild(t.suggestionList)
 
 	// Position the suggestion list below the last caret
-	lineIdx := t.controller.LineIndex(caret)
 	// TODO: What if the last caret is not visible?
 	bounds := t.Size().Rect().Contract(t.Padding())
-	line := t.Line(lineIdx)
+	line := t.rowLineControl(t.runeRow(caret))
 	lineOffset := gxui.ChildToParent(math.ZeroPoint, line, t.outer)
 	target := line.PositionAt(caret).Add(lineOffset)
 	cs := t.suggestionList.DesiredSize(math.ZeroSize, bounds.Size())
@@ -139,12 +153,31 @@ func (t *CodeEditor) HideSuggestionList() {
 }
 
 func (t *CodeEditor) Line(idx int) TextBoxLine {
-	return gxui.FindControl(t.ItemControl(idx).(gxui.Parent), func(c gxui.Control) bool {
+	return t.lineControl(t.ItemControl(idx))
+}
+
+// rowLineControl returns the TextBoxLine displaying the list row.
+func (t *CodeEditor) rowLineControl(row int) TextBoxLine {
+	return t.lineControl(t.ItemControl(t.adapter.ItemAt(row)))
+}
+
+func (t *CodeEditor) lineControl(item gxui.Control) TextBoxLine {
+	return gxui.FindControl(item.(gxui.Parent), func(c gxui.Control) bool {
 		_, b := c.(TextBoxLine)
 		return b
 	}).(TextBoxLine)
 }
 
+// WrapWidth returns the width that the text of rows is wrapped at, excluding
+// the gutter.
+func (t *CodeEditor) WrapWidth() int {
+	w := t.TextBox.WrapWidth() - t.gutterWidth()
+	if t.gutterVisible {
+		w -= foldMarkerWidth
+	}
+	return w
+}
+
 // mixins.List overrides
 func (t *CodeEditor) Click(ev gxui.MouseEvent) (consume bool) {
 	t.HideSuggestionList()
@@ -205,12 +238,54 @@ func (t *CodeEditor) KeyPress(ev gxui.KeyboardEvent) (consume bool) {
 			t.HideSuggestionList()
 			return true
 		}
+	case gxui.KeyBackspace:
+		if t.deleteBracketPairs() {
+			return true
+		}
+	case gxui.KeyM:
+		switch {
+		case ev.Modifier.Control() && ev.Modifier.Shift():
+			t.SelectToMatchingBracket()
+			t.ScrollToRune(t.controller.LastCaret())
+			return true
+		case ev.Modifier.Control():
+			t.JumpToMatchingBracket()
+			t.ScrollToRune(t.controller.FirstCaret())
+			return true
+		}
+	case gxui.KeyLeftBracket:
+		switch {
+		case ev.Modifier.Control() && ev.Modifier.Alt():
+			t.FoldAll()
+			return true
+		case ev.Modifier.Control() && ev.Modifier.Shift():
+			for _, c := range t.controller.Carets() {
+				t.Fold(t.controller.LineIndex(c))
+			}
+			return true
+		}
+	case gxui.KeyRightBracket:
+		switch {
+		case ev.Modifier.Control() && ev.Modifier.Alt():
+			t.UnfoldAll()
+			return true
+		case ev.Modifier.Control() && ev.Modifier.Shift():
+			for _, c := range t.controller.Carets() {
+				t.Unfold(t.controller.LineIndex(c))
+			}
+			return true
+		}
 	}
 	return t.TextBox.KeyPress(ev)
 }
 
 func (t *CodeEditor) KeyStroke(ev gxui.KeyStrokeEvent) (consume bool) {
-	consume = t.TextBox.KeyStroke(ev)
+	if !ev.Modifier.Control() && !ev.Modifier.Alt() && t.typeBracket(ev.Character) {
+		t.InputEventHandler.KeyStroke(ev)
+		consume = true
+	} else {
+		consume = t.TextBox.KeyStroke(ev)
+	}
 	if t.IsSuggestionListShowing() {
 		t.SortSuggestionList()
 	}
@@ -218,16 +293,36 @@ func (t *CodeEditor) KeyStroke(ev gxui.KeyStrokeEvent) (consume bool) {
 }
 
 // mixins.TextBox overrides
+func (t *CodeEditor) SetSyntaxLayers(layers gxui.CodeSyntaxLayers) {
+	t.TextBox.SetSyntaxLayers(layers)
+	t.bracketsDirty = true // The strings and comments may have changed
+}
+
 func (t *CodeEditor) CreateLine(theme gxui.Theme, index int) (TextBoxLine, gxui.Control) {
-	lineNumber := theme.CreateLabel()
-	lineNumber.SetText(fmt.Sprintf("%.4d", index+1)) // Displayed lines start at 1
+	return t.createLine(theme, index, 0)
+}
+
+// CreateWrappedLine creates the line displaying the wrapped row wrap of the
+// line with the specified index. The gutter of the row is left blank.
+func (t *CodeEditor) CreateWrappedLine(theme gxui.Theme, index, wrap int) (TextBoxLine, gxui.Control) {
+	return t.createLine(theme, index, wrap)
+}
+
+func (t *CodeEditor) createLine(theme gxui.Theme, index, wrap int) (TextBoxLine, gxui.Control) {
+	gutter := &codeEditorGutter{}
+	gutter.init(theme, t, index, wrap)
+
+	foldMarker := &codeEditorFoldMarker{}
+	foldMarker.init(theme, t, index, wrap)
 
 	line := &CodeEditorLine{}
 	line.Init(line, theme, t, index)
+	line.wrap = wrap
 
 	layout := theme.CreateLinearLayout()
 	layout.SetDirection(gxui.LeftToRight)
-	layout.AddChild(lineNumber)
+	layout.AddChild(gutter)
+	layout.AddChild(foldMarker)
 	layout.AddChild(line)
 
 	return line, layout
@@ -0,0 +1,371 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mixins
+
+import (
+	"unicode"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+)
+
+// The number of runes read at a time when searching for a matching bracket.
+const bracketScanChunk = 1024
+
+// bracketEdit is the way a bracket typed at a selection is inserted.
+type bracketEdit int
+
+const (
+	bracketPlain bracketEdit = iota // The bracket replaces the selection
+	bracketPair                     // The bracket is followed by its closer
+	bracketWrap                     // The bracket and its closer surround the selection
+)
+
+// textLayers returns the plain text syntax layers, such as strings and
+// comments, in which brackets are not matched.
+func (t *CodeEditor) textLayers() []*gxui.CodeSyntaxLayer {
+	layers := []*gxui.CodeSyntaxLayer{}
+	for _, l := range t.layers {
+		if l != nil && l.PlainText() {
+			layers = append(layers, l)
+		}
+	}
+	return layers
+}
+
+// inLayers returns true if the rune at index i is in a span of any of layers.
+func inLayers(layers []*gxui.CodeSyntaxLayer, i int) bool {
+	for _, l := range layers {
+		if l.SpanAt(i) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// runeAt returns the rune at index i, and true, or false if i is out of range.
+func (t *CodeEditor) runeAt(i int) (rune, bool) {
+	if i < 0 || i >= t.controller.TextLength() {
+		return 0, false
+	}
+	return t.controller.TextRangeRunes(i, i+1)[0], true
+}
+
+func (t *CodeEditor) isBracket(r rune) bool {
+	for _, p := range t.bracketPairs {
+		if r == p.Open || r == p.Close {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *CodeEditor) isCloser(r rune) bool {
+	for _, p := range t.bracketPairs {
+		if r == p.Close {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyPair returns true if the runes at i and i+1 are the opener and closer
+// of a bracket pair.
+func (t *CodeEditor) isEmptyPair(i int) bool {
+	if i < 0 || i+2 > t.controller.TextLength() {
+		return false
+	}
+	runes := t.controller.TextRangeRunes(i, i+2)
+	for _, p := range t.bracketPairs {
+		if runes[0] == p.Open && runes[1] == p.Close {
+			return true
+		}
+	}
+	return false
+}
+
+// caretBracket returns the index of the bracket after caret, or failing that
+// the bracket before caret, and true, or false if neither rune is a bracket.
+func (t *CodeEditor) caretBracket(caret int) (int, bool) {
+	for _, i := range []int{caret, caret - 1} {
+		if r, ok := t.runeAt(i); ok && t.isBracket(r) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// scanBracket searches from the rune index from in the direction dir (1 or -1)
+// for the bracket match that balances the brackets same, skipping the runes in
+// the ignored layers.
+func (t *CodeEditor) scanBracket(from, dir int, same, match rune, ignored []*gxui.CodeSyntaxLayer) (int, bool) {
+	length := t.controller.TextLength()
+	depth := 0
+	for i := from; i >= 0 && i < length; {
+		s, e := i, math.Min(i+bracketScanChunk, length)
+		if dir < 0 {
+			s, e = math.Max(i-bracketScanChunk+1, 0), i+1
+		}
+		runes := t.controller.TextRangeRunes(s, e)
+		for ; i >= s && i < e; i += dir {
+			switch runes[i-s] {
+			case match:
+				if !inLayers(ignored, i) {
+					if depth == 0 {
+						return i, true
+					}
+					depth--
+				}
+			case same:
+				if !inLayers(ignored, i) {
+					depth++
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+// updateBracketLayer replaces the spans of the bracket layer with the brackets
+// at the carets, and the brackets they match.
+func (t *CodeEditor) updateBracketLayer() {
+	t.bracketLayer.Clear()
+	for _, caret := range t.controller.Carets() {
+		if i, ok := t.caretBracket(caret); ok {
+			if m, ok := t.MatchingBracket(i); ok {
+				t.bracketLayer.Add(i, 1)
+				t.bracketLayer.Add(m, 1)
+			}
+		}
+	}
+	t.bracketsDirty = false
+}
+
+func (t *CodeEditor) BracketPairs() []gxui.BracketPair {
+	return append([]gxui.BracketPair{}, t.bracketPairs...)
+}
+
+func (t *CodeEditor) SetBracketPairs(pairs []gxui.BracketPair) {
+	t.bracketPairs = append([]gxui.BracketPair{}, pairs...)
+	t.bracketsDirty = true
+	t.onRedrawLines.Fire()
+}
+
+func (t *CodeEditor) AutoCloseBrackets() bool {
+	return t.autoCloseBrackets
+}
+
+// SetAutoCloseBrackets sets whether typing an opening bracket also inserts
+// its closer, typing a closer before the same closer moves over it, and
+// Backspace between an empty pair deletes both brackets.
+func (t *CodeEditor) SetAutoCloseBrackets(autoClose bool) {
+	t.autoCloseBrackets = autoClose
+}
+
+// BracketLayer returns the syntax layer holding the brackets at the carets,
+// and the brackets they match. The layer is painted with its border color.
+func (t *CodeEditor) BracketLayer() *gxui.CodeSyntaxLayer {
+	if t.bracketsDirty {
+		t.updateBracketLayer()
+	}
+	return t.bracketLayer
+}
+
+// MatchingBracket returns the index of the bracket matching the bracket at
+// runeIndex, and true, or false if there is no bracket at runeIndex or it has
+// no match. Brackets in strings and comments are not matched.
+func (t *CodeEditor) MatchingBracket(runeIndex int) (int, bool) {
+	r, ok := t.runeAt(runeIndex)
+	if !ok {
+		return 0, false
+	}
+	ignored := t.textLayers()
+	if inLayers(ignored, runeIndex) {
+		return 0, false
+	}
+	for _, p := range t.bracketPairs {
+		switch r {
+		case p.Open:
+			return t.scanBracket(runeIndex+1, 1, p.Open, p.Close, ignored)
+		case p.Close:
+			return t.scanBracket(runeIndex-1, -1, p.Close, p.Open, ignored)
+		}
+	}
+	return 0, false
+}
+
+// JumpToMatchingBracket moves each caret at a bracket to the bracket it
+// matches.
+func (t *CodeEditor) JumpToMatchingBracket() {
+	selections := gxui.TextSelectionList{}
+	for _, s := range t.controller.Selections() {
+		if i, ok := t.caretBracket(s.Caret()); ok {
+			if m, ok := t.MatchingBracket(i); ok {
+				s = gxui.CreateTextSelection(m, m, false)
+			}
+		}
+		selections = append(selections, s)
+	}
+	t.controller.SetSelections(selections)
+}
+
+// SelectToMatchingBracket selects the text from each bracket at a caret to
+// the bracket it matches, including both brackets.
+func (t *CodeEditor) SelectToMatchingBracket() {
+	selections := gxui.TextSelectionList{}
+	for _, s := range t.controller.Selections() {
+		if i, ok := t.caretBracket(s.Caret()); ok {
+			if m, ok := t.MatchingBracket(i); ok {
+				s = gxui.CreateTextSelection(math.Min(i, m), math.Max(i, m)+1, false)
+			}
+		}
+		selections = append(selections, s)
+	}
+	t.controller.SetSelections(selections)
+}
+
+// typeBracket types the bracket r at each selection, typing over closers and
+// auto-closing openers. typeBracket returns false, without typing r, if r is
+// not handled differently from other runes.
+func (t *CodeEditor) typeBracket(r rune) bool {
+	if !t.autoCloseBrackets {
+		return false
+	}
+	return t.typeOverCloser(r) || t.typeOpener(r)
+}
+
+// typeOverCloser types the closer r, moving over the closers that r is typed
+// before, rather than inserting another.
+func (t *CodeEditor) typeOverCloser(r rune) bool {
+	if !t.isCloser(r) {
+		return false
+	}
+	c := t.controller
+	selections := gxui.TextSelectionList{}
+	found := false
+	for _, s := range c.Selections() {
+		if next, ok := t.runeAt(s.Start()); ok && next == r && s.Length() == 0 {
+			s = gxui.CreateTextSelection(s.Start(), s.Start()+1, false)
+			found = true
+		}
+		selections = append(selections, s)
+	}
+	if !found {
+		return false
+	}
+	c.SetSelections(selections)
+	c.ReplaceAllRunes([]rune{r})
+	c.Deselect(false)
+	return true
+}
+
+// canAutoClose returns true if an opener typed at the caret should be closed:
+// the caret must not follow a rune of a string or comment, and must come
+// before whitespace, a closer or the end of the text.
+func (t *CodeEditor) canAutoClose(caret int, ignored []*gxui.CodeSyntaxLayer) bool {
+	if caret > 0 && inLayers(ignored, caret-1) {
+		return false
+	}
+	next, ok := t.runeAt(caret)
+	return !ok || unicode.IsSpace(next) || t.isCloser(next)
+}
+
+// typeOpener types the opener r, closing it at each empty selection where
+// canAutoClose returns true, and surrounding each selected text with the pair.
+func (t *CodeEditor) typeOpener(r rune) bool {
+	var closer rune
+	found := false
+	for _, p := range t.bracketPairs {
+		if p.Open == r {
+			closer, found = p.Close, true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+
+	c := t.controller
+	ignored := t.textLayers()
+	before := c.Selections()
+	edits := make([]bracketEdit, len(before))
+	kinds := make(map[gxui.TextSelection]bracketEdit, len(before))
+	closed := false
+	for i, s := range before {
+		switch {
+		case s.Length() > 0:
+			edits[i] = bracketWrap
+		case t.canAutoClose(s.Start(), ignored):
+			edits[i] = bracketPair
+		}
+		kinds[s] = edits[i]
+		closed = closed || edits[i] != bracketPlain
+	}
+	if !closed {
+		return false
+	}
+
+	c.ReplaceRunes(func(s gxui.TextSelection) []rune {
+		switch kinds[s] {
+		case bracketWrap:
+			return append(append([]rune{r}, c.TextRangeRunes(s.Start(), s.End())...), closer)
+		case bracketPair:
+			return []rune{r, closer}
+		default:
+			return []rune{r}
+		}
+	})
+
+	// Each selection now covers its replacement.
+	after := c.Selections()
+	if len(after) != len(edits) {
+		c.Deselect(false) // Selections were merged
+		return true
+	}
+	selections := gxui.TextSelectionList{}
+	for i, s := range after {
+		switch edits[i] {
+		case bracketWrap:
+			s = gxui.CreateTextSelection(s.Start()+1, s.End()-1, s.CaretAtStart())
+		case bracketPair:
+			s = gxui.CreateTextSelection(s.Start()+1, s.Start()+1, false)
+		default:
+			s = gxui.CreateTextSelection(s.End(), s.End(), false)
+		}
+		selections = append(selections, s)
+	}
+	c.SetSelections(selections)
+	return true
+}
+
+// deleteBracketPairs performs a backspace at each selection, deleting both
+// brackets of each empty pair that a caret is between. deleteBracketPairs
+// returns false, without deleting anything, if no caret is between a pair.
+func (t *CodeEditor) deleteBracketPairs() bool {
+	if !t.autoCloseBrackets {
+		return false
+	}
+	c := t.controller
+	selections := gxui.TextSelectionList{}
+	found := false
+	for _, s := range c.Selections() {
+		if p := s.Start(); s.Length() == 0 && p > 0 {
+			if t.isEmptyPair(p - 1) {
+				s = gxui.CreateTextSelection(p-1, p+1, false)
+				found = true
+			} else {
+				s = gxui.CreateTextSelection(p-1, p, false)
+			}
+		}
+		selections = append(selections, s)
+	}
+	if !found {
+		return false
+	}
+	c.SetSelections(selections)
+	c.ReplaceAllRunes(nil)
+	c.Deselect(false)
+	return true
+}
@@ -0,0 +1,330 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mixins
+
+import (
+	"sort"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+	"github.com/google/gxui/mixins/base"
+)
+
+// codeEditorFold is a folded range of lines, along with the runes it hides:
+// from the end of the First line, up to the end of the Last line.
+type codeEditorFold struct {
+	gxui.FoldRange
+	start, end int
+}
+
+type codeEditorFolds []codeEditorFold
+
+func (l codeEditorFolds) Len() int           { return len(l) }
+func (l codeEditorFolds) Less(i, j int) bool { return l[i].First < l[j].First }
+func (l codeEditorFolds) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+
+// foldRange returns the fold range starting at line, and true, or false if
+// no fold range starts at line.
+func (t *CodeEditor) foldRange(line int) (gxui.FoldRange, bool) {
+	i := sort.Search(len(t.foldRanges), func(i int) bool { return t.foldRanges[i].First >= line })
+	if i < len(t.foldRanges) && t.foldRanges[i].First == line {
+		return t.foldRanges[i], true
+	}
+	return gxui.FoldRange{}, false
+}
+
+// setFolds replaces the folded ranges, hiding their lines.
+func (t *CodeEditor) setFolds(folds codeEditorFolds) {
+	c := t.controller
+	for i, f := range folds {
+		folds[i].start, folds[i].end = c.LineEnd(f.First), c.LineEnd(f.Last)
+	}
+	sort.Sort(folds)
+	t.folds = folds
+
+	var lines []int
+	if len(folds) > 0 {
+		lines = []int{}
+		next := 0 // The first line that is not hidden by the folds visited
+		for _, f := range folds {
+			for ; next <= f.First; next++ {
+				lines = append(lines, next)
+			}
+			next = math.Max(next, f.Last+1)
+		}
+		for count := c.LineCount(); next < count; next++ {
+			lines = append(lines, next)
+		}
+	}
+	t.setVisibleLines(lines)
+
+	// Move any carets out of the hidden lines.
+	for _, s := range c.Selections() {
+		if c.LineHidden(c.LineIndex(s.Start())) || c.LineHidden(c.LineIndex(s.End())) {
+			c.MoveSelections(func(i int) int {
+				row, _ := t.lineRow(c.LineIndex(i))
+				if line := t.rowLine(row); line != c.LineIndex(i) {
+					return c.LineEnd(line)
+				}
+				return i
+			})
+			break
+		}
+	}
+	t.onRedrawLines.Fire()
+}
+
+// updateFolds moves the folds by the edits, and unfolds any fold that hides
+// edited runes. Folds whose first line no longer starts a fold range are also
+// unfolded. Without a folding provider there are no folds, and nothing to do.
+func (t *CodeEditor) updateFolds(edits []gxui.TextBoxEdit) {
+	if t.foldingProvider == nil {
+		return
+	}
+	folds := codeEditorFolds{}
+	if len(edits) > 0 {
+		for _, f := range t.folds {
+			keep := true
+			for _, e := range edits {
+				if e.Delta < 0 {
+					if e.At <= f.end && e.At-e.Delta > f.start {
+						keep = false
+						break
+					}
+				} else if e.At > f.start && e.At <= f.end {
+					keep = false
+					break
+				}
+				if e.At <= f.start {
+					f.start += e.Delta
+					f.end += e.Delta
+				}
+			}
+			if keep {
+				folds = append(folds, f)
+			}
+		}
+	}
+	t.refreshFolds(folds)
+}
+
+// refreshFolds fetches the fold ranges from the folding provider, and then
+// folds the ranges that start on the first lines of folds.
+func (t *CodeEditor) refreshFolds(folds codeEditorFolds) {
+	t.foldRanges = nil
+	if t.foldingProvider != nil {
+		t.foldRanges = t.foldingProvider.FoldRanges(t.controller)
+	}
+	valid := codeEditorFolds{}
+	for _, f := range folds {
+		if r, ok := t.foldRange(t.controller.LineIndex(f.start)); ok {
+			valid = append(valid, codeEditorFold{FoldRange: r})
+		}
+	}
+	if len(valid) > 0 || len(t.folds) > 0 {
+		t.setFolds(valid)
+	} else {
+		t.onRedrawLines.Fire() // The fold markers may have changed
+	}
+}
+
+func (t *CodeEditor) FoldingProvider() gxui.FoldingProvider {
+	return t.foldingProvider
+}
+
+// SetFoldingProvider sets the provider of the ranges of lines that can be
+// folded, such as gxui.IndentFoldingProvider. Folds that do not match a range
+// of the new provider are unfolded. The provider is called after every edit,
+// so folding is off by default, with a nil provider.
+func (t *CodeEditor) SetFoldingProvider(provider gxui.FoldingProvider) {
+	if t.foldingProvider != provider {
+		t.foldingProvider = provider
+		t.refreshFolds(t.folds)
+	}
+}
+
+// FoldRanges returns the ranges of lines that can be folded, as last returned
+// by the folding provider.
+func (t *CodeEditor) FoldRanges() []gxui.FoldRange {
+	return append([]gxui.FoldRange{}, t.foldRanges...)
+}
+
+// IsFolded returns true if the fold range starting at line is folded.
+func (t *CodeEditor) IsFolded(line int) bool {
+	for _, f := range t.folds {
+		if f.First == line {
+			return true
+		}
+	}
+	return false
+}
+
+// IsLineHidden returns true if line is hidden by a fold.
+func (t *CodeEditor) IsLineHidden(line int) bool {
+	_, visible := t.lineRow(line)
+	return !visible
+}
+
+// Fold folds the innermost fold range containing line that is not already
+// folded, returning false if there is no such range.
+func (t *CodeEditor) Fold(line int) bool {
+	for i := len(t.foldRanges) - 1; i >= 0; i-- {
+		r := t.foldRanges[i]
+		if r.Contains(line) && !t.IsFolded(r.First) {
+			t.setFolds(append(t.folds, codeEditorFold{FoldRange: r}))
+			return true
+		}
+	}
+	return false
+}
+
+// Unfold unfolds every folded range containing line, returning false if
+// there were none.
+func (t *CodeEditor) Unfold(line int) bool {
+	folds := codeEditorFolds{}
+	for _, f := range t.folds {
+		if !f.Contains(line) {
+			folds = append(folds, f)
+		}
+	}
+	if len(folds) == len(t.folds) {
+		return false
+	}
+	t.setFolds(folds)
+	return true
+}
+
+// ToggleFold unfolds the range starting at line if it is folded, otherwise
+// it folds the innermost range containing line.
+func (t *CodeEditor) ToggleFold(line int) {
+	if t.IsFolded(line) {
+		t.Unfold(line)
+	} else {
+		t.Fold(line)
+	}
+}
+
+// FoldAll folds every fold range.
+func (t *CodeEditor) FoldAll() {
+	folds := make(codeEditorFolds, len(t.foldRanges))
+	for i, r := range t.foldRanges {
+		folds[i].FoldRange = r
+	}
+	t.setFolds(folds)
+}
+
+// UnfoldAll unfolds every folded range.
+func (t *CodeEditor) UnfoldAll() {
+	if len(t.folds) > 0 {
+		t.setFolds(nil)
+	}
+}
+
+// PaintFoldMarker paints the marker for a line that starts a fold range, in
+// the rectangle r of the gutter.
+func (t *CodeEditor) PaintFoldMarker(c gxui.Canvas, r math.Rect, folded bool) {
+	m := r.Mid()
+	var poly gxui.Polygon
+	if folded {
+		poly = gxui.Polygon{
+			gxui.PolygonVertex{Position: m.Add(math.Point{X: -2, Y: -4})},
+			gxui.PolygonVertex{Position: m.Add(math.Point{X: 3, Y: 0})},
+			gxui.PolygonVertex{Position: m.Add(math.Point{X: -2, Y: 4})},
+		}
+	} else {
+		poly = gxui.Polygon{
+			gxui.PolygonVertex{Position: m.Add(math.Point{X: -4, Y: -2})},
+			gxui.PolygonVertex{Position: m.Add(math.Point{X: 4, Y: -2})},
+			gxui.PolygonVertex{Position: m.Add(math.Point{X: 0, Y: 3})},
+		}
+	}
+	c.DrawPolygon(poly, gxui.TransparentPen, gxui.CreateBrush(gxui.Gray50))
+}
+
+// PaintFoldPlaceholder paints the placeholder shown after the first line of a
+// folded range, in the rectangle r.
+func (t *CodeEditor) PaintFoldPlaceholder(c gxui.Canvas, r math.Rect) {
+	c.DrawRoundedRect(r, 3, 3, 3, 3, gxui.CreatePen(0.5, gxui.Gray50), gxui.Brush{Color: gxui.Gray20})
+	runes := []rune(foldPlaceholder)
+	offsets := t.font.Layout(&gxui.TextBlock{
+		Runes:     runes,
+		AlignRect: r,
+		H:         gxui.AlignCenter,
+		V:         gxui.AlignMiddle,
+	})
+	c.DrawRunes(t.font, runes, offsets, gxui.Gray70)
+}
+
+// The text drawn in the placeholder of a folded range.
+const foldPlaceholder = "..."
+
+// The width of the fold markers, when the gutter is visible.
+const foldMarkerWidth = 12
+
+// codeEditorFoldMarker is the control in the gutter of a line that shows
+// whether the line starts a fold range, and toggles the fold when clicked.
+type codeEditorFoldMarker struct {
+	base.Control
+	editor *CodeEditor
+	line   int
+	wrap   int // The wrapped row of the line, or 0 for the first row
+}
+
+func (m *codeEditorFoldMarker) init(theme gxui.Theme, editor *CodeEditor, line, wrap int) {
+	m.Control.Init(m, theme)
+	m.editor = editor
+	m.line = line
+	m.wrap = wrap
+	m.OnAttach(func() {
+		ev := editor.OnRedrawLines(m.update)
+		m.OnDetach(ev.Unlisten)
+	})
+}
+
+// update redraws the marker, relaying it out if the gutter has been shown or
+// hidden.
+func (m *codeEditorFoldMarker) update() {
+	if m.Size().W != m.width() {
+		m.Relayout()
+	}
+	m.Redraw()
+}
+
+func (m *codeEditorFoldMarker) width() int {
+	if m.editor.gutterVisible {
+		return foldMarkerWidth
+	}
+	return 0
+}
+
+func (m *codeEditorFoldMarker) DesiredSize(min, max math.Size) math.Size {
+	return math.Size{W: m.width(), H: max.H}.Clamp(min, max)
+}
+
+func (m *codeEditorFoldMarker) Paint(c gxui.Canvas) {
+	if !m.editor.gutterVisible || m.wrap > 0 {
+		return
+	}
+	if _, ok := m.editor.foldRange(m.line); ok {
+		m.editor.outer.PaintFoldMarker(c, m.Size().Rect(), m.editor.IsFolded(m.line))
+	}
+}
+
+func (m *codeEditorFoldMarker) Click(ev gxui.MouseEvent) (consume bool) {
+	if _, ok := m.editor.foldRange(m.line); ok && m.wrap == 0 && ev.Button == gxui.MouseButtonLeft {
+		m.editor.ToggleFold(m.line)
+		return true
+	}
+	return m.Control.Click(ev)
+}
+
+// DoubleClick toggles the fold again, as the second click of a double click
+// is not delivered to Click.
+func (m *codeEditorFoldMarker) DoubleClick(ev gxui.MouseEvent) (consume bool) {
+	if m.Click(ev) {
+		return true
+	}
+	return m.Control.DoubleClick(ev)
+}
@@ -0,0 +1,341 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mixins
+
+import (
+	"strconv"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+	"github.com/google/gxui/mixins/base"
+)
+
+const (
+	gutterBarWidth     = 3 // The width of the strip holding BarMarkers
+	gutterPadding      = 4 // The space to the right of the line numbers
+	gutterMinDigits    = 4 // The minimum number of digits room is made for
+	gutterMarkerMargin = 2 // The space around the icons and circles
+)
+
+// codeEditorMarker is a line marker, along with the start of its line.
+type codeEditorMarker struct {
+	marker *gxui.LineMarker
+	at     int
+}
+
+// updateMarkers moves the line markers by the edits. Text inserted at the
+// start of a marked line moves the marker with the line. Markers whose lines
+// are removed move to the line that the removed text joined.
+func (t *CodeEditor) updateMarkers(edits []gxui.TextBoxEdit) {
+	c := t.controller
+	if len(edits) == 0 {
+		t.markers = nil // The text was replaced
+		return
+	}
+	for i := range t.markers {
+		m := &t.markers[i]
+		for _, e := range edits {
+			if m.at > e.At || (m.at == e.At && e.Delta > 0) {
+				m.at = math.Max(m.at+e.Delta, e.At)
+			}
+		}
+		m.at = c.LineStart(c.LineIndex(m.at))
+	}
+}
+
+func (t *CodeEditor) GutterVisible() bool {
+	return t.gutterVisible
+}
+
+// SetGutterVisible shows or hides the gutter to the left of the lines, which
+// holds the line numbers, line markers and fold markers.
+func (t *CodeEditor) SetGutterVisible(visible bool) {
+	if t.gutterVisible != visible {
+		t.gutterVisible = visible
+		t.onRedrawLines.Fire()
+	}
+}
+
+func (t *CodeEditor) LineNumberMode() gxui.LineNumberMode {
+	return t.lineNumberMode
+}
+
+func (t *CodeEditor) SetLineNumberMode(mode gxui.LineNumberMode) {
+	if t.lineNumberMode != mode {
+		t.lineNumberMode = mode
+		t.onRedrawLines.Fire()
+	}
+}
+
+// AddLineMarker adds marker to the gutter of line. If marker was already
+// added, it is moved to line.
+func (t *CodeEditor) AddLineMarker(line int, marker *gxui.LineMarker) {
+	t.removeLineMarker(marker)
+	t.markers = append(t.markers, codeEditorMarker{marker, t.controller.LineStart(line)})
+	t.onRedrawLines.Fire()
+}
+
+func (t *CodeEditor) RemoveLineMarker(marker *gxui.LineMarker) {
+	if t.removeLineMarker(marker) {
+		t.onRedrawLines.Fire()
+	}
+}
+
+func (t *CodeEditor) removeLineMarker(marker *gxui.LineMarker) bool {
+	for i, m := range t.markers {
+		if m.marker == marker {
+			t.markers = append(t.markers[:i], t.markers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (t *CodeEditor) ClearLineMarkers() {
+	if len(t.markers) > 0 {
+		t.markers = nil
+		t.onRedrawLines.Fire()
+	}
+}
+
+// LineMarkers returns the markers of line, in the order they were added.
+func (t *CodeEditor) LineMarkers(line int) []*gxui.LineMarker {
+	markers := []*gxui.LineMarker{}
+	at := t.controller.LineStart(line)
+	for _, m := range t.markers {
+		if m.at == at {
+			markers = append(markers, m.marker)
+		}
+	}
+	return markers
+}
+
+// LineMarkerLine returns the line that marker is on, or -1 if marker has not
+// been added.
+func (t *CodeEditor) LineMarkerLine(marker *gxui.LineMarker) int {
+	for _, m := range t.markers {
+		if m.marker == marker {
+			return t.controller.LineIndex(m.at)
+		}
+	}
+	return -1
+}
+
+// OnGutterClicked subscribes f to be called when the gutter of a line is
+// clicked, including clicks on line markers.
+func (t *CodeEditor) OnGutterClicked(f func(line int, ev gxui.MouseEvent)) gxui.EventSubscription {
+	if t.onGutterClicked == nil {
+		t.onGutterClicked = gxui.CreateEvent(f)
+	}
+	return t.onGutterClicked.Listen(f)
+}
+
+func (t *CodeEditor) OnLineMarkerClicked(f func(marker *gxui.LineMarker, ev gxui.MouseEvent)) gxui.EventSubscription {
+	if t.onLineMarkerClicked == nil {
+		t.onLineMarkerClicked = gxui.CreateEvent(f)
+	}
+	return t.onLineMarkerClicked.Listen(f)
+}
+
+func (t *CodeEditor) OnLineMarkerEnter(f func(marker *gxui.LineMarker)) gxui.EventSubscription {
+	if t.onLineMarkerEnter == nil {
+		t.onLineMarkerEnter = gxui.CreateEvent(f)
+	}
+	return t.onLineMarkerEnter.Listen(f)
+}
+
+func (t *CodeEditor) OnLineMarkerExit(f func(marker *gxui.LineMarker)) gxui.EventSubscription {
+	if t.onLineMarkerExit == nil {
+		t.onLineMarkerExit = gxui.CreateEvent(f)
+	}
+	return t.onLineMarkerExit.Listen(f)
+}
+
+// lineNumberText returns the text displayed in the gutter of line.
+func (t *CodeEditor) lineNumberText(line int) string {
+	switch t.lineNumberMode {
+	case gxui.AbsoluteLineNumbers:
+		return strconv.Itoa(line + 1) // Displayed lines start at 1
+	case gxui.RelativeLineNumbers:
+		caretLine := t.controller.LineIndex(t.controller.LastCaret())
+		if line == caretLine {
+			return strconv.Itoa(line + 1)
+		}
+		row, _ := t.lineRow(line)
+		caretRow, _ := t.lineRow(caretLine)
+		if row < caretRow {
+			return strconv.Itoa(caretRow - row)
+		}
+		return strconv.Itoa(row - caretRow)
+	default:
+		return ""
+	}
+}
+
+// isCaretLine returns true if line holds a caret.
+func (t *CodeEditor) isCaretLine(line int) bool {
+	for _, c := range t.controller.Carets() {
+		if t.controller.LineIndex(c) == line {
+			return true
+		}
+	}
+	return false
+}
+
+// gutterWidth returns the width of the line numbers and line markers of the
+// gutter.
+func (t *CodeEditor) gutterWidth() int {
+	if !t.gutterVisible {
+		return 0
+	}
+	w := gutterBarWidth + t.ItemSize(t.theme).H
+	if t.lineNumberMode != gxui.NoLineNumbers {
+		digits := math.Max(len(strconv.Itoa(t.controller.LineCount())), gutterMinDigits)
+		w += digits*t.font.GlyphMaxSize().W + gutterPadding
+	}
+	return w
+}
+
+// PaintLineNumber paints the number of a line in the rectangle r of the
+// gutter. caretLine is true if the line holds a caret.
+func (t *CodeEditor) PaintLineNumber(c gxui.Canvas, r math.Rect, number string, caretLine bool) {
+	color := gxui.Gray50
+	if caretLine {
+		c.DrawRect(r, gxui.Brush{Color: gxui.Gray15})
+		color = gxui.Gray80
+	}
+	runes := []rune(number)
+	offsets := t.font.Layout(&gxui.TextBlock{
+		Runes:     runes,
+		AlignRect: r.Contract(math.Spacing{R: gutterPadding}),
+		H:         gxui.AlignRight,
+		V:         gxui.AlignMiddle,
+	})
+	c.DrawRunes(t.font, runes, offsets, color)
+}
+
+// PaintLineMarker paints marker in the rectangle r of the gutter.
+func (t *CodeEditor) PaintLineMarker(c gxui.Canvas, r math.Rect, marker *gxui.LineMarker) {
+	switch {
+	case marker.Icon != nil:
+		c.DrawTexture(marker.Icon, r)
+	case marker.Shape == gxui.BarMarker:
+		c.DrawRect(r, gxui.Brush{Color: marker.Color})
+	default:
+		radius := float32(math.Min(r.W(), r.H())) / 2
+		c.DrawRoundedRect(r, radius, radius, radius, radius, gxui.TransparentPen, gxui.Brush{Color: marker.Color})
+	}
+}
+
+// codeEditorGutter is the control in the gutter of a line that displays the
+// line's number and markers.
+type codeEditorGutter struct {
+	base.Control
+	editor  *CodeEditor
+	line    int
+	wrap    int // The wrapped row of the line, or 0 for the first row
+	hovered *gxui.LineMarker
+}
+
+func (g *codeEditorGutter) init(theme gxui.Theme, editor *CodeEditor, line, wrap int) {
+	g.Control.Init(g, theme)
+	g.editor = editor
+	g.line = line
+	g.wrap = wrap
+	g.OnAttach(func() {
+		ev := editor.OnRedrawLines(g.update)
+		g.OnDetach(ev.Unlisten)
+	})
+}
+
+// update redraws the gutter, relaying it out if its width has changed, such
+// as when the number of digits in the line count changes.
+func (g *codeEditorGutter) update() {
+	if g.Size().W != g.editor.gutterWidth() {
+		g.Relayout()
+	}
+	g.Redraw()
+}
+
+// markerRect returns the rectangle that marker is painted in.
+func (g *codeEditorGutter) markerRect(marker *gxui.LineMarker) math.Rect {
+	h := g.Size().H
+	if marker.Shape == gxui.BarMarker && marker.Icon == nil {
+		return math.CreateRect(0, 0, gutterBarWidth, h)
+	}
+	return math.CreateRect(gutterBarWidth, 0, gutterBarWidth+h, h).Contract(math.CreateSpacing(gutterMarkerMargin))
+}
+
+// markerAt returns the topmost marker painted at p, or nil if there is none.
+// Markers are only painted on the first row of a wrapped line.
+func (g *codeEditorGutter) markerAt(p math.Point) *gxui.LineMarker {
+	if g.wrap > 0 {
+		return nil
+	}
+	markers := g.editor.LineMarkers(g.line)
+	for i := len(markers) - 1; i >= 0; i-- {
+		if g.markerRect(markers[i]).Expand(math.CreateSpacing(gutterMarkerMargin)).Contains(p) {
+			return markers[i]
+		}
+	}
+	return nil
+}
+
+func (g *codeEditorGutter) setHovered(marker *gxui.LineMarker) {
+	if g.hovered == marker {
+		return
+	}
+	if g.hovered != nil && g.editor.onLineMarkerExit != nil {
+		g.editor.onLineMarkerExit.Fire(g.hovered)
+	}
+	g.hovered = marker
+	if marker != nil && g.editor.onLineMarkerEnter != nil {
+		g.editor.onLineMarkerEnter.Fire(marker)
+	}
+}
+
+func (g *codeEditorGutter) DesiredSize(min, max math.Size) math.Size {
+	return math.Size{W: g.editor.gutterWidth(), H: max.H}.Clamp(min, max)
+}
+
+func (g *codeEditorGutter) Paint(c gxui.Canvas) {
+	if !g.editor.gutterVisible || g.wrap > 0 {
+		return
+	}
+	r := g.Size().Rect()
+	if g.editor.lineNumberMode != gxui.NoLineNumbers {
+		numbers := r.Contract(math.Spacing{L: gutterBarWidth + r.H()})
+		g.editor.outer.PaintLineNumber(c, numbers, g.editor.lineNumberText(g.line), g.editor.isCaretLine(g.line))
+	}
+	for _, m := range g.editor.LineMarkers(g.line) {
+		g.editor.outer.PaintLineMarker(c, g.markerRect(m), m)
+	}
+}
+
+func (g *codeEditorGutter) MouseMove(ev gxui.MouseEvent) {
+	g.setHovered(g.markerAt(ev.Point))
+	g.Control.MouseMove(ev)
+}
+
+func (g *codeEditorGutter) MouseExit(ev gxui.MouseEvent) {
+	g.setHovered(nil)
+	g.Control.MouseExit(ev)
+}
+
+func (g *codeEditorGutter) Click(ev gxui.MouseEvent) (consume bool) {
+	if m := g.markerAt(ev.Point); m != nil && g.editor.onLineMarkerClicked != nil {
+		g.editor.onLineMarkerClicked.Fire(m, ev)
+	}
+	if g.editor.onGutterClicked != nil {
+		g.editor.onGutterClicked.Fire(g.line, ev)
+	}
+	return true
+}
+
+// DoubleClick is handled as another click, as the second click of a double
+// click is not delivered to Click.
+func (g *codeEditorGutter) DoubleClick(ev gxui.MouseEvent) (consume bool) {
+	return g.Click(ev)
+}
@@ -24,6 +24,7 @@ type CodeEditorLineOuter interface {
 	PaintBackgroundSpans(c gxui.Canvas, info CodeEditorLinePaintInfo)
 	PaintGlyphs(c gxui.Canvas, info CodeEditorLinePaintInfo)
 	PaintBorders(c gxui.Canvas, info CodeEditorLinePaintInfo)
+	PaintFoldPlaceholder(c gxui.Canvas)
 }
 
 // CodeEditorLine
@@ -84,28 +85,47 @@ func (t *CodeEditorLine) PaintGlyphs(c gxui.Canvas, info CodeEditorLinePaintInfo
 }
 
 func (t *CodeEditorLine) PaintBorders(c gxui.Canvas, info CodeEditorLinePaintInfo) {
+	for _, l := range t.ce.layers {
+		t.paintBorder(c, info, l)
+	}
+	t.paintBorder(c, info, t.ce.BracketLayer())
+}
+
+func (t *CodeEditorLine) paintBorder(c gxui.Canvas, info CodeEditorLinePaintInfo, l *gxui.CodeSyntaxLayer) {
+	if l.BorderColor() == nil {
+		return
+	}
 	start, _ := info.LineSpan.Span()
 	offsets := info.GlyphOffsets
-	for _, l := range t.ce.layers {
-		if l.BorderColor() != nil {
-			color := *l.BorderColor()
-			interval.Visit(l.Spans(), info.LineSpan, func(vs, ve uint64, _ int) {
-				s, e := vs-start, ve-start
-				r := math.CreateRect(offsets[s].X, 0, offsets[e-1].X+info.GlyphWidth, info.LineHeight)
-				c.DrawRoundedRect(r, 3, 3, 3, 3, gxui.CreatePen(0.5, color), gxui.TransparentBrush)
-			})
-		}
+	color := *l.BorderColor()
+	interval.Visit(l.Spans(), info.LineSpan, func(vs, ve uint64, _ int) {
+		s, e := vs-start, ve-start
+		r := math.CreateRect(offsets[s].X, 0, offsets[e-1].X+info.GlyphWidth, info.LineHeight)
+		c.DrawRoundedRect(r, 3, 3, 3, 3, gxui.CreatePen(0.5, color), gxui.TransparentBrush)
+	})
+}
+
+// PaintFoldPlaceholder paints the editor's fold placeholder after the end of
+// the line, if the line starts a folded range. The placeholder is painted on
+// the last row of a wrapped line.
+func (t *CodeEditorLine) PaintFoldPlaceholder(c gxui.Canvas) {
+	if !t.ce.IsFolded(t.lineIndex) || !t.holdsRune(t.ce.controller.LineEnd(t.lineIndex)) {
+		return
 	}
+	start, end := t.rowRange()
+	x := t.rowOffset() + t.outer.MeasureRunes(start, end).W + t.caretWidth
+	size := t.ce.font.Measure(&gxui.TextBlock{Runes: []rune(foldPlaceholder)})
+	r := math.CreateRect(x, 0, x+size.W+t.ce.font.GlyphMaxSize().W, t.Size().H)
+	t.ce.outer.PaintFoldPlaceholder(c, r)
 }
 
 // DefaultTextBoxLine overrides
 func (t *CodeEditorLine) Paint(c gxui.Canvas) {
 	font := t.ce.font
-	rect := t.Size().Rect().OffsetX(t.caretWidth)
+	rect := t.Size().Rect().OffsetX(t.rowOffset())
 	controller := t.ce.controller
-	runes := controller.LineRunes(t.lineIndex)
-	start := controller.LineStart(t.lineIndex)
-	end := controller.LineEnd(t.lineIndex)
+	start, end := t.rowRange()
+	runes := controller.TextRangeRunes(start, end)
 
 	if start != end {
 		lineSpan := interval.CreateIntData(start, end, nil)
@@ -143,8 +163,10 @@ func (t *CodeEditorLine) Paint(c gxui.Canvas) {
 		t.outer.PaintBorders(c, info)
 	}
 
+	t.outer.PaintFoldPlaceholder(c)
+
 	// Carets
-	if t.textbox.HasFocus() {
+	if t.textbox.HasFocus() && t.textbox.CaretVisible() {
 		t.outer.PaintCarets(c)
 	}
 }
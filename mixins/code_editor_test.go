@@ -0,0 +1,397 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mixins_test
+
+import test "github.com/google/gxui/testing"
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/automation"
+	"github.com/google/gxui/drivers/soft"
+	"github.com/google/gxui/math"
+	"github.com/google/gxui/mixins"
+	"github.com/google/gxui/themes/dark"
+)
+
+const foldSource = `a {
+  b {
+    c
+  }
+  d
+}
+e`
+
+// runEditor calls f with an Automation for a window holding a focused code
+// editor with the given text.
+func runEditor(text string, f func(*automation.Automation, *dark.CodeEditor)) {
+	soft.StartDriver(func(driver gxui.Driver) {
+		theme := dark.CreateTheme(driver)
+		window := theme.CreateWindow(300, 300, "test")
+		editor := theme.CreateCodeEditor().(*dark.CodeEditor)
+		editor.SetMultiline(true)
+		editor.SetText(text)
+		window.AddChild(editor)
+		a := automation.Create(driver, window)
+		go func() {
+			defer driver.Terminate()
+			a.WaitIdle()
+			a.Focus(editor)
+			f(a, editor)
+		}()
+	})
+}
+
+// visibleLines returns the lines displayed by the editor.
+func visibleLines(editor *dark.CodeEditor) []int {
+	lines := []int{}
+	adapter := editor.Adapter()
+	for i, c := 0, adapter.Count(); i < c; i++ {
+		lines = append(lines, adapter.ItemAt(i).(int))
+	}
+	return lines
+}
+
+func TestCodeEditorFolding(t *testing.T) {
+	runEditor(foldSource, func(a *automation.Automation, editor *dark.CodeEditor) {
+		c := editor.Controller()
+		a.Do(func() {
+			// Folding is off until there is a folding provider.
+			test.AssertEquals(t, []gxui.FoldRange{}, editor.FoldRanges())
+			test.AssertEquals(t, false, editor.Fold(2))
+			editor.SetFoldingProvider(gxui.IndentFoldingProvider{})
+			test.AssertEquals(t, []gxui.FoldRange{{First: 0, Last: 4}, {First: 1, Last: 2}}, editor.FoldRanges())
+			test.AssertEquals(t, true, editor.Fold(2))
+			test.AssertEquals(t, true, editor.IsFolded(1))
+			test.AssertEquals(t, true, editor.IsLineHidden(2))
+			test.AssertEquals(t, []int{0, 1, 3, 4, 5, 6}, visibleLines(editor))
+
+			// Carets skip the hidden line.
+			c.SetCaret(c.LineEnd(1))
+			c.MoveDown()
+			test.AssertEquals(t, c.LineStart(3)+3, c.FirstCaret())
+			c.MoveUp()
+			test.AssertEquals(t, c.LineStart(1)+3, c.FirstCaret())
+			c.SetCaret(c.LineEnd(1))
+			c.MoveRight()
+			test.AssertEquals(t, c.LineStart(3), c.FirstCaret())
+
+			// Folding a range containing the caret moves the caret out of it.
+			c.SetCaret(c.LineStart(4))
+			editor.FoldAll()
+			test.AssertEquals(t, []int{0, 5, 6}, visibleLines(editor))
+			test.AssertEquals(t, c.LineEnd(0), c.FirstCaret())
+			editor.ScrollToLine(3)
+			test.AssertEquals(t, true, editor.Unfold(3))
+			test.AssertEquals(t, false, editor.IsFolded(0))
+			test.AssertEquals(t, true, editor.IsFolded(1))
+
+			// Edits before a fold move it, and edits inside a fold unfold it.
+			c.SetCaret(0)
+			c.ReplaceAll("z\n")
+			test.AssertEquals(t, true, editor.IsFolded(2))
+			test.AssertEquals(t, []int{0, 1, 2, 4, 5, 6, 7}, visibleLines(editor))
+			c.ReplaceRange(c.LineStart(3), c.LineStart(3), []rune("y"))
+			test.AssertEquals(t, false, editor.IsFolded(2))
+			test.AssertEquals(t, 8, len(visibleLines(editor)))
+
+			c.SetText(foldSource)
+		})
+
+		// Keyboard and gutter commands.
+		a.Do(func() { c.SetCaret(c.LineStart(2)) })
+		a.KeyPress(gxui.KeyLeftBracket, gxui.ModControl|gxui.ModShift)
+		a.Do(func() { test.AssertEquals(t, true, editor.IsFolded(1)) })
+		a.KeyPress(gxui.KeyLeftBracket, gxui.ModControl|gxui.ModAlt)
+		a.Do(func() { test.AssertEquals(t, []int{0, 5, 6}, visibleLines(editor)) })
+		a.KeyPress(gxui.KeyRightBracket, gxui.ModControl|gxui.ModAlt)
+		a.Do(func() { test.AssertEquals(t, 7, len(visibleLines(editor))) })
+
+		var marker gxui.Control
+		a.Do(func() {
+			marker = automation.FindAll(editor.ItemControl(1).(gxui.Parent), automation.Type("codeEditorFoldMarker"))[0]
+		})
+		a.Click(marker)
+		a.Do(func() { test.AssertEquals(t, true, editor.IsFolded(1)) })
+		a.Click(marker)
+		a.Do(func() { test.AssertEquals(t, false, editor.IsFolded(1)) })
+	})
+}
+
+func TestCodeEditorGutter(t *testing.T) {
+	runEditor(foldSource, func(a *automation.Automation, editor *dark.CodeEditor) {
+		c := editor.Controller()
+		breakpoint := &gxui.LineMarker{Color: gxui.Red}
+		change := &gxui.LineMarker{Shape: gxui.BarMarker, Color: gxui.Green}
+		a.Do(func() {
+			editor.AddLineMarker(2, breakpoint)
+			editor.AddLineMarker(4, change)
+			test.AssertEquals(t, []*gxui.LineMarker{breakpoint}, editor.LineMarkers(2))
+
+			// Markers move with their lines, and join the line that removed
+			// lines are joined to.
+			c.SetCaret(0)
+			c.ReplaceAll("z\n")
+			test.AssertEquals(t, 3, editor.LineMarkerLine(breakpoint))
+			test.AssertEquals(t, 5, editor.LineMarkerLine(change))
+			c.SetSelection(gxui.CreateTextSelection(c.LineEnd(2), c.LineEnd(4), false))
+			c.ReplaceAll("")
+			test.AssertEquals(t, 2, editor.LineMarkerLine(breakpoint))
+			test.AssertEquals(t, 3, editor.LineMarkerLine(change))
+			editor.AddLineMarker(3, breakpoint)
+			test.AssertEquals(t, []*gxui.LineMarker{change, breakpoint}, editor.LineMarkers(3))
+			editor.RemoveLineMarker(change)
+			test.AssertEquals(t, -1, editor.LineMarkerLine(change))
+		})
+
+		clicked, markerClicked, entered, exited := -1, (*gxui.LineMarker)(nil), (*gxui.LineMarker)(nil), (*gxui.LineMarker)(nil)
+		editor.OnGutterClicked(func(line int, ev gxui.MouseEvent) { clicked = line })
+		editor.OnLineMarkerClicked(func(m *gxui.LineMarker, ev gxui.MouseEvent) { markerClicked = m })
+		editor.OnLineMarkerEnter(func(m *gxui.LineMarker) { entered = m })
+		editor.OnLineMarkerExit(func(m *gxui.LineMarker) { exited = m })
+
+		var gutter gxui.Control
+		var marker math.Point
+		a.Do(func() {
+			gutter = automation.FindAll(editor.ItemControl(3).(gxui.Parent), automation.Type("codeEditorGutter"))[0]
+		})
+		a.Click(gutter)
+		a.Do(func() {
+			test.AssertEquals(t, 3, clicked)
+			test.AssertEquals(t, (*gxui.LineMarker)(nil), markerClicked)
+			p := gxui.ChildToParent(math.ZeroPoint, gutter, a.Window())
+			marker = p.Add(math.Point{X: 3 + gutter.Size().H/2, Y: gutter.Size().H / 2})
+		})
+		a.MoveTo(marker)
+		a.Do(func() { test.AssertEquals(t, breakpoint, entered) })
+		a.ClickAt(marker, gxui.MouseButtonLeft, 0)
+		a.Do(func() { test.AssertEquals(t, breakpoint, markerClicked) })
+		a.MoveTo(a.Center(gutter))
+		a.Do(func() { test.AssertEquals(t, breakpoint, exited) })
+
+		// Pressing enter at the start of a marked line moves the marker with
+		// the line.
+		a.Do(func() { c.SetCaret(c.LineStart(3)) })
+		a.KeyPress(gxui.KeyEnter, 0)
+		a.Do(func() { test.AssertEquals(t, 4, editor.LineMarkerLine(breakpoint)) })
+
+		// The gutter is resized when the line count gains a digit, and when it
+		// is hidden.
+		var width int
+		a.Do(func() {
+			width = gutter.Size().W
+			c.SetCaret(c.TextLength())
+			c.ReplaceAll(strings.Repeat("\n", 10000))
+		})
+		a.Do(func() {
+			test.AssertEquals(t, width+editor.Font().GlyphMaxSize().W, gutter.Size().W)
+			editor.SetGutterVisible(false)
+		})
+		a.Do(func() { test.AssertEquals(t, 0, gutter.Size().W) })
+	})
+}
+
+func TestCodeEditorBrackets(t *testing.T) {
+	source := `f(a, "(", [b]) // )`
+	runEditor(source, func(a *automation.Automation, editor *dark.CodeEditor) {
+		c := editor.Controller()
+		a.Do(func() {
+			// Brackets in strings and comments are not matched.
+			str, comment := gxui.CreateCodeSyntaxLayer(), gxui.CreateCodeSyntaxLayer()
+			str.SetPlainText(true)
+			str.Add(strings.Index(source, `"`), 3)
+			comment.SetPlainText(true)
+			comment.Add(strings.Index(source, "//"), 4)
+			editor.SetSyntaxLayers(gxui.CodeSyntaxLayers{str, comment})
+
+			close := strings.Index(source, " //") - 1
+			m, ok := editor.MatchingBracket(1)
+			test.AssertEquals(t, close, m)
+			test.AssertEquals(t, true, ok)
+			_, ok = editor.MatchingBracket(strings.Index(source, `"`) + 1)
+			test.AssertEquals(t, false, ok)
+
+			c.SetCaret(close + 1)
+			layer := editor.BracketLayer()
+			test.AssertEquals(t, 2, len(layer.Spans()))
+			test.AssertEquals(t, true, layer.SpanAt(1) != nil)
+			test.AssertEquals(t, true, layer.SpanAt(close) != nil)
+			editor.JumpToMatchingBracket()
+			test.AssertEquals(t, 1, c.FirstCaret())
+			editor.JumpToMatchingBracket()
+			test.AssertEquals(t, close, c.FirstCaret())
+			c.SetCaret(strings.Index(source, "["))
+			editor.SelectToMatchingBracket()
+			test.AssertEquals(t, "[b]", c.SelectionText(0))
+
+			c.SetText("x\ny")
+			c.SetSelections(gxui.TextSelectionList{
+				gxui.CreateTextSelection(1, 1, false),
+				gxui.CreateTextSelection(3, 3, false),
+			})
+		})
+
+		// Openers are closed at every caret, and typed closers move over them.
+		a.Type("(")
+		a.Do(func() {
+			test.AssertEquals(t, "x()\ny()", c.Text())
+			test.AssertEquals(t, []int{2, 6}, c.Carets())
+		})
+		a.Type("a)")
+		a.Do(func() {
+			test.AssertEquals(t, "x(a)\ny(a)", c.Text())
+			test.AssertEquals(t, []int{4, 9}, c.Carets())
+		})
+		a.KeyPress(gxui.KeyBackspace, 0)
+		a.Do(func() {
+			test.AssertEquals(t, "x(a\ny(a", c.Text())
+			c.SetText("x()\ny()")
+			c.SetSelections(gxui.TextSelectionList{
+				gxui.CreateTextSelection(2, 2, false),
+				gxui.CreateTextSelection(6, 6, false),
+			})
+		})
+		a.KeyPress(gxui.KeyBackspace, 0)
+		a.Do(func() {
+			test.AssertEquals(t, "x\ny", c.Text())
+			test.AssertEquals(t, []int{1, 3}, c.Carets())
+
+			// Selections are surrounded, and openers before words are not closed.
+			c.SetSelection(gxui.CreateTextSelection(0, 1, false))
+		})
+		a.Type("[")
+		a.Do(func() {
+			test.AssertEquals(t, "[x]\ny", c.Text())
+			test.AssertEquals(t, "x", c.SelectionText(0))
+			c.SetCaret(c.LineStart(1))
+		})
+		a.Type("{")
+		a.Do(func() {
+			test.AssertEquals(t, "[x]\n{y", c.Text())
+			c.SetCaret(0)
+		})
+		a.KeyPress(gxui.KeyM, gxui.ModControl)
+		a.Do(func() { test.AssertEquals(t, 2, c.FirstCaret()) })
+		a.KeyPress(gxui.KeyM, gxui.ModControl|gxui.ModShift)
+		a.Do(func() { test.AssertEquals(t, "[x]", c.SelectionText(0)) })
+	})
+}
+
+func TestCodeEditorSoftWrap(t *testing.T) {
+	runEditor("    aaaa bbbb cccc\nx", func(a *automation.Automation, editor *dark.CodeEditor) {
+		c := editor.Controller()
+		adapter := editor.Adapter()
+		a.Do(func() {
+			editor.SetWrapColumn(10)
+			editor.SetSoftWrap(true)
+			// Wrapped rows after the first are indented by the line's indent:
+			// "    aaaa ", "    bbbb " and "    cccc".
+			test.AssertEquals(t, 4, adapter.Count())
+			line, wrap := c.LineAndWrap(c.LineStart(0) + 14)
+			test.AssertEquals(t, 0, line)
+			test.AssertEquals(t, 2, wrap)
+
+			c.SetCaret(6)
+			c.MoveDown()
+			test.AssertEquals(t, 11, c.FirstCaret())
+			c.MoveDown()
+			c.MoveDown()
+			test.AssertEquals(t, c.LineEnd(1), c.FirstCaret())
+			c.MoveUp()
+			test.AssertEquals(t, 14, c.FirstCaret())
+		})
+		a.Do(func() {
+			// Runes are found at the row displaying them.
+			line := editor.ItemControl(adapter.ItemAt(1)).(gxui.Parent)
+			text := automation.FindAll(line, automation.Type("CodeEditorLine"))[0].(mixins.TextBoxLine)
+			p := text.PositionAt(11)
+			test.AssertEquals(t, 11, text.RuneIndexAt(p.AddX(1)))
+
+			// Edits rewrap the line, and wrapping is undone when disabled.
+			c.SetText("aaaa bbbb cccc dddd")
+			test.AssertEquals(t, 2, adapter.Count())
+			editor.SetSoftWrap(false)
+			test.AssertEquals(t, 1, adapter.Count())
+
+			// Without a wrap column, lines are wrapped at the editor's width.
+			editor.SetWrapColumn(0)
+			editor.SetSoftWrap(true)
+			c.SetText(strings.Repeat("word ", 100))
+		})
+		a.Do(func() { test.AssertEquals(t, true, adapter.Count() > 1) })
+		a.Do(func() {
+			// Edits rewrap the lines they touch, and move the wraps of the lines
+			// after them.
+			editor.SetWrapColumn(10)
+			c.SetText("x\n    aaaa bbbb cccc\ny")
+			test.AssertEquals(t, 5, adapter.Count())
+			c.ReplaceRange(0, 1, []rune("x\nz"))
+			test.AssertEquals(t, 6, adapter.Count())
+			line, wrap := c.LineAndWrap(c.LineStart(2) + 14)
+			test.AssertEquals(t, 2, line)
+			test.AssertEquals(t, 2, wrap)
+
+			// Replacing the runes of several lines with as many runes.
+			c.ReplaceRange(0, 3, []rune("x z"))
+			test.AssertEquals(t, 5, adapter.Count())
+			line, wrap = c.LineAndWrap(c.LineStart(1) + 14)
+			test.AssertEquals(t, 1, line)
+			test.AssertEquals(t, 2, wrap)
+
+			c.ReplaceRange(c.LineStart(1), c.LineEnd(1), []rune("short"))
+			test.AssertEquals(t, 3, adapter.Count())
+		})
+	})
+}
+
+func TestCodeEditorColumnSelection(t *testing.T) {
+	runEditor("abcd\nx\n\tyz", func(a *automation.Automation, editor *dark.CodeEditor) {
+		c := editor.Controller()
+		a.Do(func() {
+			editor.SetTabWidth(4)
+			c.SetCaret(2)
+		})
+		a.KeyPress(gxui.KeyDown, gxui.ModAlt|gxui.ModShift)
+		a.KeyPress(gxui.KeyDown, gxui.ModAlt|gxui.ModShift)
+		a.KeyPress(gxui.KeyRight, gxui.ModAlt|gxui.ModShift)
+		a.KeyPress(gxui.KeyRight, gxui.ModAlt|gxui.ModShift)
+		a.Do(func() {
+			test.AssertEquals(t, 3, c.SelectionCount())
+			test.AssertEquals(t, true, c.ColumnSelected())
+		})
+
+		// Copy joins the columns of each line, including the empty ones, and
+		// paste puts each copied line at a caret.
+		a.KeyPress(gxui.KeyC, gxui.ModControl)
+		a.Do(func() {
+			c.SetText("1\n2\n3")
+			c.SetSelections(gxui.TextSelectionList{
+				gxui.CreateTextSelection(1, 1, false),
+				gxui.CreateTextSelection(3, 3, false),
+				gxui.CreateTextSelection(5, 5, false),
+			})
+		})
+		a.KeyPress(gxui.KeyV, gxui.ModControl)
+		a.Do(func() { test.AssertEquals(t, "1cd\n2\n3\t", c.Text()) })
+
+		// With several carets, Shift+Alt+Up adds a caret above each of them.
+		a.Do(func() {
+			c.SetText("a\nb\nc\nd")
+			c.SetSelections(gxui.TextSelectionList{
+				gxui.CreateTextSelection(4, 4, false),
+				gxui.CreateTextSelection(6, 6, false),
+			})
+		})
+		a.KeyPress(gxui.KeyUp, gxui.ModAlt|gxui.ModShift)
+		a.Do(func() {
+			test.AssertEquals(t, gxui.TextSelectionList{
+				gxui.CreateTextSelection(2, 2, false),
+				gxui.CreateTextSelection(4, 4, false),
+				gxui.CreateTextSelection(6, 6, false),
+			}, c.Selections())
+		})
+	})
+}
@@ -15,18 +15,23 @@ type DefaultTextBoxLineOuter interface {
 	base.ControlOuter
 	MeasureRunes(s, e int) math.Size
 	PaintText(c gxui.Canvas)
+	PaintSyntaxLayers(c gxui.Canvas)
 	PaintCarets(c gxui.Canvas)
 	PaintCaret(c gxui.Canvas, top, bottom math.Point)
 	PaintSelections(c gxui.Canvas)
 	PaintSelection(c gxui.Canvas, top, bottom math.Point)
 }
 
+// The default width of the caret, in pixels.
+const defaultCaretWidth = 2
+
 // DefaultTextBoxLine
 type DefaultTextBoxLine struct {
 	base.Control
 	outer      DefaultTextBoxLineOuter
 	textbox    *TextBox
 	lineIndex  int
+	wrap       int // The wrapped row of the line displayed, or 0 for the first row
 	caretWidth int
 }
 
@@ -35,7 +40,7 @@ func (t *DefaultTextBoxLine) Init(outer DefaultTextBoxLineOuter, theme gxui.Them
 	t.outer = outer
 	t.textbox = textbox
 	t.lineIndex = lineIndex
-	t.SetCaretWidth(2)
+	t.SetCaretWidth(defaultCaretWidth)
 	t.OnAttach(func() {
 		ev := t.textbox.OnRedrawLines(t.Redraw)
 		t.OnDetach(ev.Unlisten)
@@ -51,18 +56,56 @@ func (t *DefaultTextBoxLine) SetCaretWidth(width int) {
 	}
 }
 
+// rowRange returns the range of runes displayed by the line's row. Runes at
+// the end of a wrapped row are displayed by the next row.
+func (t *DefaultTextBoxLine) rowRange() (s, e int) {
+	controller := t.textbox.controller
+	s, e = controller.LineStart(t.lineIndex), controller.LineEnd(t.lineIndex)
+	wraps := controller.LineWraps(t.lineIndex)
+	if t.wrap > len(wraps) {
+		return e, e // The line has been rewrapped into fewer rows
+	}
+	if t.wrap > 0 {
+		s = wraps[t.wrap-1]
+	}
+	if t.wrap < len(wraps) {
+		e = wraps[t.wrap]
+	}
+	return s, e
+}
+
+// holdsRune returns true if the rune index i is displayed by the line's row.
+func (t *DefaultTextBoxLine) holdsRune(i int) bool {
+	s, e := t.rowRange()
+	return (i >= s && i < e) || (i == e && e == t.textbox.controller.LineEnd(t.lineIndex))
+}
+
+// rowOffset returns the x offset of the first rune of the line's row. Wrapped
+// rows after the first are indented by the line's indent.
+func (t *DefaultTextBoxLine) rowOffset() int {
+	x := t.caretWidth
+	if t.wrap > 0 {
+		controller := t.textbox.controller
+		ls := controller.LineStart(t.lineIndex)
+		x += t.outer.MeasureRunes(ls, ls+controller.LineIndent(t.lineIndex)).W
+	}
+	return x
+}
+
 func (t *DefaultTextBoxLine) DesiredSize(min, max math.Size) math.Size {
 	return max
 }
 
 func (t *DefaultTextBoxLine) Paint(c gxui.Canvas) {
+	t.outer.PaintSyntaxLayers(c)
+
 	if t.textbox.HasFocus() {
 		t.outer.PaintSelections(c)
 	}
 
 	t.outer.PaintText(c)
 
-	if t.textbox.HasFocus() {
+	if t.textbox.HasFocus() && t.textbox.CaretVisible() {
 		t.outer.PaintCarets(c)
 	}
 }
@@ -70,31 +113,62 @@ func (t *DefaultTextBoxLine) Paint(c gxui.Canvas) {
 func (t *DefaultTextBoxLine) MeasureRunes(s, e int) math.Size {
 	controller := t.textbox.controller
 	return t.textbox.font.Measure(&gxui.TextBlock{
-		Runes: controller.TextRunes()[s:e],
+		Runes: controller.TextRangeRunes(s, e),
 	})
 }
 
 func (t *DefaultTextBoxLine) PaintText(c gxui.Canvas) {
-	runes := []rune(t.textbox.controller.Line(t.lineIndex))
+	rs, re := t.rowRange()
+	runes := t.textbox.controller.TextRangeRunes(rs, re)
 	f := t.textbox.font
 	offsets := f.Layout(&gxui.TextBlock{
 		Runes:     runes,
-		AlignRect: t.Size().Rect().OffsetX(t.caretWidth),
+		AlignRect: t.Size().Rect().OffsetX(t.rowOffset()),
 		H:         gxui.AlignLeft,
 		V:         gxui.AlignBottom,
 	})
 	c.DrawRunes(f, runes, offsets, t.textbox.textColor)
 }
 
+// PaintSyntaxLayers paints the background and border colors of the text box's
+// syntax layers. The text colors of the layers are ignored.
+func (t *DefaultTextBoxLine) PaintSyntaxLayers(c gxui.Canvas) {
+	rs, re := t.rowRange()
+	if rs == re || len(t.textbox.layers) == 0 {
+		return
+	}
+	rowSpan := interval.CreateIntData(rs, re, nil)
+	h := t.Size().H
+	offset := t.rowOffset()
+	rect := func(s, e uint64) math.Rect {
+		x := t.outer.MeasureRunes(rs, int(s)).W
+		w := t.outer.MeasureRunes(int(s), int(e)).W
+		return math.CreateRect(offset+x, 0, offset+x+w, h)
+	}
+	for _, l := range t.textbox.layers {
+		if l.BackgroundColor() != nil {
+			brush := gxui.Brush{Color: *l.BackgroundColor()}
+			interval.Visit(l.Spans(), rowSpan, func(s, e uint64, _ int) {
+				c.DrawRoundedRect(rect(s, e), 3, 3, 3, 3, gxui.TransparentPen, brush)
+			})
+		}
+		if l.BorderColor() != nil {
+			pen := gxui.CreatePen(0.5, *l.BorderColor())
+			interval.Visit(l.Spans(), rowSpan, func(s, e uint64, _ int) {
+				c.DrawRoundedRect(rect(s, e), 3, 3, 3, 3, pen, gxui.TransparentBrush)
+			})
+		}
+	}
+}
+
 func (t *DefaultTextBoxLine) PaintCarets(c gxui.Canvas) {
 	controller := t.textbox.controller
+	rs, _ := t.rowRange()
 	for i, cnt := 0, controller.SelectionCount(); i < cnt; i++ {
 		e := controller.Caret(i)
-		l := controller.LineIndex(e)
-		if l == t.lineIndex {
-			s := controller.LineStart(l)
-			m := t.outer.MeasureRunes(s, e)
-			top := math.Point{X: t.caretWidth + m.W, Y: 0}
+		if t.holdsRune(e) {
+			m := t.outer.MeasureRunes(rs, e)
+			top := math.Point{X: t.rowOffset() + m.W, Y: 0}
 			bottom := top.Add(math.Point{X: 0, Y: t.Size().H})
 			t.outer.PaintCaret(c, top, bottom)
 		}
@@ -104,17 +178,18 @@ func (t *DefaultTextBoxLine) PaintCarets(c gxui.Canvas) {
 func (t *DefaultTextBoxLine) PaintSelections(c gxui.Canvas) {
 	controller := t.textbox.controller
 
-	ls, le := controller.LineStart(t.lineIndex), controller.LineEnd(t.lineIndex)
+	rs, re := t.rowRange()
+	offset := t.rowOffset()
 
 	selections := controller.Selections()
 	if t.textbox.selectionDragging {
 		interval.Replace(&selections, t.textbox.selectionDrag)
 	}
-	interval.Visit(&selections, gxui.CreateTextSelection(ls, le, false), func(s, e uint64, _ int) {
+	interval.Visit(&selections, gxui.CreateTextSelection(rs, re, false), func(s, e uint64, _ int) {
 		if s < e {
-			x := t.outer.MeasureRunes(ls, int(s)).W
+			x := t.outer.MeasureRunes(rs, int(s)).W
 			m := t.outer.MeasureRunes(int(s), int(e))
-			top := math.Point{X: t.caretWidth + x, Y: 0}
+			top := math.Point{X: offset + x, Y: 0}
 			bottom := top.Add(m.Point())
 			t.outer.PaintSelection(c, top, bottom)
 		}
@@ -136,20 +211,25 @@ func (t *DefaultTextBoxLine) RuneIndexAt(p math.Point) int {
 	font := t.textbox.font
 	controller := t.textbox.controller
 
-	x := p.X
-	line := controller.Line(t.lineIndex)
+	rs, re := t.rowRange()
+	x := p.X - (t.rowOffset() - t.caretWidth)
+	runes := controller.TextRangeRunes(rs, re)
 	i := 0
-	for ; i < len(line) && x > font.Measure(&gxui.TextBlock{Runes: []rune(line[:i+1])}).W; i++ {
+	for ; i < len(runes) && x > font.Measure(&gxui.TextBlock{Runes: runes[:i+1]}).W; i++ {
+	}
+	if re != controller.LineEnd(t.lineIndex) {
+		i = math.Min(i, len(runes)-1) // The end of the row starts the next row
 	}
 
-	return controller.LineStart(t.lineIndex) + i
+	return rs + i
 }
 
 func (t *DefaultTextBoxLine) PositionAt(runeIndex int) math.Point {
 	font := t.textbox.font
 	controller := t.textbox.controller
 
-	x := runeIndex - controller.LineStart(t.lineIndex)
-	line := controller.Line(t.lineIndex)
-	return font.Measure(&gxui.TextBlock{Runes: []rune(line[:x])}).Point()
+	rs, _ := t.rowRange()
+	runes := controller.TextRangeRunes(rs, runeIndex)
+	p := font.Measure(&gxui.TextBlock{Runes: runes}).Point()
+	return p.AddX(t.rowOffset() - t.caretWidth)
 }
@@ -212,6 +212,7 @@ func (l *DropDownList) KeyPress(ev gxui.KeyboardEvent) (consume bool) {
 func (l *DropDownList) Paint(c gxui.Canvas) {
 	r := l.outer.Size().Rect()
 	l.PaintBackground(c, r)
+	l.PaintShadow(c, r)
 	l.Container.Paint(c)
 	l.PaintBorder(c, r)
 }
@@ -0,0 +1,289 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mixins
+
+import (
+	"fmt"
+
+	"github.com/google/gxui"
+)
+
+type FindBarOuter interface {
+	LinearLayoutOuter
+}
+
+// findBarTarget is a text box that a FindBar can search. It is implemented by
+// TextBox, and so by the text boxes and code editors of the themes.
+type findBarTarget interface {
+	gxui.TextBox
+	Controller() *gxui.TextBoxController
+	SyntaxLayers() gxui.CodeSyntaxLayers
+	SetSyntaxLayers(gxui.CodeSyntaxLayers)
+}
+
+type FindBar struct {
+	LinearLayout
+
+	outer         FindBarOuter
+	theme         gxui.Theme
+	target        findBarTarget
+	finder        *gxui.TextFinder
+	subscriptions []gxui.EventSubscription
+	mode          gxui.FindMode
+	highlight     gxui.Color
+	pattern       gxui.TextBox
+	replacement   gxui.TextBox
+	ignoreCase    gxui.Button
+	wholeWord     gxui.Button
+	regexp        gxui.Button
+	status        gxui.Label
+	onClose       gxui.Event
+}
+
+func (f *FindBar) Init(outer FindBarOuter, theme gxui.Theme) {
+	f.LinearLayout.Init(outer, theme)
+	f.outer = outer
+	f.theme = theme
+	f.onClose = gxui.CreateEvent(func() {})
+	f.highlight = gxui.Color{R: 1, G: 0.8, B: 0, A: 0.3}
+	f.SetDirection(gxui.LeftToRight)
+
+	f.pattern = theme.CreateTextBox()
+	f.pattern.SetDesiredWidth(150)
+	f.pattern.OnTextChanged(func([]gxui.TextBoxEdit) {
+		if f.finder != nil {
+			f.finder.SetPattern(f.pattern.Text())
+		}
+		f.updateStatus()
+	})
+	f.pattern.OnKeyPress(func(ev gxui.KeyboardEvent) {
+		switch {
+		case ev.Key == gxui.KeyEnter && ev.Modifier.Shift():
+			f.FindPrevious()
+		case ev.Key == gxui.KeyEnter:
+			f.FindNext()
+		default:
+			f.keyPress(ev)
+		}
+	})
+	f.AddChild(f.pattern)
+
+	f.ignoreCase = f.createToggle("Aa", gxui.FindIgnoreCase)
+	f.wholeWord = f.createToggle("W", gxui.FindWholeWord)
+	f.regexp = f.createToggle(".*", gxui.FindRegexp)
+
+	f.status = theme.CreateLabel()
+	f.AddChild(f.status)
+
+	f.AddChild(f.createButton("<", f.FindPrevious))
+	f.AddChild(f.createButton(">", f.FindNext))
+
+	f.replacement = theme.CreateTextBox()
+	f.replacement.SetDesiredWidth(150)
+	f.replacement.OnKeyPress(f.keyPress)
+	f.AddChild(f.replacement)
+
+	f.AddChild(f.createButton("Replace", f.Replace))
+	f.AddChild(f.createButton("All", f.ReplaceAll))
+	f.AddChild(f.createButton("x", func() { f.onClose.Fire() }))
+
+	// Interface compliance test
+	_ = gxui.FindBar(f)
+}
+
+func (f *FindBar) createButton(text string, onClick func()) gxui.Button {
+	b := f.theme.CreateButton()
+	b.SetText(text)
+	b.OnClick(func(gxui.MouseEvent) { onClick() })
+	return b
+}
+
+func (f *FindBar) createToggle(text string, mode gxui.FindMode) gxui.Button {
+	b := f.theme.CreateButton()
+	b.SetText(text)
+	b.SetType(gxui.ToggleButton)
+	b.OnClick(func(gxui.MouseEvent) {
+		if b.IsChecked() {
+			f.SetMode(f.mode | mode)
+		} else {
+			f.SetMode(f.mode &^ mode)
+		}
+	})
+	f.AddChild(b)
+	return b
+}
+
+// keyPress handles the key presses of the find bar's text boxes and the
+// target.
+func (f *FindBar) keyPress(ev gxui.KeyboardEvent) {
+	switch {
+	case ev.Key == gxui.KeyF3 && ev.Modifier.Shift():
+		f.FindPrevious()
+	case ev.Key == gxui.KeyF3:
+		f.FindNext()
+	}
+}
+
+func (f *FindBar) updateStatus() {
+	switch {
+	case f.finder == nil || f.finder.Pattern() == "":
+		f.status.SetText("")
+	case f.finder.Err() != nil:
+		f.status.SetText("Invalid pattern")
+	case f.finder.MatchCount() == 0:
+		f.status.SetText("No results")
+	case f.finder.Current() >= 0:
+		f.status.SetText(fmt.Sprintf("%d of %d", f.finder.Current()+1, f.finder.MatchCount()))
+	case f.finder.MatchCount() == 1:
+		f.status.SetText("1 match")
+	default:
+		f.status.SetText(fmt.Sprintf("%d matches", f.finder.MatchCount()))
+	}
+}
+
+// HighlightColor returns the background color of the highlighted matches.
+func (f *FindBar) HighlightColor() gxui.Color {
+	return f.highlight
+}
+
+// SetHighlightColor sets the background color of the highlighted matches.
+func (f *FindBar) SetHighlightColor(color gxui.Color) {
+	f.highlight = color
+	if f.finder != nil {
+		f.finder.Layer().SetBackgroundColor(color)
+		f.target.SetSyntaxLayers(f.target.SyntaxLayers())
+	}
+}
+
+// PatternTextBox returns the TextBox used to enter the pattern.
+func (f *FindBar) PatternTextBox() gxui.TextBox {
+	return f.pattern
+}
+
+// gxui.FindBar compliance
+func (f *FindBar) Target() gxui.TextBox {
+	return f.target
+}
+
+func (f *FindBar) SetTarget(textBox gxui.TextBox) {
+	var target findBarTarget
+	if textBox != nil {
+		var ok bool
+		if target, ok = textBox.(findBarTarget); !ok {
+			panic(fmt.Errorf("FindBar target %T does not have a controller and syntax layers", textBox))
+		}
+	}
+	if f.target == target {
+		return
+	}
+	if f.target != nil {
+		layers := gxui.CodeSyntaxLayers{}
+		for _, l := range f.target.SyntaxLayers() {
+			if l != f.finder.Layer() {
+				layers = append(layers, l)
+			}
+		}
+		f.target.SetSyntaxLayers(layers)
+		for _, s := range f.subscriptions {
+			s.Unlisten()
+		}
+		f.subscriptions = nil
+		f.finder.Release()
+		f.finder = nil
+	}
+	f.target = target
+	if target != nil {
+		f.finder = gxui.CreateTextFinder(target.Controller())
+		f.finder.SetMode(f.mode)
+		f.finder.SetPattern(f.pattern.Text())
+		f.finder.Layer().SetBackgroundColor(f.highlight)
+		layers := append(gxui.CodeSyntaxLayers{f.finder.Layer()}, target.SyntaxLayers()...)
+		target.SetSyntaxLayers(layers)
+		f.subscriptions = []gxui.EventSubscription{
+			f.finder.OnMatchesChanged(func() {
+				f.updateStatus()
+				f.target.SetSyntaxLayers(f.target.SyntaxLayers()) // Redraw
+			}),
+			target.OnSelectionChanged(f.updateStatus),
+			target.OnKeyPress(f.keyPress),
+		}
+	}
+	f.updateStatus()
+}
+
+func (f *FindBar) Finder() *gxui.TextFinder {
+	return f.finder
+}
+
+func (f *FindBar) Mode() gxui.FindMode {
+	return f.mode
+}
+
+func (f *FindBar) SetMode(mode gxui.FindMode) {
+	if f.mode != mode {
+		f.mode = mode
+		f.ignoreCase.SetChecked(mode.IgnoreCase())
+		f.wholeWord.SetChecked(mode.WholeWord())
+		f.regexp.SetChecked(mode.Regexp())
+		if f.finder != nil {
+			f.finder.SetMode(mode)
+		}
+		f.updateStatus()
+	}
+}
+
+func (f *FindBar) Pattern() string {
+	return f.pattern.Text()
+}
+
+func (f *FindBar) SetPattern(pattern string) {
+	f.pattern.SetText(pattern)
+}
+
+func (f *FindBar) Replacement() string {
+	return f.replacement.Text()
+}
+
+func (f *FindBar) SetReplacement(replacement string) {
+	f.replacement.SetText(replacement)
+}
+
+func (f *FindBar) FindNext() {
+	if f.finder != nil && f.finder.Next() {
+		f.target.ScrollToRune(f.target.Controller().LastSelection().Start())
+	}
+}
+
+func (f *FindBar) FindPrevious() {
+	if f.finder != nil && f.finder.Previous() {
+		f.target.ScrollToRune(f.target.Controller().LastSelection().Start())
+	}
+}
+
+func (f *FindBar) Replace() {
+	if f.finder != nil {
+		f.finder.Replace(f.replacement.Text())
+		f.target.ScrollToRune(f.target.Controller().LastSelection().Start())
+	}
+}
+
+func (f *FindBar) ReplaceAll() {
+	if f.finder != nil {
+		f.finder.ReplaceAll(f.replacement.Text())
+	}
+}
+
+func (f *FindBar) OnClose(cb func()) gxui.EventSubscription {
+	return f.onClose.Listen(cb)
+}
+
+// InputEventHandler override
+func (f *FindBar) KeyPress(ev gxui.KeyboardEvent) (consume bool) {
+	if ev.Key == gxui.KeyEscape {
+		f.onClose.Fire()
+		return true
+	}
+	return f.LinearLayout.KeyPress(ev)
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"github.com/google/gxui"
+	"github.com/google/gxui/animation"
 	"github.com/google/gxui/math"
 	"github.com/google/gxui/mixins/base"
 	"github.com/google/gxui/mixins/parts"
@@ -27,10 +28,37 @@ type itemDetails struct {
 	index               int
 	mark                int
 	onClickSubscription gxui.EventSubscription
+
+	// Reorder animation state
+	moving      bool      // Slide from the bounds at the start of the animation
+	from        math.Rect // Bounds at the start of the animation
+	fading      bool      // Fade in from fromOpacity to opacity
+	fromOpacity float32
+	opacity     float32
+}
+
+// leavingItem is an item control that is no longer laid out by the list, but
+// is kept until the reorder animation ends. Items that are still held by the
+// adapter slide out of view to their new index, while removed items collapse.
+type leavingItem struct {
+	item                gxui.AdapterItem
+	child               *gxui.Child
+	onClickSubscription gxui.EventSubscription
+	index               int // -1 for removed items
+	from                math.Rect
+	fromOpacity         float32
+}
+
+func lerpRect(a, b math.Rect, t float32) math.Rect {
+	return math.Rect{
+		Min: math.Point{X: math.Lerp(a.Min.X, b.Min.X, t), Y: math.Lerp(a.Min.Y, b.Min.Y, t)},
+		Max: math.Point{X: math.Lerp(a.Max.X, b.Max.X, t), Y: math.Lerp(a.Max.Y, b.Max.Y, t)},
+	}
 }
 
 type List struct {
 	base.Container
+	parts.Animated
 	parts.BackgroundBorderPainter
 	parts.Focusable
 
@@ -54,11 +82,16 @@ type List struct {
 	onItemClicked            gxui.Event
 	dataChangedSubscription  gxui.EventSubscription
 	dataReplacedSubscription gxui.EventSubscription
+	reorderPending           bool // Start a reorder animation on the next layout
+	reorder                  *animation.Handle
+	reorderTime              float32
+	leaving                  []leavingItem
 }
 
 func (l *List) Init(outer ListOuter, theme gxui.Theme) {
 	l.outer = outer
 	l.Container.Init(outer, theme)
+	l.Animated.Init(outer)
 	l.BackgroundBorderPainter.Init(outer)
 	l.Focusable.Init(outer)
 
@@ -73,6 +106,7 @@ func (l *List) Init(outer ListOuter, theme gxui.Theme) {
 	l.SetMouseEventTarget(true)
 
 	l.details = make(map[gxui.AdapterItem]itemDetails)
+	l.OnDetach(l.finishReorder)
 
 	// Interface compliance test
 	_ = gxui.List(l)
@@ -120,9 +154,10 @@ func (l *List) LayoutChildren() {
 	}
 
 	startIndex, endIndex := l.VisibleItemRange(true)
-	majorAxisItemSize := l.MajorAxisItemSize()
 
-	d := startIndex*majorAxisItemSize - l.scrollOffset
+	startReorder := l.reorderPending
+	l.reorderPending = false
+	animating := startReorder || l.reorder != nil
 
 	mark := l.layoutMark
 	l.layoutMark++
@@ -142,31 +177,77 @@ func (l *List) LayoutChildren() {
 				l.ItemClicked(ev, item)
 			})
 			details.child = l.AddChildAt(0, control)
+			if startReorder {
+				details.fading = true
+				details.fromOpacity = 0
+				details.opacity = control.Opacity()
+			}
 		}
 		details.mark = mark
 		details.index = idx
-		l.details[item] = details
 
-		c := details.child
-		cm := c.Control.Margin()
-		cs := itemSize.Contract(cm).Max(math.ZeroSize)
-		if l.orientation.Horizontal() {
-			c.Layout(math.CreateRect(d, cm.T, d+cs.W, cm.T+cs.H).Offset(o))
-		} else {
-			c.Layout(math.CreateRect(cm.L, d, cm.L+cs.W, d+cs.H).Offset(o))
+		bounds := l.itemBounds(details.child, idx, itemSize).Offset(o)
+		if animating {
+			if details.fading {
+				details.child.Control.SetOpacity(math.Lerpf(details.fromOpacity, details.opacity, l.reorderTime))
+			}
+			if details.moving {
+				bounds = lerpRect(details.from, bounds, l.reorderTime)
+			}
 		}
-		d += majorAxisItemSize
+		details.child.Layout(bounds)
+		l.details[item] = details
 	}
 
 	// Reap unused items
 	for item, details := range l.details {
 		if details.mark != mark {
-			details.onClickSubscription.Unlisten()
-			l.RemoveChild(details.child.Control)
+			if startReorder {
+				l.leaving = append(l.leaving, leavingItem{
+					item:                item,
+					child:               details.child,
+					onClickSubscription: details.onClickSubscription,
+					from:                details.from,
+					fromOpacity:         details.child.Control.Opacity(),
+				})
+			} else {
+				details.onClickSubscription.Unlisten()
+				l.RemoveChild(details.child.Control)
+			}
 			delete(l.details, item)
 		}
 	}
 
+	if animating {
+		for i := range l.leaving {
+			leaving := &l.leaving[i]
+			if startReorder {
+				leaving.index = l.adapter.ItemIndex(leaving.item)
+				if leaving.index >= 0 && l.adapter.ItemAt(leaving.index) != leaving.item {
+					leaving.index = -1 // Item is hidden, such as by a collapsed tree node
+				}
+			}
+			var bounds math.Rect
+			if leaving.index >= 0 {
+				to := l.itemBounds(leaving.child, leaving.index, itemSize).Offset(o)
+				bounds = lerpRect(leaving.from, to, l.reorderTime)
+			} else {
+				bounds = leaving.from
+				if l.orientation.Horizontal() {
+					bounds.Max.X = math.Lerp(bounds.Max.X, bounds.Min.X, l.reorderTime)
+				} else {
+					bounds.Max.Y = math.Lerp(bounds.Max.Y, bounds.Min.Y, l.reorderTime)
+				}
+				leaving.child.Control.SetOpacity(math.Lerpf(leaving.fromOpacity, 0, l.reorderTime))
+			}
+			leaving.child.Layout(bounds)
+		}
+	}
+
+	if startReorder {
+		l.startReorder()
+	}
+
 	if l.scrollBarEnabled {
 		ss := l.scrollBar.DesiredSize(math.ZeroSize, s)
 		if l.Orientation().Horizontal() {
@@ -183,6 +264,90 @@ func (l *List) LayoutChildren() {
 	l.UpdateItemMouseOver()
 }
 
+// itemBounds returns the bounds of the item child at index, relative to the
+// list's padding.
+func (l *List) itemBounds(c *gxui.Child, index int, itemSize math.Size) math.Rect {
+	d := index*l.MajorAxisItemSize() - l.scrollOffset
+	cm := c.Control.Margin()
+	cs := itemSize.Contract(cm).Max(math.ZeroSize)
+	if l.orientation.Horizontal() {
+		return math.CreateRect(d, cm.T, d+cs.W, cm.T+cs.H)
+	} else {
+		return math.CreateRect(cm.L, d, cm.L+cs.W, d+cs.H)
+	}
+}
+
+// canAnimateReorder returns true if data changes should be animated.
+func (l *List) canAnimateReorder() bool {
+	return l.Animator() != nil && l.AnimationDuration() > 0 && l.Attached()
+}
+
+// prepareReorder records the current bounds and opacities of the item
+// controls so that the next layout can animate from them.
+func (l *List) prepareReorder() {
+	for item, details := range l.details {
+		details.moving = true
+		details.from = details.child.Bounds()
+		if details.fading {
+			details.fromOpacity = details.child.Control.Opacity()
+		}
+		l.details[item] = details
+	}
+	for i := range l.leaving {
+		l.leaving[i].from = l.leaving[i].child.Bounds()
+		l.leaving[i].fromOpacity = l.leaving[i].child.Control.Opacity()
+	}
+	l.reorderPending = true
+}
+
+func (l *List) startReorder() {
+	if l.reorder != nil {
+		l.reorder.Cancel()
+		l.reorder = nil
+	}
+	l.reorderTime = 0
+	l.reorder = l.Animate(animation.Sequence(
+		animation.Float(0, 1, l.AnimationDuration(), animation.EaseInOut, func(t float32) {
+			l.reorderTime = t
+			if l.reorder != nil {
+				l.LayoutChildren()
+				l.Redraw()
+			}
+		}),
+		animation.Call(l.endReorder),
+	))
+}
+
+// endReorder removes the leaving items and restores the item controls to
+// their laid out state.
+func (l *List) endReorder() {
+	l.reorder = nil
+	l.reorderTime = 1
+	for _, leaving := range l.leaving {
+		leaving.onClickSubscription.Unlisten()
+		l.RemoveChild(leaving.child.Control)
+	}
+	l.leaving = nil
+	for item, details := range l.details {
+		if details.fading {
+			details.child.Control.SetOpacity(details.opacity)
+		}
+		details.moving, details.fading = false, false
+		l.details[item] = details
+	}
+}
+
+// finishReorder immediately ends any pending or running reorder animation.
+func (l *List) finishReorder() {
+	l.reorderPending = false
+	if l.reorder != nil {
+		l.reorder.Cancel()
+	}
+	if l.reorder != nil || len(l.leaving) > 0 {
+		l.endReorder()
+	}
+}
+
 func (l *List) SetSize(size math.Size) {
 	l.Layoutable.SetSize(size)
 	// Ensure scroll offset is still valid
@@ -280,19 +445,27 @@ func (l *List) SizeChanged() {
 	l.outer.Relayout()
 }
 
+// DataChanged is called when the adapter's data changes. If the list has an
+// animator, the item controls are animated to their new positions: moved
+// items slide, inserted items fade in and removed items collapse.
 func (l *List) DataChanged() {
+	if l.canAnimateReorder() {
+		l.prepareReorder()
+	}
 	l.itemCount = l.adapter.Count()
 	l.SizeChanged()
 }
 
 func (l *List) DataReplaced() {
 	l.selectedItem = nil
+	l.finishReorder()
 	for item, details := range l.details {
 		details.onClickSubscription.Unlisten()
 		l.RemoveChild(details.child.Control)
 		delete(l.details, item)
 	}
-	l.DataChanged()
+	l.itemCount = l.adapter.Count()
+	l.SizeChanged()
 }
 
 func (l *List) Paint(c gxui.Canvas) {
@@ -333,6 +506,7 @@ func (l *List) ContainsItem(item gxui.AdapterItem) bool {
 }
 
 func (l *List) RemoveAll() {
+	l.finishReorder()
 	for _, details := range l.details {
 		details.onClickSubscription.Unlisten()
 		l.outer.RemoveChild(details.child.Control)
@@ -0,0 +1,134 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mixins_test
+
+import test "github.com/google/gxui/testing"
+import (
+	"testing"
+	"time"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/animation"
+	"github.com/google/gxui/automation"
+	"github.com/google/gxui/drivers/soft"
+	"github.com/google/gxui/math"
+	"github.com/google/gxui/themes/dark"
+)
+
+type testListAdapter struct {
+	gxui.AdapterBase
+	items []string
+}
+
+func (a *testListAdapter) Count() int {
+	return len(a.items)
+}
+
+func (a *testListAdapter) ItemAt(index int) gxui.AdapterItem {
+	return a.items[index]
+}
+
+func (a *testListAdapter) ItemIndex(item gxui.AdapterItem) int {
+	for i, s := range a.items {
+		if s == item {
+			return i
+		}
+	}
+	return -1
+}
+
+func (a *testListAdapter) Size(gxui.Theme) math.Size {
+	return math.Size{W: 50, H: 10}
+}
+
+func (a *testListAdapter) Create(theme gxui.Theme, index int) gxui.Control {
+	l := theme.CreateLabel()
+	l.SetText(a.items[index])
+	return l
+}
+
+// runList calls f with an Automation for a window holding a list of the
+// adapter's items, animated by clock.
+func runList(adapter gxui.ListAdapter, clock *gxui.ManualClock, f func(*automation.Automation, gxui.List)) {
+	soft.StartDriver(func(driver gxui.Driver) {
+		theme := dark.CreateTheme(driver)
+		window := theme.CreateWindow(100, 100, "test")
+		list := theme.CreateList()
+		list.(*dark.List).SetAnimator(animation.CreateAnimator(animation.ClockFrames(clock, 10*time.Millisecond)))
+		list.(*dark.List).SetAnimationDuration(100 * time.Millisecond)
+		list.SetAdapter(adapter)
+		window.AddChild(list)
+		a := automation.Create(driver, window)
+		go func() {
+			defer driver.Terminate()
+			a.WaitIdle()
+			f(a, list)
+		}()
+	})
+}
+
+func TestListReorderAnimation(t *testing.T) {
+	adapter := &testListAdapter{items: []string{"a", "b", "c"}}
+	clock := gxui.CreateManualClock(time.Unix(0, 0))
+	runList(adapter, clock, func(a *automation.Automation, list gxui.List) {
+		var b gxui.Control
+		top := func(item string) int {
+			p := -1
+			a.Do(func() {
+				if c := list.ItemControl(item); c != nil {
+					p = gxui.ChildToParent(math.ZeroPoint, c, list).Y
+				}
+			})
+			return p
+		}
+		opacity := func(item string) float32 {
+			var o float32
+			a.Do(func() { o = list.ItemControl(item).Opacity() })
+			return o
+		}
+		test.AssertEquals(t, []int{2, 12, 22}, []int{top("a"), top("b"), top("c")})
+
+		a.Do(func() {
+			b = list.ItemControl("b")
+			adapter.items = []string{"c", "a", "d"}
+			adapter.DataChanged()
+		})
+		a.WaitIdle()
+
+		// Items start from their old positions, and inserted items are transparent.
+		test.AssertEquals(t, 22, top("c"))
+		test.AssertEquals(t, 2, top("a"))
+		test.AssertEquals(t, float32(0), opacity("d"))
+
+		a.Do(func() { clock.Advance(50 * time.Millisecond) })
+		a.WaitIdle()
+		test.AssertEquals(t, 12, top("c"))
+		test.AssertEquals(t, 7, top("a"))
+		test.AssertEquals(t, float32(0.5), opacity("d"))
+		a.Do(func() { test.AssertEquals(t, true, b.Attached()) })
+
+		a.Do(func() { clock.Advance(50 * time.Millisecond) })
+		a.WaitIdle()
+		test.AssertEquals(t, []int{2, 12, 22}, []int{top("c"), top("a"), top("d")})
+		test.AssertEquals(t, float32(1), opacity("d"))
+		a.Do(func() { test.AssertEquals(t, false, b.Attached()) })
+	})
+}
+
+func TestListReorderAnimationDisabled(t *testing.T) {
+	adapter := &testListAdapter{items: []string{"a", "b"}}
+	clock := gxui.CreateManualClock(time.Unix(0, 0))
+	runList(adapter, clock, func(a *automation.Automation, list gxui.List) {
+		a.Do(func() {
+			list.(*dark.List).SetAnimationDuration(0)
+			adapter.items = []string{"b", "a"}
+			adapter.DataChanged()
+		})
+		a.WaitIdle()
+		a.Do(func() {
+			test.AssertEquals(t, 2, gxui.ChildToParent(math.ZeroPoint, list.ItemControl("b"), list).Y)
+		})
+	})
+}
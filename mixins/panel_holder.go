@@ -8,8 +8,10 @@ import (
 	"fmt"
 
 	"github.com/google/gxui"
+	"github.com/google/gxui/animation"
 	"github.com/google/gxui/math"
 	"github.com/google/gxui/mixins/base"
+	"github.com/google/gxui/mixins/parts"
 )
 
 type PanelTab interface {
@@ -36,6 +38,7 @@ type PanelEntry struct {
 
 type PanelHolder struct {
 	base.Container
+	parts.Animated
 
 	outer PanelHolderOuter
 
@@ -43,6 +46,7 @@ type PanelHolder struct {
 	tabLayout gxui.LinearLayout
 	entries   []PanelEntry
 	selected  PanelEntry
+	fade      *animation.Handle
 }
 
 func insertIndex(holder gxui.PanelHolder, at math.Point) int {
@@ -93,6 +97,7 @@ func beginTabDragging(holder gxui.PanelHolder, panel gxui.Control, name string,
 
 func (p *PanelHolder) Init(outer PanelHolderOuter, theme gxui.Theme) {
 	p.Container.Init(outer, theme)
+	p.Animated.Init(outer)
 
 	p.outer = outer
 	p.theme = theme
@@ -201,9 +206,18 @@ func (p *PanelHolder) Select(index int) {
 		p.selected = PanelEntry{}
 	}
 
+	if p.fade != nil {
+		// Restore the previous panel's opacity before it is replaced.
+		p.fade.Finish()
+		p.fade = nil
+	}
+
 	if p.selected.Panel != nil {
 		p.Container.AddChild(p.selected.Panel)
 		p.selected.Tab.SetActive(true)
+		panel := p.selected.Panel
+		p.fade = p.Animate(animation.Float(0, panel.Opacity(), p.AnimationDuration(),
+			animation.EaseOut, panel.SetOpacity))
 	}
 }
 
@@ -0,0 +1,67 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parts
+
+import (
+	"time"
+
+	"github.com/google/gxui/animation"
+	"github.com/google/gxui/mixins/outer"
+)
+
+// DefaultAnimationDuration is the length of a control's property animations,
+// unless changed with SetAnimationDuration.
+const DefaultAnimationDuration = 150 * time.Millisecond
+
+type AnimatedOuter interface {
+	outer.Attachable
+}
+
+// Animated is the part of a control that animates changes to its properties.
+type Animated struct {
+	outer    AnimatedOuter
+	animator *animation.Animator
+	duration time.Duration
+}
+
+func (a *Animated) Init(outer AnimatedOuter) {
+	a.outer = outer
+	a.duration = DefaultAnimationDuration
+}
+
+func (a *Animated) Animator() *animation.Animator {
+	return a.animator
+}
+
+// SetAnimator sets the animator used to animate changes to the control's
+// properties. If animator is nil, which is the default, changes are applied
+// immediately.
+func (a *Animated) SetAnimator(animator *animation.Animator) {
+	a.animator = animator
+}
+
+func (a *Animated) AnimationDuration() time.Duration {
+	return a.duration
+}
+
+// SetAnimationDuration sets the length of the control's property animations.
+// A duration of 0 applies changes immediately.
+func (a *Animated) SetAnimationDuration(duration time.Duration) {
+	a.duration = duration
+}
+
+// Animate starts anim with the control's animator, returning its handle. If
+// the control has no animator, is not attached or has an animation duration
+// of 0 then anim is instead immediately updated to its end, and Animate
+// returns nil.
+func (a *Animated) Animate(anim animation.Animation) *animation.Handle {
+	if a.animator == nil || a.duration <= 0 || !a.outer.Attached() {
+		if d := anim.Duration(); d != animation.Forever {
+			anim.Update(d)
+		}
+		return nil
+	}
+	return a.animator.Start(anim)
+}
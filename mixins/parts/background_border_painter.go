@@ -15,9 +15,10 @@ type BackgroundBorderPainterOuter interface {
 }
 
 type BackgroundBorderPainter struct {
-	outer BackgroundBorderPainterOuter
-	brush gxui.Brush
-	pen   gxui.Pen
+	outer  BackgroundBorderPainterOuter
+	brush  gxui.Brush
+	pen    gxui.Pen
+	shadow gxui.Shadow
 }
 
 func (b *BackgroundBorderPainter) Init(outer BackgroundBorderPainterOuter) {
@@ -26,8 +27,18 @@ func (b *BackgroundBorderPainter) Init(outer BackgroundBorderPainterOuter) {
 	b.pen = gxui.DefaultPen
 }
 
+// PaintShadow draws the shadow of the rectangle r, which is rounded to match
+// the background and border. Outer shadows are drawn outside of r, so are only
+// visible if r is inset from the edges of the control's canvas.
+func (b *BackgroundBorderPainter) PaintShadow(c gxui.Canvas, r math.Rect) {
+	if b.shadow.IsVisible() {
+		w := b.pen.Width
+		c.DrawShadow(r, w, w, w, w, b.shadow)
+	}
+}
+
 func (b *BackgroundBorderPainter) PaintBackground(c gxui.Canvas, r math.Rect) {
-	if b.brush.Color.A != 0 {
+	if !b.brush.IsTransparent() {
 		w := b.pen.Width
 		c.DrawRoundedRect(r, w, w, w, w, gxui.TransparentPen, b.brush)
 	}
@@ -61,3 +72,14 @@ func (b *BackgroundBorderPainter) SetBorderPen(pen gxui.Pen) {
 		b.outer.Redraw()
 	}
 }
+
+func (b *BackgroundBorderPainter) Shadow() gxui.Shadow {
+	return b.shadow
+}
+
+func (b *BackgroundBorderPainter) SetShadow(shadow gxui.Shadow) {
+	if b.shadow != shadow {
+		b.shadow = shadow
+		b.outer.Redraw()
+	}
+}
@@ -132,7 +132,7 @@ func (c *Container) ContainsPoint(p math.Point) bool {
 		return false
 	}
 	for _, v := range c.children {
-		if v.Control.ContainsPoint(p.Sub(v.Offset)) {
+		if v.Control.ContainsPoint(v.ToChild(p)) {
 			return true
 		}
 	}
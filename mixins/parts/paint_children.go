@@ -6,6 +6,7 @@ package parts
 
 import (
 	"github.com/google/gxui"
+	"github.com/google/gxui/math"
 	"github.com/google/gxui/mixins/outer"
 )
 
@@ -25,9 +26,9 @@ func (p *PaintChildren) Init(outer PaintChildrenOuter) {
 
 func (p *PaintChildren) Paint(c gxui.Canvas) {
 	for i, v := range p.outer.Children() {
-		if v.Control.IsVisible() {
+		if v.Control.IsVisible() && v.Control.Opacity() > 0 {
 			c.Push()
-			c.AddClip(v.Control.Size().Rect().Offset(v.Offset))
+			c.AddClip(v.Bounds())
 			p.outer.PaintChild(c, v, i)
 			c.Pop()
 		}
@@ -35,7 +36,22 @@ func (p *PaintChildren) Paint(c gxui.Canvas) {
 }
 
 func (p *PaintChildren) PaintChild(c gxui.Canvas, child *gxui.Child, idx int) {
-	if canvas := child.Control.Draw(); canvas != nil {
-		c.DrawCanvas(canvas, child.Offset)
+	canvas := child.Control.Draw()
+	if canvas == nil {
+		return
+	}
+	offset := child.Offset
+	if child.Transform != nil {
+		c.Push()
+		c.Transform(child.CanvasTransform())
+		offset = math.ZeroPoint
+	}
+	if opacity := child.Control.Opacity(); opacity < 1 {
+		c.DrawCanvasBlend(canvas, offset, opacity, gxui.BlendNormal)
+	} else {
+		c.DrawCanvas(canvas, offset)
+	}
+	if child.Transform != nil {
+		c.Pop()
 	}
 }
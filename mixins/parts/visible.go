@@ -5,6 +5,7 @@
 package parts
 
 import (
+	"github.com/google/gxui/math"
 	"github.com/google/gxui/mixins/outer"
 )
 
@@ -16,11 +17,13 @@ type VisibleOuter interface {
 type Visible struct {
 	outer   VisibleOuter
 	visible bool
+	opacity float32
 }
 
 func (v *Visible) Init(outer VisibleOuter) {
 	v.outer = outer
 	v.visible = true
+	v.opacity = 1
 }
 
 func (v *Visible) IsVisible() bool {
@@ -35,3 +38,17 @@ func (v *Visible) SetVisible(visible bool) {
 		}
 	}
 }
+
+func (v *Visible) Opacity() float32 {
+	return v.opacity
+}
+
+func (v *Visible) SetOpacity(opacity float32) {
+	opacity = math.Saturate(opacity)
+	if v.opacity != opacity {
+		v.opacity = opacity
+		if p := v.outer.Parent(); p != nil {
+			p.Redraw()
+		}
+	}
+}
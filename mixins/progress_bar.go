@@ -6,6 +6,7 @@ package mixins
 
 import (
 	"github.com/google/gxui"
+	"github.com/google/gxui/animation"
 	"github.com/google/gxui/math"
 	"github.com/google/gxui/mixins/base"
 	"github.com/google/gxui/mixins/parts"
@@ -18,16 +19,20 @@ type ProgressBarOuter interface {
 
 type ProgressBar struct {
 	base.Control
+	parts.Animated
 	parts.BackgroundBorderPainter
 
 	outer            ProgressBarOuter
 	desiredSize      math.Size
 	progress, target int
+	fraction         float32 // The painted fraction, which lags progress while animating.
+	animation        *animation.Handle
 }
 
 func (b *ProgressBar) Init(outer ProgressBarOuter, theme gxui.Theme) {
 	b.outer = outer
 	b.Control.Init(outer, theme)
+	b.Animated.Init(outer)
 	b.BackgroundBorderPainter.Init(outer)
 	b.desiredSize = math.MaxSize
 	b.target = 100
@@ -37,10 +42,9 @@ func (b *ProgressBar) Init(outer ProgressBarOuter, theme gxui.Theme) {
 }
 
 func (b *ProgressBar) Paint(c gxui.Canvas) {
-	frac := math.Saturate(float32(b.progress) / float32(b.target))
 	r := b.outer.Size().Rect()
 	b.PaintBackground(c, r)
-	b.outer.PaintProgress(c, r, frac)
+	b.outer.PaintProgress(c, r, b.fraction)
 	b.PaintBorder(c, r)
 }
 
@@ -49,6 +53,22 @@ func (b *ProgressBar) PaintProgress(c gxui.Canvas, r math.Rect, frac float32) {
 	c.DrawRect(r, gxui.CreateBrush(gxui.Gray50))
 }
 
+// animateFraction animates the painted fraction to the current progress.
+func (b *ProgressBar) animateFraction() {
+	if b.animation != nil {
+		b.animation.Cancel()
+	}
+	to := math.Saturate(float32(b.progress) / float32(b.target))
+	b.animation = b.Animate(animation.Float(b.fraction, to, b.AnimationDuration(), animation.EaseOut, b.setFraction))
+}
+
+func (b *ProgressBar) setFraction(fraction float32) {
+	if b.fraction != fraction {
+		b.fraction = fraction
+		b.Redraw()
+	}
+}
+
 func (b *ProgressBar) DesiredSize(min, max math.Size) math.Size {
 	return b.desiredSize.Clamp(min, max)
 }
@@ -62,7 +82,7 @@ func (b *ProgressBar) SetDesiredSize(size math.Size) {
 func (b *ProgressBar) SetProgress(progress int) {
 	if b.progress != progress {
 		b.progress = progress
-		b.Redraw()
+		b.animateFraction()
 	}
 }
 
@@ -73,7 +93,7 @@ func (b *ProgressBar) Progress() int {
 func (b *ProgressBar) SetTarget(target int) {
 	if b.target != target {
 		b.target = target
-		b.Redraw()
+		b.animateFraction()
 	}
 }
 
@@ -6,6 +6,7 @@ package mixins
 
 import (
 	"github.com/google/gxui"
+	"github.com/google/gxui/animation"
 	"github.com/google/gxui/math"
 	"github.com/google/gxui/mixins/base"
 	"github.com/google/gxui/mixins/parts"
@@ -17,6 +18,7 @@ type ScrollLayoutOuter interface {
 
 type ScrollLayout struct {
 	base.Container
+	parts.Animated
 	parts.BackgroundBorderPainter
 
 	outer                  ScrollLayoutOuter
@@ -26,10 +28,13 @@ type ScrollLayout struct {
 	scrollBarX, scrollBarY *gxui.Child
 	child                  *gxui.Child
 	innerSize              math.Size
+	scrollAnimation        *animation.Handle
+	scrollTarget           math.Point
 }
 
 func (l *ScrollLayout) Init(outer ScrollLayoutOuter, theme gxui.Theme) {
 	l.Container.Init(outer, theme)
+	l.Animated.Init(outer)
 	l.BackgroundBorderPainter.Init(outer)
 
 	l.outer = outer
@@ -38,10 +43,10 @@ func (l *ScrollLayout) Init(outer ScrollLayoutOuter, theme gxui.Theme) {
 	l.canScrollY = true
 	scrollBarX := theme.CreateScrollBar()
 	scrollBarX.SetOrientation(gxui.Horizontal)
-	scrollBarX.OnScroll(func(from, to int) { l.SetScrollOffset(math.Point{X: from, Y: l.scrollOffset.Y}) })
+	scrollBarX.OnScroll(func(from, to int) { l.scrollBarScrolled(math.Point{X: from, Y: l.scrollOffset.Y}) })
 	scrollBarY := theme.CreateScrollBar()
 	scrollBarY.SetOrientation(gxui.Vertical)
-	scrollBarY.OnScroll(func(from, to int) { l.SetScrollOffset(math.Point{X: l.scrollOffset.X, Y: from}) })
+	scrollBarY.OnScroll(func(from, to int) { l.scrollBarScrolled(math.Point{X: l.scrollOffset.X, Y: from}) })
 	l.scrollBarX = l.AddChild(scrollBarX)
 	l.scrollBarY = l.AddChild(scrollBarY)
 	l.SetMouseEventTarget(true)
@@ -88,6 +93,15 @@ func (l *ScrollLayout) DesiredSize(min, max math.Size) math.Size {
 	return max
 }
 
+// clampScrollOffset returns scrollOffset limited to the scrollable range.
+func (l *ScrollLayout) clampScrollOffset(scrollOffset math.Point) math.Point {
+	var cs math.Size
+	if l.child != nil {
+		cs = l.child.Control.Size()
+	}
+	return scrollOffset.Min(cs.Sub(l.innerSize).Point()).Max(math.Point{})
+}
+
 func (l *ScrollLayout) SetScrollOffset(scrollOffset math.Point) bool {
 	var cs math.Size
 	if l.child != nil {
@@ -95,7 +109,7 @@ func (l *ScrollLayout) SetScrollOffset(scrollOffset math.Point) bool {
 	}
 
 	s := l.innerSize
-	scrollOffset = scrollOffset.Min(cs.Sub(s).Point()).Max(math.Point{})
+	scrollOffset = l.clampScrollOffset(scrollOffset)
 
 	l.scrollBarX.Control.SetVisible(l.canScrollX && cs.W > s.W)
 	l.scrollBarY.Control.SetVisible(l.canScrollY && cs.H > s.H)
@@ -111,16 +125,54 @@ func (l *ScrollLayout) SetScrollOffset(scrollOffset math.Point) bool {
 	return false
 }
 
+// scrollTo animates the scroll offset to scrollOffset, returning true if the
+// scroll offset will change.
+func (l *ScrollLayout) scrollTo(scrollOffset math.Point) bool {
+	l.cancelScrollAnimation()
+	from, to := l.scrollOffset, l.clampScrollOffset(scrollOffset)
+	if from == to {
+		return false
+	}
+	l.scrollTarget = to
+	l.scrollAnimation = l.Animate(animation.Point(from, to, l.AnimationDuration(), animation.EaseOut,
+		func(p math.Point) { l.SetScrollOffset(p) }))
+	return true
+}
+
+func (l *ScrollLayout) cancelScrollAnimation() {
+	if l.scrollAnimation != nil {
+		l.scrollAnimation.Cancel()
+		l.scrollAnimation = nil
+	}
+}
+
+// scrollBarScrolled is called when either scroll bar's position changes. The
+// scroll bars are also moved by SetScrollOffset, in which case scrollOffset
+// is unchanged.
+func (l *ScrollLayout) scrollBarScrolled(scrollOffset math.Point) {
+	if scrollOffset != l.scrollOffset {
+		// The user has dragged a scroll bar, which takes over from any
+		// animation.
+		l.cancelScrollAnimation()
+	}
+	l.SetScrollOffset(scrollOffset)
+}
+
 // InputEventHandler override
 func (l *ScrollLayout) MouseScroll(ev gxui.MouseEvent) (consume bool) {
 	if ev.ScrollY == 0 {
 		return l.InputEventHandler.MouseScroll(ev)
 	}
+	// Scrolls accumulate onto the end of any scroll that is still animating.
+	from := l.scrollOffset
+	if l.scrollAnimation != nil && l.scrollAnimation.Running() {
+		from = l.scrollTarget
+	}
 	switch {
 	case l.canScrollY:
-		return l.SetScrollOffset(l.scrollOffset.AddY(-ev.ScrollY))
+		return l.scrollTo(from.AddY(-ev.ScrollY))
 	case l.canScrollX:
-		return l.SetScrollOffset(l.scrollOffset.AddX(-ev.ScrollY))
+		return l.scrollTo(from.AddX(-ev.ScrollY))
 	default:
 		return false
 	}
@@ -8,9 +8,16 @@ import (
 	"github.com/google/gxui"
 	"github.com/google/gxui/math"
 	"github.com/google/gxui/mixins/parts"
+	"sort"
 	"strings"
+	"time"
+	"unicode"
 )
 
+// DefaultCaretBlinkInterval is the time between the caret being shown and
+// hidden, unless changed with SetCaretBlinkInterval.
+const DefaultCaretBlinkInterval = 500 * time.Millisecond
+
 type TextBoxLine interface {
 	gxui.Control
 	RuneIndexAt(math.Point) int
@@ -20,6 +27,22 @@ type TextBoxLine interface {
 type TextBoxOuter interface {
 	ListOuter
 	CreateLine(theme gxui.Theme, index int) (line TextBoxLine, container gxui.Control)
+	CreateWrappedLine(theme gxui.Theme, index, wrap int) (line TextBoxLine, container gxui.Control)
+	WrapWidth() int
+}
+
+// lineWrap holds the wraps of a line, as the offsets from the line start of
+// the runes that start its wrapped rows, and a hash of the line's runes.
+type lineWrap struct {
+	hash    uint64
+	offsets []int
+}
+
+// textBoxRow is a row of the list, displaying the row wrap of the line. The
+// first row of a line has a wrap of 0, and uses the line index as its
+// AdapterItem. Wrapped rows after the first use their textBoxRow.
+type textBoxRow struct {
+	line, wrap int
 }
 
 type TextBox struct {
@@ -34,13 +57,33 @@ type TextBox struct {
 	onRedrawLines     gxui.Event
 	multiline         bool
 	controller        *gxui.TextBoxController
+	layers            gxui.CodeSyntaxLayers
 	adapter           *TextBoxAdapter
+	rows              []textBoxRow // The row of each list row, or nil for every line unwrapped
+	visibleLines      []int        // The lines that are not hidden, or nil for every line
+	softWrap          bool
+	wrapColumn        int
+	wraps             []lineWrap // The wraps of each line, or nil if soft wrapping is off
+	wrappedWidth      int        // The WrapWidth that wraps was built for
 	selectionDragging bool
 	selectionDrag     gxui.TextSelection
+	columnDragging    bool
+	columnDragLine    int // The line that the column selection drag started at
+	columnDragColumn  int // The column that the column selection drag started at
 	desiredWidth      int
+	caretBlink        gxui.Timer
+	caretInterval     time.Duration
+	caretHidden       bool
 }
 
 func (t *TextBox) lineMouseDown(line TextBoxLine, ev gxui.MouseEvent) {
+	if ev.Button == gxui.MouseButtonLeft && ev.Modifier.Alt() {
+		l, c := t.lineColumnAt(line, ev.Point)
+		t.columnDragging = true
+		t.columnDragLine, t.columnDragColumn = l, c
+		t.controller.SelectColumns(l, c, l, c)
+		return
+	}
 	if ev.Button == gxui.MouseButtonLeft {
 		p := line.RuneIndexAt(ev.Point)
 		t.selectionDragging = true
@@ -52,6 +95,10 @@ func (t *TextBox) lineMouseDown(line TextBoxLine, ev gxui.MouseEvent) {
 }
 
 func (t *TextBox) lineMouseUp(line TextBoxLine, ev gxui.MouseEvent) {
+	if ev.Button == gxui.MouseButtonLeft && t.columnDragging {
+		t.columnDragging = false
+		return
+	}
 	if ev.Button == gxui.MouseButtonLeft {
 		t.selectionDragging = false
 		if !ev.Modifier.Control() {
@@ -62,6 +109,17 @@ func (t *TextBox) lineMouseUp(line TextBoxLine, ev gxui.MouseEvent) {
 	}
 }
 
+// lineColumnAt returns the line and column at the point p of line, counting
+// the columns past the end of the line for column selections.
+func (t *TextBox) lineColumnAt(line TextBoxLine, p math.Point) (l, c int) {
+	i := line.RuneIndexAt(p)
+	l, c = t.controller.LineIndex(i), t.controller.Column(i)
+	if i == t.controller.LineEnd(l) {
+		c += math.Max(p.X-line.PositionAt(i).X, 0) / t.font.GlyphMaxSize().W
+	}
+	return l, c
+}
+
 func (t *TextBox) Init(outer TextBoxOuter, driver gxui.Driver, theme gxui.Theme, font gxui.Font) {
 	t.List.Init(outer, theme)
 	t.Focusable.Init(outer)
@@ -72,14 +130,28 @@ func (t *TextBox) Init(outer TextBoxOuter, driver gxui.Driver, theme gxui.Theme,
 	t.controller = gxui.CreateTextBoxController()
 	t.adapter = &TextBoxAdapter{TextBox: t}
 	t.desiredWidth = 100
+	t.caretInterval = DefaultCaretBlinkInterval
 	t.SetScrollBarEnabled(false) // Defaults to single line
-	t.OnGainedFocus(func() { t.onRedrawLines.Fire() })
-	t.OnLostFocus(func() { t.onRedrawLines.Fire() })
-	t.controller.OnTextChanged(func([]gxui.TextBoxEdit) {
+	t.OnGainedFocus(func() {
+		t.restartCaretBlink()
+		t.onRedrawLines.Fire()
+	})
+	t.OnLostFocus(func() {
+		t.restartCaretBlink()
+		t.onRedrawLines.Fire()
+	})
+	t.OnAttach(t.restartCaretBlink)
+	t.OnDetach(t.restartCaretBlink)
+	t.controller.SetLineWraps(t.lineWraps)
+	t.controller.OnTextChanged(func(edits []gxui.TextBoxEdit) {
+		t.updateSpans(edits)
+		t.updateEditedWraps(edits)
+		t.restartCaretBlink()
 		t.onRedrawLines.Fire()
 		t.List.DataChanged()
 	})
 	t.controller.OnSelectionChanged(func() {
+		t.restartCaretBlink()
 		t.onRedrawLines.Fire()
 	})
 
@@ -89,6 +161,249 @@ func (t *TextBox) Init(outer TextBoxOuter, driver gxui.Driver, theme gxui.Theme,
 	_ = gxui.TextBox(t)
 }
 
+// restartCaretBlink shows the caret, and then blinks it if the text box is
+// attached and has focus.
+func (t *TextBox) restartCaretBlink() {
+	if t.caretBlink != nil {
+		t.caretBlink.Stop()
+		t.caretBlink = nil
+	}
+	t.caretHidden = false
+	if t.caretInterval > 0 && t.Attached() && t.HasFocus() {
+		t.caretBlink = t.driver.Every(t.caretInterval, func() {
+			t.caretHidden = !t.caretHidden
+			t.onRedrawLines.Fire()
+		})
+	}
+}
+
+func (t *TextBox) updateSpans(edits []gxui.TextBoxEdit) {
+	runeCount := t.controller.TextLength()
+	for _, l := range t.layers {
+		l.UpdateSpans(runeCount, edits)
+	}
+}
+
+// setVisibleLines sets the lines that are displayed, in ascending order.
+// Lines that are not in lines are hidden. nil displays every line.
+func (t *TextBox) setVisibleLines(lines []int) {
+	t.visibleLines = lines
+	t.controller.SetVisibleLines(lines)
+	t.updateRows()
+	t.List.DataChanged()
+}
+
+// updateRows rebuilds the rows of the list from the visible lines and their
+// wraps.
+func (t *TextBox) updateRows() {
+	wrapped := false
+	for _, w := range t.wraps {
+		wrapped = wrapped || len(w.offsets) > 0
+	}
+	if t.visibleLines == nil && !wrapped {
+		t.rows = nil
+		return
+	}
+	rows := []textBoxRow{}
+	add := func(line int) {
+		rows = append(rows, textBoxRow{line, 0})
+		if line < len(t.wraps) {
+			for i := range t.wraps[line].offsets {
+				rows = append(rows, textBoxRow{line, i + 1})
+			}
+		}
+	}
+	if t.visibleLines == nil {
+		for l, c := 0, math.Max(t.controller.LineCount(), 1); l < c; l++ {
+			add(l)
+		}
+	} else {
+		for _, l := range t.visibleLines {
+			add(l)
+		}
+	}
+	t.rows = rows
+}
+
+func (t *TextBox) rowCount() int {
+	if t.rows == nil {
+		return math.Max(t.controller.LineCount(), 1)
+	}
+	return len(t.rows)
+}
+
+func (t *TextBox) row(row int) textBoxRow {
+	if t.rows == nil {
+		return textBoxRow{row, 0}
+	}
+	return t.rows[row]
+}
+
+func (t *TextBox) rowLine(row int) int {
+	return t.row(row).line
+}
+
+// lineRow returns the first row displaying line, and true. If line is hidden,
+// lineRow returns the row of the closest displayed line before it, and false.
+func (t *TextBox) lineRow(line int) (row int, visible bool) {
+	if t.rows == nil {
+		return line, true
+	}
+	row = sort.Search(len(t.rows), func(i int) bool { return t.rows[i].line >= line })
+	if row < len(t.rows) && t.rows[row].line == line {
+		return row, true
+	}
+	return math.Max(row-1, 0), false
+}
+
+// runeRow returns the row displaying the rune at index i. If the line of the
+// rune is hidden, runeRow returns the row of the closest displayed line
+// before it.
+func (t *TextBox) runeRow(i int) int {
+	line, wrap := t.controller.LineAndWrap(i)
+	row, visible := t.lineRow(line)
+	if visible {
+		row += wrap
+	}
+	return row
+}
+
+// lineWraps returns the indices of the runes that start the wrapped rows of
+// line, for TextBoxController.LineWraps.
+func (t *TextBox) lineWraps(line int) []int {
+	if line >= len(t.wraps) || len(t.wraps[line].offsets) == 0 {
+		return nil
+	}
+	s, e := t.controller.LineStart(line), t.controller.LineEnd(line)
+	wraps := make([]int, 0, len(t.wraps[line].offsets))
+	for _, w := range t.wraps[line].offsets {
+		if s+w < e {
+			wraps = append(wraps, s+w)
+		}
+	}
+	return wraps
+}
+
+// updateWraps wraps each line that is too long for the text box if soft
+// wrapping is enabled, and then rebuilds the rows of the list. The list is
+// not told that its data has changed.
+func (t *TextBox) updateWraps() {
+	t.wraps = nil
+	t.wrappedWidth = t.outer.WrapWidth()
+	if t.softWrap && t.multiline {
+		t.wrapLines(0, t.controller.LineCount()-1, 0)
+	}
+	t.updateRows()
+}
+
+// updateEditedWraps rewraps the lines touched by edits, and then rebuilds the
+// rows of the list. Every line is rewrapped if edits is empty, as the whole
+// text has been replaced.
+func (t *TextBox) updateEditedWraps(edits []gxui.TextBoxEdit) {
+	if t.wraps == nil || len(edits) == 0 {
+		t.updateWraps()
+		return
+	}
+
+	// Find the runes touched by the edits, after all the edits are applied.
+	s, e := edits[0].At, edits[0].At
+	for _, edit := range edits {
+		move := func(p int) int {
+			if p < edit.At {
+				return p
+			}
+			return math.Max(p+edit.Delta, edit.At)
+		}
+		s, e = move(s), move(e)
+		s = math.Min(s, edit.At)
+		e = math.Max(e, edit.At+math.Max(edit.Delta, 0))
+	}
+	c := t.controller
+	runeCount := c.TextLength()
+	s, e = math.Clamp(s, 0, runeCount), math.Clamp(e, 0, runeCount)
+	t.wrapLines(c.LineIndex(s), c.LineIndex(e), len(t.wraps))
+	t.updateRows()
+}
+
+// wrapLines wraps the lines from first to last, and then each following line
+// until one has the same runes as the line it replaces. That line, and all the
+// lines after it, keep their wraps. Comparing the runes catches edits that
+// replace the runes of several lines, as an edit only gives the change in the
+// length of the text. oldCount is the number of lines before the edit, or 0 if
+// no lines have been wrapped.
+func (t *TextBox) wrapLines(first, last, oldCount int) {
+	c := t.controller
+	count := c.LineCount()
+	wrapped := []lineWrap{}
+	i := first
+	for ; i < count; i++ {
+		runes := c.LineRunes(i)
+		hash := gxui.HashRunes(runes)
+		if old := i + oldCount - count; i > last && old >= 0 && old < oldCount && t.wraps[old].hash == hash {
+			break
+		}
+		wrapped = append(wrapped, lineWrap{hash, t.wrapLine(i, runes)})
+	}
+	tail := t.wraps[math.Min(i+oldCount-count, len(t.wraps)):]
+	t.wraps = append(append(t.wraps[:first:first], wrapped...), tail...)
+}
+
+// wrapLine returns the offsets, from the start of line l, of the runes that
+// start the wrapped rows of the line, or nil if the line fits in one row.
+// runes are the runes of the line.
+func (t *TextBox) wrapLine(l int, runes []rune) []int {
+	var x func(i int) int // The width of runes[:i]
+	width := t.wrapColumn
+	if width > 0 {
+		x = func(i int) int { return i }
+	} else {
+		width = t.wrappedWidth
+		if width <= 0 || len(runes) == 0 {
+			return nil
+		}
+		offsets := t.font.Layout(&gxui.TextBlock{Runes: runes})
+		total := t.font.Measure(&gxui.TextBlock{Runes: runes}).W
+		x = func(i int) int {
+			if i < len(runes) {
+				return offsets[i].X - offsets[0].X
+			}
+			return total
+		}
+	}
+	if x(len(runes)) <= width {
+		return nil
+	}
+	return wrapRunes(runes, t.controller.LineIndent(l), width, x)
+}
+
+// wrapRunes returns the offsets of the runes that start the rows of runes
+// wrapped at width, where x returns the width of runes[:i]. Rows after the
+// first are indented by the first indent runes. Rows break after whitespace
+// where possible, otherwise before the rune that does not fit. Each row holds
+// at least one rune, and whitespace at the end of a row may exceed width.
+func wrapRunes(runes []rune, indent, width int, x func(i int) int) []int {
+	wraps := []int{}
+	start, offset := 0, 0 // The start of the row, and the width of its indent
+	brk := 0              // The last rune in the row that follows whitespace
+	for i := 0; i < len(runes); {
+		if i > start && !unicode.IsSpace(runes[i]) {
+			if unicode.IsSpace(runes[i-1]) && (start > 0 || i > indent) {
+				brk = i
+			}
+			if offset+x(i+1)-x(start) > width {
+				if brk <= start {
+					brk = i
+				}
+				wraps = append(wraps, brk)
+				start, offset, i = brk, x(indent), brk
+				continue
+			}
+		}
+		i++
+	}
+	return wraps
+}
+
 func (t *TextBox) textRect() math.Rect {
 	return t.outer.Size().Rect().Contract(t.Padding())
 }
@@ -109,6 +424,19 @@ func (t *TextBox) OnTextChanged(f func([]gxui.TextBoxEdit)) gxui.EventSubscripti
 	return t.controller.OnTextChanged(f)
 }
 
+func (t *TextBox) Controller() *gxui.TextBoxController {
+	return t.controller
+}
+
+func (t *TextBox) SyntaxLayers() gxui.CodeSyntaxLayers {
+	return t.layers
+}
+
+func (t *TextBox) SetSyntaxLayers(layers gxui.CodeSyntaxLayers) {
+	t.layers = layers
+	t.onRedrawLines.Fire()
+}
+
 func (t *TextBox) Runes() []rune {
 	return t.controller.TextRunes()
 }
@@ -138,7 +466,8 @@ func (t *TextBox) Font() gxui.Font {
 func (t *TextBox) SetFont(font gxui.Font) {
 	if t.font != font {
 		t.font = font
-		t.Relayout()
+		t.updateWraps()
+		t.List.DataChanged()
 	}
 }
 
@@ -150,6 +479,8 @@ func (t *TextBox) SetMultiline(multiline bool) {
 	if t.multiline != multiline {
 		t.multiline = multiline
 		t.SetScrollBarEnabled(multiline)
+		t.updateWraps()
+		t.List.DataChanged()
 		t.outer.Relayout()
 	}
 }
@@ -165,6 +496,45 @@ func (t *TextBox) SetDesiredWidth(desiredWidth int) {
 	}
 }
 
+func (t *TextBox) SoftWrap() bool {
+	return t.softWrap
+}
+
+// SetSoftWrap sets whether the lines of a multiline text box that are too long
+// to display are wrapped onto further rows. Rows are wrapped at the wrap
+// column, or at the width of the text box if the wrap column is 0.
+func (t *TextBox) SetSoftWrap(softWrap bool) {
+	if t.softWrap != softWrap {
+		t.softWrap = softWrap
+		t.updateWraps()
+		t.List.DataChanged()
+	}
+}
+
+func (t *TextBox) WrapColumn() int {
+	return t.wrapColumn
+}
+
+// SetWrapColumn sets the number of columns that rows are wrapped at when soft
+// wrapping is enabled. A column of 0 wraps rows at the width of the text box.
+func (t *TextBox) SetWrapColumn(column int) {
+	if t.wrapColumn != column {
+		t.wrapColumn = column
+		t.updateWraps()
+		t.List.DataChanged()
+	}
+}
+
+// WrapWidth returns the width that the text of rows is wrapped at, unless a
+// wrap column is set.
+func (t *TextBox) WrapWidth() int {
+	w := t.textRect().W() - 2*defaultCaretWidth
+	if t.scrollBarEnabled {
+		w -= t.scrollBar.DesiredSize(math.ZeroSize, t.textRect().Size()).W
+	}
+	return w
+}
+
 func (t *TextBox) Select(sel gxui.TextSelectionList) {
 	t.controller.StoreCaretLocations()
 	t.controller.SetSelections(sel)
@@ -179,6 +549,25 @@ func (t *TextBox) SelectAll() {
 	t.ScrollToRune(t.controller.FirstCaret())
 }
 
+func (t *TextBox) CaretBlinkInterval() time.Duration {
+	return t.caretInterval
+}
+
+// SetCaretBlinkInterval sets the time between the caret being shown and
+// hidden. An interval of 0 disables blinking.
+func (t *TextBox) SetCaretBlinkInterval(interval time.Duration) {
+	if t.caretInterval != interval {
+		t.caretInterval = interval
+		t.restartCaretBlink()
+		t.onRedrawLines.Fire()
+	}
+}
+
+// CaretVisible returns false if the carets are currently hidden by blinking.
+func (t *TextBox) CaretVisible() bool {
+	return !t.caretHidden
+}
+
 func (t *TextBox) Carets() []int {
 	return t.controller.Carets()
 }
@@ -217,18 +606,32 @@ func (t *TextBox) LineEnd(line int) int {
 	return t.controller.LineEnd(line)
 }
 
+// ScrollToLine scrolls the line with index i into view. If the line is
+// hidden, the closest displayed line before it is scrolled into view instead.
 func (t *TextBox) ScrollToLine(i int) {
-	t.List.ScrollTo(i)
+	row, _ := t.lineRow(i)
+	t.List.ScrollTo(t.adapter.ItemAt(row))
 }
 
+// ScrollToRune scrolls the row holding the rune with index i into view.
 func (t *TextBox) ScrollToRune(i int) {
-	t.ScrollToLine(t.controller.LineIndex(i))
+	t.List.ScrollTo(t.adapter.ItemAt(t.runeRow(i)))
+}
+
+// columnSelectable returns true if Shift+Alt+Up and Shift+Alt+Down extend a
+// column selection. With several selections that are not a column selection,
+// they add a caret above or below each selection instead. From a single caret,
+// both give the same carets.
+func (t *TextBox) columnSelectable() bool {
+	return t.controller.SelectionCount() == 1 || t.controller.ColumnSelected()
 }
 
 func (t *TextBox) KeyPress(ev gxui.KeyboardEvent) (consume bool) {
 	switch ev.Key {
 	case gxui.KeyLeft:
 		switch {
+		case ev.Modifier.Shift() && ev.Modifier.Alt():
+			t.controller.SelectColumnsLeft()
 		case ev.Modifier.Shift() && ev.Modifier.Control():
 			t.controller.SelectLeftByWord()
 		case ev.Modifier.Shift():
@@ -246,6 +649,8 @@ func (t *TextBox) KeyPress(ev gxui.KeyboardEvent) (consume bool) {
 		return true
 	case gxui.KeyRight:
 		switch {
+		case ev.Modifier.Shift() && ev.Modifier.Alt():
+			t.controller.SelectColumnsRight()
 		case ev.Modifier.Shift() && ev.Modifier.Control():
 			t.controller.SelectRightByWord()
 		case ev.Modifier.Shift():
@@ -263,6 +668,8 @@ func (t *TextBox) KeyPress(ev gxui.KeyboardEvent) (consume bool) {
 		return true
 	case gxui.KeyUp:
 		switch {
+		case ev.Modifier.Shift() && ev.Modifier.Alt() && t.columnSelectable():
+			t.controller.SelectColumnsUp()
 		case ev.Modifier.Shift() && ev.Modifier.Alt():
 			t.controller.AddCaretsUp()
 		case ev.Modifier.Shift():
@@ -275,6 +682,8 @@ func (t *TextBox) KeyPress(ev gxui.KeyboardEvent) (consume bool) {
 		return true
 	case gxui.KeyDown:
 		switch {
+		case ev.Modifier.Shift() && ev.Modifier.Alt() && t.columnSelectable():
+			t.controller.SelectColumnsDown()
 		case ev.Modifier.Shift() && ev.Modifier.Alt():
 			t.controller.AddCaretsDown()
 		case ev.Modifier.Shift():
@@ -362,9 +771,10 @@ func (t *TextBox) KeyPress(ev gxui.KeyboardEvent) (consume bool) {
 	case gxui.KeyC:
 		if ev.Modifier.Control() {
 			parts := make([]string, t.controller.SelectionCount())
+			columns := t.controller.ColumnSelected()
 			for i, _ := range parts {
 				parts[i] = t.controller.SelectionText(i)
-				if parts[i] == "" {
+				if parts[i] == "" && !columns {
 					// Copy line instead.
 					parts[i] = "\n" + t.controller.SelectionLineText(i)
 				}
@@ -380,10 +790,27 @@ func (t *TextBox) KeyPress(ev gxui.KeyboardEvent) (consume bool) {
 	case gxui.KeyV:
 		if ev.Modifier.Control() {
 			str, _ := t.driver.GetClipboard()
-			t.controller.ReplaceAll(str)
+			t.controller.ReplaceAllLines(str)
 			t.controller.Deselect(false)
 			return true
 		}
+	case gxui.KeyZ:
+		switch {
+		case ev.Modifier.Control() && ev.Modifier.Shift():
+			t.controller.Redo()
+			t.ScrollToRune(t.controller.FirstCaret())
+			return true
+		case ev.Modifier.Control():
+			t.controller.Undo()
+			t.ScrollToRune(t.controller.FirstCaret())
+			return true
+		}
+	case gxui.KeyY:
+		if ev.Modifier.Control() {
+			t.controller.Redo()
+			t.ScrollToRune(t.controller.FirstCaret())
+			return true
+		}
 	case gxui.KeyEscape:
 		t.controller.ClearSelections()
 	}
@@ -420,6 +847,15 @@ func (t *TextBox) DoubleClick(ev gxui.MouseEvent) (consume bool) {
 
 func (t *TextBox) MouseMove(ev gxui.MouseEvent) {
 	t.List.MouseMove(ev)
+	if t.columnDragging {
+		for _, child := range gxui.ControlsUnder(ev.Point, t) {
+			if line, _ := child.C.(TextBoxLine); line != nil {
+				l, c := t.lineColumnAt(line, gxui.ParentToChild(ev.Point, t.outer, line))
+				t.controller.SelectColumns(t.columnDragLine, t.columnDragColumn, l, c)
+				break
+			}
+		}
+	}
 	if t.selectionDragging {
 		if p, ok := t.RuneIndexAt(ev.Point); ok {
 			t.selectionDrag = gxui.CreateTextSelection(t.selectionDrag.From(), p, false)
@@ -435,7 +871,27 @@ func (t *TextBox) CreateLine(theme gxui.Theme, index int) (line TextBoxLine, con
 	return l, l
 }
 
+// CreateWrappedLine creates the line displaying the wrapped row wrap of the
+// line with the specified index. wrap is always greater than 0.
+func (t *TextBox) CreateWrappedLine(theme gxui.Theme, index, wrap int) (line TextBoxLine, container gxui.Control) {
+	l := &DefaultTextBoxLine{}
+	l.Init(l, theme, t, index)
+	l.wrap = wrap
+	return l, l
+}
+
 // mixins.List overrides
+func (t *TextBox) LayoutChildren() {
+	if t.softWrap && t.wrapColumn == 0 && t.wrappedWidth != t.outer.WrapWidth() {
+		// The text box has been resized. Rewrap the lines without requesting
+		// another layout.
+		t.updateWraps()
+		t.itemCount = t.adapter.Count()
+		t.scrollBar.SetScrollLimit(t.itemCount * t.MajorAxisItemSize())
+	}
+	t.List.LayoutChildren()
+}
+
 func (t *TextBox) PaintSelection(c gxui.Canvas, r math.Rect) {}
 
 func (t *TextBox) PaintMouseOverBackground(c gxui.Canvas, r math.Rect) {}
@@ -447,15 +903,32 @@ type TextBoxAdapter struct {
 }
 
 func (t *TextBoxAdapter) Count() int {
-	return math.Max(t.TextBox.controller.LineCount(), 1)
+	return t.TextBox.rowCount()
 }
 
+// ItemAt returns the index of the line displayed by the row index, or its
+// textBoxRow if the row is a wrapped row after the first row of the line.
 func (t *TextBoxAdapter) ItemAt(index int) gxui.AdapterItem {
-	return index
+	if row := t.TextBox.row(index); row.wrap > 0 {
+		return row
+	}
+	return t.TextBox.rowLine(index)
 }
 
 func (t *TextBoxAdapter) ItemIndex(item gxui.AdapterItem) int {
-	return item.(int)
+	line, wrap := 0, 0
+	switch item := item.(type) {
+	case int:
+		line = item
+	case textBoxRow:
+		line, wrap = item.line, item.wrap
+	}
+	if row, visible := t.TextBox.lineRow(line); visible && row+wrap < t.Count() {
+		if t.TextBox.row(row+wrap) == (textBoxRow{line, wrap}) {
+			return row + wrap
+		}
+	}
+	return -1
 }
 
 func (t *TextBoxAdapter) Size(theme gxui.Theme) math.Size {
@@ -464,7 +937,13 @@ func (t *TextBoxAdapter) Size(theme gxui.Theme) math.Size {
 }
 
 func (t *TextBoxAdapter) Create(theme gxui.Theme, index int) gxui.Control {
-	line, container := t.TextBox.outer.CreateLine(theme, index)
+	var line TextBoxLine
+	var container gxui.Control
+	if row := t.TextBox.row(index); row.wrap > 0 {
+		line, container = t.TextBox.outer.CreateWrappedLine(theme, row.line, row.wrap)
+	} else {
+		line, container = t.TextBox.outer.CreateLine(theme, row.line)
+	}
 	line.OnMouseDown(func(ev gxui.MouseEvent) {
 		t.TextBox.lineMouseDown(line, ev)
 	})
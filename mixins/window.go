@@ -112,6 +112,7 @@ func (w *Window) Init(outer WindowOuter, driver gxui.Driver, width, height int,
 
 	w.focusController = gxui.CreateFocusController(outer)
 	w.mouseController = gxui.CreateMouseController(outer, w.focusController)
+	w.mouseController.SetClock(driver.Clock())
 	w.keyboardController = gxui.CreateKeyboardController(outer)
 
 	w.onResize.Listen(func() {
@@ -128,6 +129,7 @@ func (w *Window) Init(outer WindowOuter, driver gxui.Driver, width, height int,
 
 	// Interface compliance test
 	_ = gxui.Window(w)
+	_ = gxui.InputInjector(w)
 }
 
 func (w *Window) Draw() gxui.Canvas {
@@ -324,6 +326,47 @@ func (w *Window) OnKeyStroke(f func(gxui.KeyStrokeEvent)) gxui.EventSubscription
 	return w.onKeyStroke.Listen(f)
 }
 
+// gxui.InputInjector compliance
+func (w *Window) InjectMouseMove(ev gxui.MouseEvent) {
+	w.onMouseMove.Fire(ev)
+}
+
+func (w *Window) InjectMouseEnter(ev gxui.MouseEvent) {
+	w.onMouseEnter.Fire(ev)
+}
+
+func (w *Window) InjectMouseExit(ev gxui.MouseEvent) {
+	w.onMouseExit.Fire(ev)
+}
+
+func (w *Window) InjectMouseDown(ev gxui.MouseEvent) {
+	w.onMouseDown.Fire(ev)
+}
+
+func (w *Window) InjectMouseUp(ev gxui.MouseEvent) {
+	w.onMouseUp.Fire(ev)
+}
+
+func (w *Window) InjectMouseScroll(ev gxui.MouseEvent) {
+	w.onMouseScroll.Fire(ev)
+}
+
+func (w *Window) InjectKeyDown(ev gxui.KeyboardEvent) {
+	w.onKeyDown.Fire(ev)
+}
+
+func (w *Window) InjectKeyUp(ev gxui.KeyboardEvent) {
+	w.onKeyUp.Fire(ev)
+}
+
+func (w *Window) InjectKeyRepeat(ev gxui.KeyboardEvent) {
+	w.onKeyRepeat.Fire(ev)
+}
+
+func (w *Window) InjectKeyStroke(ev gxui.KeyStrokeEvent) {
+	w.onKeyStroke.Fire(ev)
+}
+
 func (w *Window) Relayout() {
 	w.layoutPending = true
 	w.requestUpdate()
@@ -13,6 +13,7 @@ var doubleClickTime = time.Millisecond * 300
 type MouseController struct {
 	window          Window
 	focusController *FocusController
+	clock           Clock
 	lastOver        ControlPointList
 	lastDown        map[MouseButton]ControlPointList
 	lastUpTime      map[MouseButton]time.Time
@@ -22,6 +23,7 @@ func CreateMouseController(w Window, focusController *FocusController) *MouseCon
 	c := &MouseController{
 		window:          w,
 		focusController: focusController,
+		clock:           SystemClock,
 		lastDown:        make(map[MouseButton]ControlPointList),
 		lastUpTime:      make(map[MouseButton]time.Time),
 	}
@@ -34,6 +36,20 @@ func CreateMouseController(w Window, focusController *FocusController) *MouseCon
 	return c
 }
 
+// Clock returns the clock used to time double-clicks.
+func (m *MouseController) Clock() Clock {
+	return m.clock
+}
+
+// SetClock sets the clock used to time double-clicks. The default is
+// SystemClock.
+func (m *MouseController) SetClock(clock Clock) {
+	if clock == nil {
+		panic("Clock must not be nil")
+	}
+	m.clock = clock
+}
+
 func (m *MouseController) updatePosition(ev MouseEvent) {
 	ValidateHierarchy(m.window)
 
@@ -90,7 +106,7 @@ func (m *MouseController) mouseUp(ev MouseEvent) {
 
 	setFocusCount := m.focusController.SetFocusCount()
 
-	dblClick := time.Since(m.lastUpTime[ev.Button]) < doubleClickTime
+	dblClick := m.clock.Now().Sub(m.lastUpTime[ev.Button]) < doubleClickTime
 	clickConsumed := false
 	for i := len(m.lastDown[ev.Button]) - 1; i >= 0; i-- {
 		cp := m.lastDown[ev.Button][i]
@@ -128,7 +144,7 @@ func (m *MouseController) mouseUp(ev MouseEvent) {
 	}
 
 	delete(m.lastDown, ev.Button)
-	m.lastUpTime[ev.Button] = time.Now()
+	m.lastUpTime[ev.Button] = m.clock.Now()
 }
 
 func (m *MouseController) mouseScroll(ev MouseEvent) {
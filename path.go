@@ -0,0 +1,243 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gxui
+
+import (
+	"github.com/google/gxui/math"
+)
+
+// FillRule decides which regions of a VectorPath are inside the path when filled.
+type FillRule int
+
+const (
+	// NonZero fills regions where the contours surrounding the region wind a
+	// non-zero number of times in total, counting clockwise windings as +1 and
+	// anti-clockwise windings as -1.
+	NonZero FillRule = iota
+	// EvenOdd fills regions surrounded by an odd number of contours.
+	EvenOdd
+)
+
+type PathCommandType int
+
+const (
+	MoveTo  PathCommandType = iota // Points[0] is the new position
+	LineTo                         // Points[0] is the end point
+	QuadTo                         // Points[0] is the control point, Points[1] the end point
+	CubicTo                        // Points[0:2] are the control points, Points[2] the end point
+	Close
+)
+
+// PathCommand is a single command of a VectorPath.
+type PathCommand struct {
+	Type   PathCommandType
+	Points [3]math.Vec2
+}
+
+// VectorPath is a sequence of contours formed from straight lines and Bézier curves,
+// in DIPs. The zero value is an empty path using the NonZero fill rule.
+type VectorPath struct {
+	FillRule FillRule
+	commands []PathCommand
+	start    math.Vec2 // Start of the current contour
+	current  math.Vec2 // End of the last command
+	open     bool      // True if a contour has been started
+}
+
+// Commands returns the commands that describe the path. Arcs are described
+// using cubic Bézier curves.
+func (p *VectorPath) Commands() []PathCommand {
+	return p.commands
+}
+
+// IsEmpty returns true if the path has no commands.
+func (p *VectorPath) IsEmpty() bool {
+	return len(p.commands) == 0
+}
+
+// CurrentPoint returns the position at the end of the last command.
+func (p *VectorPath) CurrentPoint() math.Vec2 {
+	return p.current
+}
+
+func (p *VectorPath) add(ty PathCommandType, points ...math.Vec2) {
+	c := PathCommand{Type: ty}
+	copy(c.Points[:], points)
+	p.commands = append(p.commands, c)
+}
+
+// ensureOpen starts a new contour at the current point if there is no open
+// contour.
+func (p *VectorPath) ensureOpen() {
+	if !p.open {
+		p.MoveTo(p.current)
+	}
+}
+
+// MoveTo starts a new contour at v.
+func (p *VectorPath) MoveTo(v math.Vec2) {
+	p.add(MoveTo, v)
+	p.start, p.current, p.open = v, v, true
+}
+
+// LineTo adds a straight line from the current point to v.
+func (p *VectorPath) LineTo(v math.Vec2) {
+	p.ensureOpen()
+	p.add(LineTo, v)
+	p.current = v
+}
+
+// QuadTo adds a quadratic Bézier curve from the current point to v, using the
+// control point c.
+func (p *VectorPath) QuadTo(c, v math.Vec2) {
+	p.ensureOpen()
+	p.add(QuadTo, c, v)
+	p.current = v
+}
+
+// CubicTo adds a cubic Bézier curve from the current point to v, using the
+// control points c1 and c2.
+func (p *VectorPath) CubicTo(c1, c2, v math.Vec2) {
+	p.ensureOpen()
+	p.add(CubicTo, c1, c2, v)
+	p.current = v
+}
+
+// Arc adds a circular arc around center, from the angle start to the angle
+// end in radians. Positive angles rotate from the +X axis towards the +Y axis.
+// If the path has an open contour then a straight line is added from the
+// current point to the start of the arc, otherwise the arc starts a new
+// contour.
+func (p *VectorPath) Arc(center math.Vec2, radius, start, end float32) {
+	at := func(θ float32) math.Vec2 {
+		return center.Add(math.Vec2{X: math.Cosf(θ), Y: math.Sinf(θ)}.MulS(radius))
+	}
+	if p.open {
+		p.LineTo(at(start))
+	} else {
+		p.MoveTo(at(start))
+	}
+
+	// Approximate with one cubic per quarter turn or less.
+	sweep := end - start
+	segments := int(math.Ceilf(math.Absf(sweep) / (math.Pi / 2)))
+	step := sweep / float32(segments)
+	k := 4.0 / 3.0 * math.Tanf(step/4) * radius
+	for i := 0; i < segments; i++ {
+		θ0 := start + step*float32(i)
+		θ1 := θ0 + step
+		a, b := at(θ0), at(θ1)
+		ta := math.Vec2{X: -math.Sinf(θ0), Y: math.Cosf(θ0)}
+		tb := math.Vec2{X: -math.Sinf(θ1), Y: math.Cosf(θ1)}
+		p.CubicTo(a.Add(ta.MulS(k)), b.Sub(tb.MulS(k)), b)
+	}
+}
+
+// Close closes the current contour with a straight line back to its start.
+func (p *VectorPath) Close() {
+	if p.open {
+		p.add(Close)
+		p.current, p.open = p.start, false
+	}
+}
+
+// AddRect adds the rectangle r as a closed, clockwise contour.
+func (p *VectorPath) AddRect(r math.Rect) {
+	p.MoveTo(r.TL().Vec2())
+	p.LineTo(r.TR().Vec2())
+	p.LineTo(r.BR().Vec2())
+	p.LineTo(r.BL().Vec2())
+	p.Close()
+}
+
+// AddCircle adds a closed, clockwise circular contour.
+func (p *VectorPath) AddCircle(center math.Vec2, radius float32) {
+	p.open = false
+	p.Arc(center, radius, 0, math.TwoPi)
+	p.Close()
+}
+
+// PathContour is a sequence of points produced by flattening a VectorPath.
+type PathContour struct {
+	Points []math.Vec2
+	Closed bool // True if the contour was closed with Close.
+}
+
+// Flatten returns the contours of the path with each curve replaced by
+// straight lines that deviate from the curve by no more than tolerance.
+func (p *VectorPath) Flatten(tolerance float32) []PathContour {
+	contours := []PathContour{}
+	var cur *PathContour
+	last := math.Vec2{}
+	for _, c := range p.commands {
+		switch c.Type {
+		case MoveTo:
+			contours = append(contours, PathContour{Points: []math.Vec2{c.Points[0]}})
+			cur = &contours[len(contours)-1]
+			last = c.Points[0]
+			continue
+		case LineTo:
+			cur.Points = append(cur.Points, c.Points[0])
+		case QuadTo:
+			p0, p1, p2 := last, c.Points[0], c.Points[1]
+			n := curveSegments(p0.Sub(p1.MulS(2)).Add(p2).Len()/4, tolerance)
+			for i := 1; i <= n; i++ {
+				t := float32(i) / float32(n)
+				u := 1 - t
+				cur.Points = append(cur.Points,
+					p0.MulS(u*u).Add(p1.MulS(2*u*t)).Add(p2.MulS(t*t)))
+			}
+		case CubicTo:
+			p0, p1, p2, p3 := last, c.Points[0], c.Points[1], c.Points[2]
+			dd := math.Maxf(
+				p0.Sub(p1.MulS(2)).Add(p2).Len(),
+				p1.Sub(p2.MulS(2)).Add(p3).Len(),
+			)
+			n := curveSegments(dd*3/4, tolerance)
+			for i := 1; i <= n; i++ {
+				t := float32(i) / float32(n)
+				u := 1 - t
+				cur.Points = append(cur.Points,
+					p0.MulS(u*u*u).Add(p1.MulS(3*u*u*t)).Add(p2.MulS(3*u*t*t)).Add(p3.MulS(t*t*t)))
+			}
+		case Close:
+			cur.Closed = true
+			last = cur.Points[0]
+			continue
+		}
+		last = cur.Points[len(cur.Points)-1]
+	}
+	return contours
+}
+
+// curveSegments returns the number of lines needed to approximate a curve
+// with the second-derivative bound m to within tolerance (Wang's formula).
+func curveSegments(m, tolerance float32) int {
+	if tolerance <= 0 {
+		tolerance = 0.1
+	}
+	return math.Max(1, int(math.Ceilf(math.Sqrtf(m/tolerance))))
+}
+
+// Bounds returns the rectangle bounding the path's points, including the
+// control points of curves.
+func (p *VectorPath) Bounds() math.Rect {
+	if len(p.commands) == 0 {
+		return math.Rect{}
+	}
+	min := p.commands[0].Points[0]
+	max := min
+	for _, c := range p.commands {
+		n := [...]int{MoveTo: 1, LineTo: 1, QuadTo: 2, CubicTo: 3, Close: 0}[c.Type]
+		for _, v := range c.Points[:n] {
+			min = math.Vec2{X: math.Minf(min.X, v.X), Y: math.Minf(min.Y, v.Y)}
+			max = math.Vec2{X: math.Maxf(max.X, v.X), Y: math.Maxf(max.Y, v.Y)}
+		}
+	}
+	return math.Rect{
+		Min: math.Point{X: int(math.Floorf(min.X)), Y: int(math.Floorf(min.Y))},
+		Max: math.Point{X: int(math.Ceilf(max.X)), Y: int(math.Ceilf(max.Y))},
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gxui
+
+import (
+	"testing"
+
+	"github.com/google/gxui/math"
+	test "github.com/google/gxui/testing"
+)
+
+func TestPathFlattenLines(t *testing.T) {
+	p := VectorPath{}
+	p.MoveTo(math.Vec2{X: 1, Y: 2})
+	p.LineTo(math.Vec2{X: 3, Y: 2})
+	p.LineTo(math.Vec2{X: 3, Y: 5})
+	p.Close()
+	p.LineTo(math.Vec2{X: 0, Y: 0})
+
+	test.AssertEquals(t, []PathContour{
+		{Points: []math.Vec2{{X: 1, Y: 2}, {X: 3, Y: 2}, {X: 3, Y: 5}}, Closed: true},
+		{Points: []math.Vec2{{X: 1, Y: 2}, {X: 0, Y: 0}}},
+	}, p.Flatten(0.1))
+	test.AssertEquals(t, math.CreateRect(0, 0, 3, 5), p.Bounds())
+}
+
+func TestPathFlattenCurves(t *testing.T) {
+	const tolerance = 0.01
+	p := VectorPath{}
+	p.AddCircle(math.Vec2{X: 10, Y: 10}, 5)
+	contours := p.Flatten(tolerance)
+	test.AssertEquals(t, 1, len(contours))
+	test.AssertEquals(t, true, contours[0].Closed)
+	for _, v := range contours[0].Points {
+		// The cubic approximation of a circle is accurate to within 0.03%.
+		d := v.Sub(math.Vec2{X: 10, Y: 10}).Len()
+		test.AssertEquals(t, true, math.Absf(d-5) < 0.005)
+	}
+
+	q := VectorPath{}
+	q.MoveTo(math.Vec2{X: 0, Y: 0})
+	q.QuadTo(math.Vec2{X: 5, Y: 10}, math.Vec2{X: 10, Y: 0})
+	points := q.Flatten(tolerance)[0].Points
+	test.AssertEquals(t, math.Vec2{X: 10, Y: 0}, points[len(points)-1])
+	test.AssertEquals(t, true, len(points) > 10)
+}
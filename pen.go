@@ -8,11 +8,69 @@ var DefaultPen Pen = CreatePen(1.0, Black)
 var TransparentPen Pen = CreatePen(0.0, Transparent)
 var WhitePen Pen = CreatePen(1.0, White)
 
+// The miter limit used when Pen.MiterLimit is 0.
+const DefaultMiterLimit = 4.0
+
+// LineCap is the shape drawn at the ends of open lines and dashes.
+type LineCap int
+
+const (
+	// ButtCap ends the line flat at the end point.
+	ButtCap LineCap = iota
+	// RoundCap ends the line with a semicircle centered on the end point.
+	RoundCap
+	// SquareCap ends the line flat, half the pen width beyond the end point.
+	SquareCap
+)
+
+// LineJoin is the shape drawn where two line segments meet.
+type LineJoin int
+
+const (
+	// MiterJoin extends the outer edges of the segments until they meet. If
+	// the miter length exceeds the pen's miter limit then a BevelJoin is used.
+	MiterJoin LineJoin = iota
+	// RoundJoin joins the segments with a circular arc.
+	RoundJoin
+	// BevelJoin joins the segments with a straight line between their outer
+	// corners.
+	BevelJoin
+)
+
+// Dash describes the on and off pattern of a dashed pen.
+type Dash struct {
+	// Lengths holds the alternating on and off lengths of the pattern in DIPs,
+	// starting with the first on length. If Lengths has an odd number of
+	// elements then the pattern is repeated to make it even.
+	Lengths []float32
+	// Offset is the distance into the pattern at which the line starts.
+	Offset float32
+}
+
+// CreateDash returns a dash pattern with the alternating on and off lengths.
+func CreateDash(offset float32, lengths ...float32) *Dash {
+	return &Dash{Lengths: lengths, Offset: offset}
+}
+
+// Pen describes how lines and the outlines of shapes are stroked.
+// Strokes are drawn to the right of the line when travelling from the first
+// vertex to the last. For polygons with a clockwise winding, such as those
+// drawn by Canvas.DrawRoundedRect, this is the inside of the outline.
 type Pen struct {
-	Width float32
-	Color Color
+	Width      float32
+	Color      Color
+	Cap        LineCap
+	Join       LineJoin
+	MiterLimit float32 // The maximum ratio of miter length to pen width.
+	Dash       *Dash   // nil for a solid line.
 }
 
 func CreatePen(width float32, color Color) Pen {
-	return Pen{width, color}
+	return Pen{Width: width, Color: color}
+}
+
+// IsStyled returns true if the pen uses a dash pattern, a line cap, a line
+// join or a miter limit other than the defaults.
+func (p Pen) IsStyled() bool {
+	return p.Dash != nil || p.Cap != ButtCap || p.Join != MiterJoin || p.MiterLimit != 0
 }
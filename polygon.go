@@ -14,3 +14,16 @@ type PolygonVertex struct {
 }
 
 type Polygon []PolygonVertex
+
+// Bounds returns the rectangle bounding all the vertices of the polygon.
+func (p Polygon) Bounds() math.Rect {
+	if len(p) == 0 {
+		return math.Rect{}
+	}
+	r := math.Rect{Min: p[0].Position, Max: p[0].Position}
+	for _, v := range p[1:] {
+		r.Min = r.Min.Min(v.Position)
+		r.Max = r.Max.Max(v.Position)
+	}
+	return r
+}
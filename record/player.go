@@ -0,0 +1,76 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package record
+
+import (
+	"fmt"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+)
+
+// Play resizes window to the recorded window size, then replays the events of
+// rec into window, in order. Each event is dispatched on the UI go-routine and
+// completes before the next is dispatched. The recorded time between events
+// is preserved using the driver's clock, so that time-dependent behaviour,
+// such as double-click detection, is reproduced.
+//
+// window must implement gxui.InputInjector. Play blocks until all the events
+// have been dispatched, and so must not be called on the UI go-routine. Play
+// returns false if the driver was terminated before playback completed.
+func Play(driver gxui.Driver, window gxui.Window, rec *Recording) bool {
+	injector, ok := window.(gxui.InputInjector)
+	if !ok {
+		panic(fmt.Errorf("Window %T does not implement gxui.InputInjector", window))
+	}
+	if rec.WindowSize != (math.Size{}) {
+		if !driver.CallSync(func() { window.Viewport().SetSizeDips(rec.WindowSize) }) {
+			return false
+		}
+	}
+	clock := driver.Clock()
+	start := clock.Now()
+	for _, ev := range rec.Events {
+		if d := ev.Time - clock.Now().Sub(start); d > 0 {
+			due := make(chan struct{})
+			clock.AfterFunc(d, func() { close(due) })
+			<-due
+		}
+		ev := ev
+		if !driver.CallSync(func() { Inject(injector, ev) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// Inject dispatches the single recorded event ev into injector. Inject must be
+// called on the UI go-routine.
+func Inject(injector gxui.InputInjector, ev Event) {
+	switch ev.Type {
+	case MouseMove:
+		injector.InjectMouseMove(ev.MouseEvent())
+	case MouseEnter:
+		injector.InjectMouseEnter(ev.MouseEvent())
+	case MouseExit:
+		injector.InjectMouseExit(ev.MouseEvent())
+	case MouseDown:
+		injector.InjectMouseDown(ev.MouseEvent())
+	case MouseUp:
+		injector.InjectMouseUp(ev.MouseEvent())
+	case MouseScroll:
+		injector.InjectMouseScroll(ev.MouseEvent())
+	case KeyDown:
+		injector.InjectKeyDown(ev.KeyboardEvent())
+	case KeyUp:
+		injector.InjectKeyUp(ev.KeyboardEvent())
+	case KeyRepeat:
+		injector.InjectKeyRepeat(ev.KeyboardEvent())
+	case KeyStroke:
+		injector.InjectKeyStroke(ev.KeyStrokeEvent())
+	default:
+		panic(fmt.Errorf("Unknown event type '%s'", ev.Type))
+	}
+}
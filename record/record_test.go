@@ -0,0 +1,148 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package record
+
+import test "github.com/google/gxui/testing"
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/drivers/soft"
+	"github.com/google/gxui/math"
+	"github.com/google/gxui/themes/dark"
+)
+
+// session creates a window holding two text boxes, using a driver with clock,
+// then calls f on a separate go-routine. It returns the text of each box once
+// f returns.
+func session(clock gxui.Clock, f func(driver gxui.Driver, window gxui.Window)) (string, string) {
+	var a, b gxui.TextBox
+	soft.StartDriverWithClock(clock, func(driver gxui.Driver) {
+		theme := dark.CreateTheme(driver)
+		window := theme.CreateWindow(200, 100, "test")
+		layout := theme.CreateLinearLayout()
+		a, b = theme.CreateTextBox(), theme.CreateTextBox()
+		a.SetDesiredWidth(100)
+		b.SetDesiredWidth(100)
+		layout.AddChild(a)
+		layout.AddChild(b)
+		window.AddChild(layout)
+		go func() {
+			defer driver.Terminate()
+			driver.CallSync(func() {}) // Wait for layout
+			f(driver, window)
+		}()
+	})
+	return a.Text(), b.Text()
+}
+
+func TestRecordAndPlay(t *testing.T) {
+	var rec *Recording
+	a, b := session(gxui.SystemClock, func(driver gxui.Driver, window gxui.Window) {
+		v := window.Viewport().(soft.Viewport)
+		var r *Recorder
+		driver.CallSync(func() { r = Start(driver, window) })
+
+		click := func(p math.Point) {
+			v.InjectMouseMove(gxui.MouseEvent{Point: p})
+			v.InjectMouseDown(gxui.MouseEvent{Point: p, State: 1})
+			v.InjectMouseUp(gxui.MouseEvent{Point: p})
+		}
+		click(math.Point{X: 10, Y: 5})
+		v.InjectKeyStroke(gxui.KeyStrokeEvent{Character: 'x'})
+		time.Sleep(400 * time.Millisecond) // Avoid a double-click
+		click(math.Point{X: 10, Y: 30})
+		v.InjectKeyStroke(gxui.KeyStrokeEvent{Character: 'y'})
+		v.InjectKeyStroke(gxui.KeyStrokeEvent{Character: 'z'})
+		v.InjectKeyDown(gxui.KeyboardEvent{Key: gxui.KeyLeft})
+		v.InjectKeyUp(gxui.KeyboardEvent{Key: gxui.KeyLeft})
+		v.InjectKeyDown(gxui.KeyboardEvent{Key: gxui.KeyBackspace})
+		v.InjectKeyUp(gxui.KeyboardEvent{Key: gxui.KeyBackspace})
+
+		driver.CallSync(func() { rec = r.Stop() })
+	})
+	test.AssertEquals(t, "x", a)
+	test.AssertEquals(t, "z", b)
+	test.AssertEquals(t, 13, len(rec.Events))
+
+	buf := &bytes.Buffer{}
+	if err := rec.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.AssertEquals(t, rec.Events, loaded.Events)
+
+	test.AssertEquals(t, math.Size{W: 200, H: 100}, loaded.WindowSize)
+
+	a, b = session(gxui.SystemClock, func(driver gxui.Driver, window gxui.Window) {
+		// Play restores the recorded window size.
+		driver.CallSync(func() { window.Viewport().SetSizeDips(math.Size{W: 300, H: 150}) })
+		test.AssertEquals(t, true, Play(driver, window, loaded))
+		var size math.Size
+		driver.CallSync(func() { size = window.Viewport().SizeDips() })
+		test.AssertEquals(t, math.Size{W: 200, H: 100}, size)
+	})
+	test.AssertEquals(t, "x", a)
+	test.AssertEquals(t, "z", b)
+}
+
+func TestRecordAndPlayManualClock(t *testing.T) {
+	var rec *Recording
+	clock := gxui.CreateManualClock(time.Time{})
+	session(clock, func(driver gxui.Driver, window gxui.Window) {
+		v := window.Viewport().(soft.Viewport)
+		var r *Recorder
+		driver.CallSync(func() { r = Start(driver, window) })
+		click := func(p math.Point) {
+			v.InjectMouseDown(gxui.MouseEvent{Point: p, State: 1})
+			v.InjectMouseUp(gxui.MouseEvent{Point: p})
+		}
+		click(math.Point{X: 10, Y: 5})
+		driver.CallSync(func() {}) // Wait for the click
+		clock.Advance(400 * time.Millisecond)
+		click(math.Point{X: 10, Y: 30})
+		v.InjectKeyStroke(gxui.KeyStrokeEvent{Character: 'z'})
+		driver.CallSync(func() { rec = r.Stop() })
+	})
+
+	// The events are timed by the driver's clock, not the wall clock.
+	times := []time.Duration{}
+	for _, ev := range rec.Events {
+		times = append(times, ev.Time)
+	}
+	test.AssertEquals(t, []time.Duration{0, 0, 400 * time.Millisecond, 400 * time.Millisecond, 400 * time.Millisecond}, times)
+
+	// Play waits on the driver's clock, which only moves when advanced.
+	clock = gxui.CreateManualClock(time.Time{})
+	_, b := session(clock, func(driver gxui.Driver, window gxui.Window) {
+		done := make(chan bool)
+		go func() { done <- Play(driver, window, rec) }()
+		for {
+			select {
+			case ok := <-done:
+				test.AssertEquals(t, true, ok)
+				test.AssertEquals(t, true, clock.Now().Sub(time.Time{}) >= 400*time.Millisecond)
+				return
+			default:
+				if clock.Pending() > 0 {
+					clock.Advance(100 * time.Millisecond)
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}
+	})
+	test.AssertEquals(t, "z", b)
+}
+
+func TestReadUnsupportedVersion(t *testing.T) {
+	_, err := Read(strings.NewReader(`{"Version": 99}`))
+	test.AssertEquals(t, true, err != nil)
+}
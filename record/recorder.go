@@ -0,0 +1,97 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package record
+
+import (
+	"time"
+
+	"github.com/google/gxui"
+)
+
+// Recorder records the input events raised by a window's viewport.
+type Recorder struct {
+	clock         gxui.Clock
+	start         time.Time
+	recording     *Recording
+	subscriptions []gxui.EventSubscription
+}
+
+// Start begins recording the input received by window. The recorder listens
+// to the same viewport events that the window dispatches to its controls.
+// Changing the window's viewport, for example with SetFullscreen, ends the
+// recording of events. Events are timed with the driver's clock, which Play
+// also uses, so the recorded times are the times seen by the application.
+//
+// Start must be called on the UI go-routine.
+func Start(driver gxui.Driver, window gxui.Window) *Recorder {
+	clock := driver.Clock()
+	r := &Recorder{
+		clock: clock,
+		start: clock.Now(),
+		recording: &Recording{
+			Version:    Version,
+			WindowSize: window.Viewport().SizeDips(),
+		},
+	}
+
+	mouse := func(ty EventType) func(gxui.MouseEvent) {
+		return func(ev gxui.MouseEvent) {
+			r.add(Event{
+				Type:     ty,
+				Point:    ev.Point,
+				Button:   ev.Button,
+				State:    ev.State,
+				ScrollX:  ev.ScrollX,
+				ScrollY:  ev.ScrollY,
+				Modifier: ev.Modifier,
+			})
+		}
+	}
+	keyboard := func(ty EventType) func(gxui.KeyboardEvent) {
+		return func(ev gxui.KeyboardEvent) {
+			r.add(Event{
+				Type:     ty,
+				Key:      ev.Key,
+				Modifier: ev.Modifier,
+			})
+		}
+	}
+
+	v := window.Viewport()
+	r.subscriptions = []gxui.EventSubscription{
+		v.OnMouseMove(mouse(MouseMove)),
+		v.OnMouseEnter(mouse(MouseEnter)),
+		v.OnMouseExit(mouse(MouseExit)),
+		v.OnMouseDown(mouse(MouseDown)),
+		v.OnMouseUp(mouse(MouseUp)),
+		v.OnMouseScroll(mouse(MouseScroll)),
+		v.OnKeyDown(keyboard(KeyDown)),
+		v.OnKeyUp(keyboard(KeyUp)),
+		v.OnKeyRepeat(keyboard(KeyRepeat)),
+		v.OnKeyStroke(func(ev gxui.KeyStrokeEvent) {
+			r.add(Event{
+				Type:      KeyStroke,
+				Character: ev.Character,
+				Modifier:  ev.Modifier,
+			})
+		}),
+	}
+	return r
+}
+
+func (r *Recorder) add(ev Event) {
+	ev.Time = r.clock.Now().Sub(r.start)
+	r.recording.Events = append(r.recording.Events, ev)
+}
+
+// Stop ends the recording and returns the recorded events. Stop must be
+// called on the UI go-routine.
+func (r *Recorder) Stop() *Recording {
+	for _, s := range r.subscriptions {
+		s.Unlisten()
+	}
+	r.subscriptions = nil
+	return r.recording
+}
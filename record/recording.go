@@ -0,0 +1,115 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package record captures the input received by a window so that it can be
+// saved to a file and replayed later, for example to reproduce a bug report.
+package record
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+)
+
+// Version is the version of the recording file format written by
+// Recording.Write. Read rejects files with a different version.
+const Version = 1
+
+// EventType identifies the viewport event that was recorded.
+type EventType string
+
+const (
+	MouseMove   EventType = "MouseMove"
+	MouseEnter  EventType = "MouseEnter"
+	MouseExit   EventType = "MouseExit"
+	MouseDown   EventType = "MouseDown"
+	MouseUp     EventType = "MouseUp"
+	MouseScroll EventType = "MouseScroll"
+	KeyDown     EventType = "KeyDown"
+	KeyUp       EventType = "KeyUp"
+	KeyRepeat   EventType = "KeyRepeat"
+	KeyStroke   EventType = "KeyStroke"
+)
+
+// Event is a single recorded input event. Only the fields relevant to the
+// event's Type are used.
+type Event struct {
+	// Time is the duration from the start of the recording to the event.
+	Time time.Duration
+	Type EventType
+
+	// Mouse event fields. Point is in window coordinates.
+	Point   math.Point       `json:",omitempty"`
+	Button  gxui.MouseButton `json:",omitempty"`
+	State   gxui.MouseState  `json:",omitempty"`
+	ScrollX int              `json:",omitempty"`
+	ScrollY int              `json:",omitempty"`
+
+	// Keyboard event fields.
+	Key       gxui.KeyboardKey `json:",omitempty"`
+	Character rune             `json:",omitempty"`
+
+	Modifier gxui.KeyboardModifier `json:",omitempty"`
+}
+
+// MouseEvent returns the gxui.MouseEvent for a recorded mouse event.
+func (e Event) MouseEvent() gxui.MouseEvent {
+	return gxui.MouseEvent{
+		Button:   e.Button,
+		State:    e.State,
+		Point:    e.Point,
+		ScrollX:  e.ScrollX,
+		ScrollY:  e.ScrollY,
+		Modifier: e.Modifier,
+	}
+}
+
+// KeyboardEvent returns the gxui.KeyboardEvent for a recorded key down, up or
+// repeat event.
+func (e Event) KeyboardEvent() gxui.KeyboardEvent {
+	return gxui.KeyboardEvent{
+		Key:      e.Key,
+		Modifier: e.Modifier,
+	}
+}
+
+// KeyStrokeEvent returns the gxui.KeyStrokeEvent for a recorded key stroke
+// event.
+func (e Event) KeyStrokeEvent() gxui.KeyStrokeEvent {
+	return gxui.KeyStrokeEvent{
+		Character: e.Character,
+		Modifier:  e.Modifier,
+	}
+}
+
+// Recording is an ordered list of input events received by a window.
+type Recording struct {
+	Version int
+	// WindowSize is the size of the window when the recording was started.
+	// Play resizes the window to WindowSize before replaying the events.
+	WindowSize math.Size
+	Events     []Event
+}
+
+// Write encodes the recording to w.
+func (r *Recording) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	return enc.Encode(r)
+}
+
+// Read decodes a recording previously encoded with Recording.Write.
+func Read(r io.Reader) (*Recording, error) {
+	rec := &Recording{}
+	if err := json.NewDecoder(r).Decode(rec); err != nil {
+		return nil, err
+	}
+	if rec.Version != Version {
+		return nil, fmt.Errorf("Unsupported recording version %d, expected %d", rec.Version, Version)
+	}
+	return rec, nil
+}
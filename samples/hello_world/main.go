@@ -31,24 +31,18 @@ func appMain(driver gxui.Driver) {
 
 	window.AddChild(label)
 
-	ticker := time.NewTicker(time.Millisecond * 30)
-	go func() {
-		phase := float32(0)
-		for _ = range ticker.C {
-			c := gxui.Color{
-				R: 0.75 + 0.25*math.Cosf((phase+0.000)*math.TwoPi),
-				G: 0.75 + 0.25*math.Cosf((phase+0.333)*math.TwoPi),
-				B: 0.75 + 0.25*math.Cosf((phase+0.666)*math.TwoPi),
-				A: 0.50 + 0.50*math.Cosf(phase*10),
-			}
-			phase += 0.01
-			driver.Call(func() {
-				label.SetColor(c)
-			})
+	phase := float32(0)
+	driver.Every(time.Millisecond*30, func() {
+		c := gxui.Color{
+			R: 0.75 + 0.25*math.Cosf((phase+0.000)*math.TwoPi),
+			G: 0.75 + 0.25*math.Cosf((phase+0.333)*math.TwoPi),
+			B: 0.75 + 0.25*math.Cosf((phase+0.666)*math.TwoPi),
+			A: 0.50 + 0.50*math.Cosf(phase*10),
 		}
-	}()
+		phase += 0.01
+		label.SetColor(c)
+	})
 
-	window.OnClose(ticker.Stop)
 	window.OnClose(driver.Terminate)
 }
 
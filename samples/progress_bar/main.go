@@ -35,14 +35,9 @@ func appMain(driver gxui.Driver) {
 	window.OnClose(driver.Terminate)
 
 	progress := 0
-	pause := time.Millisecond * 500
-	var timer *time.Timer
-	timer = time.AfterFunc(pause, func() {
-		driver.Call(func() {
-			progress = (progress + 3) % progressBar.Target()
-			progressBar.SetProgress(progress)
-			timer.Reset(pause)
-		})
+	driver.Every(time.Millisecond*500, func() {
+		progress = (progress + 3) % progressBar.Target()
+		progressBar.SetProgress(progress)
 	})
 }
 
@@ -0,0 +1,59 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gxui
+
+import (
+	"github.com/google/gxui/math"
+)
+
+// Shadow describes the shadow of a rounded rectangle, as drawn by
+// Canvas.DrawShadow.
+type Shadow struct {
+	// Color is the colour of the shadow where it is fully opaque.
+	Color Color
+
+	// Offset is the distance in DIPs that the shadow is moved from the shape.
+	Offset math.Point
+
+	// Blur is the radius in DIPs over which the edge of the shadow fades from
+	// opaque to transparent. A Blur of 0 draws a hard-edged shadow.
+	Blur float32
+
+	// Inner draws the shadow inside the shape, as if the shape were cut out of
+	// the canvas, instead of outside it.
+	Inner bool
+}
+
+// NoShadow is a Shadow that draws nothing.
+var NoShadow = Shadow{}
+
+// CreateShadow returns an outer Shadow of the given colour, offset and blur
+// radius.
+func CreateShadow(color Color, offset math.Point, blur float32) Shadow {
+	return Shadow{Color: color, Offset: offset, Blur: blur}
+}
+
+// CreateInnerShadow returns an inner Shadow of the given colour, offset and
+// blur radius.
+func CreateInnerShadow(color Color, offset math.Point, blur float32) Shadow {
+	return Shadow{Color: color, Offset: offset, Blur: blur, Inner: true}
+}
+
+// IsVisible returns true if drawing the shadow would change any pixels.
+func (s Shadow) IsVisible() bool {
+	return s.Color.A > 0
+}
+
+// Bounds returns the bounds of the pixels that may be changed by drawing the
+// shadow of the rectangle r.
+func (s Shadow) Bounds(r math.Rect) math.Rect {
+	if s.Inner {
+		return r
+	}
+	// The fade of the blur is a Gaussian with a standard deviation of half the
+	// blur radius, which is negligible beyond three standard deviations.
+	e := int(s.Blur*1.5 + 0.999)
+	return r.Offset(s.Offset).ExpandI(e)
+}
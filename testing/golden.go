@@ -0,0 +1,143 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+var (
+	diffMatchColor    = color.RGBA{A: 0xff}
+	diffMismatchColor = color.RGBA{R: 0xff, A: 0xff}
+)
+
+// LoadPNG loads the PNG image at path.
+func LoadPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+// SavePNG writes img to path as a PNG image, creating any missing parent
+// directories.
+func SavePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// CompareImages compares expected and actual pixel-by-pixel, returning the
+// number of pixels where any channel differs by more than tolerance. diff is
+// an image covering both images, where matching pixels are drawn as a faded
+// copy of actual and mismatching pixels are drawn in red.
+func CompareImages(expected, actual image.Image, tolerance uint8) (mismatches int, diff *image.RGBA) {
+	eb, ab := expected.Bounds(), actual.Bounds()
+	w, h := eb.Dx(), eb.Dy()
+	if ab.Dx() > w {
+		w = ab.Dx()
+	}
+	if ab.Dy() > h {
+		h = ab.Dy()
+	}
+	diff = image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(diff, diff.Bounds(), image.NewUniform(diffMatchColor), image.ZP, draw.Src)
+	draw.Draw(diff, diff.Bounds(), actual, ab.Min, draw.Over)
+	draw.Draw(diff, diff.Bounds(), image.NewUniform(color.RGBA{A: 0xc0}), image.ZP, draw.Over)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			e, eok := pixelAt(expected, x, y)
+			a, aok := pixelAt(actual, x, y)
+			if eok != aok || (eok && !withinTolerance(e, a, tolerance)) {
+				mismatches++
+				diff.SetRGBA(x, y, diffMismatchColor)
+			}
+		}
+	}
+	return mismatches, diff
+}
+
+// AssertImageMatchesGolden compares actual against the PNG golden image at
+// path, failing the test if any channel of any pixel differs by more than
+// tolerance. On failure a diff image is written next to the golden, with the
+// extension ".diff.png". If update is true, the golden is replaced with actual
+// instead. Tests usually pass the value of an -update flag as update.
+func AssertImageMatchesGolden(t *testing.T, actual image.Image, path string, tolerance uint8, update bool) {
+	_, file, line, _ := runtime.Caller(1)
+	if update {
+		if err := SavePNG(path, actual); err != nil {
+			fmt.Printf("%s:%d ASSERT: Failed to update golden '%s': %v\n", file, line, path, err)
+			t.Fail()
+		}
+		return
+	}
+
+	expected, err := LoadPNG(path)
+	if err != nil {
+		fmt.Printf("%s:%d ASSERT: Failed to load golden '%s': %v\n(update the golden to create it)\n", file, line, path, err)
+		t.Fail()
+		return
+	}
+
+	mismatches, diff := CompareImages(expected, actual, tolerance)
+	if mismatches == 0 {
+		return
+	}
+
+	diffPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".diff.png"
+	if err := SavePNG(diffPath, diff); err != nil {
+		diffPath = fmt.Sprintf("<failed to write diff: %v>", err)
+	}
+	fmt.Printf("%s:%d ASSERT: Image does not match golden '%s':\n"+
+		"Expected size %v, got %v. %d pixels differ by more than %d.\n"+
+		"Diff: %s\n",
+		file, line, path, expected.Bounds().Size(), actual.Bounds().Size(), mismatches, tolerance, diffPath)
+	t.Fail()
+}
+
+// pixelAt returns the non-premultiplied color of the pixel at (x, y) relative
+// to the top-left of img's bounds, and whether the point is inside img.
+func pixelAt(img image.Image, x, y int) (color.NRGBA, bool) {
+	b := img.Bounds()
+	p := image.Point{X: b.Min.X + x, Y: b.Min.Y + y}
+	if !p.In(b) {
+		return color.NRGBA{}, false
+	}
+	return color.NRGBAModel.Convert(img.At(p.X, p.Y)).(color.NRGBA), true
+}
+
+func withinTolerance(a, b color.NRGBA, tolerance uint8) bool {
+	d := func(a, b uint8) uint8 {
+		if a > b {
+			return a - b
+		}
+		return b - a
+	}
+	return d(a.R, b.R) <= tolerance &&
+		d(a.G, b.G) <= tolerance &&
+		d(a.B, b.B) <= tolerance &&
+		d(a.A, b.A) <= tolerance
+}
@@ -0,0 +1,41 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func solid(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i+0], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+func TestCompareImagesTolerance(t *testing.T) {
+	a := solid(4, 4, color.RGBA{R: 100, G: 100, B: 100, A: 255})
+	b := solid(4, 4, color.RGBA{R: 102, G: 100, B: 100, A: 255})
+	b.SetRGBA(1, 2, color.RGBA{R: 110, G: 100, B: 100, A: 255})
+
+	mismatches, diff := CompareImages(a, b, 2)
+	AssertEquals(t, 1, mismatches)
+	AssertEquals(t, diffMismatchColor, diff.RGBAAt(1, 2))
+
+	mismatches, _ = CompareImages(a, b, 10)
+	AssertEquals(t, 0, mismatches)
+}
+
+func TestCompareImagesSizeMismatch(t *testing.T) {
+	a := solid(4, 4, color.RGBA{A: 255})
+	b := solid(4, 5, color.RGBA{A: 255})
+
+	mismatches, diff := CompareImages(a, b, 0)
+	AssertEquals(t, 4, mismatches)
+	AssertEquals(t, image.Rect(0, 0, 4, 5), diff.Bounds())
+}
@@ -0,0 +1,58 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package snapshot renders control trees with the soft driver so they can be
+// compared against golden images. For example:
+//
+//	img := snapshot.Render(math.Size{W: 100, H: 30}, dark.CreateTheme,
+//	    func(theme gxui.Theme) gxui.Control {
+//	        b := theme.CreateButton()
+//	        b.SetText("OK")
+//	        return b
+//	    })
+//	test.AssertImageMatchesGolden(t, img, "testdata/button.png", 2, *update)
+//
+// where update is the test's -update flag.
+//
+// The image comparison helpers live in the gxui testing package. They are kept
+// separate from this package as gxui's own tests depend on the testing
+// package, which therefore must not depend on gxui.
+package snapshot
+
+import (
+	"image"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/drivers/soft"
+	"github.com/google/gxui/math"
+)
+
+// ThemeFactory is the function signature used to create a theme, for example
+// dark.CreateTheme.
+type ThemeFactory func(gxui.Driver) gxui.Theme
+
+// ControlFactory is the function signature used to build the control tree
+// being snapshotted.
+type ControlFactory func(gxui.Theme) gxui.Control
+
+// Render creates a window of the given size using a new soft driver and the
+// theme returned by createTheme, adds the control returned by create to the
+// window, then lays out and draws the window. The rendered pixels are
+// returned.
+func Render(size math.Size, createTheme ThemeFactory, create ControlFactory) *image.RGBA {
+	var img *image.RGBA
+	soft.StartDriver(func(driver gxui.Driver) {
+		theme := createTheme(driver)
+		window := theme.CreateWindow(size.W, size.H, "snapshot")
+		window.AddChild(create(theme))
+		// Adding the child queues the layout and draw of the window. Capture
+		// the frame once this has been processed.
+		driver.Call(func() {
+			img = window.Viewport().(soft.Viewport).Image()
+			window.Close()
+			driver.Terminate()
+		})
+	})
+	return img
+}
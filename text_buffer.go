@@ -0,0 +1,114 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gxui
+
+import (
+	"sort"
+)
+
+// TextBuffer holds the runes of a TextBoxController's text along with an index
+// of its lines, which are separated by '\n' runes.
+type TextBuffer interface {
+	// Len returns the number of runes in the buffer.
+	Len() int
+
+	// RuneAt returns the rune at index i.
+	RuneAt(i int) rune
+
+	// Runes returns a copy of the runes from index s up to index e.
+	Runes(s, e int) []rune
+
+	// Replace replaces the runes from index s up to index e with r. The buffer
+	// does not hold on to r.
+	Replace(s, e int, r []rune)
+
+	// LineCount returns the number of lines, which is one more than the number
+	// of newlines.
+	LineCount() int
+
+	// LineStart returns the index of the first rune of the line.
+	LineStart(line int) int
+
+	// LineEnd returns the index of the newline that ends the line, or Len() for
+	// the last line.
+	LineEnd(line int) int
+
+	// LineIndex returns the index of the line that holds the rune index i. A
+	// newline belongs to the line it ends.
+	LineIndex(i int) int
+}
+
+// sliceTextBuffer is a TextBuffer that holds the text in a single slice, and
+// rebuilds the line index on every edit.
+type sliceTextBuffer struct {
+	text       []rune
+	lineStarts []int
+	lineEnds   []int
+}
+
+// CreateSliceTextBuffer returns a TextBuffer that holds the text in a single
+// slice. Edits take time proportional to the length of the text, which is
+// only suitable for short text.
+func CreateSliceTextBuffer() TextBuffer {
+	b := &sliceTextBuffer{}
+	b.updateLines()
+	return b
+}
+
+func (b *sliceTextBuffer) updateLines() {
+	b.lineStarts = append(b.lineStarts[:0], 0)
+	b.lineEnds = b.lineEnds[:0]
+	for i, r := range b.text {
+		if r == '\n' {
+			b.lineEnds = append(b.lineEnds, i)
+			b.lineStarts = append(b.lineStarts, i+1)
+		}
+	}
+	b.lineEnds = append(b.lineEnds, len(b.text))
+}
+
+// TextBuffer compliance
+func (b *sliceTextBuffer) Len() int {
+	return len(b.text)
+}
+
+func (b *sliceTextBuffer) RuneAt(i int) rune {
+	return b.text[i]
+}
+
+func (b *sliceTextBuffer) Runes(s, e int) []rune {
+	return append([]rune{}, b.text[s:e]...)
+}
+
+func (b *sliceTextBuffer) Replace(s, e int, r []rune) {
+	delta := len(r) - (e - s)
+	if delta > 0 {
+		b.text = append(b.text, make([]rune, delta)...)
+	}
+	copy(b.text[e+delta:], b.text[e:])
+	copy(b.text[s:], r)
+	if delta < 0 {
+		b.text = b.text[:len(b.text)+delta]
+	}
+	b.updateLines()
+}
+
+func (b *sliceTextBuffer) LineCount() int {
+	return len(b.lineStarts)
+}
+
+func (b *sliceTextBuffer) LineStart(line int) int {
+	return b.lineStarts[line]
+}
+
+func (b *sliceTextBuffer) LineEnd(line int) int {
+	return b.lineEnds[line]
+}
+
+func (b *sliceTextBuffer) LineIndex(i int) int {
+	return sort.Search(len(b.lineStarts)-1, func(l int) bool {
+		return i <= b.lineEnds[l]
+	})
+}
@@ -0,0 +1,256 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gxui
+
+import (
+	"github.com/google/gxui/math"
+)
+
+// The maximum number of runes held by a single rope leaf.
+const ropeLeafSize = 512
+
+// ropeNode is an immutable node of a height-balanced (AVL) binary tree. Leaf
+// nodes hold runs of runes, and every node caches the number of runes and
+// newlines it holds so that positions and lines can be found in O(log n).
+type ropeNode struct {
+	left, right *ropeNode
+	runes       []rune // Only used by leaves
+	length      int
+	lines       int // Number of '\n' runes
+	height      int // 0 for leaves
+}
+
+func newRopeLeaf(runes []rune) *ropeNode {
+	n := &ropeNode{runes: runes, length: len(runes)}
+	for _, r := range runes {
+		if r == '\n' {
+			n.lines++
+		}
+	}
+	return n
+}
+
+func newRopeNode(left, right *ropeNode) *ropeNode {
+	return &ropeNode{
+		left:   left,
+		right:  right,
+		length: left.length + right.length,
+		lines:  left.lines + right.lines,
+		height: 1 + math.Max(left.height, right.height),
+	}
+}
+
+// buildRope returns a balanced rope holding a copy of runes.
+func buildRope(runes []rune) *ropeNode {
+	switch {
+	case len(runes) == 0:
+		return nil
+	case len(runes) <= ropeLeafSize:
+		return newRopeLeaf(append([]rune{}, runes...))
+	default:
+		leaves := (len(runes) + ropeLeafSize - 1) / ropeLeafSize
+		mid := (leaves / 2) * ropeLeafSize
+		return newRopeNode(buildRope(runes[:mid]), buildRope(runes[mid:]))
+	}
+}
+
+// rebalance returns n with a single or double rotation applied if its
+// children's heights differ by more than one.
+func rebalance(n *ropeNode) *ropeNode {
+	switch d := n.left.height - n.right.height; {
+	case d > 1:
+		l := n.left
+		if l.left.height < l.right.height {
+			l = rotateLeft(l)
+		}
+		return rotateRight(newRopeNode(l, n.right))
+	case d < -1:
+		r := n.right
+		if r.right.height < r.left.height {
+			r = rotateRight(r)
+		}
+		return rotateLeft(newRopeNode(n.left, r))
+	default:
+		return n
+	}
+}
+
+func rotateLeft(n *ropeNode) *ropeNode {
+	r := n.right
+	return newRopeNode(newRopeNode(n.left, r.left), r.right)
+}
+
+func rotateRight(n *ropeNode) *ropeNode {
+	l := n.left
+	return newRopeNode(l.left, newRopeNode(l.right, n.right))
+}
+
+// joinRopes returns the concatenation of a and b, either of which may be nil.
+func joinRopes(a, b *ropeNode) *ropeNode {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.height == 0 && b.height == 0 && a.length+b.length <= ropeLeafSize:
+		runes := make([]rune, 0, a.length+b.length)
+		runes = append(append(runes, a.runes...), b.runes...)
+		return &ropeNode{runes: runes, length: len(runes), lines: a.lines + b.lines}
+	case a.height > b.height+1:
+		return rebalance(newRopeNode(a.left, joinRopes(a.right, b)))
+	case b.height > a.height+1:
+		return rebalance(newRopeNode(joinRopes(a, b.left), b.right))
+	default:
+		return newRopeNode(a, b)
+	}
+}
+
+// splitRope returns the runes of n before index i and the runes from index i.
+func splitRope(n *ropeNode, i int) (*ropeNode, *ropeNode) {
+	switch {
+	case n == nil:
+		return nil, nil
+	case i <= 0:
+		return nil, n
+	case i >= n.length:
+		return n, nil
+	case n.height == 0:
+		return buildRope(n.runes[:i]), buildRope(n.runes[i:])
+	case i < n.left.length:
+		ll, lr := splitRope(n.left, i)
+		return ll, joinRopes(lr, n.right)
+	default:
+		rl, rr := splitRope(n.right, i-n.left.length)
+		return joinRopes(n.left, rl), rr
+	}
+}
+
+// newlineIndex returns the index of the k'th newline of n, counting from 1.
+func (n *ropeNode) newlineIndex(k int) int {
+	offset := 0
+	for n.height > 0 {
+		if k <= n.left.lines {
+			n = n.left
+		} else {
+			k -= n.left.lines
+			offset += n.left.length
+			n = n.right
+		}
+	}
+	for i, r := range n.runes {
+		if r == '\n' {
+			if k--; k == 0 {
+				return offset + i
+			}
+		}
+	}
+	panic("Newline not found")
+}
+
+// ropeTextBuffer is a TextBuffer backed by a rope, so that edits and line
+// lookups take O(log n) time.
+type ropeTextBuffer struct {
+	root *ropeNode
+}
+
+// CreateRopeTextBuffer returns a TextBuffer that holds the text in a balanced
+// tree of runs of runes. Edits and line lookups take time logarithmic in the
+// length of the text.
+func CreateRopeTextBuffer() TextBuffer {
+	return &ropeTextBuffer{}
+}
+
+// TextBuffer compliance
+func (b *ropeTextBuffer) Len() int {
+	if b.root == nil {
+		return 0
+	}
+	return b.root.length
+}
+
+func (b *ropeTextBuffer) RuneAt(i int) rune {
+	n := b.root
+	for n.height > 0 {
+		if i < n.left.length {
+			n = n.left
+		} else {
+			i -= n.left.length
+			n = n.right
+		}
+	}
+	return n.runes[i]
+}
+
+func (b *ropeTextBuffer) Runes(s, e int) []rune {
+	out := make([]rune, 0, e-s)
+	var visit func(n *ropeNode, s, e int)
+	visit = func(n *ropeNode, s, e int) {
+		switch {
+		case n == nil || s >= e:
+		case n.height == 0:
+			out = append(out, n.runes[s:e]...)
+		default:
+			l := n.left.length
+			if s < l {
+				visit(n.left, s, math.Min(e, l))
+			}
+			if e > l {
+				visit(n.right, math.Max(s-l, 0), e-l)
+			}
+		}
+	}
+	visit(b.root, s, e)
+	return out
+}
+
+func (b *ropeTextBuffer) Replace(s, e int, r []rune) {
+	head, tail := splitRope(b.root, s)
+	_, tail = splitRope(tail, e-s)
+	b.root = joinRopes(joinRopes(head, buildRope(r)), tail)
+}
+
+func (b *ropeTextBuffer) LineCount() int {
+	if b.root == nil {
+		return 1
+	}
+	return b.root.lines + 1
+}
+
+func (b *ropeTextBuffer) LineStart(line int) int {
+	if line == 0 {
+		return 0
+	}
+	return b.root.newlineIndex(line) + 1
+}
+
+func (b *ropeTextBuffer) LineEnd(line int) int {
+	if line == b.LineCount()-1 {
+		return b.Len()
+	}
+	return b.root.newlineIndex(line + 1)
+}
+
+func (b *ropeTextBuffer) LineIndex(i int) int {
+	lines := 0
+	n := b.root
+	if n == nil {
+		return 0
+	}
+	for n.height > 0 {
+		if i < n.left.length {
+			n = n.left
+		} else {
+			i -= n.left.length
+			lines += n.left.lines
+			n = n.right
+		}
+	}
+	for _, r := range n.runes[:math.Min(i, n.length)] {
+		if r == '\n' {
+			lines++
+		}
+	}
+	return lines
+}
@@ -0,0 +1,191 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gxui
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/google/gxui/math"
+	test "github.com/google/gxui/testing"
+)
+
+func randomRunes(rng *rand.Rand, n int) []rune {
+	runes := make([]rune, n)
+	for i := range runes {
+		switch rng.Intn(8) {
+		case 0:
+			runes[i] = '\n'
+		case 1:
+			runes[i] = ' '
+		default:
+			runes[i] = rune('a' + rng.Intn(26))
+		}
+	}
+	return runes
+}
+
+func assertTextBuffersEqual(t *testing.T, expected, got TextBuffer) {
+	test.AssertEquals(t, expected.Len(), got.Len())
+	test.AssertEquals(t, expected.Runes(0, expected.Len()), got.Runes(0, got.Len()))
+	test.AssertEquals(t, expected.LineCount(), got.LineCount())
+	for l := 0; l < expected.LineCount(); l++ {
+		test.AssertEquals(t, expected.LineStart(l), got.LineStart(l))
+		test.AssertEquals(t, expected.LineEnd(l), got.LineEnd(l))
+	}
+	for i := 0; i <= expected.Len(); i++ {
+		test.AssertEquals(t, expected.LineIndex(i), got.LineIndex(i))
+		if i < expected.Len() {
+			test.AssertEquals(t, expected.RuneAt(i), got.RuneAt(i))
+		}
+	}
+}
+
+func TestTextBufferEmpty(t *testing.T) {
+	for _, b := range []TextBuffer{CreateSliceTextBuffer(), CreateRopeTextBuffer()} {
+		test.AssertEquals(t, 0, b.Len())
+		test.AssertEquals(t, 1, b.LineCount())
+		test.AssertEquals(t, 0, b.LineStart(0))
+		test.AssertEquals(t, 0, b.LineEnd(0))
+		test.AssertEquals(t, 0, b.LineIndex(0))
+		test.AssertEquals(t, []rune{}, b.Runes(0, 0))
+	}
+}
+
+func TestTextBufferLines(t *testing.T) {
+	for _, b := range []TextBuffer{CreateSliceTextBuffer(), CreateRopeTextBuffer()} {
+		b.Replace(0, 0, []rune("ab\n\ncd\n"))
+		test.AssertEquals(t, 4, b.LineCount())
+		test.AssertEquals(t, []int{0, 3, 4, 7}, []int{b.LineStart(0), b.LineStart(1), b.LineStart(2), b.LineStart(3)})
+		test.AssertEquals(t, []int{2, 3, 6, 7}, []int{b.LineEnd(0), b.LineEnd(1), b.LineEnd(2), b.LineEnd(3)})
+		test.AssertEquals(t, []int{0, 0, 0, 1, 2, 2, 2, 3}, []int{
+			b.LineIndex(0), b.LineIndex(1), b.LineIndex(2), b.LineIndex(3),
+			b.LineIndex(4), b.LineIndex(5), b.LineIndex(6), b.LineIndex(7),
+		})
+	}
+}
+
+func TestTextBufferRopeMatchesSlice(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	slice, rope := CreateSliceTextBuffer(), CreateRopeTextBuffer()
+	for i := 0; i < 500; i++ {
+		l := slice.Len()
+		s := rng.Intn(l + 1)
+		e := s + rng.Intn(l-s+1)
+		if rng.Intn(3) > 0 {
+			e = s + rng.Intn(math.Min(l-s, 8)+1)
+		}
+		r := randomRunes(rng, rng.Intn(64))
+		if rng.Intn(50) == 0 {
+			r = randomRunes(rng, 2000)
+		}
+		slice.Replace(s, e, r)
+		rope.Replace(s, e, r)
+		if i%25 == 0 {
+			assertTextBuffersEqual(t, slice, rope)
+		}
+		test.AssertEquals(t, slice.Len(), rope.Len())
+		test.AssertEquals(t, slice.LineCount(), rope.LineCount())
+	}
+	assertTextBuffersEqual(t, slice, rope)
+	test.AssertEquals(t, slice.Runes(10, 700), rope.Runes(10, 700))
+}
+
+func TestTextBoxControllerSliceBuffer(t *testing.T) {
+	c := CreateTextBoxControllerWithBuffer(CreateSliceTextBuffer())
+	c.SetText("Hello\nworld")
+	c.SetCaret(5)
+	c.ReplaceAll(",")
+	test.AssertEquals(t, "Hello,\nworld", c.Text())
+	test.AssertEquals(t, 2, c.LineCount())
+	test.AssertEquals(t, "world", c.Line(1))
+	c.Undo()
+	test.AssertEquals(t, "Hello\nworld", c.Text())
+}
+
+// benchmarkText returns about 1MB of text, split into lines of 64 runes.
+func benchmarkText() []rune {
+	runes := randomRunes(rand.New(rand.NewSource(1)), 1<<20)
+	for i := range runes {
+		if runes[i] == '\n' {
+			runes[i] = ' '
+		}
+		if i%64 == 63 {
+			runes[i] = '\n'
+		}
+	}
+	return runes
+}
+
+func benchmarkTextBufferInsert(b *testing.B, buffer TextBuffer) {
+	buffer.Replace(0, 0, benchmarkText())
+	rng := rand.New(rand.NewSource(1))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p := rng.Intn(buffer.Len())
+		buffer.Replace(p, p, []rune{'x'})
+	}
+}
+
+func benchmarkTextBufferDelete(b *testing.B, buffer TextBuffer) {
+	buffer.Replace(0, 0, benchmarkText())
+	rng := rand.New(rand.NewSource(1))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p := rng.Intn(buffer.Len() - 1)
+		buffer.Replace(p, p+1, nil)
+		buffer.Replace(p, p, []rune{'x'})
+	}
+}
+
+func benchmarkTextBufferLineIndex(b *testing.B, buffer TextBuffer) {
+	buffer.Replace(0, 0, benchmarkText())
+	rng := rand.New(rand.NewSource(1))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buffer.LineIndex(rng.Intn(buffer.Len()))
+	}
+}
+
+func benchmarkTextBufferLineStart(b *testing.B, buffer TextBuffer) {
+	buffer.Replace(0, 0, benchmarkText())
+	rng := rand.New(rand.NewSource(1))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buffer.LineStart(rng.Intn(buffer.LineCount()))
+	}
+}
+
+func BenchmarkTextBufferSliceInsert(b *testing.B) {
+	benchmarkTextBufferInsert(b, CreateSliceTextBuffer())
+}
+
+func BenchmarkTextBufferRopeInsert(b *testing.B) {
+	benchmarkTextBufferInsert(b, CreateRopeTextBuffer())
+}
+
+func BenchmarkTextBufferSliceDelete(b *testing.B) {
+	benchmarkTextBufferDelete(b, CreateSliceTextBuffer())
+}
+
+func BenchmarkTextBufferRopeDelete(b *testing.B) {
+	benchmarkTextBufferDelete(b, CreateRopeTextBuffer())
+}
+
+func BenchmarkTextBufferSliceLineIndex(b *testing.B) {
+	benchmarkTextBufferLineIndex(b, CreateSliceTextBuffer())
+}
+
+func BenchmarkTextBufferRopeLineIndex(b *testing.B) {
+	benchmarkTextBufferLineIndex(b, CreateRopeTextBuffer())
+}
+
+func BenchmarkTextBufferSliceLineStart(b *testing.B) {
+	benchmarkTextBufferLineStart(b, CreateSliceTextBuffer())
+}
+
+func BenchmarkTextBufferRopeLineStart(b *testing.B) {
+	benchmarkTextBufferLineStart(b, CreateRopeTextBuffer())
+}
@@ -0,0 +1,295 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gxui
+
+import (
+	"regexp"
+	"unicode/utf8"
+)
+
+// FindMode is a combination of flags controlling how a TextFinder matches its
+// pattern. The zero FindMode matches the pattern as plain, case-sensitive text.
+type FindMode int
+
+const (
+	// FindIgnoreCase matches the pattern regardless of case.
+	FindIgnoreCase FindMode = 1 << iota
+	// FindWholeWord only matches text that is not adjacent to word runes.
+	FindWholeWord
+	// FindRegexp treats the pattern as a regular expression, as accepted by
+	// the regexp package. Replacements may refer to submatches with $1 or
+	// ${name}.
+	FindRegexp
+)
+
+func (m FindMode) IgnoreCase() bool { return m&FindIgnoreCase != 0 }
+func (m FindMode) WholeWord() bool  { return m&FindWholeWord != 0 }
+func (m FindMode) Regexp() bool     { return m&FindRegexp != 0 }
+
+// textMatch is a match of the pattern, spanning the runes from start to end.
+// submatches holds the byte offsets of the submatches in the searched text.
+type textMatch struct {
+	start, end int
+	submatches []int
+}
+
+// TextFinder finds the matches of a pattern in the text of a
+// TextBoxController, and replaces them. The matches are kept up to date as the
+// text changes, and are added to a CodeSyntaxLayer so they can be highlighted.
+type TextFinder struct {
+	controller       *TextBoxController
+	pattern          string
+	mode             FindMode
+	re               *regexp.Regexp
+	err              error
+	matches          []textMatch
+	layer            *CodeSyntaxLayer
+	onMatchesChanged Event
+	textChanged      EventSubscription
+}
+
+// CreateTextFinder returns a TextFinder that searches the text of controller.
+// Release must be called once the TextFinder is no longer needed.
+func CreateTextFinder(controller *TextBoxController) *TextFinder {
+	f := &TextFinder{
+		controller:       controller,
+		layer:            CreateCodeSyntaxLayer(),
+		onMatchesChanged: CreateEvent(func() {}),
+	}
+	f.textChanged = controller.OnTextChanged(func([]TextBoxEdit) { f.update() })
+	return f
+}
+
+// Release stops the TextFinder from tracking changes to the text.
+func (f *TextFinder) Release() {
+	if f.textChanged != nil {
+		f.textChanged.Unlisten()
+		f.textChanged = nil
+	}
+}
+
+// compile builds the regular expression for the pattern and mode.
+func (f *TextFinder) compile() {
+	f.re, f.err = nil, nil
+	if f.pattern == "" {
+		return
+	}
+	expr := f.pattern
+	if !f.mode.Regexp() {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if f.mode.IgnoreCase() {
+		expr = "(?i)" + expr
+	}
+	f.re, f.err = regexp.Compile(expr)
+}
+
+// find returns the matches of the pattern in text, which is the controller's
+// text.
+func (f *TextFinder) find(text string) []textMatch {
+	if f.re == nil {
+		return nil
+	}
+	matches := []textMatch{}
+	c := f.controller
+	runeIdx, byteIdx := 0, 0
+	toRunes := func(b int) int {
+		runeIdx += utf8.RuneCountInString(text[byteIdx:b])
+		byteIdx = b
+		return runeIdx
+	}
+	for _, sub := range f.re.FindAllStringSubmatchIndex(text, -1) {
+		if sub[0] == sub[1] {
+			continue // Empty matches cannot be highlighted or stepped through.
+		}
+		m := textMatch{start: toRunes(sub[0]), end: toRunes(sub[1]), submatches: sub}
+		if f.mode.WholeWord() {
+			if m.start > 0 && c.RuneInWord(c.buffer.RuneAt(m.start-1)) ||
+				m.end < c.TextLength() && c.RuneInWord(c.buffer.RuneAt(m.end)) {
+				continue
+			}
+		}
+		matches = append(matches, m)
+	}
+	return matches
+}
+
+// update finds the matches in the current text, and updates the layer.
+func (f *TextFinder) update() {
+	f.matches = f.find(f.controller.Text())
+	f.layer.Clear()
+	for _, m := range f.matches {
+		f.layer.Add(m.start, m.end-m.start)
+	}
+	f.onMatchesChanged.Fire()
+}
+
+func (f *TextFinder) Pattern() string {
+	return f.pattern
+}
+
+func (f *TextFinder) SetPattern(pattern string) {
+	if f.pattern != pattern {
+		f.pattern = pattern
+		f.compile()
+		f.update()
+	}
+}
+
+func (f *TextFinder) Mode() FindMode {
+	return f.mode
+}
+
+func (f *TextFinder) SetMode(mode FindMode) {
+	if f.mode != mode {
+		f.mode = mode
+		f.compile()
+		f.update()
+	}
+}
+
+// Err returns the error from compiling the pattern as a regular expression,
+// or nil if the pattern is valid.
+func (f *TextFinder) Err() error {
+	return f.err
+}
+
+// Layer returns the CodeSyntaxLayer holding a span for each match. The layer
+// has no colors until they are set by the caller.
+func (f *TextFinder) Layer() *CodeSyntaxLayer {
+	return f.layer
+}
+
+// OnMatchesChanged subscribes f to be called whenever the matches are updated.
+func (f *TextFinder) OnMatchesChanged(cb func()) EventSubscription {
+	return f.onMatchesChanged.Listen(cb)
+}
+
+func (f *TextFinder) MatchCount() int {
+	return len(f.matches)
+}
+
+// Matches returns a selection spanning each of the matches.
+func (f *TextFinder) Matches() TextSelectionList {
+	list := make(TextSelectionList, len(f.matches))
+	for i, m := range f.matches {
+		list[i] = CreateTextSelection(m.start, m.end, false)
+	}
+	return list
+}
+
+// Current returns the index of the match that is exactly selected by the last
+// selection, or -1 if there is no such match.
+func (f *TextFinder) Current() int {
+	s, e := f.controller.LastSelection().Range()
+	for i, m := range f.matches {
+		if m.start == s && m.end == e {
+			return i
+		}
+	}
+	return -1
+}
+
+// Next selects the first match that starts at or after the end of the last
+// selection, wrapping around to the first match. Next returns false if there
+// are no matches.
+func (f *TextFinder) Next() bool {
+	if len(f.matches) == 0 {
+		return false
+	}
+	from := f.controller.LastSelection().End()
+	i := 0
+	for i < len(f.matches) && f.matches[i].start < from {
+		i++
+	}
+	f.selectMatch(i % len(f.matches))
+	return true
+}
+
+// Previous selects the last match that ends at or before the start of the
+// first selection, wrapping around to the last match. Previous returns false
+// if there are no matches.
+func (f *TextFinder) Previous() bool {
+	if len(f.matches) == 0 {
+		return false
+	}
+	from := f.controller.FirstSelection().Start()
+	i := len(f.matches) - 1
+	for i >= 0 && f.matches[i].end > from {
+		i--
+	}
+	f.selectMatch((i + len(f.matches)) % len(f.matches))
+	return true
+}
+
+func (f *TextFinder) selectMatch(i int) {
+	m := f.matches[i]
+	f.controller.StoreCaretLocations()
+	f.controller.SetSelection(CreateTextSelection(m.start, m.end, false))
+}
+
+// Replace replaces the current match with replacement, and then selects the
+// next match. If no match is selected, Replace only selects the next match.
+// Replace returns true if a match was replaced.
+func (f *TextFinder) Replace(replacement string) bool {
+	i := f.Current()
+	if i < 0 {
+		f.Next()
+		return false
+	}
+	f.replace(f.matches[i:i+1], replacement)
+	f.Next()
+	return true
+}
+
+// ReplaceAll replaces every match with replacement as a single edit, and
+// returns the number of matches replaced.
+func (f *TextFinder) ReplaceAll(replacement string) int {
+	matches := f.matches
+	if len(matches) > 0 {
+		f.replace(matches, replacement)
+	}
+	return len(matches)
+}
+
+// replace replaces each of the matches with the expansion of replacement,
+// using ReplaceRunes so that the replacements form a single undoable edit.
+// The selections are restored afterwards, moved by the replacements.
+func (f *TextFinder) replace(matches []textMatch, replacement string) {
+	c := f.controller
+	text := c.Text()
+	c.beginEdit()
+	selections := c.Selections()
+
+	runes := make(map[int][]rune, len(matches))
+	matched := make(TextSelectionList, len(matches))
+	for i, m := range matches {
+		if f.mode.Regexp() {
+			runes[m.start] = []rune(string(f.re.ExpandString(nil, replacement, text, m.submatches)))
+		} else {
+			runes[m.start] = []rune(replacement)
+		}
+		matched[i] = CreateTextSelection(m.start, m.end, false)
+	}
+	c.SetSelections(matched)
+	c.ReplaceRunes(func(s TextSelection) []rune { return runes[s.Start()] })
+
+	move := func(p int) int {
+		delta := 0
+		for _, m := range matches {
+			switch n := len(runes[m.start]); {
+			case p >= m.end:
+				delta += n - (m.end - m.start)
+			case p > m.start:
+				return m.start + n + delta
+			}
+		}
+		return p + delta
+	}
+	for i, s := range selections {
+		selections[i] = CreateTextSelection(move(s.Start()), move(s.End()), s.CaretAtStart())
+	}
+	c.SetSelections(selections)
+}
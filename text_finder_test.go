@@ -0,0 +1,113 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gxui
+
+import (
+	"testing"
+
+	test "github.com/google/gxui/testing"
+)
+
+func createFinder(text, pattern string, mode FindMode) (*TextBoxController, *TextFinder) {
+	c := CreateTextBoxController()
+	c.SetText(text)
+	f := CreateTextFinder(c)
+	f.SetMode(mode)
+	f.SetPattern(pattern)
+	return c, f
+}
+
+func TestTextFinderModes(t *testing.T) {
+	text := "Cat cat concat (cat)"
+	for _, tc := range []struct {
+		pattern  string
+		mode     FindMode
+		expected TextSelectionList
+	}{
+		{"cat", 0, TextSelectionList{{4, 7, false}, {11, 14, false}, {16, 19, false}}},
+		{"cat", FindIgnoreCase, TextSelectionList{{0, 3, false}, {4, 7, false}, {11, 14, false}, {16, 19, false}}},
+		{"cat", FindWholeWord, TextSelectionList{{4, 7, false}, {16, 19, false}}},
+		{"(cat)", 0, TextSelectionList{{15, 20, false}}},
+		{"c[a-z]+t", FindRegexp, TextSelectionList{{4, 7, false}, {8, 14, false}, {16, 19, false}}},
+		{"x*", FindRegexp, TextSelectionList{}},
+		{"", 0, TextSelectionList{}},
+	} {
+		_, f := createFinder(text, tc.pattern, tc.mode)
+		test.AssertEquals(t, tc.expected, f.Matches())
+		test.AssertEquals(t, nil, f.Err())
+	}
+
+	_, f := createFinder(text, "c(at", FindRegexp)
+	test.AssertEquals(t, 0, f.MatchCount())
+	test.AssertEquals(t, true, f.Err() != nil)
+}
+
+func TestTextFinderUnicode(t *testing.T) {
+	_, f := createFinder("héllo wörld wörld", "wörld", 0)
+	test.AssertEquals(t, TextSelectionList{{6, 11, false}, {12, 17, false}}, f.Matches())
+}
+
+func TestTextFinderTracksEdits(t *testing.T) {
+	c, f := createFinder("a b a", "a", 0)
+	test.AssertEquals(t, 2, f.MatchCount())
+	c.SetCaret(3)
+	c.ReplaceAll("a")
+	test.AssertEquals(t, 3, f.MatchCount())
+	test.AssertEquals(t, 3, len(f.Layer().Spans()))
+}
+
+func TestTextFinderNextPrevious(t *testing.T) {
+	c, f := createFinder("ab ab ab", "ab", 0)
+	c.SetCaret(4)
+	test.AssertEquals(t, -1, f.Current())
+	test.AssertEquals(t, true, f.Next())
+	test.AssertEquals(t, TextSelection{6, 8, false}, c.LastSelection())
+	test.AssertEquals(t, 2, f.Current())
+	f.Next()
+	test.AssertEquals(t, TextSelection{0, 2, false}, c.LastSelection())
+	f.Previous()
+	test.AssertEquals(t, TextSelection{6, 8, false}, c.LastSelection())
+	f.Previous()
+	test.AssertEquals(t, TextSelection{3, 5, false}, c.LastSelection())
+
+	_, f = createFinder("ab", "x", 0)
+	test.AssertEquals(t, false, f.Next())
+	test.AssertEquals(t, false, f.Previous())
+}
+
+func TestTextFinderReplace(t *testing.T) {
+	c, f := createFinder("one two one", "one", 0)
+	test.AssertEquals(t, false, f.Replace("1"))
+	test.AssertEquals(t, TextSelection{0, 3, false}, c.LastSelection())
+	test.AssertEquals(t, true, f.Replace("1"))
+	test.AssertEquals(t, "1 two one", c.Text())
+	test.AssertEquals(t, TextSelection{6, 9, false}, c.LastSelection())
+	test.AssertEquals(t, true, f.Replace("1"))
+	test.AssertEquals(t, "1 two 1", c.Text())
+	test.AssertEquals(t, 0, f.MatchCount())
+}
+
+func TestTextFinderReplaceAll(t *testing.T) {
+	edits := []TextBoxEdit{}
+	c, f := createFinder("ab ab cd ab", "ab", 0)
+	c.OnTextChanged(func(e []TextBoxEdit) { edits = append(edits, e...) })
+	c.SetSelections(TextSelectionList{{2, 2, false}, {7, 7, false}, {10, 10, false}})
+	test.AssertEquals(t, 3, f.ReplaceAll("xyz"))
+	test.AssertEquals(t, "xyz xyz cd xyz", c.Text())
+	test.AssertEquals(t, []TextBoxEdit{{9, 1}, {3, 1}, {0, 1}}, edits)
+	// Carets are moved by the replacements, and carets inside a match are
+	// moved to the end of its replacement.
+	test.AssertEquals(t, []int{3, 9, 14}, c.Carets())
+
+	c.Undo()
+	test.AssertEquals(t, "ab ab cd ab", c.Text())
+	test.AssertEquals(t, []int{2, 7, 10}, c.Carets())
+}
+
+func TestTextFinderReplaceRegexp(t *testing.T) {
+	c, f := createFinder("x=1, y=22", `(\w)=(\d+)`, FindRegexp)
+	f.ReplaceAll("$2:$1")
+	test.AssertEquals(t, "1:x, 22:y", c.Text())
+}
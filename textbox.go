@@ -23,6 +23,10 @@ type TextBox interface {
 	SetMultiline(bool)
 	DesiredWidth() int
 	SetDesiredWidth(desiredWidth int)
+	SoftWrap() bool
+	SetSoftWrap(bool)
+	WrapColumn() int
+	SetWrapColumn(column int)
 	TextColor() Color
 	SetTextColor(Color)
 	Select(TextSelectionList)
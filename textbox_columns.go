@@ -0,0 +1,160 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gxui
+
+import (
+	"strings"
+
+	"github.com/google/gxui/math"
+)
+
+// textBoxColumns is the rectangle of a column selection, from the corner at
+// the anchor to the corner at the caret, in lines and columns.
+type textBoxColumns struct {
+	anchorLine, anchorColumn int
+	caretLine, caretColumn   int
+	selections               TextSelectionList // The selections made for the rectangle
+}
+
+func (t *TextBoxController) TabWidth() int {
+	return t.tabWidth
+}
+
+// SetTabWidth sets the number of columns between tab stops, used to find the
+// columns of runes. A tab width of 0 counts tabs as a single column.
+func (t *TextBoxController) SetTabWidth(tabWidth int) {
+	t.tabWidth = tabWidth
+}
+
+// runeColumns returns the number of columns taken by the rune r at column.
+func (t *TextBoxController) runeColumns(r rune, column int) int {
+	if r == '\t' && t.tabWidth > 0 {
+		return t.tabWidth - column%t.tabWidth
+	}
+	return 1
+}
+
+// Column returns the column of the rune at index i in its line, counting tabs
+// up to the next tab stop.
+func (t *TextBoxController) Column(i int) int {
+	column := 0
+	for _, r := range t.TextRangeRunes(t.LineStart(t.LineIndex(i)), i) {
+		column += t.runeColumns(r, column)
+	}
+	return column
+}
+
+// IndexAtColumn returns the index of the rune at column of line. Columns past
+// the end of the line return the end of the line, and columns inside a tab
+// return the index of the tab.
+func (t *TextBoxController) IndexAtColumn(line, column int) int {
+	s := t.LineStart(line)
+	c := 0
+	for i, r := range t.LineRunes(line) {
+		c += t.runeColumns(r, c)
+		if c > column {
+			return s + i
+		}
+	}
+	return t.LineEnd(line)
+}
+
+// SelectColumns replaces the selections with a column selection: one
+// selection on each visible line from fromLine to toLine, covering the
+// columns from fromColumn to toColumn, clamped to the end of the line. The
+// carets are placed on the toColumn side of each selection.
+func (t *TextBoxController) SelectColumns(fromLine, fromColumn, toLine, toColumn int) {
+	fromLine = math.Clamp(fromLine, 0, t.LineCount()-1)
+	toLine = math.Clamp(toLine, 0, t.LineCount()-1)
+	fromColumn, toColumn = math.Max(fromColumn, 0), math.Max(toColumn, 0)
+	first, last := math.Min(fromLine, toLine), math.Max(fromLine, toLine)
+	left, right := math.Min(fromColumn, toColumn), math.Max(fromColumn, toColumn)
+	selections := TextSelectionList{}
+	for l := first; l <= last; l++ {
+		if t.LineHidden(l) {
+			continue
+		}
+		s, e := t.IndexAtColumn(l, left), t.IndexAtColumn(l, right)
+		selections = append(selections, TextSelection{s, e, toColumn < fromColumn})
+	}
+	if len(selections) == 0 {
+		// Every line is hidden, so select where the caret would move to.
+		c := t.visibleIndex(t.LineStart(toLine), false)
+		selections = append(selections, TextSelection{c, c, false})
+	}
+	t.columns = textBoxColumns{
+		anchorLine:   fromLine,
+		anchorColumn: fromColumn,
+		caretLine:    toLine,
+		caretColumn:  toColumn,
+		selections:   selections,
+	}
+	t.SetSelections(append(TextSelectionList{}, selections...))
+}
+
+// ColumnSelected returns true if the selections are the column selection last
+// made with SelectColumns, and have not changed since.
+func (t *TextBoxController) ColumnSelected() bool {
+	if len(t.columns.selections) != len(t.selections) {
+		return false
+	}
+	for i, s := range t.columns.selections {
+		if t.selections[i] != s {
+			return false
+		}
+	}
+	return true
+}
+
+// moveColumnCaret moves the caret corner of the column selection by the
+// lines and columns, starting a column selection from the last selection if
+// there is none.
+func (t *TextBoxController) moveColumnCaret(lines, columns int) {
+	if !t.ColumnSelected() {
+		s := t.LastSelection()
+		t.columns.anchorLine, t.columns.anchorColumn = t.LineIndex(s.From()), t.Column(s.From())
+		t.columns.caretLine, t.columns.caretColumn = t.LineIndex(s.Caret()), t.Column(s.Caret())
+	}
+	c := t.columns
+	line := c.caretLine
+	switch {
+	case lines < 0:
+		line = t.previousVisibleLine(line)
+	case lines > 0:
+		line = t.nextVisibleLine(line)
+	}
+	column := c.caretColumn + columns
+	if columns > 0 {
+		// Stop at the end of the longest line of the selection.
+		first, last := math.Min(c.anchorLine, line), math.Max(c.anchorLine, line)
+		longest := 0
+		for l := first; l <= last; l++ {
+			longest = math.Max(longest, t.Column(t.LineEnd(l)))
+		}
+		column = math.Min(column, math.Max(longest, c.caretColumn))
+	}
+	t.SelectColumns(c.anchorLine, c.anchorColumn, line, column)
+}
+
+func (t *TextBoxController) SelectColumnsUp()    { t.moveColumnCaret(-1, 0) }
+func (t *TextBoxController) SelectColumnsDown()  { t.moveColumnCaret(1, 0) }
+func (t *TextBoxController) SelectColumnsLeft()  { t.moveColumnCaret(0, -1) }
+func (t *TextBoxController) SelectColumnsRight() { t.moveColumnCaret(0, 1) }
+
+// ReplaceAllLines replaces the text of each selection with the next line of
+// str, if str has one line for each selection. Otherwise each selection is
+// replaced with str, as with ReplaceAll.
+func (t *TextBoxController) ReplaceAllLines(str string) {
+	lines := strings.Split(str, "\n")
+	if len(lines) < 2 || len(lines) != len(t.selections) {
+		t.ReplaceAll(str)
+		return
+	}
+	replacements := make(map[TextSelection]string, len(lines))
+	for i, s := range t.selections {
+		replacements[s] = lines[i]
+	}
+	t.Replace(func(s TextSelection) string { return replacements[s] })
+}
@@ -12,6 +12,9 @@ import (
 	"unicode"
 )
 
+// The number of runes compared at a time by SetTextEdits.
+const textDiffChunk = 256
+
 type TextBoxEdit struct {
 	At    int
 	Delta int
@@ -20,32 +23,47 @@ type TextBoxEdit struct {
 type TextBoxController struct {
 	onSelectionChanged          Event
 	onTextChanged               Event
-	text                        []rune
-	lineStarts                  []int
-	lineEnds                    []int
+	buffer                      TextBuffer
 	selections                  TextSelectionList
 	locationHistory             [][]int
 	locationHistoryIndex        int
 	storeCaretLocationsNextEdit bool
+	history                     textBoxHistory
+	visibleLines                []int // nil if every line is visible
+	lineWraps                   func(line int) []int
+	tabWidth                    int
+	columns                     textBoxColumns
 }
 
+// CreateTextBoxController returns a TextBoxController that holds its text in
+// a rope, as returned by CreateRopeTextBuffer.
 func CreateTextBoxController() *TextBoxController {
+	return CreateTextBoxControllerWithBuffer(CreateRopeTextBuffer())
+}
+
+// CreateTextBoxControllerWithBuffer returns a TextBoxController that holds its
+// text in buffer.
+func CreateTextBoxControllerWithBuffer(buffer TextBuffer) *TextBoxController {
 	t := &TextBoxController{
 		onSelectionChanged: CreateEvent(func() {}),
 		onTextChanged:      CreateEvent(func([]TextBoxEdit) {}),
+		buffer:             buffer,
 	}
 	t.selections = TextSelectionList{TextSelection{}}
+	t.history.limit = DefaultTextBoxHistoryLimit
 	return t
 }
 
 func (t *TextBoxController) textEdited(edits []TextBoxEdit) {
+	t.beginEdit() // For edits made directly with SetTextEdits
 	t.updateSelectionsForEdits(edits)
+	t.recordEdit(edits)
 	t.onTextChanged.Fire(edits)
 }
 
 func (t *TextBoxController) updateSelectionsForEdits(edits []TextBoxEdit) {
 	min := 0
-	max := len(t.text)
+	max := t.buffer.Len()
 	selections := TextSelectionList{}
 	for _, selection := range t.selections {
 		for _, e := range edits {
@@ -68,19 +86,12 @@ func (t *TextBoxController) updateSelectionsForEdits(edits []TextBoxEdit) {
 	t.selections = selections
 }
 
-func (t *TextBoxController) setTextRunesNoEvent(text []rune) {
-	t.text = text
-	t.lineStarts = t.lineStarts[:0]
-	t.lineEnds = t.lineEnds[:0]
-
-	t.lineStarts = append(t.lineStarts, 0)
-	for i, r := range text {
-		if r == '\n' {
-			t.lineEnds = append(t.lineEnds, i)
-			t.lineStarts = append(t.lineStarts, i+1)
-		}
-	}
-	t.lineEnds = append(t.lineEnds, len(text))
+// replaceAt replaces the runes from s to e with replacement, without updating
+// the selections or raising OnTextChanged.
+func (t *TextBoxController) replaceAt(s, e int, replacement []rune) TextBoxEdit {
+	t.recordReplace(s, e, replacement)
+	t.buffer.Replace(s, e, replacement)
+	return TextBoxEdit{s, len(replacement) - (e - s)}
 }
 
 func (t *TextBoxController) maybeStoreCaretLocations() {
@@ -120,15 +131,12 @@ func (t *TextBoxController) Selections() TextSelectionList {
 
 func (t *TextBoxController) SelectionText(i int) string {
 	sel := t.selections[i]
-	runes := t.text[sel.start:sel.end]
-	return RuneArrayToString(runes)
+	return t.TextRange(sel.start, sel.end)
 }
 
 func (t *TextBoxController) SelectionLineText(i int) string {
 	sel := t.selections[i]
-	line := t.LineIndex(sel.start)
-	runes := t.text[t.LineStart(line):t.LineEnd(line)]
-	return RuneArrayToString(runes)
+	return t.Line(t.LineIndex(sel.start))
 }
 
 func (t *TextBoxController) Caret(i int) int {
@@ -159,39 +167,38 @@ func (t *TextBoxController) LastSelection() TextSelection {
 	return t.Selection(t.SelectionCount() - 1)
 }
 
+// Buffer returns the TextBuffer holding the text. The buffer must not be
+// modified directly.
+func (t *TextBoxController) Buffer() TextBuffer {
+	return t.buffer
+}
+
 func (t *TextBoxController) LineCount() int {
-	return len(t.lineStarts)
+	return t.buffer.LineCount()
 }
 
 func (t *TextBoxController) Line(i int) string {
 	return RuneArrayToString(t.LineRunes(i))
 }
 
+// LineRunes returns a copy of the runes of line i, excluding the newline.
 func (t *TextBoxController) LineRunes(i int) []rune {
-	s := t.LineStart(i)
-	e := t.LineEnd(i)
-	return t.text[s:e]
+	return t.buffer.Runes(t.LineStart(i), t.LineEnd(i))
 }
 
 func (t *TextBoxController) LineStart(i int) int {
-	if t.LineCount() == 0 {
-		return 0
-	}
-	return t.lineStarts[i]
+	return t.buffer.LineStart(i)
 }
 
 func (t *TextBoxController) LineEnd(i int) int {
-	if t.LineCount() == 0 {
-		return 0
-	}
-	return t.lineEnds[i]
+	return t.buffer.LineEnd(i)
 }
 
 func (t *TextBoxController) LineIndent(i int) int {
 	s, e := t.LineStart(i), t.LineEnd(i)
 	l := e - s
 	for i := 0; i < l; i++ {
-		if !unicode.IsSpace(t.text[i+s]) {
+		if !unicode.IsSpace(t.buffer.RuneAt(i + s)) {
 			return i
 		}
 	}
@@ -199,34 +206,226 @@ func (t *TextBoxController) LineIndent(i int) int {
 }
 
 func (t *TextBoxController) LineIndex(p int) int {
-	return sort.Search(len(t.lineStarts), func(i int) bool {
-		return p <= t.lineEnds[i]
-	})
+	return t.buffer.LineIndex(p)
+}
+
+// SetVisibleLines sets the lines that are displayed, in ascending order, such
+// as the lines not hidden by the folds of a CodeEditor. Lines that are not in
+// lines are hidden, and carets moved with the Index functions skip over them.
+// nil shows every line.
+func (t *TextBoxController) SetVisibleLines(lines []int) {
+	t.visibleLines = lines
+}
+
+// VisibleLines returns the lines that are displayed, or nil if every line is
+// displayed.
+func (t *TextBoxController) VisibleLines() []int {
+	return t.visibleLines
+}
+
+// visibleLineIndex returns the index of the first visible line that is not
+// before the line l, in the visible lines.
+func (t *TextBoxController) visibleLineIndex(l int) int {
+	return sort.SearchInts(t.visibleLines, l)
+}
+
+// LineHidden returns true if the line with index i is hidden.
+func (t *TextBoxController) LineHidden(i int) bool {
+	if t.visibleLines == nil {
+		return false
+	}
+	v := t.visibleLineIndex(i)
+	return v == len(t.visibleLines) || t.visibleLines[v] != i
+}
+
+// SetLineWraps sets the function returning the indices of the runes that
+// start the wrapped rows of a line, after its first row, such as when a
+// TextBox soft wraps its lines. The indices must be in ascending order, after
+// the start of the line and before its end. Carets moved with IndexUp and
+// IndexDown move by row. A nil function leaves every line unwrapped.
+func (t *TextBoxController) SetLineWraps(wraps func(line int) []int) {
+	t.lineWraps = wraps
+}
+
+// LineWraps returns the indices of the runes that start the wrapped rows of
+// the line with index i, after its first row, or nil if the line is not
+// wrapped.
+func (t *TextBoxController) LineWraps(i int) []int {
+	if t.lineWraps == nil {
+		return nil
+	}
+	return t.lineWraps(i)
+}
+
+// RowStart returns the index of the first rune of the row holding the rune
+// at index p. This is the start of the line holding p, unless the line is
+// wrapped. A rune index at a wrap belongs to the row that starts there.
+func (t *TextBoxController) RowStart(p int) int {
+	l := t.LineIndex(p)
+	s := t.LineStart(l)
+	for _, w := range t.LineWraps(l) {
+		if w > p {
+			break
+		}
+		s = w
+	}
+	return s
+}
+
+// RowEnd returns the index after the last rune of the row holding the rune
+// at index p. This is the end of the line holding p, unless the line is
+// wrapped.
+func (t *TextBoxController) RowEnd(p int) int {
+	l := t.LineIndex(p)
+	for _, w := range t.LineWraps(l) {
+		if w > p {
+			return w
+		}
+	}
+	return t.LineEnd(l)
+}
+
+// rowColumn returns the column that the rune at index p is displayed at.
+// Wrapped rows after the first are displayed indented by the line's indent.
+func (t *TextBoxController) rowColumn(p int) int {
+	l := t.LineIndex(p)
+	s := t.RowStart(p)
+	if s == t.LineStart(l) {
+		return p - s
+	}
+	return t.LineIndent(l) + p - s
+}
+
+// rowIndexAt returns the index of the rune displayed closest to column x in
+// the row starting at the rune index s.
+func (t *TextBoxController) rowIndexAt(s, x int) int {
+	l := t.LineIndex(s)
+	if s != t.LineStart(l) {
+		x -= t.LineIndent(l)
+	}
+	e := t.RowEnd(s)
+	if e != t.LineEnd(l) {
+		e-- // The end of a wrapped row is the start of the next row
+	}
+	return math.Clamp(s+x, s, e)
+}
+
+// visibleIndex returns i if its line is not hidden. Otherwise visibleIndex
+// returns the start of the next visible line if forward is true and there is
+// one, or else the end of the previous visible line.
+func (t *TextBoxController) visibleIndex(i int, forward bool) int {
+	l := t.LineIndex(i)
+	if !t.LineHidden(l) {
+		return i
+	}
+	if forward {
+		if n := t.nextVisibleLine(l); n != l {
+			return t.LineStart(n)
+		}
+	}
+	return t.LineEnd(t.previousVisibleLine(l))
+}
+
+// previousVisibleLine returns the closest visible line before l, or l if
+// there is none.
+func (t *TextBoxController) previousVisibleLine(l int) int {
+	if t.visibleLines == nil {
+		return math.Max(l-1, 0)
+	}
+	if v := t.visibleLineIndex(l); v > 0 {
+		return t.visibleLines[v-1]
+	}
+	return l
+}
+
+// nextVisibleLine returns the closest visible line after l, or l if there is
+// none.
+func (t *TextBoxController) nextVisibleLine(l int) int {
+	if t.visibleLines == nil {
+		return math.Min(l+1, t.LineCount()-1)
+	}
+	if v := t.visibleLineIndex(l + 1); v < len(t.visibleLines) && t.visibleLines[v] < t.LineCount() {
+		return t.visibleLines[v]
+	}
+	return l
 }
 
 func (t *TextBoxController) Text() string {
-	return RuneArrayToString(t.text)
+	return RuneArrayToString(t.TextRunes())
 }
 
 func (t *TextBoxController) TextRange(s, e int) string {
-	return RuneArrayToString(t.text[s:e])
+	return RuneArrayToString(t.buffer.Runes(s, e))
+}
+
+// TextRangeRunes returns a copy of the runes from s to e.
+func (t *TextBoxController) TextRangeRunes(s, e int) []rune {
+	return t.buffer.Runes(s, e)
 }
 
+// TextLength returns the number of runes in the text.
+func (t *TextBoxController) TextLength() int {
+	return t.buffer.Len()
+}
+
+// TextRunes returns a copy of the entire text. Use TextRangeRunes or
+// LineRunes where possible, as the copy takes time proportional to the length
+// of the text.
 func (t *TextBoxController) TextRunes() []rune {
-	return t.text
+	return t.buffer.Runes(0, t.buffer.Len())
 }
 
 func (t *TextBoxController) SetText(str string) {
 	t.SetTextRunes(StringToRuneArray(str))
 }
 
+// SetTextRunes replaces the entire text, clearing the undo history.
 func (t *TextBoxController) SetTextRunes(text []rune) {
-	t.setTextRunesNoEvent(text)
+	t.buffer.Replace(0, t.buffer.Len(), text)
+	t.resetHistory()
 	t.textEdited([]TextBoxEdit{})
 }
 
+// SetTextEdits replaces the entire text with text, which is described by
+// edits applied to the current text. Only the runes that differ, from the
+// first edit onwards, are replaced in the buffer and recorded in the history.
 func (t *TextBoxController) SetTextEdits(text []rune, edits []TextBoxEdit) {
-	t.setTextRunesNoEvent(text)
+	t.beginEdit()
+	s, e, te := 0, t.buffer.Len(), len(text)
+	if len(edits) > 0 {
+		// The runes before the first edit are unchanged.
+		s = te
+		for _, edit := range edits {
+			s = math.Min(s, math.Max(edit.At, 0))
+		}
+		s = math.Min(s, e)
+	}
+
+	// Skip the unchanged runes at the start and end of the text, comparing
+	// the buffer a chunk at a time.
+	for s < e && s < te {
+		n := math.Min(textDiffChunk, math.Min(e, te)-s)
+		chunk, i := t.buffer.Runes(s, s+n), 0
+		for i < n && chunk[i] == text[s+i] {
+			i++
+		}
+		s += i
+		if i < n {
+			break
+		}
+	}
+	for e > s && te > s {
+		n := math.Min(textDiffChunk, math.Min(e, te)-s)
+		chunk, i := t.buffer.Runes(e-n, e), 0
+		for i < n && chunk[n-1-i] == text[te-1-i] {
+			i++
+		}
+		e, te = e-i, te-i
+		if i < n {
+			break
+		}
+	}
+	t.replaceAt(s, e, text[s:te])
 	t.textEdited(edits)
 }
 
@@ -235,23 +434,27 @@ func (t *TextBoxController) IndexFirst(i int) int {
 }
 
 func (t *TextBoxController) IndexLast(i int) int {
-	return len(t.text)
+	return t.visibleIndex(t.buffer.Len(), false)
 }
 
 func (t *TextBoxController) IndexLeft(i int) int {
-	return math.Max(i-1, 0)
+	return t.visibleIndex(math.Max(i-1, 0), false)
 }
 
 func (t *TextBoxController) IndexRight(i int) int {
-	return math.Min(i+1, len(t.text))
+	return t.visibleIndex(math.Min(i+1, t.buffer.Len()), true)
 }
 
 func (t *TextBoxController) IndexWordLeft(i int) int {
+	return t.visibleIndex(t.indexWordLeft(i), false)
+}
+
+func (t *TextBoxController) indexWordLeft(i int) int {
 	i--
 	if i >= 0 {
-		wasInWord := t.RuneInWord(t.text[i])
+		wasInWord := t.RuneInWord(t.buffer.RuneAt(i))
 		for i > 0 {
-			isInWord := t.RuneInWord(t.text[i-1])
+			isInWord := t.RuneInWord(t.buffer.RuneAt(i - 1))
 			if isInWord != wasInWord {
 				return i
 			}
@@ -263,35 +466,46 @@ func (t *TextBoxController) IndexWordLeft(i int) int {
 }
 
 func (t *TextBoxController) IndexWordRight(i int) int {
-	if i < len(t.text) {
-		wasInWord := t.RuneInWord(t.text[i])
-		for i < len(t.text)-1 {
+	return t.visibleIndex(t.indexWordRight(i), true)
+}
+
+func (t *TextBoxController) indexWordRight(i int) int {
+	l := t.buffer.Len()
+	if i < l {
+		wasInWord := t.RuneInWord(t.buffer.RuneAt(i))
+		for i < l-1 {
 			i++
-			isInWord := t.RuneInWord(t.text[i])
+			isInWord := t.RuneInWord(t.buffer.RuneAt(i))
 			if isInWord != wasInWord {
 				return i
 			}
 			wasInWord = isInWord
 		}
 	}
-	return len(t.text)
+	return l
 }
 
 func (t *TextBoxController) IndexUp(i int) int {
 	l := t.LineIndex(i)
-	x := i - t.LineStart(l)
-	if l > 0 {
-		return math.Min(t.LineStart(l-1)+x, t.LineEnd(l-1))
+	x := t.rowColumn(i)
+	if s := t.RowStart(i); s != t.LineStart(l) {
+		return t.rowIndexAt(t.RowStart(s-1), x)
+	}
+	if p := t.previousVisibleLine(l); p != l {
+		return t.rowIndexAt(t.RowStart(t.LineEnd(p)), x)
 	} else {
-		return 0
+		return t.LineStart(l)
 	}
 }
 
 func (t *TextBoxController) IndexDown(i int) int {
 	l := t.LineIndex(i)
-	x := i - t.LineStart(l)
-	if l < t.LineCount()-1 {
-		return math.Min(t.LineStart(l+1)+x, t.LineEnd(l+1))
+	x := t.rowColumn(i)
+	if e := t.RowEnd(i); e != t.LineEnd(l) {
+		return t.rowIndexAt(e, x)
+	}
+	if n := t.nextVisibleLine(l); n != l {
+		return t.rowIndexAt(t.LineStart(n), x)
 	} else {
 		return t.LineEnd(l)
 	}
@@ -355,7 +569,7 @@ func (t *TextBoxController) SetSelections(s TextSelectionList) {
 
 func (t *TextBoxController) SelectAll() {
 	t.storeCaretLocationsNextEdit = true
-	t.SetSelection(TextSelection{0, len(t.text), false})
+	t.SetSelection(TextSelection{0, t.buffer.Len(), false})
 }
 
 func (t *TextBoxController) RestorePreviousSelections() {
@@ -431,45 +645,36 @@ func (t *TextBoxController) MoveHome()          { t.MoveSelections(t.IndexHome)
 func (t *TextBoxController) MoveEnd()           { t.MoveSelections(t.IndexEnd) }
 
 func (t *TextBoxController) Delete() {
+	t.beginEdit()
 	t.maybeStoreCaretLocations()
-	text := t.text
 	edits := []TextBoxEdit{}
 	for i := len(t.selections) - 1; i >= 0; i-- {
 		s := t.selections[i]
-		if s.start == s.end && s.end < len(t.text) {
-			copy(text[s.start:], text[s.start+1:])
-			text = text[:len(text)-1]
-			edits = append(edits, TextBoxEdit{s.start, -1})
+		if s.start == s.end && s.end < t.buffer.Len() {
+			edits = append(edits, t.replaceAt(s.start, s.start+1, nil))
 		} else {
-			copy(text[s.start:], text[s.end:])
-			l := s.Length()
-			text = text[:len(text)-l]
-			edits = append(edits, TextBoxEdit{s.start, -l})
+			edits = append(edits, t.replaceAt(s.start, s.end, nil))
 		}
 		t.selections[i] = TextSelection{s.end, s.end, false}
 	}
-	t.SetTextEdits(text, edits)
+	t.textEdited(edits)
 }
 
 func (t *TextBoxController) Backspace() {
+	t.beginEdit()
 	t.maybeStoreCaretLocations()
-	text := t.text
 	edits := []TextBoxEdit{}
 	for i := len(t.selections) - 1; i >= 0; i-- {
 		s := t.selections[i]
 		if s.start == s.end && s.start > 0 {
-			copy(text[s.start-1:], text[s.start:])
-			text = text[:len(text)-1]
-			edits = append(edits, TextBoxEdit{s.start - 1, -1})
+			edits = append(edits, t.replaceAt(s.start-1, s.start, nil))
 		} else {
-			copy(text[s.start:], text[s.end:])
-			l := s.Length()
-			text = text[:len(text)-l]
-			edits = append(edits, TextBoxEdit{s.start - 1, -l})
+			t.replaceAt(s.start, s.end, nil)
+			edits = append(edits, TextBoxEdit{s.start - 1, -s.Length()})
 		}
 		t.selections[i] = TextSelection{s.end, s.end, false}
 	}
-	t.SetTextEdits(text, edits)
+	t.textEdited(edits)
 }
 
 func (t *TextBoxController) ReplaceAll(str string) {
@@ -485,17 +690,28 @@ func (t *TextBoxController) Replace(f func(sel TextSelection) string) {
 }
 
 func (t *TextBoxController) ReplaceRunes(f func(sel TextSelection) []rune) {
+	t.beginEdit()
 	t.maybeStoreCaretLocations()
-	text, edit, edits := t.text, TextBoxEdit{}, []TextBoxEdit{}
+	edits := []TextBoxEdit{}
 	for i := len(t.selections) - 1; i >= 0; i-- {
 		s := t.selections[i]
-		text, edit = t.ReplaceAt(text, s.start, s.end, f(s))
-		edits = append(edits, edit)
+		edits = append(edits, t.replaceAt(s.start, s.end, f(s)))
 	}
-	t.setTextRunesNoEvent(text)
 	t.textEdited(edits)
 }
 
+// ReplaceRange replaces the runes from s to e of the text with replacement as
+// a single edit, returning the edit.
+func (t *TextBoxController) ReplaceRange(s, e int, replacement []rune) TextBoxEdit {
+	t.beginEdit()
+	edit := t.replaceAt(s, e, replacement)
+	t.textEdited([]TextBoxEdit{edit})
+	return edit
+}
+
+// ReplaceAt returns text with the runes from s to e replaced with
+// replacement, along with the edit describing the change. ReplaceAt does not
+// modify the controller's text; use ReplaceRange for that.
 func (t *TextBoxController) ReplaceAt(text []rune, s, e int, replacement []rune) ([]rune, TextBoxEdit) {
 	replacementLen := len(replacement)
 	delta := replacementLen - (e - s)
@@ -525,11 +741,12 @@ func (t *TextBoxController) ReplaceWithNewlineKeepIndent() {
 }
 
 func (t *TextBoxController) IndentSelection(tabWidth int) {
+	t.beginEdit()
 	tab := make([]rune, tabWidth)
 	for i := range tab {
 		tab[i] = ' '
 	}
-	text, edit, edits := t.text, TextBoxEdit{}, []TextBoxEdit{}
+	edits := []TextBoxEdit{}
 	lastLine := -1
 	for i := len(t.selections) - 1; i >= 0; i-- {
 		s := t.selections[i]
@@ -539,16 +756,16 @@ func (t *TextBoxController) IndentSelection(tabWidth int) {
 		}
 		for l := lie; l >= lis; l-- {
 			ls := t.LineStart(l)
-			text, edit = t.ReplaceAt(text, ls, ls, tab)
-			edits = append(edits, edit)
+			edits = append(edits, t.replaceAt(ls, ls, tab))
 		}
 		lastLine = lis
 	}
-	t.SetTextEdits(text, edits)
+	t.textEdited(edits)
 }
 
 func (t *TextBoxController) UnindentSelection(tabWidth int) {
-	text, edit, edits := t.text, TextBoxEdit{}, []TextBoxEdit{}
+	t.beginEdit()
+	edits := []TextBoxEdit{}
 	lastLine := -1
 	for i := len(t.selections) - 1; i >= 0; i-- {
 		s := t.selections[i]
@@ -560,13 +777,12 @@ func (t *TextBoxController) UnindentSelection(tabWidth int) {
 			c := math.Min(t.LineIndent(l), tabWidth)
 			if c > 0 {
 				ls := t.LineStart(l)
-				text, edit = t.ReplaceAt(text, ls, ls+c, []rune{})
-				edits = append(edits, edit)
+				edits = append(edits, t.replaceAt(ls, ls+c, nil))
 			}
 		}
 		lastLine = lis
 	}
-	t.SetTextEdits(text, edits)
+	t.textEdited(edits)
 }
 
 func (t *TextBoxController) RuneInWord(r rune) bool {
@@ -579,13 +795,11 @@ func (t *TextBoxController) RuneInWord(r rune) bool {
 }
 
 func (t *TextBoxController) WordAt(runeIdx int) (s, e int) {
-	text := t.text
 	s, e = runeIdx, runeIdx
-	for s > 0 && t.RuneInWord(text[s-1]) {
+	for s > 0 && t.RuneInWord(t.buffer.RuneAt(s-1)) {
 		s--
 	}
-	for e < len(t.text) && t.RuneInWord(text[e]) {
-		e++
+	for l := t.buffer.Len(); e < l && t.RuneInWord(t.buffer.RuneAt(e)); e++ {
 	}
 	return s, e
 }
@@ -615,3 +829,17 @@ func (t *TextBoxController) LineAndRow(index int) (line, row int) {
 	row = index - t.LineStart(line)
 	return
 }
+
+// LineAndWrap returns the index of the line holding the rune at index, and
+// the index of the wrapped row of the line holding the rune, where 0 is the
+// first row of the line.
+func (t *TextBoxController) LineAndWrap(index int) (line, wrap int) {
+	line = t.LineIndex(index)
+	for _, w := range t.LineWraps(line) {
+		if w > index {
+			break
+		}
+		wrap++
+	}
+	return
+}
@@ -7,6 +7,7 @@ package gxui
 import (
 	"fmt"
 	test "github.com/google/gxui/testing"
+	"strings"
 	"testing"
 )
 
@@ -276,3 +277,218 @@ func TestTBCUnindentSelection(t *testing.T) {
 	c.UnindentSelection(2)
 	assertTBCTextAndSelectionsEqual(t, "a{aa\n  b]bb|bb\n    [cc}\nddd\ne{e][e}e\n", c)
 }
+
+func TestTBCUndoTyping(t *testing.T) {
+	c := parseTBC("ab|")
+	for _, r := range "cd ef" {
+		c.ReplaceAllRunes([]rune{r})
+		c.Deselect(false)
+	}
+	assertTBCTextAndSelectionsEqual(t, "abcd ef|", c)
+	test.AssertEquals(t, true, c.CanUndo())
+	c.Undo()
+	assertTBCTextAndSelectionsEqual(t, "abcd |", c)
+	c.Undo()
+	assertTBCTextAndSelectionsEqual(t, "ab|", c)
+	test.AssertEquals(t, false, c.CanUndo())
+	c.Redo()
+	assertTBCTextAndSelectionsEqual(t, "abcd |", c)
+	c.Redo()
+	assertTBCTextAndSelectionsEqual(t, "abcd ef|", c)
+	test.AssertEquals(t, false, c.CanRedo())
+}
+
+func TestTBCUndoCaretMoveBreaksGroup(t *testing.T) {
+	c := parseTBC("|")
+	typeText := func(s string) {
+		for _, r := range s {
+			c.ReplaceAllRunes([]rune{r})
+			c.Deselect(false)
+		}
+	}
+	typeText("ab")
+	c.MoveLeft()
+	typeText("c")
+	assertTBCTextAndSelectionsEqual(t, "ac|b", c)
+	c.Undo()
+	assertTBCTextAndSelectionsEqual(t, "a|b", c)
+	c.Undo()
+	assertTBCTextAndSelectionsEqual(t, "|", c)
+}
+
+func TestTBCUndoBackspace(t *testing.T) {
+	c := parseTBC("ab cd|")
+	for i := 0; i < 4; i++ {
+		c.Backspace()
+	}
+	assertTBCTextAndSelectionsEqual(t, "a|", c)
+	c.Undo()
+	assertTBCTextAndSelectionsEqual(t, "ab|", c)
+	c.Undo()
+	assertTBCTextAndSelectionsEqual(t, "ab cd|", c)
+}
+
+func TestTBCUndoTypingMultipleCarets(t *testing.T) {
+	c := parseTBC("a|\nb|")
+	for _, r := range "cd ef" {
+		c.ReplaceAllRunes([]rune{r})
+		c.Deselect(false)
+	}
+	assertTBCTextAndSelectionsEqual(t, "acd ef|\nbcd ef|", c)
+	c.Undo()
+	assertTBCTextAndSelectionsEqual(t, "acd |\nbcd |", c)
+	for i := 0; i < 3; i++ {
+		c.Backspace()
+	}
+	assertTBCTextAndSelectionsEqual(t, "a|\nb|", c)
+	c.Undo()
+	assertTBCTextAndSelectionsEqual(t, "acd|\nbcd|", c)
+	c.Undo()
+	assertTBCTextAndSelectionsEqual(t, "acd |\nbcd |", c)
+	c.Undo()
+	assertTBCTextAndSelectionsEqual(t, "a|\nb|", c)
+	test.AssertEquals(t, false, c.CanUndo())
+	c.Redo()
+	assertTBCTextAndSelectionsEqual(t, "acd |\nbcd |", c)
+}
+
+func TestTBCUndoRestoresSelections(t *testing.T) {
+	c := parseTBC("a{bc]d\ne{fg]h")
+	c.ReplaceAll("_")
+	c.Deselect(false)
+	assertTBCTextAndSelectionsEqual(t, "a_|d\ne_|h", c)
+	c.Undo()
+	assertTBCTextAndSelectionsEqual(t, "a{bc]d\ne{fg]h", c)
+	c.Redo()
+	assertTBCTextAndSelectionsEqual(t, "a_|d\ne_|h", c)
+
+	// A new edit discards the redo steps.
+	c.Undo()
+	c.Delete()
+	test.AssertEquals(t, false, c.CanRedo())
+}
+
+func TestTBCHistoryLimit(t *testing.T) {
+	c := parseTBC("|")
+	c.SetHistoryLimit(2)
+	for _, s := range []string{"a", " ", "b", " ", "c"} {
+		c.ReplaceAll(s)
+		c.Deselect(false)
+		c.MoveRight() // Break the typing groups
+	}
+	c.Undo()
+	c.Undo()
+	test.AssertEquals(t, false, c.CanUndo())
+	test.AssertEquals(t, "a b", c.Text())
+
+	c.SetHistoryLimit(0)
+	c.ReplaceAll("d")
+	test.AssertEquals(t, false, c.CanUndo())
+}
+
+func TestTBCUndoSetTextEdits(t *testing.T) {
+	c := CreateTextBoxController()
+	before := strings.Repeat("a", 1000)
+	after := before[:600] + "bc" + before[601:]
+	c.SetText(before)
+	c.SetTextEdits([]rune(after), []TextBoxEdit{{At: 600, Delta: 1}})
+	test.AssertEquals(t, after, c.Text())
+	// Only the changed runes are recorded.
+	test.AssertEquals(t, []textBoxReplace{{at: 600, old: []rune("a"), new: []rune("bc")}}, c.history.undos[0].replaces)
+	c.Undo()
+	test.AssertEquals(t, before, c.Text())
+	c.Redo()
+	test.AssertEquals(t, after, c.Text())
+}
+
+func TestTBCSetTextClearsHistory(t *testing.T) {
+	c := parseTBC("|")
+	c.ReplaceAll("a")
+	c.SetText("b")
+	test.AssertEquals(t, false, c.CanUndo())
+}
+
+func TestTBCHiddenLines(t *testing.T) {
+	c := parseTBC("AA|\nBB\nCC\nD|D")
+	c.SetVisibleLines([]int{0, 3})
+	c.MoveDown()
+	assertTBCTextAndSelectionsEqual(t, "AA\nBB\nCC\nDD|", c)
+	c.MoveUp()
+	assertTBCTextAndSelectionsEqual(t, "AA|\nBB\nCC\nDD", c)
+
+	c = parseTBC("AA|\nBB\nCC\n|DD")
+	c.SetVisibleLines([]int{0, 3})
+	c.MoveRight()
+	assertTBCTextAndSelectionsEqual(t, "AA\nBB\nCC\n|D|D", c)
+	c.SetCaret(9)
+	c.MoveLeft()
+	assertTBCTextAndSelectionsEqual(t, "AA|\nBB\nCC\nDD", c)
+	c.SelectDown()
+	assertTBCTextAndSelectionsEqual(t, "AA{\nBB\nCC\nDD]", c)
+
+	c = parseTBC("AA|\nBB\nCC")
+	c.SetVisibleLines([]int{0})
+	c.MoveRightByWord()
+	assertTBCTextAndSelectionsEqual(t, "AA|\nBB\nCC", c)
+	c.MoveLast()
+	assertTBCTextAndSelectionsEqual(t, "AA|\nBB\nCC", c)
+	test.AssertEquals(t, true, c.LineHidden(2))
+	c.SetVisibleLines(nil)
+	test.AssertEquals(t, false, c.LineHidden(2))
+}
+
+func TestTBCLineWraps(t *testing.T) {
+	// The first line is displayed as "  abc", "  def " and "  gh".
+	c := parseTBC("  a|bcdef gh\nxy")
+	c.SetLineWraps(func(l int) []int {
+		if l == 0 {
+			return []int{5, 9}
+		}
+		return nil
+	})
+	test.AssertEquals(t, 5, c.RowStart(5))
+	test.AssertEquals(t, 9, c.RowEnd(5))
+	line, wrap := c.LineAndWrap(9)
+	test.AssertEquals(t, 0, line)
+	test.AssertEquals(t, 2, wrap)
+	line, row := c.LineAndRow(9)
+	test.AssertEquals(t, 0, line)
+	test.AssertEquals(t, 9, row)
+
+	c.MoveDown()
+	assertTBCTextAndSelectionsEqual(t, "  abcd|ef gh\nxy", c)
+	c.MoveDown()
+	assertTBCTextAndSelectionsEqual(t, "  abcdef g|h\nxy", c)
+	c.MoveDown()
+	assertTBCTextAndSelectionsEqual(t, "  abcdef gh\nxy|", c)
+	c.MoveUp()
+	assertTBCTextAndSelectionsEqual(t, "  abcdef |gh\nxy", c)
+	c.MoveUp()
+	assertTBCTextAndSelectionsEqual(t, "  abc|def gh\nxy", c)
+}
+
+func TestTBCColumnSelection(t *testing.T) {
+	c := parseTBC("ab|cd\nx\n\tyz")
+	c.SetTabWidth(4)
+	test.AssertEquals(t, 4, c.Column(8))
+	test.AssertEquals(t, 7, c.IndexAtColumn(2, 3))
+
+	c.SelectColumnsDown()
+	c.SelectColumnsRight()
+	assertTBCTextAndSelectionsEqual(t, "ab{c]d\nx|\n\tyz", c)
+	c.SelectColumnsRight()
+	c.SelectColumnsRight() // Stops at the end of the longest line
+	assertTBCTextAndSelectionsEqual(t, "ab{cd]\nx|\n\tyz", c)
+	c.SelectColumnsDown()
+	assertTBCTextAndSelectionsEqual(t, "ab{cd]\nx|\n{\t]yz", c)
+	test.AssertEquals(t, true, c.ColumnSelected())
+
+	c.ReplaceAllLines("1\n2\n3")
+	test.AssertEquals(t, "ab1\nx2\n3yz", c.Text())
+	test.AssertEquals(t, false, c.ColumnSelected())
+
+	c.SelectColumns(0, 3, 1, 1)
+	assertTBCTextAndSelectionsEqual(t, "a[b1}\nx[2}\n3yz", c)
+	c.ReplaceAllLines("z")
+	test.AssertEquals(t, "az\nxz\n3yz", c.Text())
+}
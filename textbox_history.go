@@ -0,0 +1,240 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gxui
+
+import (
+	"github.com/google/gxui/math"
+)
+
+// DefaultTextBoxHistoryLimit is the maximum number of undo steps held by a
+// TextBoxController, unless changed with SetHistoryLimit.
+const DefaultTextBoxHistoryLimit = 1000
+
+// textBoxReplace replaces the runes old at the index at with the runes new.
+type textBoxReplace struct {
+	at       int
+	old, new []rune
+}
+
+// textBoxUndo is a single undo step, made of the replacements applied by one
+// edit, in the order they were applied.
+type textBoxUndo struct {
+	replaces []textBoxReplace
+	before   TextSelectionList // Selections to restore on undo
+	after    TextSelectionList // Selections to restore on redo
+}
+
+type textBoxHistory struct {
+	pending  []textBoxReplace // Replacements made by the current edit
+	undos    []textBoxUndo
+	index    int // Number of undos that can be undone
+	limit    int
+	editing  bool // Between beginEdit and recordEdit
+	before   TextSelectionList
+	moved    bool // The carets were moved before the current edit
+	applying bool // An undo or redo is being applied
+	group    bool // The last undo can be extended by further typing
+}
+
+// beginEdit records the selections before an edit is made. beginEdit must be
+// called before the text or selections are modified by an edit.
+func (t *TextBoxController) beginEdit() {
+	h := &t.history
+	if !h.editing {
+		h.editing = true
+		h.before = t.Selections()
+		h.moved = t.storeCaretLocationsNextEdit
+	}
+}
+
+// recordReplace records the replacement of the runes from s to e with
+// replacement as part of the current edit. recordReplace must be called
+// before the buffer is modified.
+func (t *TextBoxController) recordReplace(s, e int, replacement []rune) {
+	h := &t.history
+	if !h.editing || h.applying || (s == e && len(replacement) == 0) {
+		return
+	}
+	h.pending = append(h.pending, textBoxReplace{
+		at:  s,
+		old: t.buffer.Runes(s, e),
+		new: append([]rune{}, replacement...),
+	})
+}
+
+// recordEdit adds the replacements made since beginEdit to the history.
+func (t *TextBoxController) recordEdit(edits []TextBoxEdit) {
+	h := &t.history
+	h.editing = false
+	replaces := h.pending
+	h.pending = nil
+	if len(replaces) == 0 || h.applying {
+		return
+	}
+
+	after := t.Selections()
+	for i, s := range after {
+		after[i] = TextSelection{s.end, s.end, false}
+	}
+	u := textBoxUndo{replaces: replaces, before: h.before, after: after}
+	merged := h.group && !h.moved && h.index == len(h.undos) && h.index > 0 &&
+		t.mergeUndo(&h.undos[h.index-1], u)
+	if !merged {
+		h.undos = append(h.undos[:h.index], u)
+		if h.limit >= 0 && len(h.undos) > h.limit {
+			h.undos = h.undos[len(h.undos)-h.limit:]
+		}
+		h.index = len(h.undos)
+	}
+	h.group = true
+	for _, r := range replaces {
+		h.group = h.group && len(r.old)+len(r.new) == 1
+	}
+}
+
+// mergeUndo attempts to extend the typing or deletion of a single rune at each
+// caret in p with u. Runs of word runes are kept together, with a new undo
+// step starting when any caret types or deletes the first word rune after a
+// non-word rune.
+func (t *TextBoxController) mergeUndo(p *textBoxUndo, u textBoxUndo) bool {
+	if len(p.replaces) != len(u.replaces) {
+		return false
+	}
+	merged := make([]textBoxReplace, len(p.replaces))
+	for i, pr := range p.replaces {
+		// Find where the replace starts once the replaces after it are applied.
+		at := pr.at
+		for _, r := range p.replaces[i+1:] {
+			if r.at+len(r.old) <= at {
+				at += len(r.new) - len(r.old)
+			}
+		}
+		r, ok := t.mergeReplace(pr, u.replaces[i], at)
+		if !ok {
+			return false
+		}
+		merged[i] = r
+	}
+	p.replaces = merged
+	p.after = u.after
+	return true
+}
+
+// mergeReplace attempts to extend the typing or deletion of pr with ur, where
+// at is the index of the start of pr once all the replaces of its undo step
+// are applied.
+func (t *TextBoxController) mergeReplace(pr, ur textBoxReplace, at int) (textBoxReplace, bool) {
+	startsWord := func(r, prev rune) bool {
+		return t.RuneInWord(r) && !t.RuneInWord(prev)
+	}
+	switch {
+	case len(pr.old) == 0 && len(ur.old) == 0 && len(ur.new) == 1:
+		// Typing
+		if ur.at != at+len(pr.new) || startsWord(ur.new[0], pr.new[len(pr.new)-1]) {
+			return pr, false
+		}
+		pr.new = append(pr.new[:len(pr.new):len(pr.new)], ur.new...)
+	case len(pr.new) == 0 && len(ur.new) == 0 && len(ur.old) == 1 && ur.at+1 == at:
+		// Backspace
+		if startsWord(ur.old[0], pr.old[0]) {
+			return pr, false
+		}
+		pr.at, pr.old = pr.at-1, append(ur.old, pr.old...)
+	case len(pr.new) == 0 && len(ur.new) == 0 && len(ur.old) == 1 && ur.at == at:
+		// Delete
+		if startsWord(ur.old[0], pr.old[len(pr.old)-1]) {
+			return pr, false
+		}
+		pr.old = append(pr.old[:len(pr.old):len(pr.old)], ur.old...)
+	default:
+		return pr, false
+	}
+	return pr, true
+}
+
+// resetHistory clears the history.
+func (t *TextBoxController) resetHistory() {
+	h := &t.history
+	h.pending = nil
+	h.undos = nil
+	h.index = 0
+	h.group = false
+}
+
+// applyHistory applies the replacements of u, or reverts them if undo is true,
+// and then restores the selections.
+func (t *TextBoxController) applyHistory(u textBoxUndo, undo bool) {
+	h := &t.history
+	h.applying = true
+	t.beginEdit()
+	edits := make([]TextBoxEdit, len(u.replaces))
+	selections := u.after
+	if undo {
+		selections = u.before
+		for i := len(u.replaces) - 1; i >= 0; i-- {
+			r := u.replaces[i]
+			edits[len(edits)-1-i] = t.replaceAt(r.at, r.at+len(r.new), r.old)
+		}
+	} else {
+		for i, r := range u.replaces {
+			edits[i] = t.replaceAt(r.at, r.at+len(r.old), r.new)
+		}
+	}
+	t.textEdited(edits)
+	h.applying = false
+	h.group = false
+	t.SetSelections(append(TextSelectionList{}, selections...))
+}
+
+// CanUndo returns true if there is an edit that can be undone.
+func (t *TextBoxController) CanUndo() bool {
+	return t.history.index > 0
+}
+
+// CanRedo returns true if there is an undone edit that can be redone.
+func (t *TextBoxController) CanRedo() bool {
+	return t.history.index < len(t.history.undos)
+}
+
+// Undo reverts the last edit, or group of typed runes, and restores the
+// selections from before the edit.
+func (t *TextBoxController) Undo() {
+	if t.CanUndo() {
+		h := &t.history
+		h.index--
+		t.applyHistory(h.undos[h.index], true)
+	}
+}
+
+// Redo reapplies the last undone edit.
+func (t *TextBoxController) Redo() {
+	if t.CanRedo() {
+		h := &t.history
+		h.index++
+		t.applyHistory(h.undos[h.index-1], false)
+	}
+}
+
+// ClearHistory removes all the undo and redo steps.
+func (t *TextBoxController) ClearHistory() {
+	t.resetHistory()
+}
+
+func (t *TextBoxController) HistoryLimit() int {
+	return t.history.limit
+}
+
+// SetHistoryLimit sets the maximum number of undo steps held. Older steps are
+// discarded once the limit is reached. A limit of 0 disables undo, and a
+// negative limit allows an unlimited number of steps.
+func (t *TextBoxController) SetHistoryLimit(limit int) {
+	h := &t.history
+	h.limit = limit
+	if limit >= 0 && len(h.undos) > limit {
+		drop := len(h.undos) - limit
+		h.undos = h.undos[drop:]
+		h.index = math.Max(h.index-drop, 0)
+	}
+}
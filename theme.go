@@ -12,6 +12,7 @@ type Theme interface {
 	CreateButton() Button
 	CreateCodeEditor() CodeEditor
 	CreateDropDownList() DropDownList
+	CreateFindBar() FindBar
 	CreateImage() Image
 	CreateLabel() Label
 	CreateLinearLayout() LinearLayout
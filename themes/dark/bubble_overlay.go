@@ -22,6 +22,7 @@ func CreateBubbleOverlay(theme *Theme) gxui.BubbleOverlay {
 	b.SetPadding(math.Spacing{L: 5, T: 5, R: 5, B: 5})
 	b.SetPen(theme.BubbleOverlayStyle.Pen)
 	b.SetBrush(theme.BubbleOverlayStyle.Brush)
+	b.SetShadow(theme.BubbleOverlayStyle.Shadow)
 	b.theme = theme
 	return b
 }
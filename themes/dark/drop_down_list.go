@@ -24,13 +24,16 @@ func CreateDropDownList(theme *Theme) gxui.DropDownList {
 	l.List().OnDetach(l.Redraw)
 	l.OnMouseEnter(func(gxui.MouseEvent) {
 		l.SetBorderPen(theme.DropDownListOverStyle.Pen)
+		l.SetShadow(theme.DropDownListOverStyle.Shadow)
 	})
 	l.OnMouseExit(func(gxui.MouseEvent) {
 		l.SetBorderPen(theme.DropDownListDefaultStyle.Pen)
+		l.SetShadow(theme.DropDownListDefaultStyle.Shadow)
 	})
 	l.SetPadding(math.CreateSpacing(2))
 	l.SetBorderPen(theme.DropDownListDefaultStyle.Pen)
 	l.SetBackgroundBrush(theme.DropDownListDefaultStyle.Brush)
+	l.SetShadow(theme.DropDownListDefaultStyle.Shadow)
 	l.theme = theme
 	return l
 }
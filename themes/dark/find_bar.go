@@ -0,0 +1,21 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dark
+
+import (
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+	"github.com/google/gxui/mixins"
+)
+
+func CreateFindBar(theme *Theme) gxui.FindBar {
+	f := &mixins.FindBar{}
+	f.Init(f, theme)
+	f.SetPadding(math.Spacing{L: 3, T: 3, R: 3, B: 3})
+	f.SetBackgroundBrush(theme.PanelBackgroundStyle.Brush)
+	f.SetBorderPen(theme.PanelBackgroundStyle.Pen)
+	f.SetHighlightColor(theme.FindHighlightColor)
+	return f
+}
@@ -18,6 +18,7 @@ type List struct {
 func CreateList(theme *Theme) gxui.List {
 	l := &List{}
 	l.Init(l, theme)
+	l.SetAnimator(theme.Animator)
 	l.OnGainedFocus(l.Redraw)
 	l.OnLostFocus(l.Redraw)
 	l.SetPadding(math.CreateSpacing(2))
@@ -18,6 +18,7 @@ type PanelHolder struct {
 func CreatePanelHolder(theme *Theme) gxui.PanelHolder {
 	p := &PanelHolder{}
 	p.PanelHolder.Init(p, theme)
+	p.SetAnimator(theme.Animator)
 	p.theme = theme
 	p.SetMargin(math.Spacing{L: 0, T: 2, R: 0, B: 0})
 	return p
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/google/gxui"
+	"github.com/google/gxui/animation"
 	"github.com/google/gxui/math"
 	"github.com/google/gxui/mixins"
 )
@@ -15,7 +16,7 @@ import (
 type ProgressBar struct {
 	mixins.ProgressBar
 	theme        *Theme
-	ticker       *time.Ticker
+	scrolling    *animation.Handle
 	chevrons     gxui.Canvas
 	chevronWidth int
 	scroll       int
@@ -26,25 +27,19 @@ func CreateProgressBar(theme *Theme) gxui.ProgressBar {
 	b.Init(b, theme)
 	b.theme = theme
 	b.chevronWidth = 10
+	b.SetAnimator(theme.Animator)
 
 	b.OnAttach(func() {
-		driver := theme.Driver()
-		b.ticker = time.NewTicker(time.Millisecond * 50)
-		go func() {
-			for _ = range b.ticker.C {
-				if !driver.Call(b.animationTick) {
-					return
-				}
-			}
-		}()
+		scroll := animation.Float(0, 1, time.Second, animation.Linear, b.setScroll)
+		b.scrolling = theme.Animator.Start(animation.Repeat(scroll, 0))
 	})
 
 	b.OnDetach(func() {
+		b.scrolling.Cancel()
+		b.scrolling = nil
 		if b.chevrons != nil {
 			b.chevrons.Release()
 			b.chevrons = nil
-			b.ticker.Stop()
-			b.ticker = nil
 		}
 	})
 	b.SetBackgroundBrush(gxui.CreateBrush(gxui.Gray10))
@@ -52,9 +47,10 @@ func CreateProgressBar(theme *Theme) gxui.ProgressBar {
 	return b
 }
 
-func (b *ProgressBar) animationTick() {
-	if b.Attached() {
-		b.scroll = (b.scroll + 1) % (b.chevronWidth * 2)
+func (b *ProgressBar) setScroll(f float32) {
+	scroll := int(f * float32(b.chevronWidth*2))
+	if b.scroll != scroll {
+		b.scroll = scroll
 		b.Redraw()
 	}
 }
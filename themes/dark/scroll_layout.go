@@ -12,5 +12,6 @@ import (
 func CreateScrollLayout(theme *Theme) gxui.ScrollLayout {
 	l := &mixins.ScrollLayout{}
 	l.Init(l, theme)
+	l.SetAnimator(theme.Animator)
 	return l
 }
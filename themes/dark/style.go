@@ -12,6 +12,7 @@ type Style struct {
 	FontColor gxui.Color
 	Brush     gxui.Brush
 	Pen       gxui.Pen
+	Shadow    gxui.Shadow
 }
 
 func CreateStyle(fontColor, brushColor, penColor gxui.Color, penWidth float32) Style {
@@ -21,3 +22,19 @@ func CreateStyle(fontColor, brushColor, penColor gxui.Color, penWidth float32) S
 		Brush:     gxui.CreateBrush(brushColor),
 	}
 }
+
+// CreateGradientStyle returns a Style with a brush that blends vertically from
+// top to bottom across the painted control.
+func CreateGradientStyle(fontColor, top, bottom, penColor gxui.Color, penWidth float32) Style {
+	return Style{
+		FontColor: fontColor,
+		Pen:       gxui.CreatePen(penWidth, penColor),
+		Brush:     gxui.CreateVerticalGradientBrush(top, bottom),
+	}
+}
+
+// WithShadow returns a copy of the Style that draws shadow.
+func (s Style) WithShadow(shadow gxui.Shadow) Style {
+	s.Shadow = shadow
+	return s
+}
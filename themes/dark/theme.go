@@ -8,7 +8,9 @@ import (
 	"fmt"
 
 	"github.com/google/gxui"
+	"github.com/google/gxui/animation"
 	"github.com/google/gxui/gxfont"
+	"github.com/google/gxui/math"
 )
 
 type Theme struct {
@@ -16,8 +18,15 @@ type Theme struct {
 	defaultFont          gxui.Font
 	defaultMonospaceFont gxui.Font
 
+	// Animator runs the animations of the theme's controls.
+	Animator *animation.Animator
+
 	WindowBackground gxui.Color
 
+	// FindHighlightColor is the background color of the matches found by a
+	// FindBar.
+	FindHighlightColor gxui.Color
+
 	BubbleOverlayStyle        Style
 	ButtonDefaultStyle        Style
 	ButtonOverStyle           Style
@@ -67,20 +76,25 @@ func CreateTheme(driver gxui.Driver) gxui.Theme {
 	neonBlue := gxui.ColorFromHex(0xFF5C8CFF)
 	focus := gxui.ColorFromHex(0xA0C4D6FF)
 
+	dropShadow := gxui.CreateShadow(gxui.Color{A: 0.6}, math.Point{Y: 3}, 8)
+	insetShadow := gxui.CreateInnerShadow(gxui.Color{A: 0.5}, math.Point{Y: 1}, 3)
+
 	return &Theme{
 		driver:               driver,
 		defaultFont:          defaultFont,
 		defaultMonospaceFont: defaultMonospaceFont,
+		Animator:             animation.CreateAnimator(animation.DriverClock(driver)),
 		WindowBackground:     gxui.Black,
+		FindHighlightColor:   gxui.Color{R: 0.36, G: 0.55, B: 1.0, A: 0.35},
 
 		//                                   fontColor    brushColor   penColor
-		BubbleOverlayStyle:        CreateStyle(gxui.Gray80, gxui.Gray20, gxui.Gray40, 1.0),
+		BubbleOverlayStyle:        CreateStyle(gxui.Gray80, gxui.Gray20, gxui.Gray40, 1.0).WithShadow(dropShadow),
 		ButtonDefaultStyle:        CreateStyle(gxui.Gray80, gxui.Gray10, gxui.Gray20, 1.0),
 		ButtonOverStyle:           CreateStyle(gxui.Gray90, gxui.Gray15, gxui.Gray50, 1.0),
 		ButtonPressedStyle:        CreateStyle(gxui.Gray20, gxui.Gray70, gxui.Gray30, 1.0),
 		CodeSuggestionListStyle:   CreateStyle(gxui.Gray80, gxui.Gray20, gxui.Gray10, 1.0),
-		DropDownListDefaultStyle:  CreateStyle(gxui.Gray80, gxui.Gray10, gxui.Gray20, 1.0),
-		DropDownListOverStyle:     CreateStyle(gxui.Gray80, gxui.Gray15, gxui.Gray50, 1.0),
+		DropDownListDefaultStyle:  CreateStyle(gxui.Gray80, gxui.Gray10, gxui.Gray20, 1.0).WithShadow(insetShadow),
+		DropDownListOverStyle:     CreateStyle(gxui.Gray80, gxui.Gray15, gxui.Gray50, 1.0).WithShadow(insetShadow),
 		FocusedStyle:              CreateStyle(gxui.Gray80, gxui.Transparent, focus, 1.0),
 		HighlightStyle:            CreateStyle(gxui.Gray80, gxui.Transparent, neonBlue, 2.0),
 		LabelStyle:                CreateStyle(gxui.Gray80, gxui.Transparent, gxui.Transparent, 0.0),
@@ -129,6 +143,10 @@ func (t *Theme) CreateDropDownList() gxui.DropDownList {
 	return CreateDropDownList(t)
 }
 
+func (t *Theme) CreateFindBar() gxui.FindBar {
+	return CreateFindBar(t)
+}
+
 func (t *Theme) CreateImage() gxui.Image {
 	return CreateImage(t)
 }
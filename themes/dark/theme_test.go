@@ -0,0 +1,44 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dark
+
+import test "github.com/google/gxui/testing"
+import (
+	"flag"
+	"testing"
+
+	"github.com/google/gxui"
+	"github.com/google/gxui/math"
+	"github.com/google/gxui/testing/snapshot"
+)
+
+// The per-channel tolerance used for golden comparisons. This absorbs minor
+// differences in font rasterization between freetype versions.
+const goldenTolerance = 8
+
+var updateGoldens = flag.Bool("update", false, "regenerate golden images instead of comparing against them")
+
+func TestButtonGolden(t *testing.T) {
+	img := snapshot.Render(math.Size{W: 80, H: 32}, CreateTheme, func(theme gxui.Theme) gxui.Control {
+		b := theme.CreateButton()
+		b.SetText("Button")
+		return b
+	})
+	test.AssertImageMatchesGolden(t, img, "testdata/button.png", goldenTolerance, *updateGoldens)
+}
+
+func TestLinearLayoutGolden(t *testing.T) {
+	img := snapshot.Render(math.Size{W: 120, H: 80}, CreateTheme, func(theme gxui.Theme) gxui.Control {
+		l := theme.CreateLinearLayout()
+		label := theme.CreateLabel()
+		label.SetText("Label")
+		box := theme.CreateTextBox()
+		box.SetText("Text")
+		l.AddChild(label)
+		l.AddChild(box)
+		return l
+	})
+	test.AssertImageMatchesGolden(t, img, "testdata/linear_layout.png", goldenTolerance, *updateGoldens)
+}
@@ -30,6 +30,7 @@ var collapsedPoly = gxui.Polygon{
 func CreateTree(theme *Theme) gxui.Tree {
 	t := &Tree{}
 	t.Init(t, theme)
+	t.SetAnimator(theme.Animator)
 	t.SetPadding(math.Spacing{L: 3, T: 3, R: 3, B: 3})
 	t.SetBorderPen(gxui.TransparentPen)
 	t.theme = theme
@@ -0,0 +1,183 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gxui
+
+import (
+	"sync"
+	"time"
+)
+
+// FrameInterval is the time between the calls of a function registered with
+// Driver.OnFrame.
+const FrameInterval = time.Second / 60
+
+// Timer is a handle to a function scheduled with Driver.After, Driver.Every or
+// Driver.OnFrame.
+type Timer interface {
+	// Stop cancels all future calls of the timer's function, returning false if
+	// the timer had already been stopped or had already called its function
+	// for the last time. If Stop is called on the UI go-routine, the function
+	// is not called after Stop returns.
+	Stop() bool
+}
+
+// DriverTimers implements the timer methods of Driver on top of a Clock. It is
+// intended to be embedded by driver implementations.
+type DriverTimers struct {
+	clock      Clock
+	call       func(func()) bool
+	mutex      sync.Mutex
+	timers     map[*driverTimer]struct{}
+	queued     int // Timer calls queued on the UI go-routine but not yet run
+	terminated bool
+}
+
+type driverTimer struct {
+	owner      *DriverTimers
+	f          func()
+	interval   time.Duration // 0 for timers that only fire once
+	coalesce   bool          // Skip ticks while a call is still queued
+	pending    bool
+	stopped    bool
+	clockTimer Timer
+}
+
+// CreateDriverTimers returns a DriverTimers using clock for time, and call to
+// queue functions on the UI go-routine.
+func CreateDriverTimers(clock Clock, call func(f func()) bool) *DriverTimers {
+	if clock == nil {
+		panic("Clock must not be nil")
+	}
+	return &DriverTimers{
+		clock:  clock,
+		call:   call,
+		timers: make(map[*driverTimer]struct{}),
+	}
+}
+
+func (d *DriverTimers) start(interval, delay time.Duration, coalesce bool, f func()) Timer {
+	if f == nil {
+		panic("Function must not be nil")
+	}
+	t := &driverTimer{owner: d, f: f, interval: interval, coalesce: coalesce}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.terminated {
+		t.stopped = true
+		return t
+	}
+	d.timers[t] = struct{}{}
+	t.schedule(delay)
+	return t
+}
+
+// StopAll stops all the timers, and any subsequently created timers. Drivers
+// call StopAll when they are terminated.
+func (d *DriverTimers) StopAll() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.terminated = true
+	for t := range d.timers {
+		t.stop()
+	}
+}
+
+// Queued returns the number of calls of timer functions that have fallen due
+// but have not yet been run on the UI go-routine.
+func (d *DriverTimers) Queued() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.queued
+}
+
+// Driver compliance
+func (d *DriverTimers) Clock() Clock {
+	return d.clock
+}
+
+func (d *DriverTimers) After(delay time.Duration, f func()) Timer {
+	return d.start(0, delay, false, f)
+}
+
+func (d *DriverTimers) Every(interval time.Duration, f func()) Timer {
+	if interval <= 0 {
+		panic("Interval must be positive")
+	}
+	return d.start(interval, interval, false, f)
+}
+
+func (d *DriverTimers) OnFrame(f func()) Timer {
+	return d.start(FrameInterval, FrameInterval, true, f)
+}
+
+// schedule must be called with owner.mutex locked.
+func (t *driverTimer) schedule(delay time.Duration) {
+	t.clockTimer = t.owner.clock.AfterFunc(delay, t.fire)
+}
+
+// fire is called by the clock when the timer is due.
+func (t *driverTimer) fire() {
+	d := t.owner
+	d.mutex.Lock()
+	if t.stopped {
+		d.mutex.Unlock()
+		return
+	}
+	if t.interval > 0 {
+		t.schedule(t.interval)
+	}
+	if t.coalesce && t.pending {
+		d.mutex.Unlock()
+		return
+	}
+	t.pending = true
+	d.queued++
+	d.mutex.Unlock()
+
+	if !d.call(t.run) {
+		d.mutex.Lock()
+		d.queued--
+		t.stop() // Driver has been terminated
+		d.mutex.Unlock()
+	}
+}
+
+// run is called on the UI go-routine.
+func (t *driverTimer) run() {
+	d := t.owner
+	d.mutex.Lock()
+	d.queued--
+	if t.stopped {
+		d.mutex.Unlock()
+		return
+	}
+	t.pending = false
+	if t.interval == 0 {
+		t.stopped = true
+		delete(d.timers, t)
+	}
+	d.mutex.Unlock()
+	t.f()
+}
+
+// stop must be called with owner.mutex locked.
+func (t *driverTimer) stop() bool {
+	if t.stopped {
+		return false
+	}
+	t.stopped = true
+	delete(t.owner.timers, t)
+	if t.clockTimer != nil {
+		t.clockTimer.Stop()
+	}
+	return true
+}
+
+// Timer compliance
+func (t *driverTimer) Stop() bool {
+	t.owner.mutex.Lock()
+	defer t.owner.mutex.Unlock()
+	return t.stop()
+}
@@ -0,0 +1,125 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gxui
+
+import (
+	"testing"
+	"time"
+
+	test "github.com/google/gxui/testing"
+)
+
+// testTimers returns DriverTimers using a ManualClock, and a function that
+// runs the functions queued for the UI go-routine.
+func testTimers() (*DriverTimers, *ManualClock, func()) {
+	clock := CreateManualClock(time.Unix(0, 0))
+	queue := []func(){}
+	timers := CreateDriverTimers(clock, func(f func()) bool {
+		queue = append(queue, f)
+		return true
+	})
+	flush := func() {
+		for len(queue) > 0 {
+			f := queue[0]
+			queue = queue[1:]
+			f()
+		}
+	}
+	return timers, clock, flush
+}
+
+func TestManualClock(t *testing.T) {
+	clock := CreateManualClock(time.Unix(0, 0))
+	calls := []time.Duration{}
+	record := func() { calls = append(calls, clock.Now().Sub(time.Unix(0, 0))) }
+	clock.AfterFunc(30*time.Millisecond, record)
+	clock.AfterFunc(10*time.Millisecond, func() {
+		record()
+		clock.AfterFunc(5*time.Millisecond, record)
+	})
+	stopped := clock.AfterFunc(20*time.Millisecond, record)
+	test.AssertEquals(t, true, stopped.Stop())
+	test.AssertEquals(t, false, stopped.Stop())
+
+	clock.Advance(25 * time.Millisecond)
+	test.AssertEquals(t, []time.Duration{10 * time.Millisecond, 15 * time.Millisecond}, calls)
+	test.AssertEquals(t, time.Unix(0, 0).Add(25*time.Millisecond), clock.Now())
+
+	clock.Advance(5 * time.Millisecond)
+	test.AssertEquals(t, 3, len(calls))
+}
+
+func TestDriverTimersAfter(t *testing.T) {
+	timers, clock, flush := testTimers()
+	count := 0
+	timer := timers.After(100*time.Millisecond, func() { count++ })
+
+	clock.Advance(99 * time.Millisecond)
+	flush()
+	test.AssertEquals(t, 0, count)
+
+	clock.Advance(time.Millisecond)
+	test.AssertEquals(t, 1, timers.Queued())
+	flush()
+	test.AssertEquals(t, 0, timers.Queued())
+	test.AssertEquals(t, 1, count)
+	test.AssertEquals(t, false, timer.Stop())
+
+	clock.Advance(time.Second)
+	flush()
+	test.AssertEquals(t, 1, count)
+
+	// Stopping a timer that is due but has not yet run cancels the call.
+	timer = timers.After(100*time.Millisecond, func() { count++ })
+	clock.Advance(100 * time.Millisecond)
+	test.AssertEquals(t, true, timer.Stop())
+	flush()
+	test.AssertEquals(t, 0, timers.Queued())
+	test.AssertEquals(t, 1, count)
+}
+
+func TestDriverTimersEvery(t *testing.T) {
+	timers, clock, flush := testTimers()
+	count := 0
+	timer := timers.Every(100*time.Millisecond, func() { count++ })
+
+	clock.Advance(350 * time.Millisecond)
+	flush()
+	test.AssertEquals(t, 3, count)
+
+	test.AssertEquals(t, true, timer.Stop())
+	clock.Advance(time.Second)
+	flush()
+	test.AssertEquals(t, 3, count)
+}
+
+func TestDriverTimersOnFrame(t *testing.T) {
+	timers, clock, flush := testTimers()
+	count := 0
+	timers.OnFrame(func() { count++ })
+
+	// Frames are dropped while one is still queued.
+	clock.Advance(10 * FrameInterval)
+	flush()
+	test.AssertEquals(t, 1, count)
+
+	clock.Advance(FrameInterval)
+	flush()
+	test.AssertEquals(t, 2, count)
+}
+
+func TestDriverTimersStopAll(t *testing.T) {
+	timers, clock, flush := testTimers()
+	count := 0
+	timers.Every(100*time.Millisecond, func() { count++ })
+	timers.After(100*time.Millisecond, func() { count++ })
+
+	timers.StopAll()
+	timer := timers.After(100*time.Millisecond, func() { count++ })
+	clock.Advance(time.Second)
+	flush()
+	test.AssertEquals(t, 0, count)
+	test.AssertEquals(t, false, timer.Stop())
+}
@@ -22,7 +22,7 @@ type toolTipTracker struct {
 
 type ToolTipController struct {
 	driver        Driver
-	timer         *time.Timer
+	timer         Timer
 	bubbleOverlay BubbleOverlay
 	trackers      []*toolTipTracker
 	showing       *toolTipTracker
@@ -34,8 +34,9 @@ func (c *ToolTipController) beginTimer(tracker *toolTipTracker, timeout time.Dur
 		c.timer = nil
 	}
 	if timeout > 0 {
-		c.timer = time.AfterFunc(timeout, func() {
-			c.driver.Call(func() { c.showToolTipForTracker(tracker) })
+		c.timer = c.driver.After(timeout, func() {
+			c.timer = nil
+			c.showToolTipForTracker(tracker)
 		})
 	} else {
 		c.showToolTipForTracker(tracker)
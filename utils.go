@@ -79,7 +79,7 @@ func TopControlsUnder(p math.Point, c Parent) ControlPointList {
 	children := c.Children()
 	for i := len(children) - 1; i >= 0; i-- {
 		child := children[i]
-		cp := p.Sub(child.Offset)
+		cp := child.ToChild(p)
 		if child.Control.ContainsPoint(cp) {
 			l := ControlPointList{ControlPoint{child.Control, cp}}
 			if cc, ok := child.Control.(Parent); ok {
@@ -99,7 +99,7 @@ func ControlsUnder(p math.Point, c Parent) ControlPointList {
 		p = toVisit[0].P
 		toVisit = toVisit[1:]
 		for _, child := range c.Children() {
-			cp := p.Sub(child.Offset)
+			cp := child.ToChild(p)
 			if child.Control.ContainsPoint(cp) {
 				l = append(l, ControlPoint{child.Control, cp})
 				if cc, ok := child.Control.(Parent); ok {
@@ -123,7 +123,7 @@ func WindowToChild(coord math.Point, to Control) math.Point {
 			Dump(p)
 			panic(fmt.Errorf("Control's parent (%p %T) did not contain control (%p %T).", p, p, c, c))
 		}
-		coord = coord.Sub(child.Offset)
+		coord = child.ToChild(coord)
 		if _, ok := p.(Window); ok {
 			return coord
 		}
@@ -143,7 +143,7 @@ func ChildToParent(coord math.Point, from Control, to Parent) math.Point {
 			Dump(p)
 			panic(fmt.Errorf("Control's parent (%p %T) did not contain control (%p %T).", p, p, c, c))
 		}
-		coord = coord.Add(child.Offset)
+		coord = child.ToParent(coord)
 		if p == to {
 			return coord
 		}
@@ -159,7 +159,37 @@ func ChildToParent(coord math.Point, from Control, to Parent) math.Point {
 }
 
 func ParentToChild(coord math.Point, from Parent, to Control) math.Point {
-	return coord.Sub(ChildToParent(math.ZeroPoint, to, from))
+	// Gather the children from to up to from, then apply the transforms in
+	// reverse order.
+	chain := []*Child{}
+	c := to
+	for {
+		p := c.Parent()
+		if p == nil {
+			panic(fmt.Errorf("Control detached: %s", Path(c)))
+		}
+		child := p.Children().Find(c)
+		if child == nil {
+			Dump(p)
+			panic(fmt.Errorf("Control's parent (%p %T) did not contain control (%p %T).", p, p, c, c))
+		}
+		chain = append(chain, child)
+		if p == from {
+			break
+		}
+
+		if control, ok := p.(Control); ok {
+			c = control
+		} else {
+			Dump(p)
+			panic(fmt.Errorf("ParentToChild (%p %T) -> (%p %T) reached non-control parent (%p %T).",
+				from, from, to, to, p, p))
+		}
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		coord = chain[i].ToChild(coord)
+	}
+	return coord
 }
 
 func TransformCoordinate(coord math.Point, from, to Control) math.Point {
@@ -236,3 +266,13 @@ func RuneArrayToString(arr []rune) string {
 	}
 	return string(enc)
 }
+
+// HashRunes returns the FNV-1a hash of runes. It is used to find lines whose
+// runes have not changed since they were last processed.
+func HashRunes(runes []rune) uint64 {
+	h := uint64(14695981039346656037)
+	for _, r := range runes {
+		h = (h ^ uint64(r)) * 1099511628211
+	}
+	return h
+}